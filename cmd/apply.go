@@ -2,28 +2,53 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"reflect"
+	"sort"
 	"strings"
 	"syscall"
 
 	"github.com/fatih/color"
 	"github.com/myzkey/gh-repo-settings/internal/config"
 	"github.com/myzkey/gh-repo-settings/internal/diff"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 	"github.com/myzkey/gh-repo-settings/internal/infra/github"
 	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
 	"github.com/spf13/cobra"
 )
 
 var (
-	applyDir          string
-	applyConfig       string
-	autoApprove       bool
-	applyCheckSecrets bool
-	applyCheckEnv     bool
-	applySyncDelete   bool
+	applyDir                []string
+	applyConfig             string
+	autoApprove             bool
+	applyCheckSecrets       bool
+	applyCheckEnv           bool
+	applySyncDelete         bool
+	applyCreate             bool
+	applyContinue           bool
+	applyStdin              bool
+	applyEnvName            string
+	applyNoState            bool
+	applyForceSocialPreview bool
+	applyVerify             bool
+	applyValidateSchema     bool
+	applyVerifyAfter        bool
+	applyStrict             bool
+	applyInteractive        bool
+	applyInteractiveBy      string
+	applyAllowDisruptive    bool
+	applyConcurrency        int
+	applyRequirePlanHash    string
+	applyShowPayloads       bool
+	applyLabelRecreate      bool
+	applyBackup             string
+	applyPruneLabels        bool
+	applyJSON               bool
 )
 
 var applyCmd = &cobra.Command{
@@ -35,12 +60,40 @@ var applyCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(applyCmd)
-	applyCmd.Flags().StringVarP(&applyDir, "dir", "d", "", "Config directory")
-	applyCmd.Flags().StringVarP(&applyConfig, "config", "c", "", "Config file path")
+	applyCmd.Flags().StringArrayVarP(&applyDir, "dir", "d", nil, "Config directory; repeat to merge several in order, later ones winning (with --config, they override the file)")
+	applyCmd.Flags().StringVarP(&applyConfig, "config", "c", "", "Config file path, or gh://owner/repo/path@ref")
 	applyCmd.Flags().BoolVarP(&autoApprove, "yes", "y", false, "Auto-approve changes")
 	applyCmd.Flags().BoolVar(&applyCheckSecrets, "secrets", false, "Apply secrets from .env file")
 	applyCmd.Flags().BoolVar(&applyCheckEnv, "env", false, "Apply environment variables")
 	applyCmd.Flags().BoolVar(&applySyncDelete, "sync", false, "Delete variables/secrets not in config")
+	applyCmd.Flags().BoolVar(&applyPruneLabels, "prune-labels", false, "Delete labels not in config for this run, as if labels.replace_default were set (asks first unless --yes)")
+	applyCmd.Flags().BoolVar(&applyCreate, "create", false, "Create the repository if it does not exist")
+	applyCmd.Flags().BoolVar(&applyContinue, "continue-on-error", false, "Skip protection for branches that don't exist, and with --verify changes whose setting changed since the plan, instead of aborting (failed API calls still abort)")
+	applyCmd.Flags().StringVar(&applyEnvName, "environment", "", "Overlay .github/.env.<environment> on .github/.env (default: $ENV)")
+	applyCmd.Flags().BoolVar(&applyNoState, "no-state", false, "Don't read or write the local secret hash state file")
+	applyCmd.Flags().BoolVar(&applyForceSocialPreview, "force-social-preview", false, "Upload repo.social_preview_image (the current image can't be compared)")
+	applyCmd.Flags().BoolVar(&applyVerify, "verify", false, "Re-read current settings before applying and abort if they changed since the plan (skip with --continue-on-error)")
+	applyCmd.Flags().BoolVar(&applyVerifyAfter, "verify-after", false, "Re-plan after applying and fail if any changes remain")
+	applyCmd.Flags().BoolVar(&applyStrict, "strict", false, "Fail when the token may not read a section or the repository was renamed or transferred, instead of warning")
+	applyCmd.Flags().BoolVar(&applyValidateSchema, "validate-schema", false, "Validate the config against the JSON Schema before applying")
+	applyCmd.Flags().BoolVar(&applyStdin, "config-stdin", false, "Read YAML config from stdin (requires --yes)")
+	applyCmd.Flags().StringVar(&applyRequirePlanHash, "require-plan-hash", "", "Only apply if the plan matches this hash from plan --print-hash")
+	applyCmd.Flags().BoolVarP(&applyInteractive, "interactive", "i", false, "Ask to apply, skip or quit for each change; quitting applies nothing")
+	applyCmd.Flags().StringVar(&applyInteractiveBy, "interactive-by", interactiveByChange, "What --interactive asks about: change or category")
+	applyCmd.Flags().BoolVar(&applyAllowDisruptive, "allow-disruptive", false, "Apply changes that may be disruptive (e.g. switching the Pages build type) without asking")
+	applyCmd.Flags().BoolVar(&applyShowPayloads, "show-payloads", false, "Print the request each change would send, with its JSON body, without sending anything (secret values are never shown)")
+	for _, flag := range []string{"interactive", "create", "verify", "verify-after"} {
+		applyCmd.MarkFlagsMutuallyExclusive("show-payloads", flag)
+	}
+	applyCmd.Flags().BoolVar(&applyLabelRecreate, "allow-label-recreate", false, "Delete and recreate a label when GitHub rejects updating it (the label is removed from its issues and pull requests)")
+	applyCmd.Flags().StringVar(&applyBackup, "backup", "", "Write the current settings to this file (YAML, or JSON for .json) or directory before applying; apply it to restore them")
+	applyCmd.MarkFlagsMutuallyExclusive("backup", "show-payloads")
+	applyCmd.Flags().BoolVar(&applyJSON, "json", false, "After applying, print a JSON summary of each change's outcome with applied, failed and skipped counts; progress goes to stderr (requires --yes)")
+	applyCmd.MarkFlagsMutuallyExclusive("json", "interactive")
+	applyCmd.MarkFlagsMutuallyExclusive("json", "show-payloads")
+	applyCmd.Flags().IntVar(&applyConcurrency, "concurrency", 1, "Apply up to this many label and branch protection changes at once (changes to the same label or branch never overlap)")
+	applyCmd.MarkFlagsMutuallyExclusive("config-stdin", "dir")
+	applyCmd.MarkFlagsMutuallyExclusive("config-stdin", "config")
 }
 
 func runApply(cmd *cobra.Command, args []string) error {
@@ -59,16 +112,88 @@ func runApply(cmd *cobra.Command, args []string) error {
 
 	logger.Debug("Starting apply command")
 
-	client, err := github.NewClientWithContext(ctx, repo)
+	// stdin carries the config, so the confirmation prompt can't read from it
+	if applyStdin && !autoApprove {
+		return fmt.Errorf("--config-stdin requires --yes")
+	}
+	// The summary is the only output on stdout, so nothing may prompt there
+	if applyJSON && !autoApprove {
+		return fmt.Errorf("--json requires --yes")
+	}
+	if applyConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if applyInteractiveBy != interactiveByChange && applyInteractiveBy != interactiveByCategory {
+		return fmt.Errorf("invalid --interactive-by %q (valid: %s, %s)", applyInteractiveBy, interactiveByChange, interactiveByCategory)
+	}
+	if cmd.Flags().Changed("interactive-by") && !applyInteractive {
+		return fmt.Errorf("--interactive-by requires --interactive")
+	}
+
+	if err := checkMatchFlags(false, applyStdin); err != nil {
+		return err
+	}
+	if applyBackup != "" && (org != "" || repoMatch != "") {
+		return fmt.Errorf("--backup only works with a single repository and can't be combined with --org or --match")
+	}
+	if applyJSON && (org != "" || repoMatch != "") {
+		return fmt.Errorf("--json only works with a single repository and can't be combined with --org or --match")
+	}
+
+	// Suppress log output in JSON mode
+	if applyJSON {
+		logger.SetDefaultLevel(logger.LevelQuiet)
+	}
+	if org != "" && repoMatch == "" {
+		return runOrgApply(ctx, config.LoadOptions{
+			Dir:    applyDir,
+			Config: applyConfig,
+			Stdin:  configStdin(applyStdin),
+
+			ValidateSchema: applyValidateSchema,
+			WarnConflicts:  warnConflicts,
+
+			Context:     ctx,
+			FetchGitHub: configSources(),
+		}, cmd.OutOrStdout())
+	}
+
+	if repoMatch != "" {
+		_, err := forEachMatchingRepo(ctx, func(ctx context.Context, repoArg string) (int, error) {
+			return 0, applyRepo(ctx, repoArg, cmd.OutOrStdout(), cmd.ErrOrStderr())
+		})
+		return err
+	}
+	return applyRepo(ctx, repo, cmd.OutOrStdout(), cmd.ErrOrStderr())
+}
+
+// applyRepo applies the configuration to repoArg. Progress is printed to stdout,
+// or to stderr with --json so that stdout holds only the summary.
+func applyRepo(ctx context.Context, repoArg string, stdout, stderr io.Writer) error {
+	client, err := newRepoClient(ctx, repoArg)
 	if err != nil {
 		return err
 	}
 
 	logger.Debug("Connected to repository: %s/%s", client.RepoOwner(), client.RepoName())
 
+	progress := stdout
+	var report *applyReport
+	if applyJSON {
+		report = newApplyReport()
+		progress = stderr
+	}
+
 	cfg, err := config.Load(config.LoadOptions{
 		Dir:    applyDir,
 		Config: applyConfig,
+		Stdin:  configStdin(applyStdin),
+
+		ValidateSchema: applyValidateSchema,
+		WarnConflicts:  warnConflicts,
+
+		Context:     ctx,
+		FetchGitHub: configSources(),
 	})
 	if err != nil {
 		return err
@@ -76,6 +201,20 @@ func runApply(cmd *cobra.Command, args []string) error {
 
 	logger.Debug("Loaded configuration")
 
+	if applyCreate {
+		if err := ensureRepoExists(ctx, client, cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := checkRepoLocation(ctx, client, applyStrict); err != nil {
+		return err
+	}
+
+	if err := checkArchived(ctx, client, cfg); err != nil {
+		return err
+	}
+
 	// Load .env file for variables/secrets values
 	configPath := applyConfig
 	if configPath == "" {
@@ -88,7 +227,7 @@ func runApply(cmd *cobra.Command, args []string) error {
 	if cfg.Env != nil && cfg.Env.Provider != nil {
 		// Collect keys to filter (empty means all keys)
 		var keysToLoad []string
-		keysToLoad = append(keysToLoad, cfg.Env.Secrets...)
+		keysToLoad = append(keysToLoad, config.SecretNames(cfg.Env.Secrets)...)
 
 		var err error
 		providerResult, err = config.LoadFromProvider(ctx, cfg.Env.Provider, keysToLoad, configPath)
@@ -98,7 +237,7 @@ func runApply(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load .env file
-	dotEnvValues, err := config.LoadDotEnv(configPath)
+	dotEnvValues, err := config.LoadDotEnvForEnvironment(configPath, dotEnvEnvironment(applyEnvName))
 	if err != nil {
 		logger.Debug("Failed to load .env file: %v", err)
 	}
@@ -108,144 +247,511 @@ func runApply(cmd *cobra.Command, args []string) error {
 		dotEnvValues.Merge(&config.DotEnvValues{Values: providerResult.Values})
 	}
 
+	secretState := loadSecretState(configPath, applyCheckSecrets && !applyNoState)
+
 	logger.Info("Applying changes to %s/%s...\n", client.RepoOwner(), client.RepoName())
 
+	sp := newStderrSpinner(applyJSON)
 	calculator := diff.NewCalculatorWithEnv(client, cfg, dotEnvValues)
-	plan, err := calculator.CalculateWithOptions(ctx, diff.CalculateOptions{
-		CheckSecrets: applyCheckSecrets,
-		CheckEnv:     applyCheckEnv,
-		SyncDelete:   applySyncDelete,
-	})
+	calcOpts := diff.CalculateOptions{
+		CheckSecrets:       applyCheckSecrets,
+		CheckEnv:           applyCheckEnv,
+		SyncDelete:         applySyncDelete,
+		PruneLabels:        applyPruneLabels,
+		SecretState:        secretState,
+		ForceSocialPreview: applyForceSocialPreview,
+		SkipForbidden:      !applyStrict,
+	}
+	// Only the initial plan reports progress; later recalculations print their own output
+	planOpts := calcOpts
+	planOpts.Progress = sp.Progress
+	plan, err := calculator.CalculateWithOptions(ctx, planOpts)
+	if err != nil {
+		sp.Stop()
+		return err
+	}
+
+	// Sections that couldn't be read have nothing to apply
+	if plan.HasUnreadable() {
+		sp.Stop()
+		warnUnreadable(plan)
+		plan = plan.WithoutUnreadable()
+	}
+
+	// The summary also lists changes dropped from here on as skipped
+	planned := plan
+
+	sp.Update("Checking protected branches exist…")
+	plan, err = approvedPlan(ctx, client, plan, applyRequirePlanHash, applyContinue)
+	sp.Stop()
 	if err != nil {
 		return err
 	}
 
 	if !plan.HasChanges() {
 		logger.Success("No changes to apply. Repository is up to date.")
+		if report != nil {
+			return writeApplySummary(stdout, report, planned)
+		}
 		return nil
 	}
 
 	// Check for missing secrets/env before proceeding
 	if plan.HasMissingSecrets() || plan.HasMissingVariables() {
-		_ = printPlanWithOptions(plan, false)
+		_ = renderPlan(progress, plan, false, colorEnabled(progress))
 		return fmt.Errorf("cannot apply: required secrets or environment variables are missing")
 	}
 
-	_ = printPlanWithOptions(plan, false)
+	_ = renderPlan(progress, plan, false, colorEnabled(progress))
+
+	if applyShowPayloads {
+		return previewPayloads(ctx, client, cfg, plan, dotEnvValues)
+	}
 
-	if !autoApprove {
-		fmt.Print("Do you want to apply these changes? (yes/no): ")
-		var answer string
-		if _, err := fmt.Scanln(&answer); err != nil {
+	// --yes approves everything, so --interactive doesn't ask
+	var skippedCategories []diff.ChangeCategory
+	if applyInteractive && !autoApprove && applyInteractiveBy == interactiveByCategory {
+		selected, skipped, err := selectCategories(plan, surveyCategoryPrompt)
+		if err != nil {
+			return fmt.Errorf("failed to read user input: %w", err)
+		}
+		if !selected.HasChanges() {
+			logger.Info("No changes selected.")
+			return nil
+		}
+		plan, skippedCategories = selected, skipped
+	} else if applyInteractive && !autoApprove {
+		selected, ok, err := selectChanges(plan, surveyChangePrompt)
+		if err != nil {
 			return fmt.Errorf("failed to read user input: %w", err)
 		}
-		if answer != "yes" && answer != "y" {
+		if !ok {
+			logger.Info("Apply cancelled.")
+			return nil
+		}
+		if !selected.HasChanges() {
+			logger.Info("No changes selected.")
+			return nil
+		}
+		plan = selected
+	} else if !autoApprove {
+		ok, err := askYesNo("Do you want to apply these changes?")
+		if err != nil {
+			return err
+		}
+		if !ok {
 			logger.Info("Apply cancelled.")
 			return nil
 		}
 	}
 
-	fmt.Println()
+	if ok, err := confirmDisruptive(plan, applyAllowDisruptive, autoApprove, askYesNo); err != nil {
+		return err
+	} else if !ok {
+		logger.Info("Apply cancelled.")
+		return nil
+	}
+
+	if applyPruneLabels && !autoApprove {
+		if ok, err := confirmPruneLabels(plan, askYesNo); err != nil {
+			return err
+		} else if !ok {
+			logger.Info("Apply cancelled.")
+			return nil
+		}
+	}
+
+	if applyBackup != "" {
+		if err := writeBackup(ctx, client, cfg, applyBackup, applyCheckSecrets || applyCheckEnv); err != nil {
+			return fmt.Errorf("failed to back up current settings: %w", err)
+		}
+	}
+
+	fmt.Fprintln(progress)
 	logger.Info("Applying changes...")
-	fmt.Println()
+	fmt.Fprintln(progress)
+
+	var verifier *changeVerifier
+	if applyVerify {
+		verifier = newChangeVerifier(calculator, calcOpts, applyContinue)
+	}
+
+	applyErr := applyChangesWithReport(ctx, client, cfg, plan, dotEnvValues, secretState, verifier, progress, report)
+	if len(skippedCategories) > 0 {
+		logger.Info("Skipped: %s", joinCategories(skippedCategories))
+	}
 
-	return applyChanges(ctx, client, cfg, plan, dotEnvValues)
+	// Persist hashes for the secrets that were set, even after a partial failure
+	if secretState != nil {
+		if err := secretState.Save(configPath); err != nil {
+			logger.Warn("Failed to save state file: %v", err)
+		}
+	}
+
+	if report != nil {
+		if err := writeApplySummary(stdout, report, planned); err != nil {
+			return err
+		}
+	}
+
+	if applyErr != nil {
+		return applyErr
+	}
+	fmt.Fprintln(progress)
+	logger.Success("Apply complete!")
+
+	if applyVerifyAfter {
+		return verifyConverged(ctx, calculator, calcOpts, progress)
+	}
+	return nil
+}
+
+// askYesNo asks question on the terminal and reports whether the answer was yes
+func askYesNo(question string) (bool, error) {
+	fmt.Printf("%s (yes/no): ", question)
+	var answer string
+	if _, err := fmt.Scanln(&answer); err != nil {
+		return false, fmt.Errorf("failed to read user input: %w", err)
+	}
+	return answer == "yes" || answer == "y", nil
+}
+
+// confirmDisruptive asks once more before applying changes that may be disruptive, unless
+// allowed is set. With --yes nobody can be asked, so they are an error unless allowed.
+func confirmDisruptive(plan *diff.Plan, allowed, autoApproved bool, ask func(question string) (bool, error)) (bool, error) {
+	disruptive := plan.Disruptive()
+	if disruptive.IsEmpty() || allowed {
+		return true, nil
+	}
+
+	keys := make([]string, 0, disruptive.Size())
+	for _, c := range disruptive.Changes() {
+		keys = append(keys, fmt.Sprintf("%s.%s", c.Category, c.Key))
+	}
+	if autoApproved {
+		return false, fmt.Errorf("%s may be disruptive; pass --allow-disruptive to apply with --yes", strings.Join(keys, ", "))
+	}
+
+	for _, c := range disruptive.Changes() {
+		logger.Warn("%s.%s: %s", c.Category, c.Key, c.Warning)
+	}
+	return ask(fmt.Sprintf("Apply %s anyway?", strings.Join(keys, ", ")))
+}
+
+// confirmPruneLabels asks once more before deleting the labels that --prune-labels added to the plan
+func confirmPruneLabels(plan *diff.Plan, ask func(question string) (bool, error)) (bool, error) {
+	deletes := plan.Filter(func(c diff.Change) bool {
+		return c.Category == diff.CategoryLabels && c.Type == diff.ChangeDelete
+	})
+	if deletes.IsEmpty() {
+		return true, nil
+	}
+
+	names := make([]string, 0, deletes.Size())
+	for _, c := range deletes.Changes() {
+		names = append(names, c.Key)
+	}
+	return ask(fmt.Sprintf("Delete %d label(s) not in the config (%s)? They are removed from their issues and pull requests", len(names), strings.Join(names, ", ")))
+}
+
+// approvedPlan checks plan against the approved hash, when one is given, and then drops
+// the changes to protected branches that don't exist (see skipMissingBranches). The hash
+// covers the plan as computed, which is what plan --print-hash prints.
+func approvedPlan(ctx context.Context, client github.GitHubClient, plan *diff.Plan, approvedHash string, continueOnError bool) (*diff.Plan, error) {
+	if approvedHash != "" {
+		if err := checkPlanHash(plan, approvedHash); err != nil {
+			return nil, err
+		}
+	}
+	// Branch protection can't be applied to branches that don't exist
+	return skipMissingBranches(ctx, client, plan, continueOnError)
+}
+
+// checkPlanHash returns an error wrapping ErrPlanConflict unless plan has the approved hash
+func checkPlanHash(plan *diff.Plan, approved string) error {
+	if hash := plan.Hash(); !strings.EqualFold(hash, strings.TrimSpace(approved)) {
+		return fmt.Errorf("%w: plan hash is %s, expected %s; run plan again and review the changes",
+			apperrors.ErrPlanConflict, hash, approved)
+	}
+	return nil
+}
+
+// skipMissingBranches checks that every branch with pending protection changes exists.
+// Missing branches are an error unless continueOnError is set, in which case their
+// changes are dropped from the returned plan.
+func skipMissingBranches(ctx context.Context, client github.GitHubClient, plan *diff.Plan, continueOnError bool) (*diff.Plan, error) {
+	missing, err := missingProtectedBranches(ctx, client, plan)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) == 0 {
+		return plan, nil
+	}
+
+	missingSet := make(map[string]bool, len(missing))
+	for _, branch := range missing {
+		if !continueOnError {
+			return nil, fmt.Errorf("branch '%s' does not exist; cannot apply protection", branch)
+		}
+		logger.Warn("branch '%s' does not exist; skipping protection", branch)
+		missingSet[branch] = true
+	}
+
+	return plan.Filter(func(c diff.Change) bool {
+		return c.Category != diff.CategoryBranchProtection || !missingSet[c.Branch]
+	}), nil
+}
+
+// missingProtectedBranches returns branches with branch protection changes that don't exist on GitHub
+func missingProtectedBranches(ctx context.Context, client github.GitHubClient, plan *diff.Plan) ([]string, error) {
+	var missing []string
+	checked := make(map[string]bool)
+	for _, change := range plan.FilterByCategory(diff.CategoryBranchProtection).Changes() {
+		branch := change.Branch
+		if checked[branch] {
+			continue
+		}
+		checked[branch] = true
+
+		exists, err := client.BranchExists(ctx, branch)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			missing = append(missing, branch)
+		}
+	}
+	return missing, nil
+}
+
+// ensureRepoExists creates the target repository if GitHub reports it as missing
+func ensureRepoExists(ctx context.Context, client github.GitHubClient, cfg *config.Config) error {
+	_, err := client.GetRepo(ctx)
+	if err == nil {
+		return nil
+	}
+	if !apperrors.Is(err, apperrors.ErrRepoNotFound) {
+		return err
+	}
+
+	logger.Info("Repository %s/%s does not exist, creating it...", client.RepoOwner(), client.RepoName())
+	if err := client.CreateRepo(ctx, client.RepoOwner(), client.RepoName(), cfg.Repo); err != nil {
+		return fmt.Errorf("failed to create repository %s/%s: %w", client.RepoOwner(), client.RepoName(), err)
+	}
+	logger.Success("Created repository %s/%s", client.RepoOwner(), client.RepoName())
+	return nil
+}
+
+// checkRepoLocation warns when the repository was renamed or transferred, since requests
+// then reach its new location; with strict it fails instead
+func checkRepoLocation(ctx context.Context, client github.GitHubClient, strict bool) error {
+	err := github.CheckRepoLocation(ctx, client)
+	var moved *apperrors.RepoMovedError
+	if !apperrors.As(err, &moved) {
+		return err
+	}
+	if strict {
+		return fmt.Errorf("%w; point --repo at %s", err, moved.Actual)
+	}
+	logger.Warn("%v; continuing with %s", err, moved.Actual)
+	return nil
+}
+
+// checkArchived refuses to apply to an archived (read-only) repository
+// unless the config unarchives it with repo.archived: false
+func checkArchived(ctx context.Context, client github.GitHubClient, cfg *config.Config) error {
+	repoData, err := client.GetRepo(ctx)
+	if err != nil {
+		return err
+	}
+	if !repoData.Archived {
+		return nil
+	}
+	if cfg.Repo != nil && cfg.Repo.Archived != nil && !*cfg.Repo.Archived {
+		logger.Info("Repository is archived; it will be unarchived before other changes are applied")
+		return nil
+	}
+	return fmt.Errorf("repository is archived; unarchive before applying (or set repo.archived: false)")
+}
+
+// splitArchiveChange separates the "archived" change from the other repo changes,
+// since it has to be applied before (unarchive) or after (archive) everything else
+func splitArchiveChange(changes []diff.Change) (archive *diff.Change, rest []diff.Change) {
+	for i := range changes {
+		if changes[i].Key == "archived" {
+			archive = &changes[i]
+			continue
+		}
+		rest = append(rest, changes[i])
+	}
+	return archive, rest
 }
 
-func applyChanges(ctx context.Context, client *github.Client, cfg *config.Config, plan *diff.Plan, dotEnvValues *config.DotEnvValues) error {
+// applyArchived archives or unarchives the repository
+func applyArchived(ctx context.Context, client github.GitHubClient, archived bool, w io.Writer, green, red func(a ...interface{}) string) error {
+	if archived {
+		fmt.Fprint(w, "  Archiving repository... ")
+	} else {
+		fmt.Fprint(w, "  Unarchiving repository... ")
+	}
+	if err := client.UpdateRepo(ctx, map[string]interface{}{"archived": archived}); err != nil {
+		fmt.Fprintln(w, red("✗"))
+		return describeApplyError(err, "failed to update archived state")
+	}
+	fmt.Fprintln(w, green("✓"))
+	return nil
+}
+
+func applyChanges(ctx context.Context, client github.GitHubClient, cfg *config.Config, plan *diff.Plan, dotEnvValues *config.DotEnvValues, secretState *config.State, verifier *changeVerifier) error {
+	return applyChangesWithReport(ctx, client, cfg, plan, dotEnvValues, secretState, verifier, os.Stdout, nil)
+}
+
+// applyChangesWithReport applies plan like applyChanges, printing progress to w,
+// and records the outcome of each change in report
+func applyChangesWithReport(ctx context.Context, client github.GitHubClient, cfg *config.Config, plan *diff.Plan, dotEnvValues *config.DotEnvValues, secretState *config.State, verifier *changeVerifier, w io.Writer, report *applyReport) error {
 	green := color.New(color.FgGreen).SprintFunc()
 	red := color.New(color.FgRed).SprintFunc()
 
-	// Group changes by category
-	repoChanges := make(map[string]interface{})
-	var topicsChanged bool
-	var labelChanges []diff.Change
-	branchProtectionChanges := make(map[string][]diff.Change)
-	var actionsChanges []diff.Change
-	var pagesChanges []diff.Change
-	var variableChanges []diff.Change
-	var secretChanges []diff.Change
+	groups := groupApplyChanges(plan)
+	repoChanges := groups.repo
+	topicsChanges := groups.topics
+	labelChanges := groups.labels
+	branchProtectionChanges := groups.branchProtection
+	actionsChanges := groups.actions
+	pagesChanges := groups.pages
+	socialPreviewChanges := groups.socialPreview
+	templateChanges := groups.templates
+	variableChanges := groups.variables
+	secretChanges := groups.secrets
+
+	// With --verify, each group of changes is checked against current state right before it is applied
+	var err error
 
-	for _, change := range plan.Changes() {
-		switch change.Category {
-		case "repo":
-			repoChanges[change.Key] = change.New
-		case "topics":
-			topicsChanged = true
-		case "labels":
-			labelChanges = append(labelChanges, change)
-		case "branch_protection":
-			// Extract branch name from key (format: "branch.setting")
-			branchName := extractBranchName(change.Key)
-			branchProtectionChanges[branchName] = append(branchProtectionChanges[branchName], change)
-		case "actions":
-			actionsChanges = append(actionsChanges, change)
-		case "pages":
-			pagesChanges = append(pagesChanges, change)
-		case "variables":
-			variableChanges = append(variableChanges, change)
-		case "secrets":
-			secretChanges = append(secretChanges, change)
+	// Apply repo changes
+	if repoChanges, err = verifier.filter(ctx, repoChanges); err != nil {
+		return err
+	}
+	archiveChange, repoChanges := splitArchiveChange(repoChanges)
+
+	// An archived repository rejects every other change, so unarchive first
+	if archiveChange != nil && archiveChange.New == false {
+		err := applyArchived(ctx, client, false, w, green, red)
+		report.record([]diff.Change{*archiveChange}, err)
+		if err != nil {
+			return err
 		}
 	}
 
-	// Apply repo changes
 	if len(repoChanges) > 0 {
-		fmt.Print("  Updating repository settings... ")
-		if err := client.UpdateRepo(ctx, repoChanges); err != nil {
-			fmt.Println(red("✗"))
-			return fmt.Errorf("failed to update repo: %w", err)
+		settings := make(map[string]interface{}, len(repoChanges))
+		for _, change := range repoChanges {
+			settings[change.Key] = change.New
 		}
-		fmt.Println(green("✓"))
+		fmt.Fprint(w, "  Updating repository settings... ")
+		if err := client.UpdateRepo(ctx, settings); err != nil {
+			fmt.Fprintln(w, red("✗"))
+			if _, ok := settings["is_template"]; ok {
+				// GitHub refuses to make some repositories templates; its message tells why
+				err = fmt.Errorf("failed to update repo settings including is_template: %w", err)
+			} else {
+				err = describeApplyError(err, "failed to update repo")
+			}
+			report.record(repoChanges, err)
+			return err
+		}
+		fmt.Fprintln(w, green("✓"))
+		report.record(repoChanges, nil)
 	}
 
 	// Apply topics
-	if topicsChanged {
-		fmt.Print("  Updating topics... ")
+	if topicsChanges, err = verifier.filter(ctx, topicsChanges); err != nil {
+		return err
+	}
+	if len(topicsChanges) > 0 {
+		fmt.Fprint(w, "  Updating topics... ")
 		if err := client.SetTopics(ctx, cfg.Topics); err != nil {
-			fmt.Println(red("✗"))
-			return fmt.Errorf("failed to update topics: %w", err)
+			fmt.Fprintln(w, red("✗"))
+			err = describeApplyError(err, "failed to update topics")
+			report.record(topicsChanges, err)
+			return err
 		}
-		fmt.Println(green("✓"))
+		fmt.Fprintln(w, green("✓"))
+		report.record(topicsChanges, nil)
 	}
 
 	// Apply label changes
-	for _, change := range labelChanges {
+	if labelChanges, err = verifier.filter(ctx, labelChanges); err != nil {
+		return err
+	}
+	steps := newStepPrinter(w, applyConcurrency, green, red)
+	err = runConcurrently(len(labelChanges), applyConcurrency, func(i int) error {
+		change := labelChanges[i]
+		var err error
 		switch change.Type {
 		case diff.ChangeAdd:
-			fmt.Printf("  Creating label '%s'... ", change.Key)
 			label := findLabel(cfg.Labels.Items, change.Key)
-			if err := client.CreateLabel(ctx, label.Name, label.Color, label.Description); err != nil {
-				fmt.Println(red("✗"))
-				return fmt.Errorf("failed to create label %s: %w", change.Key, err)
+			if err = steps.run(fmt.Sprintf("Creating label '%s'", change.Key), func() error {
+				return client.CreateLabel(ctx, label.Name, label.Color, ptrStringVal(label.Description))
+			}); err != nil {
+				err = describeApplyError(err, "failed to create label %s", change.Key)
 			}
-			fmt.Println(green("✓"))
 
 		case diff.ChangeUpdate:
-			fmt.Printf("  Updating label '%s'... ", change.Key)
 			label := findLabel(cfg.Labels.Items, change.Key)
-			if err := client.UpdateLabel(ctx, change.Key, label.Name, label.Color, label.Description); err != nil {
-				fmt.Println(red("✗"))
-				return fmt.Errorf("failed to update label %s: %w", change.Key, err)
+			// The label is addressed by its current name; label.Name renames it if they differ
+			current := change.Key
+			if change.CurrentName != "" {
+				current = change.CurrentName
+			}
+			if err = steps.run(fmt.Sprintf("Updating label '%s'", change.Key), func() error {
+				if !applyLabelRecreate {
+					return client.UpdateLabel(ctx, current, label.Name, label.Color, label.Description)
+				}
+				recreated, err := github.UpdateLabelOrRecreate(ctx, client, current, label.Name, label.Color, label.Description)
+				if recreated {
+					logger.Warn("label '%s' couldn't be updated and was recreated", change.Key)
+				}
+				return err
+			}); err != nil {
+				err = describeApplyError(err, "failed to update label %s", change.Key)
 			}
-			fmt.Println(green("✓"))
 
 		case diff.ChangeDelete:
-			fmt.Printf("  Deleting label '%s'... ", change.Key)
-			if err := client.DeleteLabel(ctx, change.Key); err != nil {
-				fmt.Println(red("✗"))
-				return fmt.Errorf("failed to delete label %s: %w", change.Key, err)
+			if err = steps.run(fmt.Sprintf("Deleting label '%s'", change.Key), func() error {
+				return client.DeleteLabel(ctx, change.Key)
+			}); err != nil {
+				err = describeApplyError(err, "failed to delete label %s", change.Key)
 			}
-			fmt.Println(green("✓"))
 		}
+		report.record([]diff.Change{change}, err)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
-	// Apply branch protection changes
-	for branchName := range branchProtectionChanges {
-		fmt.Printf("  Updating branch protection for '%s'... ", branchName)
-
-		rule := cfg.BranchProtection[branchName]
+	// Apply branch protection changes.
+	// The whole rule is sent, so a conflict on any setting skips the branch.
+	var branches []string
+	for branchName, changes := range branchProtectionChanges {
+		verified, err := verifier.filter(ctx, changes)
+		if err != nil {
+			return err
+		}
+		if len(verified) == len(changes) {
+			branches = append(branches, branchName)
+		}
+	}
+	sort.Strings(branches)
+	err = runConcurrently(len(branches), applyConcurrency, func(i int) error {
+		branchName := branches[i]
+		rule, err := branchRuleKeepingIgnored(ctx, client, cfg, branchName)
+		if err != nil {
+			report.record(branchProtectionChanges[branchName], err)
+			return err
+		}
 		settings := &github.BranchProtectionSettings{
+			RequireReviews:          rule.RequireReviews,
 			RequiredReviews:         rule.RequiredReviews,
 			DismissStaleReviews:     rule.DismissStaleReviews,
 			RequireCodeOwnerReviews: rule.RequireCodeOwner,
@@ -259,52 +765,208 @@ func applyChanges(ctx context.Context, client *github.Client, cfg *config.Config
 			RequireSignedCommits:    rule.RequireSignedCommits,
 		}
 
-		if err := client.UpdateBranchProtection(ctx, branchName, settings); err != nil {
-			fmt.Println(red("✗"))
-			return fmt.Errorf("failed to update branch protection for %s: %w", branchName, err)
+		err = steps.run(fmt.Sprintf("Updating branch protection for '%s'", branchName), func() error {
+			return client.UpdateBranchProtection(ctx, branchName, settings)
+		})
+		if err != nil {
+			err = describeApplyError(err, "failed to update branch protection for %s", branchName)
 		}
-		fmt.Println(green("✓"))
+		report.record(branchProtectionChanges[branchName], err)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
 	// Apply actions changes
+	if actionsChanges, err = verifier.filter(ctx, actionsChanges); err != nil {
+		return err
+	}
 	if len(actionsChanges) > 0 && cfg.Actions != nil {
-		if err := applyActionsChanges(ctx, client, cfg, actionsChanges, green, red); err != nil {
+		err := applyActionsChanges(ctx, client, cfg, actionsChanges, w, green, red)
+		report.record(actionsChanges, err)
+		if err != nil {
 			return err
 		}
 	}
 
 	// Apply pages changes
+	if pagesChanges, err = verifier.filter(ctx, pagesChanges); err != nil {
+		return err
+	}
 	if len(pagesChanges) > 0 && cfg.Pages != nil {
-		if err := applyPagesChanges(ctx, client, cfg, pagesChanges, green, red); err != nil {
+		err := applyPagesChanges(ctx, client, cfg, pagesChanges, w, green, red)
+		report.record(pagesChanges, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Apply template files
+	if templateChanges, err = verifier.filter(ctx, templateChanges); err != nil {
+		return err
+	}
+	if len(templateChanges) > 0 && cfg.Templates != nil {
+		if err := applyTemplateChanges(ctx, client, cfg.Templates, templateChanges, w, green, red, report); err != nil {
+			return err
+		}
+	}
+
+	// Apply social preview image
+	if len(socialPreviewChanges) > 0 && cfg.Repo != nil && cfg.Repo.SocialPreviewImage != nil {
+		fmt.Fprint(w, "  Uploading social preview image... ")
+		if err := applySocialPreview(ctx, client, *cfg.Repo.SocialPreviewImage); err != nil {
+			fmt.Fprintln(w, red("✗"))
+			err = describeApplyError(err, "failed to upload social preview image")
+			report.record(socialPreviewChanges, err)
 			return err
 		}
+		fmt.Fprintln(w, green("✓"))
+		report.record(socialPreviewChanges, nil)
 	}
 
 	// Apply variable changes
+	if variableChanges, err = verifier.filter(ctx, variableChanges); err != nil {
+		return err
+	}
 	if len(variableChanges) > 0 {
-		if err := applyVariableChanges(ctx, client, cfg, dotEnvValues, variableChanges, green, red); err != nil {
+		if err := applyVariableChanges(ctx, client, cfg, dotEnvValues, variableChanges, w, green, red, report); err != nil {
 			return err
 		}
 	}
 
 	// Apply secret changes
+	if secretChanges, err = verifier.filter(ctx, secretChanges); err != nil {
+		return err
+	}
 	if len(secretChanges) > 0 {
-		if err := applySecretChanges(ctx, client, dotEnvValues, secretChanges, green, red); err != nil {
+		if err := applySecretChanges(ctx, client, cfg.Env, dotEnvValues, secretState, secretChanges, w, green, red, report); err != nil {
+			return err
+		}
+	}
+
+	// Archiving makes the repository read-only, so it goes last
+	if archiveChange != nil && archiveChange.New == true {
+		err := applyArchived(ctx, client, true, w, green, red)
+		report.record([]diff.Change{*archiveChange}, err)
+		if err != nil {
 			return err
 		}
 	}
 
+	return nil
+}
+
+// branchRuleKeepingIgnored returns the rule to send for branch. The whole rule is sent,
+// so settings matched by an ignore pattern, e.g. "branch_protection.main.enforce_admins",
+// keep their current value on GitHub instead of taking the config's.
+func branchRuleKeepingIgnored(ctx context.Context, client github.GitHubClient, cfg *config.Config, branch string) (*config.BranchRule, error) {
+	rule := cfg.BranchProtection[branch]
+	ruleValue := reflect.ValueOf(rule).Elem()
+	var ignored []int
+	for i := 0; i < ruleValue.NumField(); i++ {
+		name, _, _ := strings.Cut(ruleValue.Type().Field(i).Tag.Get("yaml"), ",")
+		if cfg.IsIgnored(string(diff.CategoryBranchProtection), branch+"."+name) {
+			ignored = append(ignored, i)
+		}
+	}
+	if len(ignored) == 0 {
+		return rule, nil
+	}
+
+	protection, err := client.GetBranchProtection(ctx, branch)
+	if apperrors.Is(err, apperrors.ErrBranchNotProtected) {
+		// Nothing to keep on a branch that isn't protected yet
+		return rule, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read branch protection for %s: %w", branch, err)
+	}
+
+	kept := *rule
+	keptValue := reflect.ValueOf(&kept).Elem()
+	currentValue := reflect.ValueOf(branchRuleFromProtection(protection)).Elem()
+	for _, i := range ignored {
+		keptValue.Field(i).Set(currentValue.Field(i))
+	}
+	return &kept, nil
+}
+
+// previewPayloads runs the apply steps against a client that only prints the requests they
+// would send. Nothing is changed, so no secret hashes are recorded.
+func previewPayloads(ctx context.Context, client github.GitHubClient, cfg *config.Config, plan *diff.Plan, dotEnvValues *config.DotEnvValues) error {
 	fmt.Println()
-	logger.Success("Apply complete!")
+	logger.Info("Previewing requests; nothing is sent to GitHub...")
+	fmt.Println()
+
+	var requests bytes.Buffer
+	if err := applyChanges(ctx, github.NewPreviewClient(client, &requests), cfg, plan, dotEnvValues, nil, nil); err != nil {
+		return err
+	}
 
+	fmt.Println()
+	fmt.Println("Requests apply would send:")
+	fmt.Println()
+	fmt.Print(requests.String())
 	return nil
 }
 
-func applyActionsChanges(ctx context.Context, client *github.Client, cfg *config.Config, changes []diff.Change, green, red func(a ...interface{}) string) error {
+// applyGroups holds planned changes grouped by the apply step that handles them
+type applyGroups struct {
+	repo             []diff.Change
+	topics           []diff.Change
+	labels           []diff.Change
+	branchProtection map[string][]diff.Change // keyed by branch name
+	actions          []diff.Change
+	pages            []diff.Change
+	socialPreview    []diff.Change
+	templates        []diff.Change
+	variables        []diff.Change
+	secrets          []diff.Change
+}
+
+// groupApplyChanges groups changes by category.
+// Categories apply has no step for (org actions, custom comparators) and
+// informational changes are left out.
+func groupApplyChanges(plan *diff.Plan) applyGroups {
+	groups := applyGroups{branchProtection: make(map[string][]diff.Change)}
+	for _, change := range plan.Changes() {
+		if change.IsInfo() {
+			continue
+		}
+		switch change.Category {
+		case diff.CategoryRepo:
+			groups.repo = append(groups.repo, change)
+		case diff.CategoryTopics:
+			groups.topics = append(groups.topics, change)
+		case diff.CategoryLabels:
+			groups.labels = append(groups.labels, change)
+		case diff.CategoryBranchProtection:
+			groups.branchProtection[change.Branch] = append(groups.branchProtection[change.Branch], change)
+		case diff.CategoryActions:
+			groups.actions = append(groups.actions, change)
+		case diff.CategoryPages:
+			groups.pages = append(groups.pages, change)
+		case diff.CategorySocialPreview:
+			groups.socialPreview = append(groups.socialPreview, change)
+		case diff.CategoryTemplates:
+			groups.templates = append(groups.templates, change)
+		case diff.CategoryVariables:
+			groups.variables = append(groups.variables, change)
+		case diff.CategorySecrets:
+			groups.secrets = append(groups.secrets, change)
+		}
+	}
+	return groups
+}
+
+func applyActionsChanges(ctx context.Context, client github.GitHubClient, cfg *config.Config, changes []diff.Change, w io.Writer, green, red func(a ...interface{}) string) error {
 	// Check which settings need updating
 	needsPermissionsUpdate := false
 	needsSelectedUpdate := false
 	needsWorkflowUpdate := false
+	needsRetentionUpdate := false
+	needsAccessUpdate := false
 
 	for _, change := range changes {
 		switch change.Key {
@@ -314,12 +976,16 @@ func applyActionsChanges(ctx context.Context, client *github.Client, cfg *config
 			needsSelectedUpdate = true
 		case "default_workflow_permissions", "can_approve_pull_request_reviews":
 			needsWorkflowUpdate = true
+		case "artifact_retention_days":
+			needsRetentionUpdate = true
+		case "access_level":
+			needsAccessUpdate = true
 		}
 	}
 
 	// Update actions permissions
 	if needsPermissionsUpdate {
-		fmt.Print("  Updating actions permissions... ")
+		fmt.Fprint(w, "  Updating actions permissions... ")
 		enabled := true
 		if cfg.Actions.Enabled != nil {
 			enabled = *cfg.Actions.Enabled
@@ -329,15 +995,16 @@ func applyActionsChanges(ctx context.Context, client *github.Client, cfg *config
 			allowedActions = *cfg.Actions.AllowedActions
 		}
 		if err := client.UpdateActionsPermissions(ctx, enabled, allowedActions); err != nil {
-			fmt.Println(red("✗"))
-			return fmt.Errorf("failed to update actions permissions: %w", err)
+			fmt.Fprintln(w, red("✗"))
+			return describeApplyError(err, "failed to update actions permissions")
 		}
-		fmt.Println(green("✓"))
+		fmt.Fprintln(w, green("✓"))
 	}
 
-	// Update selected actions
-	if needsSelectedUpdate && cfg.Actions.SelectedActions != nil {
-		fmt.Print("  Updating selected actions... ")
+	// Update selected actions, which GitHub only accepts while allowed_actions is "selected"
+	selectedAllowed := cfg.Actions.AllowedActions == nil || *cfg.Actions.AllowedActions == "selected"
+	if needsSelectedUpdate && cfg.Actions.SelectedActions != nil && selectedAllowed {
+		fmt.Fprint(w, "  Updating selected actions... ")
 		settings := &github.ActionsSelectedData{}
 		if cfg.Actions.SelectedActions.GithubOwnedAllowed != nil {
 			settings.GithubOwnedAllowed = cfg.Actions.SelectedActions.GithubOwnedAllowed
@@ -349,15 +1016,15 @@ func applyActionsChanges(ctx context.Context, client *github.Client, cfg *config
 			settings.PatternsAllowed = &cfg.Actions.SelectedActions.PatternsAllowed
 		}
 		if err := client.UpdateActionsSelectedActions(ctx, settings); err != nil {
-			fmt.Println(red("✗"))
-			return fmt.Errorf("failed to update selected actions: %w", err)
+			fmt.Fprintln(w, red("✗"))
+			return describeApplyError(err, "failed to update selected actions")
 		}
-		fmt.Println(green("✓"))
+		fmt.Fprintln(w, green("✓"))
 	}
 
 	// Update workflow permissions
 	if needsWorkflowUpdate {
-		fmt.Print("  Updating workflow permissions... ")
+		fmt.Fprint(w, "  Updating workflow permissions... ")
 		permissions := "read"
 		if cfg.Actions.DefaultWorkflowPermissions != nil {
 			permissions = *cfg.Actions.DefaultWorkflowPermissions
@@ -367,15 +1034,97 @@ func applyActionsChanges(ctx context.Context, client *github.Client, cfg *config
 			canApprove = *cfg.Actions.CanApprovePullRequestReviews
 		}
 		if err := client.UpdateActionsWorkflowPermissions(ctx, permissions, canApprove); err != nil {
-			fmt.Println(red("✗"))
-			return fmt.Errorf("failed to update workflow permissions: %w", err)
+			fmt.Fprintln(w, red("✗"))
+			return describeApplyError(err, "failed to update workflow permissions")
+		}
+		fmt.Fprintln(w, green("✓"))
+	}
+
+	// Update artifact and log retention
+	if needsRetentionUpdate && cfg.Actions.ArtifactRetentionDays != nil {
+		fmt.Fprint(w, "  Updating artifact and log retention... ")
+		if err := client.UpdateActionsRetention(ctx, *cfg.Actions.ArtifactRetentionDays); err != nil {
+			fmt.Fprintln(w, red("✗"))
+			return describeApplyError(err, "failed to update artifact and log retention")
+		}
+		fmt.Fprintln(w, green("✓"))
+	}
+
+	// Update access level
+	if needsAccessUpdate && cfg.Actions.AccessLevel != nil {
+		fmt.Fprint(w, "  Updating actions access level... ")
+		if err := client.UpdateActionsAccessLevel(ctx, *cfg.Actions.AccessLevel); err != nil {
+			fmt.Fprintln(w, red("✗"))
+			return describeApplyError(err, "failed to update actions access level")
 		}
-		fmt.Println(green("✓"))
+		fmt.Fprintln(w, green("✓"))
+	}
+
+	return nil
+}
+
+// describeApplyError returns err unchanged when it already names the failing
+// resource (e.g. "label 'bug': 422 Validation Failed"), otherwise wraps it with context
+func describeApplyError(err error, format string, args ...interface{}) error {
+	var apiErr *apperrors.APIError
+	if apperrors.As(err, &apiErr) && apiErr.Resource != "" {
+		return err
+	}
+	return fmt.Errorf(format+": %w", append(args, err)...)
+}
+
+// applyTemplateChanges writes changed template files to the repository
+func applyTemplateChanges(ctx context.Context, client github.GitHubClient, cfg *config.TemplatesConfig, changes []diff.Change, w io.Writer, green, red func(a ...interface{}) string, report *applyReport) error {
+	files := make(map[string]config.TemplateFile, len(cfg.Files))
+	for _, f := range cfg.Files {
+		files[f.Path] = f
 	}
 
+	for _, change := range changes {
+		file, ok := files[change.Key]
+		if !ok {
+			continue
+		}
+
+		action := "Creating"
+		if change.Type == diff.ChangeUpdate {
+			action = "Updating"
+		}
+		fmt.Fprintf(w, "  %s file '%s'... ", action, file.Path)
+
+		content, err := file.LoadContent()
+		if err != nil {
+			fmt.Fprintln(w, red("✗"))
+			report.record([]diff.Change{change}, err)
+			return err
+		}
+
+		message := cfg.Message
+		if message == "" {
+			message = fmt.Sprintf("Update %s", file.Path)
+		}
+
+		if err := client.PutFile(ctx, file.Path, content, message); err != nil {
+			fmt.Fprintln(w, red("✗"))
+			err = describeApplyError(err, "failed to write %s", file.Path)
+			report.record([]diff.Change{change}, err)
+			return err
+		}
+		fmt.Fprintln(w, green("✓"))
+		report.record([]diff.Change{change}, nil)
+	}
 	return nil
 }
 
+// applySocialPreview loads the configured image and uploads it
+func applySocialPreview(ctx context.Context, client github.GitHubClient, path string) error {
+	image, err := config.LoadSocialPreviewImage(path)
+	if err != nil {
+		return err
+	}
+	return client.SetSocialPreview(ctx, image)
+}
+
 func findLabel(labels []config.Label, name string) config.Label {
 	for _, l := range labels {
 		if l.Name == name {
@@ -385,29 +1134,32 @@ func findLabel(labels []config.Label, name string) config.Label {
 	return config.Label{}
 }
 
-func extractBranchName(key string) string {
-	// Key format is "branchName.setting"
-	for i, c := range key {
-		if c == '.' {
-			return key[:i]
-		}
-	}
-	return key
-}
-
-func applyPagesChanges(ctx context.Context, client *github.Client, cfg *config.Config, changes []diff.Change, green, red func(a ...interface{}) string) error {
+func applyPagesChanges(ctx context.Context, client github.GitHubClient, cfg *config.Config, changes []diff.Change, w io.Writer, green, red func(a ...interface{}) string) error {
 	// Check if pages needs to be created or updated
 	needsCreate := false
 	needsUpdate := false
+	needsDomainUpdate := false
+	needsHTTPSUpdate := false
 
 	for _, change := range changes {
-		if change.Type == diff.ChangeAdd && change.Key == "pages" {
+		switch {
+		case change.Type == diff.ChangeAdd && change.Key == "pages":
 			needsCreate = true
-		} else {
+		case change.Key == "cname":
+			needsDomainUpdate = true
+		case change.Key == "https_enforced":
+			needsHTTPSUpdate = true
+		default:
 			needsUpdate = true
 		}
 	}
 
+	// A newly created site has no custom domain or HTTPS setting yet
+	if needsCreate {
+		needsDomainUpdate = cfg.Pages.Cname != nil && *cfg.Pages.Cname != ""
+		needsHTTPSUpdate = cfg.Pages.HttpsEnforced != nil
+	}
+
 	buildType := "workflow"
 	if cfg.Pages.BuildType != nil {
 		buildType = *cfg.Pages.BuildType
@@ -425,25 +1177,45 @@ func applyPagesChanges(ctx context.Context, client *github.Client, cfg *config.C
 	}
 
 	if needsCreate {
-		fmt.Print("  Creating GitHub Pages... ")
+		fmt.Fprint(w, "  Creating GitHub Pages... ")
 		if err := client.CreatePages(ctx, buildType, source); err != nil {
-			fmt.Println(red("✗"))
-			return fmt.Errorf("failed to create pages: %w", err)
+			fmt.Fprintln(w, red("✗"))
+			return describeApplyError(err, "failed to create pages")
 		}
-		fmt.Println(green("✓"))
+		fmt.Fprintln(w, green("✓"))
 	} else if needsUpdate {
-		fmt.Print("  Updating GitHub Pages... ")
-		if err := client.UpdatePages(ctx, buildType, source); err != nil {
-			fmt.Println(red("✗"))
-			return fmt.Errorf("failed to update pages: %w", err)
+		fmt.Fprint(w, "  Updating GitHub Pages... ")
+		if err := client.UpdatePages(ctx, buildType, source, nil, nil); err != nil {
+			fmt.Fprintln(w, red("✗"))
+			return describeApplyError(err, "failed to update pages")
+		}
+		fmt.Fprintln(w, green("✓"))
+	}
+
+	// The custom domain is set before HTTPS is enforced, since GitHub only provisions
+	// the certificate once the domain is in place
+	if needsDomainUpdate {
+		fmt.Fprint(w, "  Updating GitHub Pages custom domain... ")
+		if err := client.UpdatePages(ctx, buildType, source, cfg.Pages.Cname, nil); err != nil {
+			fmt.Fprintln(w, red("✗"))
+			return describeApplyError(err, "failed to update pages custom domain")
 		}
-		fmt.Println(green("✓"))
+		fmt.Fprintln(w, green("✓"))
+	}
+
+	if needsHTTPSUpdate {
+		fmt.Fprint(w, "  Updating GitHub Pages HTTPS enforcement... ")
+		if err := client.UpdatePages(ctx, buildType, source, nil, cfg.Pages.HttpsEnforced); err != nil {
+			fmt.Fprintln(w, red("✗"))
+			return describeApplyError(err, "failed to update pages HTTPS enforcement")
+		}
+		fmt.Fprintln(w, green("✓"))
 	}
 
 	return nil
 }
 
-func applyVariableChanges(ctx context.Context, client *github.Client, cfg *config.Config, dotEnvValues *config.DotEnvValues, changes []diff.Change, green, red func(a ...interface{}) string) error {
+func applyVariableChanges(ctx context.Context, client github.GitHubClient, cfg *config.Config, dotEnvValues *config.DotEnvValues, changes []diff.Change, w io.Writer, green, red func(a ...interface{}) string, report *applyReport) error {
 	var errors []string
 	succeeded := 0
 
@@ -454,7 +1226,7 @@ func applyVariableChanges(ctx context.Context, client *github.Client, cfg *confi
 			if change.Type == diff.ChangeUpdate {
 				action = "Updating"
 			}
-			fmt.Printf("  %s variable '%s'... ", action, change.Key)
+			fmt.Fprintf(w, "  %s variable '%s'... ", action, change.Key)
 
 			// Get value from config (and override with .env if present)
 			value := ""
@@ -466,22 +1238,28 @@ func applyVariableChanges(ctx context.Context, client *github.Client, cfg *confi
 			}
 
 			if err := client.SetVariable(ctx, change.Key, value); err != nil {
-				fmt.Println(red("✗"))
-				errors = append(errors, fmt.Sprintf("%s: %v", change.Key, err))
+				fmt.Fprintln(w, red("✗"))
+				err = describeApplyError(err, "%s", change.Key)
+				errors = append(errors, err.Error())
+				report.record([]diff.Change{change}, err)
 				continue
 			}
-			fmt.Println(green("✓"))
+			fmt.Fprintln(w, green("✓"))
 			succeeded++
+			report.record([]diff.Change{change}, nil)
 
 		case diff.ChangeDelete:
-			fmt.Printf("  Deleting variable '%s'... ", change.Key)
+			fmt.Fprintf(w, "  Deleting variable '%s'... ", change.Key)
 			if err := client.DeleteVariable(ctx, change.Key); err != nil {
-				fmt.Println(red("✗"))
-				errors = append(errors, fmt.Sprintf("%s: %v", change.Key, err))
+				fmt.Fprintln(w, red("✗"))
+				err = describeApplyError(err, "%s", change.Key)
+				errors = append(errors, err.Error())
+				report.record([]diff.Change{change}, err)
 				continue
 			}
-			fmt.Println(green("✓"))
+			fmt.Fprintln(w, green("✓"))
 			succeeded++
+			report.record([]diff.Change{change}, nil)
 		}
 	}
 
@@ -491,58 +1269,85 @@ func applyVariableChanges(ctx context.Context, client *github.Client, cfg *confi
 	return nil
 }
 
-func applySecretChanges(ctx context.Context, client *github.Client, dotEnvValues *config.DotEnvValues, changes []diff.Change, green, red func(a ...interface{}) string) error {
+func applySecretChanges(ctx context.Context, client github.GitHubClient, env *config.EnvConfig, dotEnvValues *config.DotEnvValues, state *config.State, changes []diff.Change, w io.Writer, green, red func(a ...interface{}) string, report *applyReport) error {
 	reader := bufio.NewReader(os.Stdin)
 	var errors []string
 	succeeded := 0
 
 	for _, change := range changes {
 		switch change.Type {
-		case diff.ChangeAdd:
-			fmt.Printf("  Creating secret '%s'... ", change.Key)
+		case diff.ChangeAdd, diff.ChangeUpdate:
+			action := "Creating"
+			if change.Type == diff.ChangeUpdate {
+				action = "Updating"
+			}
+			fmt.Fprintf(w, "  %s secret '%s'... ", action, change.Key)
 
-			// Get value from .env
+			// Get value from .env, then from an inline value in the config
 			var value string
 			if dotEnvValues != nil {
 				value, _ = dotEnvValues.GetSecret(change.Key)
 			}
+			if value == "" && env != nil {
+				value, _ = env.InlineSecret(change.Key)
+			}
 
 			if value == "" {
 				// Secret value not found, prompt user
-				fmt.Println()
-				fmt.Printf("    Enter value for secret '%s': ", change.Key)
+				fmt.Fprintln(w)
+				fmt.Fprintf(w, "    Enter value for secret '%s': ", change.Key)
 				inputValue, err := reader.ReadString('\n')
 				if err != nil {
-					fmt.Println(red("✗"))
-					errors = append(errors, fmt.Sprintf("%s: failed to read input: %v", change.Key, err))
+					fmt.Fprintln(w, red("✗"))
+					err = fmt.Errorf("%s: failed to read input: %w", change.Key, err)
+					errors = append(errors, err.Error())
+					report.record([]diff.Change{change}, err)
 					continue
 				}
 				value = strings.TrimSpace(inputValue)
 				if value == "" {
-					fmt.Println(red("✗"))
-					errors = append(errors, fmt.Sprintf("%s: value cannot be empty", change.Key))
+					fmt.Fprintln(w, red("✗"))
+					err := fmt.Errorf("%s: value cannot be empty", change.Key)
+					errors = append(errors, err.Error())
+					report.record([]diff.Change{change}, err)
 					continue
 				}
-				fmt.Printf("  Creating secret '%s'... ", change.Key)
+				fmt.Fprintf(w, "  %s secret '%s'... ", action, change.Key)
 			}
 
 			if err := client.SetSecret(ctx, change.Key, value); err != nil {
-				fmt.Println(red("✗"))
-				errors = append(errors, fmt.Sprintf("%s: %v", change.Key, err))
+				fmt.Fprintln(w, red("✗"))
+				err = describeApplyError(err, "%s", change.Key)
+				errors = append(errors, err.Error())
+				report.record([]diff.Change{change}, err)
 				continue
 			}
-			fmt.Println(green("✓"))
+			fmt.Fprintln(w, green("✓"))
 			succeeded++
+			report.record([]diff.Change{change}, nil)
+
+			if state != nil {
+				if err := state.RecordSecret(github.FullName(client), change.Key, value); err != nil {
+					logger.Warn("Failed to record state for secret '%s': %v", change.Key, err)
+				}
+			}
 
 		case diff.ChangeDelete:
-			fmt.Printf("  Deleting secret '%s'... ", change.Key)
+			fmt.Fprintf(w, "  Deleting secret '%s'... ", change.Key)
 			if err := client.DeleteSecret(ctx, change.Key); err != nil {
-				fmt.Println(red("✗"))
-				errors = append(errors, fmt.Sprintf("%s: %v", change.Key, err))
+				fmt.Fprintln(w, red("✗"))
+				err = describeApplyError(err, "%s", change.Key)
+				errors = append(errors, err.Error())
+				report.record([]diff.Change{change}, err)
 				continue
 			}
-			fmt.Println(green("✓"))
+			fmt.Fprintln(w, green("✓"))
 			succeeded++
+			report.record([]diff.Change{change}, nil)
+
+			if state != nil {
+				state.ForgetSecret(github.FullName(client), change.Key)
+			}
 		}
 	}
 
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
+)
+
+// writeBackup writes the current settings of the repository to path as a config, so that
+// applying it restores them. Besides what export reads, the protection rules of every
+// branch the config protects are included. A path ending in .json is written as JSON,
+// a directory (an existing one, or a path ending in a slash) as a directory config that
+// loads with --dir, anything else as YAML.
+func writeBackup(ctx context.Context, client github.GitHubClient, cfg *config.Config, path string, includeEnv bool) error {
+	backup, err := buildConfigFromRepo(ctx, client, includeEnv)
+	if err != nil {
+		return err
+	}
+
+	branches := make([]string, 0, len(cfg.BranchProtection))
+	for branch := range cfg.BranchProtection {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+	importBranchProtectionFor(ctx, client, backup, branches)
+
+	if isBackupDirectory(path) {
+		if err := writeDirectorySections(backup, path); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+		logger.Success("Current settings backed up to %s", path)
+		return nil
+	}
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err = json.MarshalIndent(backup, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = marshalYAML(backup)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	logger.Success("Current settings backed up to %s", path)
+	return nil
+}
+
+// isBackupDirectory reports whether a backup at path is written as a directory config
+func isBackupDirectory(path string) bool {
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(filepath.Separator)) {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -1,13 +1,29 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/myzkey/gh-repo-settings/internal/config"
 	"github.com/myzkey/gh-repo-settings/internal/diff"
 	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+	"github.com/myzkey/gh-repo-settings/internal/infra/githubopenapi"
+	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
+	"github.com/oapi-codegen/nullable"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 // Test utility functions from init.go
@@ -133,9 +149,9 @@ func TestTrimString(t *testing.T) {
 
 func TestFindLabel(t *testing.T) {
 	labels := []config.Label{
-		{Name: "bug", Color: "d73a4a", Description: "Bug fix"},
-		{Name: "feature", Color: "0e8a16", Description: "New feature"},
-		{Name: "docs", Color: "0075ca", Description: "Documentation"},
+		{Name: "bug", Color: "d73a4a", Description: ptrString("Bug fix")},
+		{Name: "feature", Color: "0e8a16", Description: ptrString("New feature")},
+		{Name: "docs", Color: "0075ca", Description: ptrString("Documentation")},
 	}
 
 	tests := []struct {
@@ -161,29 +177,6 @@ func TestFindLabel(t *testing.T) {
 	}
 }
 
-func TestExtractBranchName(t *testing.T) {
-	tests := []struct {
-		name   string
-		key    string
-		expect string
-	}{
-		{"with dot", "main.required_reviews", "main"},
-		{"multiple dots", "release/v1.0.strict", "release/v1"},
-		{"no dot", "main", "main"},
-		{"empty", "", ""},
-		{"starts with dot", ".setting", ""},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := extractBranchName(tt.key)
-			if got != tt.expect {
-				t.Errorf("extractBranchName(%q) = %q, want %q", tt.key, got, tt.expect)
-			}
-		})
-	}
-}
-
 // Test init.go file writing functions
 
 func TestWriteConfigToFile(t *testing.T) {
@@ -237,6 +230,8 @@ func TestWriteConfigToDirectory(t *testing.T) {
 	t.Run("write directory structure", func(t *testing.T) {
 		dir := filepath.Join(tmpDir, "repo-settings")
 		visibility := "public"
+		allowedActions := "all"
+		buildType := "workflow"
 		cfg := &config.Config{
 			Repo: &config.RepoConfig{
 				Visibility: &visibility,
@@ -248,6 +243,9 @@ func TestWriteConfigToDirectory(t *testing.T) {
 			BranchProtection: map[string]*config.BranchRule{
 				"main": {},
 			},
+			Env:     &config.EnvConfig{Secrets: []config.Secret{{Name: "API_TOKEN"}}},
+			Actions: &config.ActionsConfig{AllowedActions: &allowedActions},
+			Pages:   &config.PagesConfig{BuildType: &buildType},
 		}
 
 		err := writeConfigToDirectory(cfg, dir)
@@ -256,7 +254,7 @@ func TestWriteConfigToDirectory(t *testing.T) {
 		}
 
 		// Verify files exist
-		expectedFiles := []string{"repo.yaml", "topics.yaml", "labels.yaml", "branch-protection.yaml"}
+		expectedFiles := []string{"repo.yaml", "topics.yaml", "labels.yaml", "branch-protection.yaml", "env.yaml", "actions.yaml", "pages.yaml"}
 		for _, file := range expectedFiles {
 			path := filepath.Join(dir, file)
 			if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -288,6 +286,54 @@ func TestWriteConfigToDirectory(t *testing.T) {
 	})
 }
 
+func TestWriteConfigToDirectoryRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "repo-settings")
+	enabled := true
+	cfg := &config.Config{
+		Repo: &config.RepoConfig{
+			Visibility: ptrString("public"),
+		},
+		Topics: []string{"go"},
+		Env: &config.EnvConfig{
+			Variables: map[string]string{"NODE_ENV": "production"},
+			Secrets:   []config.Secret{{Name: "API_TOKEN"}},
+		},
+		Actions: &config.ActionsConfig{
+			Enabled:        &enabled,
+			AllowedActions: ptrString("selected"),
+			SelectedActions: &config.SelectedActionsConfig{
+				GithubOwnedAllowed: &enabled,
+				PatternsAllowed:    []string{"actions/*"},
+			},
+		},
+		Pages: &config.PagesConfig{
+			BuildType: ptrString("legacy"),
+			Source: &config.PagesSourceConfig{
+				Branch: ptrString("gh-pages"),
+				Path:   ptrString("/"),
+			},
+		},
+	}
+
+	if err := writeConfigToDirectory(cfg, dir); err != nil {
+		t.Fatalf("writeConfigToDirectory() error = %v", err)
+	}
+
+	for _, file := range []string{"env.yaml", "actions.yaml", "pages.yaml"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("expected file %s was not created", file)
+		}
+	}
+
+	loaded, err := config.Load(config.LoadOptions{Dir: []string{dir}})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, cfg) {
+		t.Errorf("round-tripped config differs\ngot:  %+v\nwant: %+v", loaded, cfg)
+	}
+}
+
 // Test export.go utility functions
 
 func TestWriteYAMLFile(t *testing.T) {
@@ -367,6 +413,38 @@ func TestRootCommand(t *testing.T) {
 		if rFlag == nil {
 			t.Error("missing --repo flag")
 		}
+
+		oFlag := rootCmd.PersistentFlags().Lookup("org")
+		if oFlag == nil {
+			t.Error("missing --org flag")
+		}
+	})
+
+	t.Run("api-timeout is an alias of timeout", func(t *testing.T) {
+		original := timeout
+		defer func() { timeout = original }()
+
+		if err := rootCmd.PersistentFlags().Parse([]string{"--api-timeout", "5s"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if timeout != 5*time.Second {
+			t.Errorf("timeout = %v, want 5s", timeout)
+		}
+	})
+
+	t.Run("api-timeout works on subcommands", func(t *testing.T) {
+		original := timeout
+		defer func() { timeout = original }()
+
+		for _, sub := range []*cobra.Command{planCmd, applyCmd} {
+			timeout = original
+			if err := sub.ParseFlags([]string{"--api-timeout", "7s"}); err != nil {
+				t.Fatalf("%s: unexpected error: %v", sub.Name(), err)
+			}
+			if timeout != 7*time.Second {
+				t.Errorf("%s: timeout = %v, want 7s", sub.Name(), timeout)
+			}
+		}
 	})
 
 	t.Run("has subcommands", func(t *testing.T) {
@@ -429,9 +507,105 @@ func TestPlanCommand(t *testing.T) {
 		if jsonFlag == nil {
 			t.Error("missing --json flag")
 		}
+
+		environmentFlag := planCmd.Flags().Lookup("environment")
+		if environmentFlag == nil {
+			t.Error("missing --environment flag")
+		}
+
+		noStateFlag := planCmd.Flags().Lookup("no-state")
+		if noStateFlag == nil {
+			t.Error("missing --no-state flag")
+		}
+
+		stdinFlag := planCmd.Flags().Lookup("config-stdin")
+		if stdinFlag == nil {
+			t.Error("missing --config-stdin flag")
+		}
+
+		outFlag := planCmd.Flags().Lookup("out")
+		if outFlag == nil {
+			t.Error("missing --out flag")
+		}
+
+		failOnFlag := planCmd.Flags().Lookup("fail-on")
+		if failOnFlag == nil {
+			t.Error("missing --fail-on flag")
+		} else if failOnFlag.DefValue != "delete,missing" {
+			t.Errorf("--fail-on default = %q, want %q", failOnFlag.DefValue, "delete,missing")
+		}
 	})
 }
 
+func TestParseFailOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []diff.ChangeType
+		wantErr bool
+	}{
+		{"default", "delete,missing", []diff.ChangeType{diff.ChangeDelete, diff.ChangeMissing}, false},
+		{"single", "update", []diff.ChangeType{diff.ChangeUpdate}, false},
+		{"spaces and case", " Add , UPDATE ", []diff.ChangeType{diff.ChangeAdd, diff.ChangeUpdate}, false},
+		{"none", "none", nil, false},
+		{"empty", "", nil, false},
+		{"invalid", "delete,bogus", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFailOn(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseFailOn(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("parseFailOn(%q)[%d] = %v, want %v", tt.value, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExitCodeFor(t *testing.T) {
+	defaultFailOn := []diff.ChangeType{diff.ChangeDelete, diff.ChangeMissing}
+
+	tests := []struct {
+		name   string
+		stats  diff.PlanStats
+		failOn []diff.ChangeType
+		want   int
+	}{
+		{"no changes", diff.PlanStats{}, defaultFailOn, 0},
+		{"updates only with default", diff.PlanStats{Update: 3}, defaultFailOn, 0},
+		{"deletes with default", diff.PlanStats{Delete: 1}, defaultFailOn, 2},
+		{"missing with default", diff.PlanStats{Missing: 1}, defaultFailOn, 3},
+		{"missing takes priority over deletes", diff.PlanStats{Delete: 1, Missing: 1}, defaultFailOn, 3},
+		{"fail on update", diff.PlanStats{Update: 1}, []diff.ChangeType{diff.ChangeUpdate}, 2},
+		{"fail on update ignores deletes", diff.PlanStats{Delete: 1}, []diff.ChangeType{diff.ChangeUpdate}, 0},
+		{"fail on add", diff.PlanStats{Add: 2}, []diff.ChangeType{diff.ChangeAdd}, 2},
+		{"none never fails", diff.PlanStats{Add: 1, Update: 1, Delete: 1, Missing: 1}, nil, 0},
+		{"missing before update in list", diff.PlanStats{Update: 1, Missing: 1}, []diff.ChangeType{diff.ChangeMissing, diff.ChangeUpdate}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCodeFor(tt.stats, tt.failOn); got != tt.want {
+				t.Errorf("exitCodeFor(%+v, %v) = %d, want %d", tt.stats, tt.failOn, got, tt.want)
+			}
+		})
+	}
+}
+
 // Test apply.go command structure
 
 func TestApplyCommand(t *testing.T) {
@@ -456,6 +630,180 @@ func TestApplyCommand(t *testing.T) {
 		if yFlag == nil {
 			t.Error("missing --yes flag")
 		}
+
+		createFlag := applyCmd.Flags().Lookup("create")
+		if createFlag == nil {
+			t.Error("missing --create flag")
+		}
+
+		continueFlag := applyCmd.Flags().Lookup("continue-on-error")
+		if continueFlag == nil {
+			t.Error("missing --continue-on-error flag")
+		}
+
+		environmentFlag := applyCmd.Flags().Lookup("environment")
+		if environmentFlag == nil {
+			t.Error("missing --environment flag")
+		}
+
+		noStateFlag := applyCmd.Flags().Lookup("no-state")
+		if noStateFlag == nil {
+			t.Error("missing --no-state flag")
+		}
+
+		stdinFlag := applyCmd.Flags().Lookup("config-stdin")
+		if stdinFlag == nil {
+			t.Error("missing --config-stdin flag")
+		}
+
+		verifyFlag := applyCmd.Flags().Lookup("verify")
+		if verifyFlag == nil {
+			t.Error("missing --verify flag")
+		}
+	})
+}
+
+func TestDescribeApplyError(t *testing.T) {
+	t.Run("API error with resource is returned as is", func(t *testing.T) {
+		apiErr := apperrors.NewAPIError("POST", "repos/o/r/labels", 422, "Validation Failed", nil)
+		apiErr.Resource = "label 'bug'"
+
+		err := describeApplyError(apiErr, "failed to create label %s", "bug")
+		if err.Error() != "label 'bug': 422 Validation Failed" {
+			t.Errorf("got %q", err.Error())
+		}
+	})
+
+	t.Run("other errors are wrapped with context", func(t *testing.T) {
+		err := describeApplyError(apperrors.ErrPermissionDenied, "failed to create label %s", "bug")
+		if err.Error() != "failed to create label bug: permission denied" {
+			t.Errorf("got %q", err.Error())
+		}
+		if !apperrors.Is(err, apperrors.ErrPermissionDenied) {
+			t.Error("wrapped error should match ErrPermissionDenied")
+		}
+	})
+}
+
+func TestSkipMissingBranches(t *testing.T) {
+	newPlan := func() *diff.Plan {
+		return model.NewPlanFromChanges([]model.Change{
+			model.NewBranchProtectionAddChange("develop", "{required_reviews=1}"),
+			model.NewBranchProtectionUpdateChange("main", "required_reviews", 1, 2),
+			model.NewUpdateChange(model.CategoryRepo, "description", "old", "new"),
+		})
+	}
+
+	t.Run("all branches exist", func(t *testing.T) {
+		mock := github.NewMockClient()
+
+		plan, err := skipMissingBranches(context.Background(), mock, newPlan(), false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if plan.Size() != 3 {
+			t.Errorf("expected 3 changes, got %d", plan.Size())
+		}
+	})
+
+	t.Run("missing branch is an error", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.MissingBranches = []string{"develop"}
+
+		_, err := skipMissingBranches(context.Background(), mock, newPlan(), false)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		want := "branch 'develop' does not exist; cannot apply protection"
+		if err.Error() != want {
+			t.Errorf("error = %q, want %q", err.Error(), want)
+		}
+	})
+
+	t.Run("missing branch is skipped with continue-on-error", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.MissingBranches = []string{"develop"}
+
+		plan, err := skipMissingBranches(context.Background(), mock, newPlan(), true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if plan.Size() != 2 {
+			t.Fatalf("expected 2 changes, got %d", plan.Size())
+		}
+		for _, c := range plan.Changes() {
+			if c.Key == "develop" {
+				t.Error("changes for missing branch should be removed")
+			}
+		}
+	})
+
+	t.Run("BranchExists error is returned", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.BranchExistsError = apperrors.ErrPermissionDenied
+
+		_, err := skipMissingBranches(context.Background(), mock, newPlan(), true)
+		if !apperrors.Is(err, apperrors.ErrPermissionDenied) {
+			t.Errorf("expected ErrPermissionDenied, got %v", err)
+		}
+	})
+}
+
+func TestEnsureRepoExists(t *testing.T) {
+	t.Run("existing repo is not created", func(t *testing.T) {
+		mock := github.NewMockClient()
+
+		if err := ensureRepoExists(context.Background(), mock, &config.Config{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mock.CreateRepoCalls) != 0 {
+			t.Errorf("expected no CreateRepo calls, got %d", len(mock.CreateRepoCalls))
+		}
+	})
+
+	t.Run("missing repo is created with repo config", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.GetRepoError = apperrors.ErrRepoNotFound
+		desc := "New repo"
+		cfg := &config.Config{Repo: &config.RepoConfig{Description: &desc}}
+
+		if err := ensureRepoExists(context.Background(), mock, cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mock.CreateRepoCalls) != 1 {
+			t.Fatalf("expected 1 CreateRepo call, got %d", len(mock.CreateRepoCalls))
+		}
+		call := mock.CreateRepoCalls[0]
+		if call.Owner != "test-owner" || call.Name != "test-repo" {
+			t.Errorf("CreateRepo called with %s/%s, want test-owner/test-repo", call.Owner, call.Name)
+		}
+		if call.Config != cfg.Repo {
+			t.Error("CreateRepo should receive the repo config")
+		}
+	})
+
+	t.Run("other errors are returned without creating", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.GetRepoError = apperrors.ErrPermissionDenied
+
+		err := ensureRepoExists(context.Background(), mock, &config.Config{})
+		if !apperrors.Is(err, apperrors.ErrPermissionDenied) {
+			t.Errorf("expected ErrPermissionDenied, got %v", err)
+		}
+		if len(mock.CreateRepoCalls) != 0 {
+			t.Errorf("expected no CreateRepo calls, got %d", len(mock.CreateRepoCalls))
+		}
+	})
+
+	t.Run("create failure is returned", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.GetRepoError = apperrors.ErrRepoNotFound
+		mock.CreateRepoError = apperrors.ErrPermissionDenied
+
+		err := ensureRepoExists(context.Background(), mock, &config.Config{})
+		if !apperrors.Is(err, apperrors.ErrPermissionDenied) {
+			t.Errorf("expected ErrPermissionDenied, got %v", err)
+		}
 	})
 }
 
@@ -658,118 +1006,2658 @@ func TestPrintPlanWithOptions(t *testing.T) {
 	})
 }
 
-// Test groupChanges helper logic via applyChanges structure
-func TestChangeCategoryGrouping(t *testing.T) {
-	changes := []diff.Change{
-		{Category: "repo", Key: "description", Type: diff.ChangeUpdate},
-		{Category: "repo", Key: "visibility", Type: diff.ChangeUpdate},
-		{Category: "topics", Key: "topics", Type: diff.ChangeUpdate},
-		{Category: "labels", Key: "bug", Type: diff.ChangeAdd},
-		{Category: "labels", Key: "feature", Type: diff.ChangeUpdate},
-		{Category: "branch_protection", Key: "main.required_reviews", Type: diff.ChangeUpdate},
-		{Category: "branch_protection", Key: "develop.required_reviews", Type: diff.ChangeUpdate},
-		{Category: "actions", Key: "enabled", Type: diff.ChangeUpdate},
-		{Category: "pages", Key: "build_type", Type: diff.ChangeUpdate},
-		{Category: "variables", Key: "NODE_ENV", Type: diff.ChangeAdd},
-		{Category: "secrets", Key: "API_KEY", Type: diff.ChangeAdd},
-	}
-
-	// Group changes by category (same logic as applyChanges)
-	repoChanges := make(map[string]interface{})
-	var topicsChanged bool
-	var labelChanges []diff.Change
-	branchProtectionChanges := make(map[string][]diff.Change)
-	var actionsChanges []diff.Change
-	var pagesChanges []diff.Change
-	var variableChanges []diff.Change
-	var secretChanges []diff.Change
-
-	for _, change := range changes {
-		switch change.Category {
-		case "repo":
-			repoChanges[change.Key] = change.New
-		case "topics":
-			topicsChanged = true
-		case "labels":
-			labelChanges = append(labelChanges, change)
-		case "branch_protection":
-			branchName := extractBranchName(change.Key)
-			branchProtectionChanges[branchName] = append(branchProtectionChanges[branchName], change)
-		case "actions":
-			actionsChanges = append(actionsChanges, change)
-		case "pages":
-			pagesChanges = append(pagesChanges, change)
-		case "variables":
-			variableChanges = append(variableChanges, change)
-		case "secrets":
-			secretChanges = append(secretChanges, change)
-		}
-	}
+func TestOutputPlanToFile(t *testing.T) {
+	plan := model.NewPlanFromChanges([]diff.Change{
+		{Category: "repo", Key: "description", Type: diff.ChangeUpdate, Old: "old", New: "new"},
+		{Category: "labels", Key: "stale", Type: diff.ChangeDelete, Old: "color=ffffff"},
+	})
 
-	// Verify grouping
-	if len(repoChanges) != 2 {
-		t.Errorf("expected 2 repo changes, got %d", len(repoChanges))
-	}
-	if !topicsChanged {
-		t.Error("expected topicsChanged to be true")
-	}
-	if len(labelChanges) != 2 {
-		t.Errorf("expected 2 label changes, got %d", len(labelChanges))
-	}
-	if len(branchProtectionChanges) != 2 {
-		t.Errorf("expected 2 branch protection branches, got %d", len(branchProtectionChanges))
-	}
-	if len(actionsChanges) != 1 {
-		t.Errorf("expected 1 actions change, got %d", len(actionsChanges))
-	}
-	if len(pagesChanges) != 1 {
-		t.Errorf("expected 1 pages change, got %d", len(pagesChanges))
-	}
-	if len(variableChanges) != 1 {
-		t.Errorf("expected 1 variable change, got %d", len(variableChanges))
-	}
-	if len(secretChanges) != 1 {
-		t.Errorf("expected 1 secret change, got %d", len(secretChanges))
-	}
-}
+	origOut, origJSON := planOut, jsonOutput
+	t.Cleanup(func() {
+		planOut, jsonOutput = origOut, origJSON
+	})
 
-// Test extractBranchName with more edge cases
-func TestExtractBranchNameEdgeCases(t *testing.T) {
 	tests := []struct {
-		key    string
-		expect string
+		name   string
+		asJSON bool
+		want   func() string
 	}{
-		{"main.required_reviews", "main"},
-		{"develop.dismiss_stale_reviews", "develop"},
-		{"feature/test.strict_status_checks", "feature/test"},
-		{"release-1.0.enforce_admins", "release-1"},
-		{"no-dot-here", "no-dot-here"},
-		{"", ""},
-		{".", ""},
-		{".only-dot", ""},
-		{"a.b.c.d", "a"},
+		{
+			name:   "text",
+			asJSON: false,
+			want: func() string {
+				var buf bytes.Buffer
+				renderPlan(&buf, plan, true, false)
+				return buf.String()
+			},
+		},
+		{
+			name:   "json",
+			asJSON: true,
+			want: func() string {
+				data, err := diff.PlanMarshalIndent(plan)
+				if err != nil {
+					t.Fatalf("PlanMarshalIndent() error = %v", err)
+				}
+				return string(data) + "\n"
+			},
+		},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.key, func(t *testing.T) {
-			got := extractBranchName(tt.key)
-			if got != tt.expect {
-				t.Errorf("extractBranchName(%q) = %q, want %q", tt.key, got, tt.expect)
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nested", "dir", "plan.out")
+			planOut, jsonOutput = path, tt.asJSON
+
+			if err := outputPlan(plan, "up to date"); err != nil {
+				t.Fatalf("outputPlan() error = %v", err)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read plan output: %v", err)
+			}
+			if string(got) != tt.want() {
+				t.Errorf("plan output mismatch\ngot:\n%s\nwant:\n%s", got, tt.want())
+			}
+			if strings.Contains(string(got), "\x1b[") {
+				t.Error("plan output should not contain color codes")
 			}
 		})
 	}
-}
 
-// Helper function
-func containsStr(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsSubstr(s, substr))
-}
+	t.Run("findings", func(t *testing.T) {
+		origFormat := planFormat
+		t.Cleanup(func() { planFormat = origFormat })
+		planFormat = "findings"
 
-func containsSubstr(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
+		findings := model.NewPlanFromChanges([]diff.Change{
+			{Category: "repo", Key: "description", Type: diff.ChangeUpdate, Old: "old", New: "new"},
+			{Category: "repo", Key: "visibility", Type: diff.ChangeUpdate, Old: "public", New: "private"},
+		})
+		data, err := renderPlanOutput(findings, true, false)
+		if err != nil {
+			t.Fatalf("renderPlanOutput() error = %v", err)
 		}
+		var got []diff.Finding
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("findings output isn't a JSON array: %v\n%s", err, data)
+		}
+		if len(got) != 1 || got[0].RuleID != "repo.visibility" || got[0].Level != "error" {
+			t.Errorf("findings = %+v", got)
+		}
+	})
+
+	t.Run("template", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "plan.tmpl")
+		text := "{{range .Changes}}{{.Category}}.{{.Key}} {{.Type}}\n{{end}}{{.Stats.Update}} to change\n"
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		tmpl, err := loadPlanTemplate(path)
+		if err != nil {
+			t.Fatalf("loadPlanTemplate() error = %v", err)
+		}
+		origTemplate := planTemplate
+		t.Cleanup(func() { planTemplate = origTemplate })
+		planTemplate = tmpl
+
+		data, err := renderPlanOutput(plan, true, false)
+		if err != nil {
+			t.Fatalf("renderPlanOutput() error = %v", err)
+		}
+		want := "repo.description update\nlabels.stale delete\n1 to change\n"
+		if string(data) != want {
+			t.Errorf("renderPlanOutput() = %q, want %q", data, want)
+		}
+	})
+
+	t.Run("text without changes", func(t *testing.T) {
+		data, err := renderPlanOutput(model.NewPlan(), false, false)
+		if err != nil {
+			t.Fatalf("renderPlanOutput() error = %v", err)
+		}
+		if string(data) != "No changes detected.\n" {
+			t.Errorf("renderPlanOutput() = %q", data)
+		}
+	})
+}
+
+func TestRenderPlanSummary(t *testing.T) {
+	plan := model.NewPlanFromChanges([]diff.Change{
+		{Category: "repo", Key: "description", Type: diff.ChangeUpdate, Old: "old", New: "new"},
+		{Category: "repo", Key: "homepage", Type: diff.ChangeAdd, New: "https://example.com"},
+		{Category: "labels", Key: "stale", Type: diff.ChangeDelete, Old: "color=ffffff"},
+		{Category: "secrets", Key: "TOKEN", Type: diff.ChangeMissing, New: "not set"},
+	})
+
+	var buf bytes.Buffer
+	renderPlanSummary(&buf, plan, false)
+
+	stats := plan.Stats()
+	want := fmt.Sprintf("Plan: %d to add, %d to change, %d to destroy, %d missing.\n  repo: 2\n  labels: 1\n  secrets: 1\n",
+		stats.Add, stats.Update, stats.Delete, stats.Missing)
+	if buf.String() != want {
+		t.Errorf("renderPlanSummary() =\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	for _, change := range plan.Changes() {
+		if strings.Contains(buf.String(), change.Key) {
+			t.Errorf("summary should not contain per-change line for %q", change.Key)
+		}
+	}
+
+	t.Run("without changes", func(t *testing.T) {
+		data, err := renderPlanOutput(model.NewPlan(), false, true)
+		if err != nil {
+			t.Fatalf("renderPlanOutput() error = %v", err)
+		}
+		if string(data) != "Plan: 0 to add, 0 to change, 0 to destroy.\n" {
+			t.Errorf("renderPlanOutput() = %q", data)
+		}
+	})
+}
+
+func TestSchemaCommand(t *testing.T) {
+	checkSchema := func(t *testing.T, data []byte) {
+		t.Helper()
+		var schema map[string]interface{}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			t.Fatalf("schema is not valid JSON: %v", err)
+		}
+		if schema["$id"] != config.SchemaID {
+			t.Errorf("$id = %v, want %s", schema["$id"], config.SchemaID)
+		}
+		if schema["title"] != config.SchemaTitle {
+			t.Errorf("title = %v, want %s", schema["title"], config.SchemaTitle)
+		}
+	}
+
+	t.Run("stdout", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := printSchema(&buf); err != nil {
+			t.Fatalf("printSchema() error = %v", err)
+		}
+		checkSchema(t, buf.Bytes())
+	})
+
+	t.Run("output file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "schema.json")
+		if err := writeSchema(path); err != nil {
+			t.Fatalf("writeSchema() error = %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read schema: %v", err)
+		}
+		checkSchema(t, data)
+	})
+}
+
+func TestKeysCommand(t *testing.T) {
+	keys := []config.KeyDescriptor{
+		{Path: "actions.allowed_actions", Type: "string", Enum: []string{"all", "local_only", "selected"}, Description: "Which actions are allowed"},
+		{Path: "repo.old_setting", Type: "boolean", Description: "Replaced by new_setting", Deprecated: true},
+		{Path: "topics", Type: "array<string>"},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := printKeys(&buf, keys); err != nil {
+			t.Fatalf("printKeys() error = %v", err)
+		}
+		want := "actions.allowed_actions  string         all|local_only|selected  Which actions are allowed\n" +
+			"repo.old_setting         boolean        -                        (deprecated) Replaced by new_setting\n" +
+			"topics                   array<string>  -                        \n"
+		if buf.String() != want {
+			t.Errorf("output =\n%s\nwant\n%s", buf.String(), want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := printKeysJSON(&buf, keys); err != nil {
+			t.Fatalf("printKeysJSON() error = %v", err)
+		}
+		var got []config.KeyDescriptor
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("output is not valid JSON: %v", err)
+		}
+		if !reflect.DeepEqual(got, keys) {
+			t.Errorf("keys = %+v, want %+v", got, keys)
+		}
+	})
+}
+
+func TestVerifyConverged(t *testing.T) {
+	cfg := &config.Config{
+		Repo: &config.RepoConfig{Description: ptrString("new description")},
+	}
+
+	t.Run("converged after apply", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.RepoData = &github.RepoData{}
+		calculator := diff.NewCalculator(mock, cfg)
+
+		// Simulate the apply updating GitHub to the desired state
+		mock.RepoData.Description = nullable.NewNullableWithValue("new description")
+
+		if err := verifyConverged(context.Background(), calculator, diff.CalculateOptions{}, io.Discard); err != nil {
+			t.Errorf("verifyConverged() unexpected error: %v", err)
+		}
+	})
+
+	t.Run("not converged after apply", func(t *testing.T) {
+		mock := github.NewMockClient()
+		// GitHub normalized the value, so it never matches the config
+		mock.RepoData = &github.RepoData{Description: nullable.NewNullableWithValue("New description")}
+		calculator := diff.NewCalculator(mock, cfg)
+
+		var out bytes.Buffer
+		err := verifyConverged(context.Background(), calculator, diff.CalculateOptions{}, &out)
+		if !apperrors.Is(err, apperrors.ErrNotConverged) {
+			t.Fatalf("verifyConverged() error = %v, want ErrNotConverged", err)
+		}
+		if !strings.Contains(err.Error(), "1 change(s) remain") {
+			t.Errorf("verifyConverged() error = %q, want residual count", err.Error())
+		}
+		// The residual goes to the progress writer, which is stderr under apply --json
+		if !strings.Contains(out.String(), "description") {
+			t.Errorf("expected the residual change in the output, got %q", out.String())
+		}
+	})
+}
+
+func TestBuildConfigFromRepoIsIdempotent(t *testing.T) {
+	boolPtr := func(b bool) *bool { return &b }
+	intPtr := func(i int) *int { return &i }
+	topics := []string{"go", "cli"}
+	buildType := githubopenapi.GithubPageBuildType("legacy")
+
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{
+		Description:      nullable.NewNullableWithValue("A repository"),
+		Homepage:         nullable.NewNullableWithValue("https://example.com"),
+		Visibility:       ptrString("public"),
+		AllowMergeCommit: boolPtr(false),
+		AllowSquashMerge: boolPtr(true),
+		Topics:           &topics,
+	}
+	mock.Labels = []github.LabelData{
+		{Name: "bug", Color: "d73a4a", Description: nullable.NewNullableWithValue("Something isn't working")},
+	}
+	mock.Variables = []github.VariableData{{Name: "APP_ENV", Value: "production"}}
+	mock.PagesData = &github.PagesData{
+		BuildType: nullable.NewNullableWithValue(buildType),
+		Source:    &github.PagesSourceData{Branch: "gh-pages", Path: "/"},
+	}
+	mock.BranchProtections["main"] = &github.BranchProtectionData{
+		RequiredPullRequestReviews: &githubopenapi.ProtectedBranchPullRequestReview{
+			RequiredApprovingReviewCount: intPtr(2),
+			DismissStaleReviews:          true,
+		},
+		EnforceAdmins: &githubopenapi.ProtectedBranchAdminEnforced{Enabled: true},
+		RequiredStatusChecks: &githubopenapi.ProtectedBranchRequiredStatusCheck{
+			Contexts: []string{"test"},
+			Strict:   boolPtr(true),
+		},
+	}
+
+	cfg, err := buildConfigFromRepo(context.Background(), mock, true)
+	if err != nil {
+		t.Fatalf("buildConfigFromRepo() error = %v", err)
+	}
+	if cfg.Pages == nil || cfg.BranchProtection["main"] == nil || cfg.Env == nil {
+		t.Fatalf("expected pages, branch protection and env to be imported, got %+v", cfg)
+	}
+
+	dotEnv := &config.DotEnvValues{Values: map[string]string{}}
+	plan, err := diff.NewCalculatorWithEnv(mock, cfg, dotEnv).CalculateWithOptions(context.Background(), diff.CalculateOptions{CheckEnv: true})
+	if err != nil {
+		t.Fatalf("CalculateWithOptions() error = %v", err)
+	}
+	if !plan.IsClean() {
+		t.Errorf("re-plan of exported config should be empty, got %+v", plan.Changes())
+	}
+}
+
+func TestWriteBackup(t *testing.T) {
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{
+		Description: nullable.NewNullableWithValue("before apply"),
+		Visibility:  ptrString("private"),
+	}
+	mock.Labels = []github.LabelData{{Name: "bug", Color: "d73a4a"}}
+	mock.BranchProtections["release/v1.0"] = &github.BranchProtectionData{
+		RequiredPullRequestReviews: &githubopenapi.ProtectedBranchPullRequestReview{
+			RequiredApprovingReviewCount: ptr(1),
+		},
+	}
+
+	// The config changes these settings; the backup must hold the values from before
+	cfg := &config.Config{
+		Repo: &config.RepoConfig{Description: ptrString("after apply")},
+		BranchProtection: map[string]*config.BranchRule{
+			"release/v1.0": {RequiredReviews: ptr(2)},
+		},
+	}
+
+	for _, name := range []string{"backup.yaml", "backup.json", "backup/"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "backups", name)
+			if strings.HasSuffix(name, "/") {
+				path += "/"
+			}
+			if err := writeBackup(context.Background(), mock, cfg, path, false); err != nil {
+				t.Fatalf("writeBackup() error = %v", err)
+			}
+
+			opts := config.LoadOptions{Config: path}
+			if strings.HasSuffix(path, "/") {
+				opts = config.LoadOptions{Dir: []string{path}}
+			}
+			backup, err := config.Load(opts)
+			if err != nil {
+				t.Fatalf("backup doesn't load: %v", err)
+			}
+			if backup.Repo == nil || backup.Repo.Description == nil || *backup.Repo.Description != "before apply" {
+				t.Errorf("repo = %+v, want the description before apply", backup.Repo)
+			}
+			rule := backup.BranchProtection["release/v1.0"]
+			if rule == nil || rule.RequiredReviews == nil || *rule.RequiredReviews != 1 {
+				t.Errorf("branch protection = %+v, want release/v1.0 with 1 review", backup.BranchProtection)
+			}
+			if backup.Labels == nil || len(backup.Labels.Items) != 1 {
+				t.Errorf("labels = %+v, want bug", backup.Labels)
+			}
+
+			// Restoring the backup on the unchanged repository is a no-op
+			plan, err := diff.NewCalculator(mock, backup).Calculate(context.Background())
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if !plan.IsClean() {
+				t.Errorf("plan of the backup should be empty, got %+v", plan.Changes())
+			}
+		})
+	}
+}
+
+func TestImportedConfigDirectoryRoundTrip(t *testing.T) {
+	boolPtr := func(b bool) *bool { return &b }
+	allowed := githubopenapi.AllowedActions("selected")
+	patterns := []string{"actions/*", "docker/*"}
+	buildType := githubopenapi.GithubPageBuildType("legacy")
+
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{Visibility: ptrString("public")}
+	mock.ActionsPermissions = &github.ActionsPermissionsData{Enabled: true, AllowedActions: &allowed}
+	mock.ActionsSelected = &github.ActionsSelectedData{
+		GithubOwnedAllowed: boolPtr(true),
+		VerifiedAllowed:    boolPtr(false),
+		PatternsAllowed:    &patterns,
+	}
+	mock.ActionsWorkflowPerms = &github.ActionsWorkflowPermissionsData{
+		DefaultWorkflowPermissions:   "read",
+		CanApprovePullRequestReviews: false,
+	}
+	mock.PagesData = &github.PagesData{
+		BuildType: nullable.NewNullableWithValue(buildType),
+		Source:    &github.PagesSourceData{Branch: "gh-pages", Path: "/docs"},
+	}
+	mock.Variables = []github.VariableData{{Name: "APP_ENV", Value: "production"}}
+
+	cfg, err := buildConfigFromRepo(context.Background(), mock, true)
+	if err != nil {
+		t.Fatalf("buildConfigFromRepo() error = %v", err)
+	}
+	if cfg.Actions == nil || cfg.Pages == nil || cfg.Env == nil {
+		t.Fatalf("expected actions, pages and env to be imported, got %+v", cfg)
+	}
+
+	dir := filepath.Join(t.TempDir(), "repo-settings")
+	if err := writeConfigToDirectory(cfg, dir); err != nil {
+		t.Fatalf("writeConfigToDirectory() error = %v", err)
+	}
+	loaded, err := config.Load(config.LoadOptions{Dir: []string{dir}})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded.Actions, cfg.Actions) {
+		t.Errorf("actions did not survive the round trip\ngot:  %+v\nwant: %+v", loaded.Actions, cfg.Actions)
+	}
+	if !reflect.DeepEqual(loaded.Pages, cfg.Pages) {
+		t.Errorf("pages did not survive the round trip\ngot:  %+v\nwant: %+v", loaded.Pages, cfg.Pages)
+	}
+	if !reflect.DeepEqual(loaded, cfg) {
+		t.Errorf("round-tripped config differs\ngot:  %+v\nwant: %+v", loaded, cfg)
+	}
+}
+
+func TestPrintCurrentSettingsYAML(t *testing.T) {
+	topics := []string{"go"}
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{
+		Description: nullable.NewNullableWithValue("A repository"),
+		Visibility:  ptrString("private"),
+		Topics:      &topics,
+	}
+	mock.Labels = []github.LabelData{{Name: "bug", Color: "d73a4a"}}
+	mock.Secrets = []string{"API_TOKEN"}
+
+	var buf bytes.Buffer
+	if err := printCurrentSettingsYAML(context.Background(), mock, &buf, true); err != nil {
+		t.Fatalf("printCurrentSettingsYAML() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "repo-settings.yaml")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := config.Load(config.LoadOptions{Config: path})
+	if err != nil {
+		t.Fatalf("emitted YAML doesn't load: %v\n%s", err, buf.String())
+	}
+
+	want, err := buildConfigFromRepo(context.Background(), mock, true)
+	if err != nil {
+		t.Fatalf("buildConfigFromRepo() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, want) {
+		t.Errorf("loaded config differs\ngot:  %+v\nwant: %+v", loaded, want)
+	}
+	if loaded.Env == nil || len(loaded.Env.Secrets) != 1 {
+		t.Errorf("expected secret names with includeEnv, got %+v", loaded.Env)
+	}
+}
+
+func TestValidatePlanFormat(t *testing.T) {
+	tests := []struct {
+		format      string
+		showCurrent bool
+		wantErr     string
+	}{
+		{format: "text"},
+		{format: "yaml", showCurrent: true},
+		{format: "yaml", wantErr: "--format yaml requires --show-current"},
+		{format: "findings"},
+		{format: "findings", showCurrent: true, wantErr: "--format findings can't be combined with --show-current"},
+		{format: "template"},
+		{format: "template", showCurrent: true, wantErr: "--format template can't be combined with --show-current"},
+		{format: "xml", wantErr: `invalid --format value "xml" (valid: text, yaml, findings, template)`},
+	}
+	for _, tt := range tests {
+		err := validatePlanFormat(tt.format, tt.showCurrent)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("validatePlanFormat(%q, %v) error = %v", tt.format, tt.showCurrent, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("validatePlanFormat(%q, %v) = %v, want %q", tt.format, tt.showCurrent, err, tt.wantErr)
+		}
+	}
+}
+
+// ptrString returns a pointer to s (test helper)
+func ptrString(s string) *string {
+	return &s
+}
+
+func TestChangeVerifier(t *testing.T) {
+	cfg := &config.Config{
+		Repo: &config.RepoConfig{Visibility: ptrString("public")},
+		Labels: &config.LabelsConfig{
+			Items: []config.Label{{Name: "bug", Color: "d73a4a"}},
+		},
+	}
+
+	// newPlan returns a mock in the planned state and the plan computed from it
+	newPlan := func(t *testing.T) (*github.MockClient, *diff.Calculator, *diff.Plan) {
+		t.Helper()
+		mock := github.NewMockClient()
+		mock.RepoData = &github.RepoData{Visibility: ptrString("private")}
+		calculator := diff.NewCalculator(mock, cfg)
+		plan, err := calculator.Calculate(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if plan.Size() != 2 {
+			t.Fatalf("expected 2 planned changes, got %d", plan.Size())
+		}
+		return mock, calculator, plan
+	}
+
+	t.Run("unchanged state keeps all changes", func(t *testing.T) {
+		_, calculator, plan := newPlan(t)
+		verifier := newChangeVerifier(calculator, diff.CalculateOptions{}, false)
+
+		kept, err := verifier.filter(context.Background(), plan.Changes())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(kept) != 2 {
+			t.Errorf("expected 2 changes, got %d", len(kept))
+		}
+	})
+
+	t.Run("value changed since plan is a conflict", func(t *testing.T) {
+		mock, calculator, plan := newPlan(t)
+		mock.RepoData.Visibility = ptrString("internal")
+		verifier := newChangeVerifier(calculator, diff.CalculateOptions{}, false)
+
+		_, err := verifier.filter(context.Background(), plan.FilterByCategory(diff.CategoryRepo).Changes())
+		if !apperrors.Is(err, apperrors.ErrPlanConflict) {
+			t.Fatalf("expected ErrPlanConflict, got %v", err)
+		}
+		want := "current value changed since plan: repo visibility: planned from private, now internal"
+		if err.Error() != want {
+			t.Errorf("error = %q, want %q", err.Error(), want)
+		}
+	})
+
+	t.Run("resource created since plan is a conflict", func(t *testing.T) {
+		mock, calculator, plan := newPlan(t)
+		mock.Labels = []github.LabelData{{Name: "bug", Color: "ffffff"}}
+		verifier := newChangeVerifier(calculator, diff.CalculateOptions{}, false)
+
+		_, err := verifier.filter(context.Background(), plan.FilterByCategory(diff.CategoryLabels).Changes())
+		if !apperrors.Is(err, apperrors.ErrPlanConflict) {
+			t.Fatalf("expected ErrPlanConflict, got %v", err)
+		}
+	})
+
+	t.Run("change already applied is a conflict", func(t *testing.T) {
+		mock, calculator, plan := newPlan(t)
+		mock.RepoData.Visibility = ptrString("public")
+		verifier := newChangeVerifier(calculator, diff.CalculateOptions{}, false)
+
+		_, err := verifier.filter(context.Background(), plan.FilterByCategory(diff.CategoryRepo).Changes())
+		if !apperrors.Is(err, apperrors.ErrPlanConflict) {
+			t.Fatalf("expected ErrPlanConflict, got %v", err)
+		}
+	})
+
+	t.Run("skip drops conflicting changes", func(t *testing.T) {
+		mock, calculator, plan := newPlan(t)
+		mock.RepoData.Visibility = ptrString("internal")
+		verifier := newChangeVerifier(calculator, diff.CalculateOptions{}, true)
+
+		kept, err := verifier.filter(context.Background(), plan.Changes())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(kept) != 1 || kept[0].Category != diff.CategoryLabels {
+			t.Errorf("expected only the label change to be kept, got %+v", kept)
+		}
+	})
+
+	t.Run("nil verifier keeps all changes", func(t *testing.T) {
+		_, _, plan := newPlan(t)
+		var verifier *changeVerifier
+
+		kept, err := verifier.filter(context.Background(), plan.Changes())
+		if err != nil || len(kept) != 2 {
+			t.Errorf("filter() = %d changes, %v; want 2, nil", len(kept), err)
+		}
+	})
+}
+
+// Test groupChanges helper logic via applyChanges structure
+func TestChangeCategoryGrouping(t *testing.T) {
+	changes := []diff.Change{
+		{Category: "repo", Key: "description", Type: diff.ChangeUpdate},
+		{Category: "repo", Key: "visibility", Type: diff.ChangeUpdate},
+		{Category: "topics", Key: "topics", Type: diff.ChangeUpdate},
+		{Category: "labels", Key: "bug", Type: diff.ChangeAdd},
+		{Category: "labels", Key: "feature", Type: diff.ChangeUpdate},
+		model.NewBranchProtectionUpdateChange("main", "required_reviews", 1, 2),
+		model.NewBranchProtectionUpdateChange("develop", "required_reviews", 1, 2),
+		{Category: "actions", Key: "enabled", Type: diff.ChangeUpdate},
+		{Category: "pages", Key: "build_type", Type: diff.ChangeUpdate},
+		{Category: "variables", Key: "NODE_ENV", Type: diff.ChangeAdd},
+		{Category: "secrets", Key: "API_KEY", Type: diff.ChangeAdd},
+	}
+
+	// Group changes by category (same logic as applyChanges)
+	repoChanges := make(map[string]interface{})
+	var topicsChanged bool
+	var labelChanges []diff.Change
+	branchProtectionChanges := make(map[string][]diff.Change)
+	var actionsChanges []diff.Change
+	var pagesChanges []diff.Change
+	var variableChanges []diff.Change
+	var secretChanges []diff.Change
+
+	for _, change := range changes {
+		switch change.Category {
+		case "repo":
+			repoChanges[change.Key] = change.New
+		case "topics":
+			topicsChanged = true
+		case "labels":
+			labelChanges = append(labelChanges, change)
+		case "branch_protection":
+			branchProtectionChanges[change.Branch] = append(branchProtectionChanges[change.Branch], change)
+		case "actions":
+			actionsChanges = append(actionsChanges, change)
+		case "pages":
+			pagesChanges = append(pagesChanges, change)
+		case "variables":
+			variableChanges = append(variableChanges, change)
+		case "secrets":
+			secretChanges = append(secretChanges, change)
+		}
+	}
+
+	// Verify grouping
+	if len(repoChanges) != 2 {
+		t.Errorf("expected 2 repo changes, got %d", len(repoChanges))
+	}
+	if !topicsChanged {
+		t.Error("expected topicsChanged to be true")
+	}
+	if len(labelChanges) != 2 {
+		t.Errorf("expected 2 label changes, got %d", len(labelChanges))
+	}
+	if len(branchProtectionChanges) != 2 {
+		t.Errorf("expected 2 branch protection branches, got %d", len(branchProtectionChanges))
+	}
+	if len(actionsChanges) != 1 {
+		t.Errorf("expected 1 actions change, got %d", len(actionsChanges))
+	}
+	if len(pagesChanges) != 1 {
+		t.Errorf("expected 1 pages change, got %d", len(pagesChanges))
+	}
+	if len(variableChanges) != 1 {
+		t.Errorf("expected 1 variable change, got %d", len(variableChanges))
+	}
+	if len(secretChanges) != 1 {
+		t.Errorf("expected 1 secret change, got %d", len(secretChanges))
+	}
+}
+
+// Helper function
+func containsStr(s, substr string) bool {
+	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsSubstr(s, substr))
+}
+
+func containsSubstr(s, substr string) bool {
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
+
+func TestApplyOrgActionsChanges(t *testing.T) {
+	t.Run("keeps current values for unconfigured fields", func(t *testing.T) {
+		mock := github.NewMockOrgClient()
+		allowed := "selected"
+		mock.ActionsPermissions = &github.OrgActionsPermissionsData{EnabledRepositories: "all", AllowedActions: &allowed}
+
+		enabled := "none"
+		cfg := &config.OrgActionsConfig{EnabledRepositories: &enabled}
+		changes := []diff.Change{
+			{Type: diff.ChangeUpdate, Category: diff.CategoryOrgActions, Key: "enabled_repositories", Old: "all", New: "none"},
+		}
+
+		if err := applyOrgActionsChanges(context.Background(), mock, cfg, changes, io.Discard); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(mock.UpdateOrgActionsPermissionsCalls) != 1 {
+			t.Fatalf("expected 1 permissions update, got %d", len(mock.UpdateOrgActionsPermissionsCalls))
+		}
+		call := mock.UpdateOrgActionsPermissionsCalls[0]
+		if call.EnabledRepositories != "none" || call.AllowedActions != "selected" {
+			t.Errorf("unexpected call: %+v", call)
+		}
+		if len(mock.UpdateOrgWorkflowPermsCalls) != 0 {
+			t.Errorf("expected no workflow update, got %d", len(mock.UpdateOrgWorkflowPermsCalls))
+		}
+	})
+
+	t.Run("updates workflow permissions", func(t *testing.T) {
+		mock := github.NewMockOrgClient()
+		mock.ActionsWorkflowPerms = &github.ActionsWorkflowPermissionsData{DefaultWorkflowPermissions: "write", CanApprovePullRequestReviews: true}
+
+		perms := "read"
+		cfg := &config.OrgActionsConfig{DefaultWorkflowPermissions: &perms}
+		changes := []diff.Change{
+			{Type: diff.ChangeUpdate, Category: diff.CategoryOrgActions, Key: "default_workflow_permissions", Old: "write", New: "read"},
+		}
+
+		var out bytes.Buffer
+		if err := applyOrgActionsChanges(context.Background(), mock, cfg, changes, &out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(out.String(), "Updating org workflow permissions... ✓") {
+			t.Errorf("expected the step in the output, got %q", out.String())
+		}
+
+		if len(mock.UpdateOrgWorkflowPermsCalls) != 1 {
+			t.Fatalf("expected 1 workflow update, got %d", len(mock.UpdateOrgWorkflowPermsCalls))
+		}
+		call := mock.UpdateOrgWorkflowPermsCalls[0]
+		if call.Permissions != "read" || !call.CanApprove {
+			t.Errorf("unexpected call: %+v", call)
+		}
+	})
+}
+
+func TestApplyTemplateChanges(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "bug.md")
+	if err := os.WriteFile(source, []byte("## Bug\n"), 0o644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	identity := func(a ...interface{}) string { return fmt.Sprint(a...) }
+
+	tests := []struct {
+		name        string
+		message     string
+		wantMessage string
+	}{
+		{name: "default commit message", wantMessage: "Update .github/ISSUE_TEMPLATE/bug.md"},
+		{name: "configured commit message", message: "chore: sync templates", wantMessage: "chore: sync templates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			cfg := &config.TemplatesConfig{
+				Message: tt.message,
+				Files:   []config.TemplateFile{{Path: ".github/ISSUE_TEMPLATE/bug.md", Source: source}},
+			}
+			changes := []diff.Change{
+				{Type: diff.ChangeAdd, Category: diff.CategoryTemplates, Key: ".github/ISSUE_TEMPLATE/bug.md", New: "blob 1234567"},
+			}
+
+			if err := applyTemplateChanges(context.Background(), mock, cfg, changes, io.Discard, identity, identity, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(mock.PutFileCalls) != 1 {
+				t.Fatalf("expected 1 PutFile call, got %d", len(mock.PutFileCalls))
+			}
+			call := mock.PutFileCalls[0]
+			if call.Path != ".github/ISSUE_TEMPLATE/bug.md" || string(call.Content) != "## Bug\n" {
+				t.Errorf("unexpected call: path=%s content=%q", call.Path, call.Content)
+			}
+			if call.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", call.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestCheckArchived(t *testing.T) {
+	boolPtr := func(b bool) *bool { return &b }
+
+	tests := []struct {
+		name     string
+		archived bool
+		cfg      *config.Config
+		wantErr  bool
+	}{
+		{
+			name:     "not archived",
+			archived: false,
+			cfg:      &config.Config{},
+		},
+		{
+			name:     "archived without archived setting is refused",
+			archived: true,
+			cfg:      &config.Config{Repo: &config.RepoConfig{Description: ptrString("desc")}},
+			wantErr:  true,
+		},
+		{
+			name:     "archived and config keeps it archived is refused",
+			archived: true,
+			cfg:      &config.Config{Repo: &config.RepoConfig{Archived: boolPtr(true)}},
+			wantErr:  true,
+		},
+		{
+			name:     "archived and config unarchives it",
+			archived: true,
+			cfg:      &config.Config{Repo: &config.RepoConfig{Archived: boolPtr(false)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			mock.RepoData.Archived = tt.archived
+
+			err := checkArchived(context.Background(), mock, tt.cfg)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "repository is archived; unarchive before applying") {
+					t.Errorf("checkArchived() error = %v, want archived refusal", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("checkArchived() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestUnarchiveAppliedFirst(t *testing.T) {
+	identity := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	changes := []diff.Change{
+		{Type: diff.ChangeUpdate, Category: diff.CategoryRepo, Key: "description", Old: "old", New: "new"},
+		{Type: diff.ChangeUpdate, Category: diff.CategoryRepo, Key: "archived", Old: true, New: false},
+	}
+
+	archive, rest := splitArchiveChange(changes)
+	if archive == nil || archive.New != false {
+		t.Fatalf("splitArchiveChange() archive = %+v, want unarchive change", archive)
+	}
+	if len(rest) != 1 || rest[0].Key != "description" {
+		t.Fatalf("splitArchiveChange() rest = %+v, want only description", rest)
+	}
+
+	mock := github.NewMockClient()
+	if err := applyArchived(context.Background(), mock, false, io.Discard, identity, identity); err != nil {
+		t.Fatalf("applyArchived() error = %v", err)
+	}
+	if len(mock.UpdateRepoCalls) != 1 {
+		t.Fatalf("expected 1 UpdateRepo call, got %d", len(mock.UpdateRepoCalls))
+	}
+	if got := mock.UpdateRepoCalls[0]; len(got) != 1 || got["archived"] != false {
+		t.Errorf("UpdateRepo settings = %v, want only archived=false", got)
+	}
+}
+
+func TestSpinnerDisabled(t *testing.T) {
+	origLevel := logger.DefaultLevel()
+	t.Cleanup(func() { logger.SetDefaultLevel(origLevel) })
+
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	logger.SetDefaultLevel(logger.LevelNormal)
+	if spinnerEnabled(f, false) {
+		t.Error("spinner should be disabled when the output is not a terminal")
+	}
+	if spinnerEnabled(f, true) {
+		t.Error("spinner should be disabled in JSON mode")
+	}
+
+	logger.SetDefaultLevel(logger.LevelQuiet)
+	if spinnerEnabled(f, false) {
+		t.Error("spinner should be disabled in quiet mode")
+	}
+
+	// A disabled spinner writes nothing
+	var buf bytes.Buffer
+	sp := newSpinner(&buf, false)
+	sp.Progress("branch protection")
+	sp.Stop()
+	if buf.Len() != 0 {
+		t.Errorf("disabled spinner wrote %q", buf.String())
+	}
+}
+
+func TestSpinnerEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	sp := newSpinner(&buf, true)
+	sp.Progress("branch protection")
+	sp.Stop()
+	sp.Stop()
+
+	out := buf.String()
+	if !strings.Contains(out, "Fetching branch protection…") {
+		t.Errorf("spinner output %q should contain the current operation", out)
+	}
+	if !strings.HasSuffix(out, "\r\033[K") {
+		t.Errorf("spinner output %q should end by clearing the line", out)
+	}
+}
+
+func TestPrintCurrentField(t *testing.T) {
+	topics := []string{"go", "cli"}
+	reviews := 2
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{
+		Visibility:       ptrString("private"),
+		AllowSquashMerge: ptr(true),
+		Topics:           &topics,
+	}
+	mock.BranchProtections = map[string]*github.BranchProtectionData{
+		"release.v1": {
+			RequiredPullRequestReviews: &githubopenapi.ProtectedBranchPullRequestReview{
+				RequiredApprovingReviewCount: &reviews,
+			},
+			RequiredStatusChecks: &githubopenapi.ProtectedBranchRequiredStatusCheck{
+				Contexts: []string{"ci/test", "ci/lint"},
+			},
+		},
+	}
+	mock.GetPagesError = apperrors.ErrPagesNotEnabled
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{field: "visibility", want: "private\n"},
+		{field: "repo.allow_squash_merge", want: "true\n"},
+		{field: "repo.homepage", want: "\n"},
+		{field: "topics", want: "go\ncli\n"},
+		{field: "branch_protection.release.v1.required_reviews", want: "2\n"},
+		{field: "branch_protection.release.v1.status_checks", want: "ci/test\nci/lint\n"},
+		{field: "branch_protection.main.required_reviews", want: "\n"},
+		{field: "pages.cname", want: "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printCurrentField(context.Background(), mock, &buf, tt.field); err != nil {
+				t.Fatalf("printCurrentField() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("printCurrentField(%q) = %q, want %q", tt.field, buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCurrentFieldUnknown(t *testing.T) {
+	for _, field := range []string{"visbility", "repo.topics", "branch_protection.main", "branch_protection.main.unknown", "pages"} {
+		_, err := parseCurrentField(field)
+		if err == nil {
+			t.Errorf("parseCurrentField(%q) expected error", field)
+			continue
+		}
+		if !strings.Contains(err.Error(), "branch_protection.<branch>.required_reviews") {
+			t.Errorf("expected supported fields in error, got %q", err.Error())
+		}
+	}
+}
+
+func TestApplyActionsChangesAccessLevel(t *testing.T) {
+	mock := github.NewMockClient()
+	cfg := &config.Config{Actions: &config.ActionsConfig{AccessLevel: ptr("organization")}}
+	changes := []diff.Change{
+		{Type: diff.ChangeUpdate, Category: diff.CategoryActions, Key: "access_level", Old: "none", New: "organization"},
+	}
+
+	identity := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	if err := applyActionsChanges(context.Background(), mock, cfg, changes, io.Discard, identity, identity); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mock.UpdateActionsAccessLevelCalls) != 1 || mock.UpdateActionsAccessLevelCalls[0] != "organization" {
+		t.Errorf("expected one access level update to organization, got %v", mock.UpdateActionsAccessLevelCalls)
+	}
+	if len(mock.UpdateActionsPermissionsCalls) != 0 || len(mock.UpdateActionsWorkflowPermsCalls) != 0 {
+		t.Error("expected no other actions updates")
+	}
+}
+
+func TestApplyActionsChangesRetention(t *testing.T) {
+	mock := github.NewMockClient()
+	cfg := &config.Config{Actions: &config.ActionsConfig{ArtifactRetentionDays: ptr(30)}}
+	changes := []diff.Change{
+		{Type: diff.ChangeUpdate, Category: diff.CategoryActions, Key: "artifact_retention_days", Old: 90, New: 30},
+	}
+
+	identity := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	if err := applyActionsChanges(context.Background(), mock, cfg, changes, io.Discard, identity, identity); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mock.UpdateActionsRetentionCalls) != 1 || mock.UpdateActionsRetentionCalls[0] != 30 {
+		t.Errorf("expected one retention update to 30 days, got %v", mock.UpdateActionsRetentionCalls)
+	}
+	if len(mock.UpdateActionsPermissionsCalls) != 0 || len(mock.UpdateActionsAccessLevelCalls) != 0 {
+		t.Error("expected no other actions updates")
+	}
+}
+
+func TestGroupApplyChanges(t *testing.T) {
+	plan := model.NewPlanFromChanges([]diff.Change{
+		{Type: diff.ChangeUpdate, Category: diff.CategoryRepo, Key: "description"},
+		{Type: diff.ChangeUpdate, Category: diff.CategoryTopics, Key: "topics"},
+		{Type: diff.ChangeAdd, Category: diff.CategoryLabels, Key: "bug"},
+		model.NewBranchProtectionUpdateChange("main", "required_reviews", 1, 2),
+		model.NewBranchProtectionUpdateChange("release", "enforce_admins", false, true),
+		{Type: diff.ChangeUpdate, Category: diff.CategoryActions, Key: "enabled"},
+		{Type: diff.ChangeUpdate, Category: diff.CategoryPages, Key: "build_type"},
+		{Type: diff.ChangeAdd, Category: diff.CategorySocialPreview, Key: "social_preview_image"},
+		{Type: diff.ChangeAdd, Category: diff.CategoryTemplates, Key: ".github/PULL_REQUEST_TEMPLATE.md"},
+		{Type: diff.ChangeAdd, Category: diff.CategoryVariables, Key: "ENV"},
+		{Type: diff.ChangeAdd, Category: diff.CategorySecrets, Key: "TOKEN"},
+		{Type: diff.ChangeInfo, Category: diff.CategorySecrets, Key: "LEGACY_TOKEN"},
+		{Type: diff.ChangeUpdate, Category: diff.CategoryOrgActions, Key: "enabled_repositories"},
+	})
+
+	groups := groupApplyChanges(plan)
+
+	single := map[string][]diff.Change{
+		"repo":           groups.repo,
+		"topics":         groups.topics,
+		"labels":         groups.labels,
+		"actions":        groups.actions,
+		"pages":          groups.pages,
+		"social_preview": groups.socialPreview,
+		"templates":      groups.templates,
+		"variables":      groups.variables,
+		"secrets":        groups.secrets,
+	}
+	for name, changes := range single {
+		if len(changes) != 1 || changes[0].Category.String() != name {
+			t.Errorf("%s: expected its one change, got %+v", name, changes)
+		}
+	}
+	if len(groups.branchProtection) != 2 || len(groups.branchProtection["main"]) != 1 || len(groups.branchProtection["release"]) != 1 {
+		t.Errorf("branch protection not grouped by branch: %+v", groups.branchProtection)
+	}
+}
+
+func TestGroupApplyChangesDottedBranches(t *testing.T) {
+	// Joined into keys these are ambiguous: "release/v1.0" could be the "0" setting
+	// of "release/v1", and "main.required_reviews.strict_status_checks" a setting of "main"
+	plan := model.NewPlanFromChanges([]diff.Change{
+		model.NewBranchProtectionUpdateChange("release/v1.0", "required_reviews", 1, 2),
+		model.NewBranchProtectionUpdateChange("release/v1.0", "enforce_admins", false, true),
+		model.NewBranchProtectionAddChange("release/v1", "{required_reviews=1}"),
+		model.NewBranchProtectionUpdateChange("main.required_reviews", "strict_status_checks", false, true),
+		model.NewBranchProtectionUpdateChange("main", "required_reviews", 1, 2),
+	})
+
+	groups := groupApplyChanges(plan)
+
+	want := map[string][]string{
+		"release/v1.0":          {"required_reviews", "enforce_admins"},
+		"release/v1":            {""},
+		"main.required_reviews": {"strict_status_checks"},
+		"main":                  {"required_reviews"},
+	}
+	if len(groups.branchProtection) != len(want) {
+		t.Fatalf("got %d branches, want %d: %+v", len(groups.branchProtection), len(want), groups.branchProtection)
+	}
+	for branch, fields := range want {
+		var got []string
+		for _, c := range groups.branchProtection[branch] {
+			got = append(got, c.Field)
+		}
+		if strings.Join(got, ",") != strings.Join(fields, ",") {
+			t.Errorf("%s: fields = %q, want %q", branch, got, fields)
+		}
+	}
+}
+
+func TestApplyBranchProtectionKeepsIgnoredSettings(t *testing.T) {
+	for _, enforceAdmins := range []*bool{nil, ptr(false)} {
+		mock := github.NewMockClient()
+		mock.BranchProtections["main"] = &github.BranchProtectionData{
+			RequiredPullRequestReviews: &githubopenapi.ProtectedBranchPullRequestReview{RequiredApprovingReviewCount: ptr(1)},
+			EnforceAdmins:              &githubopenapi.ProtectedBranchAdminEnforced{Enabled: true},
+		}
+		cfg := &config.Config{
+			BranchProtection: map[string]*config.BranchRule{
+				"main": {RequiredReviews: ptr(2), EnforceAdmins: enforceAdmins},
+			},
+			Ignore: []string{"branch_protection.main.enforce_admins"},
+		}
+
+		plan, err := diff.NewCalculator(mock, cfg).Calculate(context.Background())
+		if err != nil {
+			t.Fatalf("Calculate() error = %v", err)
+		}
+		if err := applyChanges(context.Background(), mock, cfg, plan, nil, nil, nil); err != nil {
+			t.Fatalf("applyChanges() error = %v", err)
+		}
+
+		if len(mock.UpdateBranchProtectionCalls) != 1 {
+			t.Fatalf("expected one update of main, got %+v", mock.UpdateBranchProtectionCalls)
+		}
+		settings := mock.UpdateBranchProtectionCalls[0].Settings
+		if settings.RequiredReviews == nil || *settings.RequiredReviews != 2 {
+			t.Errorf("required_reviews = %v, want 2", settings.RequiredReviews)
+		}
+		if settings.EnforceAdmins == nil || !*settings.EnforceAdmins {
+			t.Errorf("enforce_admins = %v with config %v, want the current true", settings.EnforceAdmins, enforceAdmins)
+		}
+		if cfg.BranchProtection["main"].EnforceAdmins != enforceAdmins {
+			t.Error("the config's rule must not be modified")
+		}
+	}
+}
+
+func TestApplyDottedBranchProtection(t *testing.T) {
+	mock := github.NewMockClient()
+	cfg := &config.Config{BranchProtection: map[string]*config.BranchRule{
+		"release/v1.0": {RequiredReviews: ptr(2), EnforceAdmins: ptr(true)},
+	}}
+	plan := model.NewPlanFromChanges([]diff.Change{
+		model.NewBranchProtectionUpdateChange("release/v1.0", "required_reviews", 1, 2),
+		model.NewBranchProtectionUpdateChange("release/v1.0", "enforce_admins", false, true),
+	})
+
+	if err := applyChanges(context.Background(), mock, cfg, plan, nil, nil, nil); err != nil {
+		t.Fatalf("applyChanges() error = %v", err)
+	}
+	if len(mock.UpdateBranchProtectionCalls) != 1 || mock.UpdateBranchProtectionCalls[0].Branch != "release/v1.0" {
+		t.Errorf("expected one update of release/v1.0, got %+v", mock.UpdateBranchProtectionCalls)
+	}
+}
+
+func TestApplyLabelUpdateByCurrentName(t *testing.T) {
+	mock := github.NewMockClient()
+	cfg := &config.Config{Labels: &config.LabelsConfig{Items: []config.Label{{Name: "bug", Color: "d73a4a"}}}}
+	change := model.NewUpdateChange(model.CategoryLabels, "bug", "color=d73a4a, description=", "color=d73a4a, description=")
+	change.CurrentName = "bug "
+	plan := model.NewPlanFromChanges([]diff.Change{change})
+
+	if err := applyChanges(context.Background(), mock, cfg, plan, nil, nil, nil); err != nil {
+		t.Fatalf("applyChanges() error = %v", err)
+	}
+	if len(mock.UpdateLabelCalls) != 1 {
+		t.Fatalf("expected one label update, got %+v", mock.UpdateLabelCalls)
+	}
+	if call := mock.UpdateLabelCalls[0]; call.OldName != "bug " || call.NewName != "bug" {
+		t.Errorf("UpdateLabel(%q -> %q), want \"bug \" -> \"bug\"", call.OldName, call.NewName)
+	}
+}
+
+func TestCheckRepoLocation(t *testing.T) {
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{FullName: "new-owner/test-repo"}
+
+	if err := checkRepoLocation(context.Background(), mock, false); err != nil {
+		t.Errorf("checkRepoLocation() should only warn, got %v", err)
+	}
+
+	err := checkRepoLocation(context.Background(), mock, true)
+	if !apperrors.Is(err, apperrors.ErrRepoMoved) {
+		t.Fatalf("checkRepoLocation() with strict = %v, want ErrRepoMoved", err)
+	}
+	if !strings.Contains(err.Error(), "new-owner/test-repo") {
+		t.Errorf("error should name the new location: %v", err)
+	}
+}
+
+func TestRollbackPlan(t *testing.T) {
+	applied := model.NewPlanFromChanges([]diff.Change{
+		model.NewUpdateChange(diff.CategoryRepo, "description", "before", "after"),
+		model.NewUpdateChange(diff.CategoryTopics, "topics", []string{"go"}, []string{"go", "cli"}),
+		model.NewAddChange(diff.CategoryLabels, "triage", "color=ededed, description="),
+		model.NewDeleteChange(diff.CategoryLabels, "wontfix", "color=ffffff, description=Won't be fixed"),
+		model.NewBranchProtectionUpdateChange("release/v1.0", "required_reviews", 1, 2),
+		model.NewUpdateChange(diff.CategoryVariables, "REGION", "us", "eu"),
+		model.NewAddChange(diff.CategorySecrets, "TOKEN", "(will be set from .env)"),
+		model.NewDeleteChange(diff.CategorySecrets, "OLD_TOKEN", "(existing secret)"),
+		model.NewUpdateChange(diff.CategoryPages, "build_type", "legacy", "workflow"),
+		model.NewBranchProtectionAddChange("main", "protection rule"),
+	})
+
+	// The plan is read back from JSON, as rollback does
+	data, err := diff.PlanMarshalIndent(applied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := diff.UnmarshalPlan(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock := github.NewMockClient()
+	mock.BranchProtections["release/v1.0"] = &github.BranchProtectionData{
+		RequiredPullRequestReviews: &githubopenapi.ProtectedBranchPullRequestReview{
+			RequiredApprovingReviewCount: ptr(2),
+		},
+		EnforceAdmins: &githubopenapi.ProtectedBranchAdminEnforced{Enabled: true},
+	}
+
+	inverse, cfg, skipped, err := rollbackPlan(context.Background(), mock, saved)
+	if err != nil {
+		t.Fatalf("rollbackPlan() error = %v", err)
+	}
+	var skippedKeys []string
+	for _, skip := range skipped {
+		skippedKeys = append(skippedKeys, fmt.Sprintf("%s.%s: %s", skip.change.Category, skip.change.Key, skip.reason))
+	}
+	want := []string{
+		"branch_protection.main: " + skipProtectionAdd,
+		"pages.build_type: " + skipUnsupported,
+		"secrets.OLD_TOKEN: " + skipSecretValue,
+	}
+	if !reflect.DeepEqual(skippedKeys, want) {
+		t.Errorf("skipped = %v, want %v", skippedKeys, want)
+	}
+
+	if err := applyChanges(context.Background(), mock, cfg, inverse, nil, nil, nil); err != nil {
+		t.Fatalf("applyChanges() error = %v", err)
+	}
+
+	if len(mock.UpdateRepoCalls) != 1 || mock.UpdateRepoCalls[0]["description"] != "before" {
+		t.Errorf("UpdateRepo calls = %v, want description restored", mock.UpdateRepoCalls)
+	}
+	if len(mock.SetTopicsCalls) != 1 || !reflect.DeepEqual(mock.SetTopicsCalls[0], []string{"go"}) {
+		t.Errorf("SetTopics calls = %v, want [go]", mock.SetTopicsCalls)
+	}
+	if !reflect.DeepEqual(mock.DeleteLabelCalls, []string{"triage"}) {
+		t.Errorf("DeleteLabel calls = %v, want [triage]", mock.DeleteLabelCalls)
+	}
+	if len(mock.CreateLabelCalls) != 1 || mock.CreateLabelCalls[0].Name != "wontfix" ||
+		mock.CreateLabelCalls[0].Color != "ffffff" || mock.CreateLabelCalls[0].Description != "Won't be fixed" {
+		t.Errorf("CreateLabel calls = %+v, want wontfix recreated", mock.CreateLabelCalls)
+	}
+	if len(mock.UpdateBranchProtectionCalls) != 1 {
+		t.Fatalf("UpdateBranchProtection calls = %+v, want 1", mock.UpdateBranchProtectionCalls)
+	}
+	settings := mock.UpdateBranchProtectionCalls[0].Settings
+	if settings.RequiredReviews == nil || *settings.RequiredReviews != 1 {
+		t.Errorf("required reviews = %v, want 1", settings.RequiredReviews)
+	}
+	// Settings the plan didn't touch keep their current value
+	if settings.EnforceAdmins == nil || !*settings.EnforceAdmins {
+		t.Errorf("enforce admins = %v, want true", settings.EnforceAdmins)
+	}
+	if len(mock.SetVariableCalls) != 1 || mock.SetVariableCalls[0] != (github.VariableCall{Name: "REGION", Value: "us"}) {
+		t.Errorf("SetVariable calls = %+v, want REGION=us", mock.SetVariableCalls)
+	}
+	if !reflect.DeepEqual(mock.DeleteSecretCalls, []string{"TOKEN"}) || len(mock.SetSecretCalls) != 0 {
+		t.Errorf("secret calls = set %+v, delete %v; want only TOKEN deleted", mock.SetSecretCalls, mock.DeleteSecretCalls)
+	}
+	if len(mock.UpdatePagesCalls) != 0 {
+		t.Errorf("Pages changes can't be undone, got %+v", mock.UpdatePagesCalls)
+	}
+}
+
+func TestPatchConfigKey(t *testing.T) {
+	actions := &config.ActionsConfig{
+		Enabled:         ptr(true),
+		SelectedActions: &config.SelectedActionsConfig{PatternsAllowed: []string{"actions/*"}},
+	}
+	if err := patchConfigKey(actions, "selected_actions.patterns_allowed", []interface{}{"docker/*"}); err != nil {
+		t.Fatalf("patchConfigKey() error = %v", err)
+	}
+	if err := patchConfigKey(actions, "artifact_retention_days", float64(30)); err != nil {
+		t.Fatalf("patchConfigKey() error = %v", err)
+	}
+	if !reflect.DeepEqual(actions.SelectedActions.PatternsAllowed, []string{"docker/*"}) {
+		t.Errorf("patterns = %v, want [docker/*]", actions.SelectedActions.PatternsAllowed)
+	}
+	if actions.ArtifactRetentionDays == nil || *actions.ArtifactRetentionDays != 30 {
+		t.Errorf("retention = %v, want 30", actions.ArtifactRetentionDays)
+	}
+	if actions.Enabled == nil || !*actions.Enabled {
+		t.Errorf("enabled = %v, want it untouched", actions.Enabled)
+	}
+
+	rule := &config.BranchRule{}
+	if err := patchConfigKey(rule, "status_checks", statusCheckValues([]interface{}{"lint", "test (app 15368)"})); err != nil {
+		t.Fatalf("patchConfigKey() error = %v", err)
+	}
+	if len(rule.StatusChecks) != 2 || rule.StatusChecks[1].String() != "test (app 15368)" {
+		t.Errorf("status checks = %v", rule.StatusChecks)
+	}
+}
+
+// scriptedPrompt answers change prompts in order and records the changes asked about
+func scriptedPrompt(answers ...changeDecision) (changePrompt, *[]string) {
+	var asked []string
+	return func(change diff.Change, index, total int) (changeDecision, error) {
+		asked = append(asked, fmt.Sprintf("%d/%d %s", index, total, change.Key))
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}, &asked
+}
+
+func TestSelectChanges(t *testing.T) {
+	plan := model.NewPlanFromChanges([]diff.Change{
+		{Type: diff.ChangeUpdate, Category: diff.CategoryRepo, Key: "description", Old: "a", New: "b"},
+		{Type: diff.ChangeAdd, Category: diff.CategoryLabels, Key: "bug", New: "ff0000"},
+		{Type: diff.ChangeDelete, Category: diff.CategoryLabels, Key: "wontfix", Old: "ffffff"},
+	})
+
+	t.Run("select some", func(t *testing.T) {
+		prompt, asked := scriptedPrompt(decisionApply, decisionSkip, decisionApply)
+		selected, ok, err := selectChanges(plan, prompt)
+		if err != nil || !ok {
+			t.Fatalf("selectChanges() = %v, %v", ok, err)
+		}
+		var keys []string
+		for _, c := range selected.Changes() {
+			keys = append(keys, c.Key)
+		}
+		if strings.Join(keys, ",") != "description,wontfix" {
+			t.Errorf("selected = %v", keys)
+		}
+		if strings.Join(*asked, ",") != "1/3 description,2/3 bug,3/3 wontfix" {
+			t.Errorf("asked = %v", *asked)
+		}
+	})
+
+	t.Run("skip all", func(t *testing.T) {
+		prompt, _ := scriptedPrompt(decisionSkip, decisionSkip, decisionSkip)
+		selected, ok, err := selectChanges(plan, prompt)
+		if err != nil || !ok {
+			t.Fatalf("selectChanges() = %v, %v", ok, err)
+		}
+		if selected.HasChanges() {
+			t.Errorf("expected no changes, got %+v", selected.Changes())
+		}
+	})
+
+	t.Run("quit discards confirmed changes", func(t *testing.T) {
+		prompt, asked := scriptedPrompt(decisionApply, decisionQuit)
+		selected, ok, err := selectChanges(plan, prompt)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok || selected != nil {
+			t.Errorf("expected quit, got ok=%v selected=%v", ok, selected)
+		}
+		if len(*asked) != 2 {
+			t.Errorf("expected no prompts after quit, got %v", *asked)
+		}
+	})
+
+	t.Run("prompt error", func(t *testing.T) {
+		failing := func(diff.Change, int, int) (changeDecision, error) { return "", fmt.Errorf("interrupt") }
+		if _, _, err := selectChanges(plan, failing); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestSelectCategories(t *testing.T) {
+	plan := model.NewPlanFromChanges([]diff.Change{
+		{Type: diff.ChangeUpdate, Category: diff.CategoryRepo, Key: "description", Old: "a", New: "b"},
+		{Type: diff.ChangeAdd, Category: diff.CategoryLabels, Key: "bug", New: "ff0000"},
+		{Type: diff.ChangeUpdate, Category: diff.CategoryRepo, Key: "homepage", Old: "", New: "https://example.com"},
+		{Type: diff.ChangeDelete, Category: diff.CategoryLabels, Key: "wontfix", Old: "ffffff"},
+		{Type: diff.ChangeAdd, Category: diff.CategoryTopics, Key: "topics", New: []string{"go"}},
+	})
+
+	var asked []string
+	prompt := func(category diff.ChangeCategory, changes []diff.Change, index, total int) (bool, error) {
+		asked = append(asked, fmt.Sprintf("%d/%d %s:%d", index, total, category, len(changes)))
+		return category != diff.CategoryLabels, nil
+	}
+
+	selected, skipped, err := selectCategories(plan, prompt)
+	if err != nil {
+		t.Fatalf("selectCategories() error = %v", err)
+	}
+
+	if strings.Join(asked, ",") != "1/3 repo:2,2/3 labels:2,3/3 topics:1" {
+		t.Errorf("asked = %v", asked)
+	}
+	var keys []string
+	for _, c := range selected.Changes() {
+		keys = append(keys, c.Key)
+	}
+	if strings.Join(keys, ",") != "description,homepage,topics" {
+		t.Errorf("selected = %v", keys)
+	}
+	if joinCategories(skipped) != "labels" {
+		t.Errorf("skipped = %v", skipped)
+	}
+
+	t.Run("prompt error", func(t *testing.T) {
+		failing := func(diff.ChangeCategory, []diff.Change, int, int) (bool, error) {
+			return false, fmt.Errorf("interrupt")
+		}
+		if _, _, err := selectCategories(plan, failing); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestRenderPlanInfoOnly(t *testing.T) {
+	plan := model.NewPlanFromChanges([]diff.Change{
+		model.NewInfoChange(diff.CategorySecrets, "LEGACY_TOKEN", "exists on GitHub but is not in the config"),
+	})
+
+	data, err := renderPlanOutput(plan, false, false)
+	if err != nil {
+		t.Fatalf("renderPlanOutput() error = %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{"i LEGACY_TOKEN", "Plan: 0 to add, 0 to change, 0 to destroy, 1 info."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "gh repo-settings apply") {
+		t.Errorf("informational findings should not suggest apply:\n%s", out)
+	}
+	if code := exitCodeFor(plan.Stats(), []diff.ChangeType{diff.ChangeAdd, diff.ChangeUpdate, diff.ChangeDelete, diff.ChangeMissing}); code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+}
+
+func TestOutputPlanQuietSuccess(t *testing.T) {
+	origQuiet, origOut, origJSON, origSummary := planQuietSuccess, planOut, jsonOutput, planSummary
+	origLevel := logger.DefaultLevel()
+	t.Cleanup(func() {
+		planQuietSuccess, planOut, jsonOutput, planSummary = origQuiet, origOut, origJSON, origSummary
+		logger.SetDefaultLevel(origLevel)
+		logger.Default().SetOutput(os.Stdout)
+	})
+	planQuietSuccess, planOut, planSummary = true, "", false
+	logger.SetDefaultLevel(logger.LevelNormal)
+
+	for _, asJSON := range []bool{false, true} {
+		jsonOutput = asJSON
+		var out, logs bytes.Buffer
+		logger.Default().SetOutput(&logs)
+
+		announcePlan(nil, "Planning changes for %s...", "owner/repo")
+		announcePlan(model.NewPlan(), "Planning changes for %s...", "owner/repo")
+		if err := outputPlanTo(&out, model.NewPlan(), "No changes detected."); err != nil {
+			t.Fatalf("outputPlanTo() error = %v", err)
+		}
+		if out.Len() != 0 || logs.Len() != 0 {
+			t.Errorf("json=%v: expected no output for an empty plan, got %q and logs %q", asJSON, out.String(), logs.String())
+		}
+	}
+
+	t.Run("drift is printed", func(t *testing.T) {
+		jsonOutput = false
+		var out, logs bytes.Buffer
+		logger.Default().SetOutput(&logs)
+		plan := model.NewPlanFromChanges([]diff.Change{
+			{Category: "repo", Key: "description", Type: diff.ChangeUpdate, Old: "old", New: "new"},
+		})
+
+		announcePlan(nil, "Planning changes...")
+		announcePlan(plan, "Planning changes...")
+		if err := outputPlanTo(&out, plan, "No changes detected."); err != nil {
+			t.Fatalf("outputPlanTo() error = %v", err)
+		}
+		if !strings.Contains(out.String(), "description") {
+			t.Errorf("expected the plan, got %q", out.String())
+		}
+		if strings.Count(logs.String(), "Planning changes...") != 1 {
+			t.Errorf("expected the banner once, got %q", logs.String())
+		}
+	})
+}
+
+func TestCheckPlanHash(t *testing.T) {
+	approved := model.NewPlanFromChanges([]diff.Change{
+		{Type: diff.ChangeUpdate, Category: diff.CategoryRepo, Key: "description", Old: "old", New: "new"},
+	})
+	hash := approved.Hash()
+
+	if err := checkPlanHash(approved, hash); err != nil {
+		t.Errorf("unexpected error for the approved plan: %v", err)
+	}
+	if err := checkPlanHash(approved, " "+strings.ToUpper(hash)+"\n"); err != nil {
+		t.Errorf("hash comparison should ignore case and surrounding space: %v", err)
+	}
+
+	// Someone changed the description again after the plan was reviewed
+	changed := model.NewPlanFromChanges([]diff.Change{
+		{Type: diff.ChangeUpdate, Category: diff.CategoryRepo, Key: "description", Old: "edited", New: "new"},
+	})
+	err := checkPlanHash(changed, hash)
+	if !apperrors.Is(err, apperrors.ErrPlanConflict) {
+		t.Fatalf("expected ErrPlanConflict, got %v", err)
+	}
+	if !strings.Contains(err.Error(), hash) {
+		t.Errorf("error should name the expected hash: %v", err)
+	}
+}
+
+func TestApprovedPlanHashesBeforeSkippingBranches(t *testing.T) {
+	plan := model.NewPlanFromChanges([]diff.Change{
+		model.NewBranchProtectionAddChange("develop", "{required_reviews=1}"),
+		model.NewUpdateChange(model.CategoryRepo, "description", "old", "new"),
+	})
+	hash := plan.Hash()
+
+	mock := github.NewMockClient()
+	mock.MissingBranches = []string{"develop"}
+
+	approved, err := approvedPlan(context.Background(), mock, plan, hash, true)
+	if err != nil {
+		t.Fatalf("approvedPlan() error = %v", err)
+	}
+	if approved.Size() != 1 {
+		t.Errorf("expected the change to the missing branch to be skipped, got %v", approved.Changes())
+	}
+
+	if _, err := approvedPlan(context.Background(), mock, approved, hash, true); !apperrors.Is(err, apperrors.ErrPlanConflict) {
+		t.Errorf("expected ErrPlanConflict for a different plan, got %v", err)
+	}
+}
+
+func TestRenderWorkflow(t *testing.T) {
+	tests := []struct {
+		name       string
+		configPath string
+		isDir      bool
+		wantFlag   string
+		wantPath   string
+	}{
+		{name: "single file", configPath: ".github/repo-settings.yaml", wantFlag: "-c .github/repo-settings.yaml", wantPath: ".github/repo-settings.yaml"},
+		{name: "directory", configPath: ".github/repo-settings/", isDir: true, wantFlag: "-d .github/repo-settings", wantPath: ".github/repo-settings/**"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := renderWorkflow(tt.configPath, tt.isDir)
+
+			var workflow struct {
+				On struct {
+					PullRequest struct {
+						Paths []string `yaml:"paths"`
+					} `yaml:"pull_request"`
+					Push struct {
+						Branches []string `yaml:"branches"`
+						Paths    []string `yaml:"paths"`
+					} `yaml:"push"`
+				} `yaml:"on"`
+				Jobs map[string]struct {
+					Steps []struct {
+						Run string `yaml:"run"`
+					} `yaml:"steps"`
+				} `yaml:"jobs"`
+			}
+			if err := yaml.Unmarshal(data, &workflow); err != nil {
+				t.Fatalf("workflow is not valid YAML: %v\n%s", err, data)
+			}
+
+			for _, paths := range [][]string{workflow.On.PullRequest.Paths, workflow.On.Push.Paths} {
+				if len(paths) == 0 || paths[0] != tt.wantPath {
+					t.Errorf("paths = %v, want %q first", paths, tt.wantPath)
+				}
+			}
+			if len(workflow.On.Push.Branches) != 1 || workflow.On.Push.Branches[0] != "main" {
+				t.Errorf("push branches = %v", workflow.On.Push.Branches)
+			}
+
+			runs := map[string]string{}
+			for job, def := range workflow.Jobs {
+				runs[job] = def.Steps[len(def.Steps)-1].Run
+			}
+			if runs["plan"] != "gh repo-settings plan "+tt.wantFlag {
+				t.Errorf("plan step = %q", runs["plan"])
+			}
+			if runs["apply"] != "gh repo-settings apply -y "+tt.wantFlag {
+				t.Errorf("apply step = %q", runs["apply"])
+			}
+		})
+	}
+}
+
+func TestWriteWorkflowKeepsExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workflows", "repo-settings.yml")
+
+	if err := writeWorkflow(path, ".github/repo-settings.yaml", false); err != nil {
+		t.Fatalf("writeWorkflow() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte("custom"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeWorkflow(path, ".github/repo-settings.yaml", false); err != nil {
+		t.Fatalf("writeWorkflow() error = %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "custom" {
+		t.Errorf("existing workflow was overwritten: %q", got)
+	}
+}
+
+func TestCheckRollbackRepository(t *testing.T) {
+	mock := github.NewMockClient()
+	mock.Owner, mock.Name = "acme", "widgets"
+
+	tests := []struct {
+		planned string
+		wantErr string
+	}{
+		{"acme/widgets", ""},
+		{"Acme/Widgets", ""},
+		{"acme/gadgets", "the plan is for acme/gadgets, not acme/widgets"},
+		{"", "doesn't name its repository"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.planned, func(t *testing.T) {
+			saved := model.NewPlanFromChanges([]diff.Change{
+				model.NewUpdateChange(diff.CategoryRepo, "description", "before", "after"),
+			})
+			saved.SetRepository(tt.planned)
+
+			err := checkRollbackRepository(mock, saved)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkRollbackRepository() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkRollbackRepository() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShowSavedPlan(t *testing.T) {
+	original := model.NewPlanFromChanges([]diff.Change{
+		{Type: diff.ChangeUpdate, Category: diff.CategoryRepo, Key: "description", Old: "a", New: "b"},
+		{Type: diff.ChangeAdd, Category: diff.CategoryLabels, Key: "bug", New: "color=ff0000"},
+	})
+	data, err := diff.PlanMarshalIndent(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := readSavedPlan(path)
+	if err != nil {
+		t.Fatalf("readSavedPlan() error = %v", err)
+	}
+
+	var got, want bytes.Buffer
+	if err := showPlan(&got, plan); err != nil {
+		t.Fatalf("showPlan() error = %v", err)
+	}
+	renderPlan(&want, original, false, false)
+	if got.String() != want.String() {
+		t.Errorf("showPlan() =\n%s\nwant\n%s", got.String(), want.String())
+	}
+
+	t.Run("text plan", func(t *testing.T) {
+		textPath := filepath.Join(t.TempDir(), "plan.txt")
+		if err := os.WriteFile(textPath, want.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readSavedPlan(textPath); err == nil || !strings.Contains(err.Error(), "--json") {
+			t.Errorf("expected an error pointing to --json, got %v", err)
+		}
+	})
+}
+
+func TestConfirmDisruptive(t *testing.T) {
+	switchBuildType := model.NewUpdateChange(diff.CategoryPages, "build_type", "legacy", "workflow").WithWarning("may take the site down")
+	disruptive := model.NewPlanFromChanges([]diff.Change{
+		{Type: diff.ChangeUpdate, Category: diff.CategoryRepo, Key: "description", Old: "a", New: "b"},
+		switchBuildType,
+	})
+	safe := model.NewPlanFromChanges([]diff.Change{
+		{Type: diff.ChangeUpdate, Category: diff.CategoryRepo, Key: "description", Old: "a", New: "b"},
+	})
+
+	answer := func(yes bool, asked *[]string) func(string) (bool, error) {
+		return func(question string) (bool, error) {
+			*asked = append(*asked, question)
+			return yes, nil
+		}
+	}
+
+	tests := []struct {
+		name         string
+		plan         *diff.Plan
+		allowed      bool
+		autoApproved bool
+		answer       bool
+		wantOK       bool
+		wantErr      bool
+		wantAsked    bool
+	}{
+		{name: "nothing disruptive", plan: safe, wantOK: true},
+		{name: "nothing disruptive with --yes", plan: safe, autoApproved: true, wantOK: true},
+		{name: "confirmed", plan: disruptive, answer: true, wantOK: true, wantAsked: true},
+		{name: "declined", plan: disruptive, answer: false, wantOK: false, wantAsked: true},
+		{name: "--yes without --allow-disruptive", plan: disruptive, autoApproved: true, wantErr: true},
+		{name: "--yes with --allow-disruptive", plan: disruptive, autoApproved: true, allowed: true, wantOK: true},
+		{name: "--allow-disruptive", plan: disruptive, allowed: true, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var asked []string
+			ok, err := confirmDisruptive(tt.plan, tt.allowed, tt.autoApproved, answer(tt.answer, &asked))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("confirmDisruptive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), "pages.build_type") || !strings.Contains(err.Error(), "--allow-disruptive") {
+					t.Errorf("error = %q, want it to name the change and --allow-disruptive", err)
+				}
+				return
+			}
+			if ok != tt.wantOK {
+				t.Errorf("confirmDisruptive() = %v, want %v", ok, tt.wantOK)
+			}
+			if (len(asked) > 0) != tt.wantAsked {
+				t.Errorf("asked = %v, want asked %v", asked, tt.wantAsked)
+			}
+		})
+	}
+
+	t.Run("plan marks disruptive changes", func(t *testing.T) {
+		var buf bytes.Buffer
+		renderPlan(&buf, disruptive, true, false)
+		if !strings.Contains(buf.String(), "⚠ may take the site down") {
+			t.Errorf("expected the warning next to the change, got:\n%s", buf.String())
+		}
+	})
+}
+
+func TestRunConcurrently(t *testing.T) {
+	t.Run("sequential stops at the first error", func(t *testing.T) {
+		var ran []int
+		err := runConcurrently(4, 1, func(i int) error {
+			ran = append(ran, i)
+			if i == 1 {
+				return fmt.Errorf("failed %d", i)
+			}
+			return nil
+		})
+		if err == nil || err.Error() != "failed 1" {
+			t.Errorf("error = %v", err)
+		}
+		if fmt.Sprint(ran) != "[0 1]" {
+			t.Errorf("ran = %v", ran)
+		}
+	})
+
+	t.Run("concurrent runs everything within the limit", func(t *testing.T) {
+		var mu sync.Mutex
+		inFlight, maxInFlight, ran := 0, 0, 0
+		err := runConcurrently(8, 3, func(i int) error {
+			mu.Lock()
+			inFlight++
+			ran++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			if i == 2 || i == 5 {
+				return fmt.Errorf("failed %d", i)
+			}
+			return nil
+		})
+		if err == nil || err.Error() != "failed 2" {
+			t.Errorf("expected the first failing index's error, got %v", err)
+		}
+		if ran != 8 {
+			t.Errorf("ran %d calls, want 8", ran)
+		}
+		if maxInFlight > 3 {
+			t.Errorf("%d calls in flight, limit is 3", maxInFlight)
+		}
+	})
+}
+
+func TestMatchOrgRepos(t *testing.T) {
+	repos := []github.OrgRepoData{
+		{Name: "svc-payments"},
+		{Name: "svc-auth"},
+		{Name: "svc-legacy", Archived: true},
+		{Name: "web"},
+		{Name: "svc"},
+	}
+
+	tests := []struct {
+		name            string
+		pattern         string
+		includeArchived bool
+		want            string
+	}{
+		{name: "prefix glob skips archived", pattern: "svc-*", want: "svc-auth,svc-payments"},
+		{name: "include archived", pattern: "svc-*", includeArchived: true, want: "svc-auth,svc-legacy,svc-payments"},
+		{name: "exact name", pattern: "web", want: "web"},
+		{name: "character class", pattern: "svc-[ap]*", want: "svc-auth,svc-payments"},
+		{name: "everything", pattern: "*", want: "svc,svc-auth,svc-payments,web"},
+		{name: "no match", pattern: "api-*", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchOrgRepos(repos, tt.pattern, tt.includeArchived)
+			if err != nil {
+				t.Fatalf("matchOrgRepos() error = %v", err)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("matchOrgRepos() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := matchOrgRepos(repos, "svc-[", false); err == nil {
+		t.Error("expected error for an invalid pattern")
+	}
+}
+
+func TestListMatchingRepos(t *testing.T) {
+	mock := github.NewMockOrgClient()
+	mock.Repos = []github.OrgRepoData{{Name: "svc-a"}, {Name: "svc-b", Archived: true}}
+
+	names, err := listMatchingRepos(context.Background(), mock, "svc-*", false)
+	if err != nil {
+		t.Fatalf("listMatchingRepos() error = %v", err)
+	}
+	if strings.Join(names, ",") != "svc-a" {
+		t.Errorf("names = %v", names)
+	}
+
+	// Only archived repositories match
+	if _, err := listMatchingRepos(context.Background(), mock, "svc-b", false); err == nil || !strings.Contains(err.Error(), "no repositories") {
+		t.Errorf("expected a no-match error, got %v", err)
+	}
+
+	mock.ListReposError = fmt.Errorf("forbidden")
+	if _, err := listMatchingRepos(context.Background(), mock, "*", false); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestCheckMatchFlags(t *testing.T) {
+	savedMatch, savedOrg, savedRepo, savedArchived := repoMatch, org, repo, includeArchived
+	t.Cleanup(func() {
+		repoMatch, org, repo, includeArchived = savedMatch, savedOrg, savedRepo, savedArchived
+	})
+
+	tests := []struct {
+		name            string
+		match, org      string
+		repo            string
+		includeArchived bool
+		hasOut, stdin   bool
+		wantErr         bool
+	}{
+		{name: "no match"},
+		{name: "match with org", match: "svc-*", org: "acme"},
+		{name: "match without org", match: "svc-*", wantErr: true},
+		{name: "match with repo", match: "svc-*", org: "acme", repo: "acme/web", wantErr: true},
+		{name: "match with stdin", match: "svc-*", org: "acme", stdin: true, wantErr: true},
+		{name: "match with out", match: "svc-*", org: "acme", hasOut: true, wantErr: true},
+		{name: "invalid pattern", match: "svc-[", org: "acme", wantErr: true},
+		{name: "include archived without match", org: "acme", includeArchived: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoMatch, org, repo, includeArchived = tt.match, tt.org, tt.repo, tt.includeArchived
+			if err := checkMatchFlags(tt.hasOut, tt.stdin); (err != nil) != tt.wantErr {
+				t.Errorf("checkMatchFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlanWatcher(t *testing.T) {
+	clean := model.NewPlan()
+	drifted := model.NewPlanFromChanges([]diff.Change{
+		model.NewUpdateChange(diff.CategoryRepo, "description", "old", "new"),
+	})
+	clock := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	now := func() time.Time { return clock }
+
+	// Each cycle returns the next result; the cycle after the last one cancels the watch
+	run := func(t *testing.T, onlyChanges bool, results ...interface{}) (string, int) {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ticks := make(chan time.Time, len(results))
+		for range results {
+			ticks <- clock
+		}
+
+		var out bytes.Buffer
+		cycles := 0
+		watcher := &planWatcher{
+			out:   &out,
+			ticks: ticks,
+			now:   now,
+			calculate: func(ctx context.Context) (*diff.Plan, error) {
+				cycles++
+				if cycles > len(results) {
+					cancel()
+					return nil, ctx.Err()
+				}
+				if err, ok := results[cycles-1].(error); ok {
+					return nil, err
+				}
+				return results[cycles-1].(*diff.Plan), nil
+			},
+			onlyChanges: onlyChanges,
+		}
+		if err := watcher.run(ctx); err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		return out.String(), cycles
 	}
-	return false
+
+	t.Run("prints every cycle", func(t *testing.T) {
+		out, cycles := run(t, false, clean, clean, fmt.Errorf("rate limited"), drifted)
+		if cycles != 5 {
+			t.Errorf("cycles = %d, want 5", cycles)
+		}
+		want := "[2026-01-02 03:04:05] Plan: 0 to add, 0 to change, 0 to destroy.\n" +
+			"[2026-01-02 03:04:05] Plan: 0 to add, 0 to change, 0 to destroy.\n" +
+			"[2026-01-02 03:04:05] Plan failed: rate limited\n" +
+			"[2026-01-02 03:04:05] Plan: 0 to add, 1 to change, 0 to destroy.\n" +
+			"  repo: 1\n" +
+			"  new: [UPDATE] repo.description: old -> new\n"
+		if out != want {
+			t.Errorf("output =\n%s\nwant\n%s", out, want)
+		}
+	})
+
+	t.Run("changes only", func(t *testing.T) {
+		out, _ := run(t, true, drifted, drifted, clean, clean)
+		want := "[2026-01-02 03:04:05] Plan: 0 to add, 1 to change, 0 to destroy.\n" +
+			"  repo: 1\n" +
+			"[2026-01-02 03:04:05] Plan: 0 to add, 0 to change, 0 to destroy.\n" +
+			"  resolved: [UPDATE] repo.description: old -> new\n"
+		if out != want {
+			t.Errorf("output =\n%s\nwant\n%s", out, want)
+		}
+	})
+
+	t.Run("stops while waiting for a tick", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		watcher := &planWatcher{
+			out:   &bytes.Buffer{},
+			ticks: make(chan time.Time),
+			now:   now,
+			calculate: func(context.Context) (*diff.Plan, error) {
+				cancel()
+				return clean, nil
+			},
+		}
+		go func() { done <- watcher.run(ctx) }()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("run() error = %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("run() didn't stop after cancel")
+		}
+	})
+}
+
+func TestDriftReport(t *testing.T) {
+	description := model.NewUpdateChange(diff.CategoryRepo, "description", "old", "new")
+	topics := model.NewUpdateChange(diff.CategoryTopics, "topics", []string{"go"}, []string{"go", "cli"})
+	reviews := model.NewBranchProtectionUpdateChange("main", "required_reviews", 1, 2)
+
+	// Baselines are read back from JSON, as --since does
+	saved := func(t *testing.T, changes ...diff.Change) *diff.Plan {
+		t.Helper()
+		data, err := diff.PlanMarshalIndent(model.NewPlanFromChanges(changes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		plan, err := diff.UnmarshalPlan(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return plan
+	}
+
+	tests := []struct {
+		name     string
+		baseline []diff.Change
+		current  []diff.Change
+		want     string
+		wantCode int
+	}{
+		{
+			name:     "unchanged drift",
+			baseline: []diff.Change{description, topics, reviews},
+			current:  []diff.Change{description, topics, reviews},
+			want:     "No drift since the baseline.\n",
+		},
+		{
+			name:     "new drift",
+			baseline: []diff.Change{description},
+			current:  []diff.Change{description, reviews},
+			want: "New drift (1):\n" +
+				"  [UPDATE] branch_protection.main.required_reviews: 1 -> 2\n" +
+				"Resolved drift (0):\n" +
+				"  none\n",
+			wantCode: exitCodeChanges,
+		},
+		{
+			name:     "resolved drift",
+			baseline: []diff.Change{description, topics},
+			current:  []diff.Change{topics},
+			want: "New drift (0):\n" +
+				"  none\n" +
+				"Resolved drift (1):\n" +
+				"  [UPDATE] repo.description: old -> new\n",
+		},
+		{
+			name:     "changed value",
+			baseline: []diff.Change{description},
+			current:  []diff.Change{model.NewUpdateChange(diff.CategoryRepo, "description", "older", "new")},
+			want: "New drift (1):\n" +
+				"  [UPDATE] repo.description: older -> new\n" +
+				"Resolved drift (1):\n" +
+				"  [UPDATE] repo.description: old -> new\n",
+			wantCode: exitCodeChanges,
+		},
+		{
+			name:     "clean baseline",
+			baseline: nil,
+			current:  []diff.Change{topics},
+			want: "New drift (1):\n" +
+				"  [UPDATE] topics.topics: [go] -> [go cli]\n" +
+				"Resolved drift (0):\n" +
+				"  none\n",
+			wantCode: exitCodeChanges,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseline := saved(t, tt.baseline...)
+			current := model.NewPlanFromChanges(tt.current)
+
+			var out bytes.Buffer
+			renderDriftReport(&out, current, baseline, false)
+			if out.String() != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+			if code := sinceExitCode(current, baseline); code != tt.wantCode {
+				t.Errorf("sinceExitCode() = %d, want %d", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestPreviewPayloadsSendsNothing(t *testing.T) {
+	mock := github.NewMockClient()
+	cfg := &config.Config{
+		Topics: []string{"go"},
+		Labels: &config.LabelsConfig{Items: []config.Label{{Name: "bug", Color: "d73a4a"}}},
+	}
+	plan := model.NewPlanFromChanges([]diff.Change{
+		model.NewUpdateChange(model.CategoryRepo, "description", "old", "new"),
+		model.NewUpdateChange(model.CategoryTopics, "topics", []string{}, []string{"go"}),
+		model.NewAddChange(model.CategoryLabels, "bug", "color=d73a4a"),
+	})
+
+	if err := previewPayloads(context.Background(), mock, cfg, plan, nil); err != nil {
+		t.Fatalf("previewPayloads() error = %v", err)
+	}
+	if len(mock.UpdateRepoCalls) != 0 || len(mock.SetTopicsCalls) != 0 || len(mock.CreateLabelCalls) != 0 {
+		t.Errorf("preview sent requests: repo=%v topics=%v labels=%v", mock.UpdateRepoCalls, mock.SetTopicsCalls, mock.CreateLabelCalls)
+	}
+}
+
+func TestProbeSections(t *testing.T) {
+	names := func(checks []doctorCheck) []string {
+		var got []string
+		for _, check := range checks {
+			got = append(got, check.name)
+		}
+		return got
+	}
+
+	t.Run("probes only configured sections", func(t *testing.T) {
+		mock := github.NewMockClient()
+		cfg := &config.Config{
+			Labels:           &config.LabelsConfig{},
+			BranchProtection: map[string]*config.BranchRule{"main": {}},
+		}
+		checks := probeSections(context.Background(), mock, cfg)
+		want := []string{"access to labels", "access to branch_protection"}
+		if !reflect.DeepEqual(names(checks), want) {
+			t.Errorf("checks = %v, want %v", names(checks), want)
+		}
+		for _, check := range checks {
+			if check.err != nil {
+				t.Errorf("%s: unexpected error %v", check.name, check.err)
+			}
+		}
+	})
+
+	t.Run("probes every section without a config", func(t *testing.T) {
+		mock := github.NewMockClient()
+		checks := probeSections(context.Background(), mock, nil)
+		if len(checks) != len(sectionProbes) {
+			t.Errorf("got %d checks, want %d", len(checks), len(sectionProbes))
+		}
+	})
+
+	t.Run("permission denied gets a token hint", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.GetVariablesError = apperrors.NewAPIError("GET", "repos/o/r/actions/variables", 403, "Resource not accessible by integration", nil)
+		cfg := &config.Config{Env: &config.EnvConfig{Variables: map[string]string{"FOO": "bar"}}}
+
+		checks := probeSections(context.Background(), mock, cfg)
+		if len(checks) != 1 || checks[0].err == nil {
+			t.Fatalf("checks = %+v, want one failed check", checks)
+		}
+		if !strings.Contains(checks[0].hint, "gh auth refresh") {
+			t.Errorf("hint = %q, want a token hint", checks[0].hint)
+		}
+	})
+
+	t.Run("non-admin token fails the repo probe", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.RepoData.Permissions = &struct {
+			Admin    bool  `json:"admin"`
+			Maintain *bool `json:"maintain,omitempty"`
+			Pull     bool  `json:"pull"`
+			Push     bool  `json:"push"`
+			Triage   *bool `json:"triage,omitempty"`
+		}{Pull: true, Push: true}
+		cfg := &config.Config{Topics: []string{"go"}}
+
+		checks := probeSections(context.Background(), mock, cfg)
+		if len(checks) != 1 || !apperrors.IsPermissionDenied(checks[0].err) {
+			t.Errorf("checks = %+v, want permission denied", checks)
+		}
+	})
+
+	t.Run("unprotected branch and disabled pages pass", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.GetBranchProtectionError = apperrors.ErrBranchNotProtected
+		mock.GetPagesError = apperrors.ErrPagesNotEnabled
+		cfg := &config.Config{
+			BranchProtection: map[string]*config.BranchRule{"main": {}},
+			Pages:            &config.PagesConfig{},
+		}
+		for _, check := range probeSections(context.Background(), mock, cfg) {
+			if check.err != nil {
+				t.Errorf("%s: unexpected error %v", check.name, check.err)
+			}
+		}
+	})
+}
+
+func TestCheckGhInstalled(t *testing.T) {
+	gh := func(ctx context.Context, args ...string) ([]byte, error) {
+		return []byte("gh version 2.40.0 (2023-12-07)\nhttps://github.com/cli/cli/releases/tag/v2.40.0\n"), nil
+	}
+
+	t.Run("installed", func(t *testing.T) {
+		lookPath := func(string) (string, error) { return "/usr/bin/gh", nil }
+		check := checkGhInstalled(context.Background(), lookPath, gh)
+		if check.err != nil {
+			t.Fatalf("unexpected error %v", check.err)
+		}
+		if check.detail != "gh version 2.40.0 (2023-12-07)" {
+			t.Errorf("detail = %q", check.detail)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		lookPath := func(string) (string, error) { return "", fmt.Errorf("executable file not found in $PATH") }
+		check := checkGhInstalled(context.Background(), lookPath, gh)
+		if check.err == nil || check.hint == "" {
+			t.Errorf("check = %+v, want an error with a hint", check)
+		}
+	})
+}
+
+func TestPrintDoctorChecks(t *testing.T) {
+	var buf bytes.Buffer
+	failed := printDoctorChecks(&buf, []doctorCheck{
+		{name: "gh installed", detail: "gh version 2.40.0"},
+		{name: "gh authenticated", err: fmt.Errorf("not logged in"), hint: "run gh auth login"},
+	})
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+	for _, want := range []string{"gh installed: gh version 2.40.0", "gh authenticated: not logged in", "→ run gh auth login"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output %q doesn't contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestSecretStateIsPerRepository(t *testing.T) {
+	env := &config.EnvConfig{Secrets: []config.Secret{{Name: "API_KEY"}}}
+	cfg := &config.Config{Env: env}
+	newRepo := func(name string) *github.MockClient {
+		mock := github.NewMockClient()
+		mock.Owner, mock.Name = "acme", name
+		mock.Secrets = []string{"API_KEY"}
+		return mock
+	}
+	repoA, repoB := newRepo("a"), newRepo("b")
+
+	// Both repositories were last applied with the old value
+	state := config.NewState()
+	for _, repo := range []string{"acme/a", "acme/b"} {
+		if err := state.RecordSecret(repo, "API_KEY", "old-value"); err != nil {
+			t.Fatalf("RecordSecret() error = %v", err)
+		}
+	}
+
+	// The rotated value is applied to repo A only
+	dotEnv := &config.DotEnvValues{Values: map[string]string{"API_KEY": "new-value"}}
+	identity := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	changes := []diff.Change{model.NewUpdateChange(model.CategorySecrets, "API_KEY", "(secret)", "(will be updated from .env)")}
+	if err := applySecretChanges(context.Background(), repoA, env, dotEnv, state, changes, io.Discard, identity, identity, nil); err != nil {
+		t.Fatalf("applySecretChanges() error = %v", err)
+	}
+
+	opts := diff.CalculateOptions{CheckSecrets: true, SecretState: state}
+	for _, tt := range []struct {
+		client  *github.MockClient
+		updates int
+	}{{repoA, 0}, {repoB, 1}} {
+		plan, err := diff.NewCalculatorWithEnv(tt.client, cfg, dotEnv).CalculateWithOptions(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("CalculateWithOptions() error = %v", err)
+		}
+		updates := plan.Filter(func(c diff.Change) bool {
+			return c.Category == model.CategorySecrets && c.Type == model.ChangeUpdate
+		}).Size()
+		if updates != tt.updates {
+			t.Errorf("%s: %d secret updates, want %d", github.FullName(tt.client), updates, tt.updates)
+		}
+	}
+}
+
+func TestApplySecretChangesInlineValue(t *testing.T) {
+	mock := github.NewMockClient()
+	inline := "inline-value"
+	env := &config.EnvConfig{Secrets: []config.Secret{{Name: "DEMO_KEY", Value: &inline}, {Name: "API_KEY", Value: &inline}}}
+	dotEnv := &config.DotEnvValues{Values: map[string]string{"API_KEY": "from-env"}}
+	changes := []diff.Change{
+		model.NewAddChange(model.CategorySecrets, "DEMO_KEY", "(will be set from config)"),
+		model.NewAddChange(model.CategorySecrets, "API_KEY", "(will be set from .env)"),
+	}
+
+	identity := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	if err := applySecretChanges(context.Background(), mock, env, dotEnv, nil, changes, io.Discard, identity, identity, nil); err != nil {
+		t.Fatalf("applySecretChanges() error = %v", err)
+	}
+	want := []github.SecretCall{{Name: "DEMO_KEY", Value: "inline-value"}, {Name: "API_KEY", Value: "from-env"}}
+	if !reflect.DeepEqual(mock.SetSecretCalls, want) {
+		t.Errorf("SetSecretCalls = %+v, want %+v", mock.SetSecretCalls, want)
+	}
+}
+
+func TestConfirmPruneLabels(t *testing.T) {
+	pruning := model.NewPlanFromChanges([]diff.Change{
+		model.NewAddChange(diff.CategoryLabels, "feature", "color=a2eeef, description="),
+		model.NewDeleteChange(diff.CategoryLabels, "old-label", "color=000000, description="),
+		model.NewDeleteChange(diff.CategoryVariables, "OLD_VAR", "x"),
+	})
+	var asked []string
+	ask := func(question string) (bool, error) {
+		asked = append(asked, question)
+		return false, nil
+	}
+
+	ok, err := confirmPruneLabels(pruning, ask)
+	if err != nil || ok {
+		t.Errorf("confirmPruneLabels() = %v, %v, want the declined answer", ok, err)
+	}
+	if len(asked) != 1 || !strings.Contains(asked[0], "Delete 1 label(s) not in the config (old-label)") {
+		t.Errorf("asked %q, want a question naming old-label", asked)
+	}
+
+	asked = nil
+	noDeletes := model.NewPlanFromChanges([]diff.Change{
+		model.NewAddChange(diff.CategoryLabels, "feature", "color=a2eeef, description="),
+	})
+	if ok, err := confirmPruneLabels(noDeletes, ask); err != nil || !ok || len(asked) != 0 {
+		t.Errorf("confirmPruneLabels() without deletes = %v, %v after asking %q, want true without asking", ok, err, asked)
+	}
+}
+
+func TestDriftedConfig(t *testing.T) {
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{
+		Description: nullable.NewNullableWithValue("unchanged"),
+		Homepage:    nullable.NewNullableWithValue("https://old.example.com"),
+	}
+	mock.Labels = []github.LabelData{
+		{Name: "bug", Color: "d73a4a"},
+		{Name: "docs", Color: "0075ca"},
+		{Name: "wontfix", Color: "ffffff"},
+	}
+	mock.BranchProtections["main"] = &github.BranchProtectionData{
+		RequiredPullRequestReviews: &githubopenapi.ProtectedBranchPullRequestReview{
+			RequiredApprovingReviewCount: ptr(1),
+		},
+		EnforceAdmins: &githubopenapi.ProtectedBranchAdminEnforced{Enabled: true},
+	}
+	mock.Branches = []github.BranchData{{Name: "main", Protected: true}, {Name: "release"}}
+	mock.Variables = []github.VariableData{
+		{Name: "APP_ENV", Value: "production"},
+		{Name: "REGION", Value: "us-east-1"},
+	}
+
+	cfg := &config.Config{
+		Repo: &config.RepoConfig{
+			Description: ptrString("unchanged"),
+			Homepage:    ptrString("https://new.example.com"),
+		},
+		Labels: &config.LabelsConfig{
+			ReplaceDefault: true,
+			Items: []config.Label{
+				{Name: "bug", Color: "ee0701"},
+				{Name: "docs", Color: "0075ca"},
+				{Name: "feature", Color: "a2eeef"},
+			},
+		},
+		BranchProtection: map[string]*config.BranchRule{
+			"main":    {RequiredReviews: ptr(2), EnforceAdmins: ptr(true)},
+			"release": {RequiredReviews: ptr(1)},
+		},
+		Env: &config.EnvConfig{
+			Variables: map[string]string{"APP_ENV": "production", "REGION": "eu-west-1"},
+		},
+	}
+
+	plan, err := diff.NewCalculator(mock, cfg).CalculateWithOptions(context.Background(), diff.CalculateOptions{CheckEnv: true})
+	if err != nil {
+		t.Fatalf("CalculateWithOptions() error = %v", err)
+	}
+	got, err := driftedConfig(cfg, plan)
+	if err != nil {
+		t.Fatalf("driftedConfig() error = %v", err)
+	}
+
+	// Deleting wontfix has no value in the config, so it isn't exported
+	want := &config.Config{
+		Repo: &config.RepoConfig{Homepage: ptrString("https://new.example.com")},
+		Labels: &config.LabelsConfig{
+			Items: []config.Label{
+				{Name: "bug", Color: "ee0701"},
+				{Name: "feature", Color: "a2eeef"},
+			},
+		},
+		BranchProtection: map[string]*config.BranchRule{
+			"main":    {RequiredReviews: ptr(2)},
+			"release": {RequiredReviews: ptr(1)},
+		},
+		Env: &config.EnvConfig{Variables: map[string]string{"REGION": "eu-west-1"}},
+	}
+	gotYAML, err := marshalYAML(got)
+	if err != nil {
+		t.Fatalf("marshalYAML() error = %v", err)
+	}
+	wantYAML, err := marshalYAML(want)
+	if err != nil {
+		t.Fatalf("marshalYAML() error = %v", err)
+	}
+	if string(gotYAML) != string(wantYAML) {
+		t.Errorf("drifted config =\n%s\nwant\n%s", gotYAML, wantYAML)
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	defer func() { forceColor, noColor = false, false }()
+
+	plan := model.NewPlanFromChanges([]model.Change{model.NewAddChange(model.CategoryLabels, "bug", "color=ff0000")})
+
+	tests := []struct {
+		name       string
+		forceColor bool
+		noColor    bool
+		noColorEnv bool
+		want       bool
+	}{
+		{name: "buffer is not a terminal", want: false},
+		{name: "--color forces colors", forceColor: true, want: true},
+		{name: "--color wins over NO_COLOR", forceColor: true, noColorEnv: true, want: true},
+		{name: "--no-color", noColor: true, want: false},
+		{name: "NO_COLOR", noColorEnv: true, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forceColor, noColor = tt.forceColor, tt.noColor
+			if tt.noColorEnv {
+				t.Setenv("NO_COLOR", "1")
+			}
+
+			var buf bytes.Buffer
+			if got := colorEnabled(&buf); got != tt.want {
+				t.Fatalf("colorEnabled() = %v, want %v", got, tt.want)
+			}
+			renderPlan(&buf, plan, false, colorEnabled(&buf))
+			if hasEscapes := strings.Contains(buf.String(), "\x1b["); hasEscapes != tt.want {
+				t.Errorf("plan output has escape codes = %v, want %v:\n%q", hasEscapes, tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestApplyIsTemplate(t *testing.T) {
+	cfg := &config.Config{Repo: &config.RepoConfig{IsTemplate: ptr(true)}}
+	plan := model.NewPlanFromChanges([]diff.Change{
+		model.NewUpdateChange(model.CategoryRepo, "is_template", false, true),
+	})
+
+	t.Run("toggles is_template", func(t *testing.T) {
+		mock := github.NewMockClient()
+		if err := applyChanges(context.Background(), mock, cfg, plan, nil, nil, nil); err != nil {
+			t.Fatalf("applyChanges() error = %v", err)
+		}
+		if len(mock.UpdateRepoCalls) != 1 || mock.UpdateRepoCalls[0]["is_template"] != true {
+			t.Errorf("expected one update setting is_template, got %+v", mock.UpdateRepoCalls)
+		}
+	})
+
+	t.Run("GitHub's error is surfaced", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.UpdateRepoError = apperrors.NewAPIError("PATCH", "repos/test-owner/test-repo", 422, "Validation Failed: is_template is not allowed for this repository", nil)
+
+		err := applyChanges(context.Background(), mock, cfg, plan, nil, nil, nil)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		for _, want := range []string{"is_template", "422", "is not allowed for this repository"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q should contain %q", err.Error(), want)
+			}
+		}
+	})
+}
+
+func TestApplyJSONSummary(t *testing.T) {
+	cfg := &config.Config{
+		Repo:   &config.RepoConfig{Description: ptr("new")},
+		Labels: &config.LabelsConfig{Items: []config.Label{{Name: "bug", Color: "d73a4a"}}},
+		Env:    &config.EnvConfig{Variables: map[string]string{"FOO": "bar"}},
+	}
+	plan := model.NewPlanFromChanges([]diff.Change{
+		model.NewUpdateChange(model.CategoryRepo, "description", "old", "new"),
+		model.NewAddChange(model.CategoryLabels, "bug", "d73a4a"),
+		model.NewAddChange(model.CategoryVariables, "FOO", "bar"),
+		model.NewInfoChange(model.CategoryRepo, "homepage", "not shown"),
+	})
+
+	mock := github.NewMockClient()
+	mock.CreateLabelError = fmt.Errorf("422 Validation Failed")
+	report := newApplyReport()
+	var progress bytes.Buffer
+	applyErr := applyChangesWithReport(context.Background(), mock, cfg, plan, nil, nil, nil, &progress, report)
+	if applyErr == nil {
+		t.Fatal("expected the label error")
+	}
+	if !strings.Contains(progress.String(), "Creating label 'bug'") {
+		t.Errorf("progress = %q, want the label step", progress.String())
+	}
+
+	var buf bytes.Buffer
+	if err := writeApplySummary(&buf, report, plan); err != nil {
+		t.Fatalf("writeApplySummary() error = %v", err)
+	}
+	var got applySummary
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("summary is not JSON: %v\n%s", err, buf.String())
+	}
+
+	want := applySummary{
+		Changes: []applySummaryChange{
+			{Category: "repo", Type: "update", Key: "description", Status: outcomeApplied},
+			{Category: "labels", Type: "add", Key: "bug", Status: outcomeFailed, Error: applyErr.Error()},
+			{Category: "variables", Type: "add", Key: "FOO", Status: outcomeSkipped},
+		},
+		Applied: 1,
+		Failed:  1,
+		Skipped: 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("summary = %+v, want %+v", got, want)
+	}
+	if len(mock.SetVariableCalls) != 0 {
+		t.Errorf("variables should not be applied after a failure, got %+v", mock.SetVariableCalls)
+	}
+}
+
+func TestPlanShowsMetaReason(t *testing.T) {
+	var cfg config.Config
+	err := yaml.Unmarshal([]byte(`
+repo:
+  description: new description
+  visibility: private
+_meta:
+  repo.visibility: Customer data lives here (SEC-142)
+`), &cfg)
+	if err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{Visibility: ptrString("public")}
+	plan, err := diff.NewCalculator(mock, &cfg).Calculate(context.Background())
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	renderPlan(&buf, plan, false, false)
+	out := buf.String()
+
+	want := "  ~ visibility\n      public → private\n      # reason: Customer data lives here (SEC-142)\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("plan output should show the reason under the visibility change:\n%s", out)
+	}
+	if strings.Count(out, "# reason:") != 1 {
+		t.Errorf("only the visibility change has a reason:\n%s", out)
+	}
+}
+
+// importCountingClient counts the reads of the sections export imports
+type importCountingClient struct {
+	*github.MockClient
+	calls map[string]int
+}
+
+func (c *importCountingClient) GetRepo(ctx context.Context) (*github.RepoData, error) {
+	c.calls["GetRepo"]++
+	return c.MockClient.GetRepo(ctx)
+}
+
+func (c *importCountingClient) GetLabels(ctx context.Context) ([]github.LabelData, error) {
+	c.calls["GetLabels"]++
+	return c.MockClient.GetLabels(ctx)
+}
+
+func (c *importCountingClient) GetActionsPermissions(ctx context.Context) (*github.ActionsPermissionsData, error) {
+	c.calls["GetActionsPermissions"]++
+	return c.MockClient.GetActionsPermissions(ctx)
+}
+
+func (c *importCountingClient) GetPages(ctx context.Context) (*github.PagesData, error) {
+	c.calls["GetPages"]++
+	return c.MockClient.GetPages(ctx)
+}
+
+func TestBuildConfigFromRepoExcluding(t *testing.T) {
+	topics := []string{"go"}
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{Visibility: ptrString("public"), Topics: &topics}
+	mock.Labels = []github.LabelData{{Name: "bug", Color: "d73a4a"}}
+	mock.BranchProtections["main"] = &github.BranchProtectionData{
+		EnforceAdmins: &githubopenapi.ProtectedBranchAdminEnforced{Enabled: true},
+	}
+
+	t.Run("excluded sections are neither fetched nor written", func(t *testing.T) {
+		exclude, err := parseExcludeSections("labels, Actions,branch_protection")
+		if err != nil {
+			t.Fatalf("parseExcludeSections() error = %v", err)
+		}
+		client := &importCountingClient{MockClient: mock, calls: make(map[string]int)}
+		mock.GetBranchProtectionCalls = nil
+
+		cfg, err := buildConfigFromRepoExcluding(context.Background(), client, false, exclude)
+		if err != nil {
+			t.Fatalf("buildConfigFromRepoExcluding() error = %v", err)
+		}
+		for _, method := range []string{"GetLabels", "GetActionsPermissions"} {
+			if client.calls[method] != 0 {
+				t.Errorf("%s called %d times, want 0", method, client.calls[method])
+			}
+		}
+		if len(mock.GetBranchProtectionCalls) != 0 {
+			t.Errorf("branch protection read for %v, want none", mock.GetBranchProtectionCalls)
+		}
+		if client.calls["GetRepo"] != 1 || client.calls["GetPages"] != 1 {
+			t.Errorf("included sections should be fetched once, got %v", client.calls)
+		}
+
+		data, err := marshalYAML(cfg)
+		if err != nil {
+			t.Fatalf("marshalYAML() error = %v", err)
+		}
+		for _, section := range []string{"labels:", "actions:", "branch_protection:"} {
+			if strings.Contains(string(data), section) {
+				t.Errorf("export should not contain %s:\n%s", section, data)
+			}
+		}
+		for _, section := range []string{"repo:", "topics:"} {
+			if !strings.Contains(string(data), section) {
+				t.Errorf("export should contain %s:\n%s", section, data)
+			}
+		}
+	})
+
+	t.Run("repository settings and topics are fetched unless both are excluded", func(t *testing.T) {
+		client := &importCountingClient{MockClient: mock, calls: make(map[string]int)}
+		cfg, err := buildConfigFromRepoExcluding(context.Background(), client, false, map[string]bool{"repo": true})
+		if err != nil {
+			t.Fatalf("buildConfigFromRepoExcluding() error = %v", err)
+		}
+		if cfg.Repo != nil || len(cfg.Topics) != 1 {
+			t.Errorf("expected only topics, got repo %+v and topics %v", cfg.Repo, cfg.Topics)
+		}
+
+		client = &importCountingClient{MockClient: mock, calls: make(map[string]int)}
+		if _, err := buildConfigFromRepoExcluding(context.Background(), client, false, map[string]bool{"repo": true, "topics": true}); err != nil {
+			t.Fatalf("buildConfigFromRepoExcluding() error = %v", err)
+		}
+		if client.calls["GetRepo"] != 0 {
+			t.Errorf("GetRepo called %d times, want 0", client.calls["GetRepo"])
+		}
+	})
+
+	t.Run("unknown section", func(t *testing.T) {
+		_, err := parseExcludeSections("labels,secrets")
+		if err == nil || !strings.Contains(err.Error(), `invalid --exclude section "secrets"`) {
+			t.Errorf("parseExcludeSections() error = %v, want invalid section", err)
+		}
+	})
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+var (
+	forceColor bool
+	noColor    bool
+)
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&forceColor, "color", false, "Always use colors, even when the output isn't a terminal")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Never use colors (also set by the NO_COLOR environment variable)")
+	rootCmd.MarkFlagsMutuallyExclusive("color", "no-color")
+}
+
+// colorEnabled reports whether output written to w should be colored: --color forces it,
+// while --no-color, a non-empty NO_COLOR and a w that isn't a terminal disable it
+func colorEnabled(w io.Writer) bool {
+	if forceColor {
+		return true
+	}
+	if noColor {
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// isTerminal reports whether w is a terminal, including Cygwin and MSYS terminals
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
+
+// setupColor sets the default for every color printed to stdout
+func setupColor() {
+	color.NoColor = !colorEnabled(os.Stdout)
+}
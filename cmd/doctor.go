@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorDir    []string
+	doctorConfig string
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that gh, authentication and token access are set up",
+	Long: `Check the environment the other commands need: gh is installed and logged in, the
+repository can be found, and the token can read every section the config manages.
+Each check is printed with a hint on how to fix it. Without a config, every section is checked.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringArrayVarP(&doctorDir, "dir", "d", nil, "Config directory; repeat to merge several in order, later ones winning (with --config, they override the file)")
+	doctorCmd.Flags().StringVarP(&doctorConfig, "config", "c", "", "Config file path, or gh://owner/repo/path@ref")
+}
+
+// doctorCheck is one line of the doctor checklist
+type doctorCheck struct {
+	name   string
+	detail string // Shown after the name when the check passes
+	err    error
+	hint   string // How to fix a failed check
+}
+
+// ghRunner runs gh with args and returns its combined output
+type ghRunner func(ctx context.Context, args ...string) ([]byte, error)
+
+func execGh(ctx context.Context, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, "gh", args...).CombinedOutput()
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	checks := []doctorCheck{checkGhInstalled(ctx, exec.LookPath, execGh)}
+	if checks[0].err == nil {
+		checks = append(checks, checkGhAuth(ctx, execGh))
+	}
+	if checks[len(checks)-1].err == nil {
+		checks = append(checks, checkRepoAccess(ctx)...)
+	}
+
+	failed := printDoctorChecks(os.Stdout, checks)
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// checkRepoAccess checks that the repository and the sections of the config can be read
+func checkRepoAccess(ctx context.Context) []doctorCheck {
+	client, err := newRepoClient(ctx, repo)
+	if err == nil {
+		_, err = client.GetRepo(ctx)
+	}
+	if err != nil {
+		return []doctorCheck{{
+			name: "repository",
+			err:  err,
+			hint: "pass --repo owner/name, or run inside a clone of the repository",
+		}}
+	}
+	checks := []doctorCheck{{name: "repository", detail: client.RepoOwner() + "/" + client.RepoName()}}
+
+	cfg, err := config.Load(config.LoadOptions{
+		Dir:           doctorDir,
+		Config:        doctorConfig,
+		WarnConflicts: warnConflicts,
+		Context:       ctx,
+		FetchGitHub:   configSources(),
+	})
+	if err != nil {
+		checks = append(checks, doctorCheck{
+			name: "config",
+			err:  err,
+			hint: "run gh repo-settings init to create one; every section is checked in the meantime",
+		})
+		cfg = nil
+	} else {
+		checks = append(checks, doctorCheck{name: "config"})
+	}
+
+	return append(checks, probeSections(ctx, client, cfg)...)
+}
+
+// checkGhInstalled checks that gh is on PATH and reports its version
+func checkGhInstalled(ctx context.Context, lookPath func(string) (string, error), gh ghRunner) doctorCheck {
+	check := doctorCheck{name: "gh installed", hint: "install the GitHub CLI from https://cli.github.com"}
+	if _, check.err = lookPath("gh"); check.err != nil {
+		return check
+	}
+	out, err := gh(ctx, "--version")
+	if err != nil {
+		check.err = fmt.Errorf("gh --version failed: %w", err)
+		return check
+	}
+	check.detail, _, _ = strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return check
+}
+
+// checkGhAuth checks that gh is logged in
+func checkGhAuth(ctx context.Context, gh ghRunner) doctorCheck {
+	check := doctorCheck{name: "gh authenticated", hint: "run gh auth login, or set GH_TOKEN"}
+	if out, err := gh(ctx, "auth", "status"); err != nil {
+		check.err = fmt.Errorf("gh auth status failed: %s", strings.TrimSpace(string(out)))
+	}
+	return check
+}
+
+// sectionProbe reads one config section the way plan does
+type sectionProbe struct {
+	section    string
+	configured func(cfg *config.Config) bool
+	probe      func(ctx context.Context, client github.GitHubClient, cfg *config.Config) error
+}
+
+var sectionProbes = []sectionProbe{
+	{
+		section:    "repo",
+		configured: func(cfg *config.Config) bool { return cfg.Repo != nil || cfg.Topics != nil },
+		probe:      probeRepoAdmin,
+	},
+	{
+		section:    "labels",
+		configured: func(cfg *config.Config) bool { return cfg.Labels != nil },
+		probe: func(ctx context.Context, client github.GitHubClient, cfg *config.Config) error {
+			_, err := client.GetLabels(ctx)
+			return err
+		},
+	},
+	{
+		section:    "branch_protection",
+		configured: func(cfg *config.Config) bool { return len(cfg.BranchProtection) > 0 },
+		probe:      probeBranchProtection,
+	},
+	{
+		section:    "env",
+		configured: func(cfg *config.Config) bool { return cfg.Env != nil },
+		probe: func(ctx context.Context, client github.GitHubClient, cfg *config.Config) error {
+			if _, err := client.GetVariables(ctx); err != nil {
+				return err
+			}
+			if cfg != nil && len(cfg.Env.Secrets) == 0 {
+				return nil
+			}
+			_, err := client.GetSecrets(ctx)
+			return err
+		},
+	},
+	{
+		section:    "actions",
+		configured: func(cfg *config.Config) bool { return cfg.Actions != nil },
+		probe: func(ctx context.Context, client github.GitHubClient, cfg *config.Config) error {
+			_, err := client.GetActionsPermissions(ctx)
+			return err
+		},
+	},
+	{
+		section:    "pages",
+		configured: func(cfg *config.Config) bool { return cfg.Pages != nil },
+		probe: func(ctx context.Context, client github.GitHubClient, cfg *config.Config) error {
+			_, err := client.GetPages(ctx)
+			if apperrors.Is(err, apperrors.ErrPagesNotEnabled) {
+				return nil
+			}
+			return err
+		},
+	},
+}
+
+// probeSections reads each section cfg manages, or every section when cfg is nil,
+// and reports the ones the token can't read
+func probeSections(ctx context.Context, client github.GitHubClient, cfg *config.Config) []doctorCheck {
+	var checks []doctorCheck
+	for _, p := range sectionProbes {
+		if cfg != nil && !p.configured(cfg) {
+			continue
+		}
+		check := doctorCheck{name: "access to " + p.section}
+		if check.err = p.probe(ctx, client, cfg); check.err != nil {
+			check.hint = "check the error above"
+			if apperrors.IsPermissionDenied(check.err) {
+				check.hint = "use a token with admin access to the repository (gh auth refresh -s repo, or a fine-grained token with Administration, Secrets and Variables read and write)"
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// probeRepoAdmin checks that the token may change repository settings, which needs admin access
+func probeRepoAdmin(ctx context.Context, client github.GitHubClient, cfg *config.Config) error {
+	data, err := client.GetRepo(ctx)
+	if err != nil {
+		return err
+	}
+	if data.Permissions != nil && !data.Permissions.Admin {
+		return fmt.Errorf("%w: changing repository settings needs admin access", apperrors.ErrPermissionDenied)
+	}
+	return nil
+}
+
+// probeBranchProtection reads the protection of each configured branch, or of the
+// default branch without a config. Unprotected and missing branches are fine.
+func probeBranchProtection(ctx context.Context, client github.GitHubClient, cfg *config.Config) error {
+	var branches []string
+	if cfg != nil {
+		for branch := range cfg.BranchProtection {
+			branches = append(branches, branch)
+		}
+		sort.Strings(branches)
+	} else {
+		data, err := client.GetRepo(ctx)
+		if err != nil {
+			return err
+		}
+		branches = []string{data.DefaultBranch}
+	}
+
+	for _, branch := range branches {
+		_, err := client.GetBranchProtection(ctx, branch)
+		if err != nil && !apperrors.Is(err, apperrors.ErrBranchNotProtected) && !apperrors.Is(err, apperrors.ErrBranchNotFound) {
+			return fmt.Errorf("%s: %w", branch, err)
+		}
+	}
+	return nil
+}
+
+// printDoctorChecks writes the checklist and returns the number of failed checks
+func printDoctorChecks(w io.Writer, checks []doctorCheck) int {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	failed := 0
+	for _, check := range checks {
+		if check.err == nil {
+			if check.detail != "" {
+				fmt.Fprintf(w, "%s %s: %s\n", green("✓"), check.name, check.detail)
+			} else {
+				fmt.Fprintf(w, "%s %s\n", green("✓"), check.name)
+			}
+			continue
+		}
+		failed++
+		fmt.Fprintf(w, "%s %s: %v\n", red("✗"), check.name, check.err)
+		if check.hint != "" {
+			fmt.Fprintf(w, "    → %s\n", check.hint)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/diff"
+)
+
+// driftedConfig returns the part of cfg that differs from the repository: for each change of
+// plan, the value cfg sets. Deletions have no value in the config and are left out, as are
+// informational and missing changes.
+func driftedConfig(cfg *config.Config, plan *diff.Plan) (*config.Config, error) {
+	drifted := &config.Config{}
+	for _, change := range plan.Changes() {
+		if change.IsInfo() || change.Type == diff.ChangeMissing || change.Type == diff.ChangeDelete {
+			continue
+		}
+		if err := addDriftedValue(drifted, cfg, change); err != nil {
+			return nil, err
+		}
+	}
+	return drifted, nil
+}
+
+// addDriftedValue copies the setting change is about from cfg into drifted
+func addDriftedValue(drifted, cfg *config.Config, change diff.Change) error {
+	switch change.Category {
+	case diff.CategoryRepo:
+		if drifted.Repo == nil {
+			drifted.Repo = &config.RepoConfig{}
+		}
+		return copyConfigKey(drifted.Repo, cfg.Repo, change.Key)
+
+	case diff.CategorySocialPreview:
+		if drifted.Repo == nil {
+			drifted.Repo = &config.RepoConfig{}
+		}
+		drifted.Repo.SocialPreviewImage = cfg.Repo.SocialPreviewImage
+
+	case diff.CategoryTopics:
+		drifted.Topics = cfg.Topics
+
+	case diff.CategoryLabels:
+		if drifted.Labels == nil {
+			drifted.Labels = &config.LabelsConfig{}
+		}
+		for _, label := range cfg.Labels.Items {
+			if strings.EqualFold(label.Name, change.Key) {
+				drifted.Labels.Items = append(drifted.Labels.Items, label)
+				break
+			}
+		}
+
+	case diff.CategoryBranchProtection:
+		if drifted.BranchProtection == nil {
+			drifted.BranchProtection = make(map[string]*config.BranchRule)
+		}
+		desired := cfg.BranchProtection[change.Branch]
+		if change.Field == "" {
+			drifted.BranchProtection[change.Branch] = desired
+			return nil
+		}
+		rule := drifted.BranchProtection[change.Branch]
+		if rule == nil {
+			rule = &config.BranchRule{}
+			drifted.BranchProtection[change.Branch] = rule
+		}
+		return copyConfigKey(rule, desired, change.Field)
+
+	case diff.CategoryActions:
+		if drifted.Actions == nil {
+			drifted.Actions = &config.ActionsConfig{}
+		}
+		key := change.Key
+		if key == "github_owned_allowed" || key == "verified_allowed" || key == "patterns_allowed" {
+			key = "selected_actions." + key
+		}
+		return copyConfigKey(drifted.Actions, cfg.Actions, key)
+
+	case diff.CategoryPages:
+		// Creating Pages is a single change for the whole section
+		if change.Key == "pages" {
+			drifted.Pages = cfg.Pages
+			return nil
+		}
+		if drifted.Pages == nil {
+			drifted.Pages = &config.PagesConfig{}
+		}
+		return copyConfigKey(drifted.Pages, cfg.Pages, change.Key)
+
+	case diff.CategoryTemplates:
+		if drifted.Templates == nil {
+			drifted.Templates = &config.TemplatesConfig{Message: cfg.Templates.Message}
+		}
+		for _, file := range cfg.Templates.Files {
+			if file.Path == change.Key {
+				drifted.Templates.Files = append(drifted.Templates.Files, file)
+			}
+		}
+
+	case diff.CategoryVariables:
+		if drifted.Env == nil {
+			drifted.Env = &config.EnvConfig{}
+		}
+		if drifted.Env.Variables == nil {
+			drifted.Env.Variables = make(map[string]string)
+		}
+		drifted.Env.Variables[change.Key] = cfg.Env.Variables[change.Key]
+
+	case diff.CategorySecrets:
+		if drifted.Env == nil {
+			drifted.Env = &config.EnvConfig{}
+		}
+		for _, secret := range cfg.Env.Secrets {
+			if secret.Name == change.Key {
+				drifted.Env.Secrets = append(drifted.Env.Secrets, secret)
+			}
+		}
+	}
+	return nil
+}
+
+// copyConfigKey sets a dotted key of the config section dst to its value in src
+func copyConfigKey(dst, src interface{}, key string) error {
+	value, err := lookupConfigKey(src, key)
+	if err != nil || value == nil {
+		return err
+	}
+	return patchConfigKey(dst, key, value)
+}
@@ -6,12 +6,13 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/diff"
 	"github.com/myzkey/gh-repo-settings/internal/infra/github"
 	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
-	"github.com/oapi-codegen/nullable"
 	"github.com/spf13/cobra"
 )
 
@@ -19,13 +20,20 @@ var (
 	exportDir            string
 	exportSingle         string
 	exportIncludeSecrets bool
+	exportOnlyDrifted    bool
+	exportConfig         string
+	exportExclude        string
 )
 
 var exportCmd = &cobra.Command{
 	Use:   "export",
 	Short: "Export current GitHub repository settings to YAML",
-	Long:  `Export current GitHub repository settings to YAML format.`,
-	RunE:  runExport,
+	Long: `Export current GitHub repository settings to YAML format.
+
+With --only-drifted, export only the settings of the config that differ from the
+repository, with the values the config sets. Settings apply would delete have no value
+in the config and are left out.`,
+	RunE: runExport,
 }
 
 func init() {
@@ -33,6 +41,10 @@ func init() {
 	exportCmd.Flags().StringVarP(&exportDir, "dir", "d", "", "Export to directory (multiple YAML files)")
 	exportCmd.Flags().StringVarP(&exportSingle, "single", "s", "", "Export to single YAML file")
 	exportCmd.Flags().BoolVar(&exportIncludeSecrets, "include-secrets", false, "Include secret names in export")
+	exportCmd.Flags().BoolVar(&exportOnlyDrifted, "only-drifted", false, "Export only the config settings that differ from the repository")
+	exportCmd.Flags().StringVarP(&exportConfig, "config", "c", "", "Config file to compare with --only-drifted")
+	exportCmd.Flags().StringVar(&exportExclude, "exclude", "", "Comma-separated sections to neither read nor write: "+strings.Join(importSections, ", "))
+	exportCmd.MarkFlagsMutuallyExclusive("exclude", "only-drifted")
 }
 
 func runExport(cmd *cobra.Command, args []string) error {
@@ -51,112 +63,26 @@ func runExport(cmd *cobra.Command, args []string) error {
 
 	logger.Debug("Starting export command")
 
-	client, err := github.NewClientWithContext(ctx, repo)
+	exclude, err := parseExcludeSections(exportExclude)
 	if err != nil {
 		return err
 	}
 
-	logger.Info("Exporting settings from %s/%s...", client.RepoOwner(), client.RepoName())
-
-	cfg := &config.Config{}
-
-	// Get repo settings
-	repoData, err := client.GetRepo(ctx)
+	client, err := newRepoClient(ctx, repo)
 	if err != nil {
-		return fmt.Errorf("failed to get repo settings: %w", err)
-	}
-
-	cfg.Repo = &config.RepoConfig{
-		Description:         nullableToPtr(repoData.Description),
-		Homepage:            nullableToPtr(repoData.Homepage),
-		Visibility:          repoData.Visibility,
-		AllowMergeCommit:    repoData.AllowMergeCommit,
-		AllowRebaseMerge:    repoData.AllowRebaseMerge,
-		AllowSquashMerge:    repoData.AllowSquashMerge,
-		DeleteBranchOnMerge: repoData.DeleteBranchOnMerge,
-		AllowUpdateBranch:   repoData.AllowUpdateBranch,
-	}
-
-	// Get topics
-	if repoData.Topics != nil && len(*repoData.Topics) > 0 {
-		cfg.Topics = *repoData.Topics
-	}
-
-	// Get labels
-	labels, err := client.GetLabels(ctx)
-	if err == nil && len(labels) > 0 {
-		cfg.Labels = &config.LabelsConfig{
-			ReplaceDefault: false,
-			Items:          make([]config.Label, len(labels)),
-		}
-		for i, l := range labels {
-			cfg.Labels.Items[i] = config.Label{
-				Name:        l.Name,
-				Color:       l.Color,
-				Description: nullableStringVal(l.Description),
-			}
-		}
+		return err
 	}
 
-	// Get secrets and variables if requested
-	if exportIncludeSecrets {
-		cfg.Env = &config.EnvConfig{}
-
-		secrets, err := client.GetSecrets(ctx)
-		if err == nil && len(secrets) > 0 {
-			cfg.Env.Secrets = secrets
-		}
-
-		vars, err := client.GetVariables(ctx)
-		if err == nil && len(vars) > 0 {
-			cfg.Env.Variables = make(map[string]string)
-			for _, v := range vars {
-				cfg.Env.Variables[v.Name] = v.Value
-			}
-		}
+	logger.Info("Exporting settings from %s/%s...", client.RepoOwner(), client.RepoName())
 
-		// Don't export empty env config
-		if len(cfg.Env.Secrets) == 0 && len(cfg.Env.Variables) == 0 {
-			cfg.Env = nil
-		}
+	var cfg *config.Config
+	if exportOnlyDrifted {
+		cfg, err = exportDrifted(ctx, client)
+	} else {
+		cfg, err = buildConfigFromRepoExcluding(ctx, client, exportIncludeSecrets, exclude)
 	}
-
-	// Get actions permissions
-	actionsPerms, err := client.GetActionsPermissions(ctx)
-	if err == nil {
-		enabled := bool(actionsPerms.Enabled)
-		var allowedActions *string
-		if actionsPerms.AllowedActions != nil {
-			s := string(*actionsPerms.AllowedActions)
-			allowedActions = &s
-		}
-		cfg.Actions = &config.ActionsConfig{
-			Enabled:        &enabled,
-			AllowedActions: allowedActions,
-		}
-
-		// Get selected actions if applicable
-		if actionsPerms.AllowedActions != nil && *actionsPerms.AllowedActions == "selected" {
-			selected, err := client.GetActionsSelectedActions(ctx)
-			if err == nil {
-				cfg.Actions.SelectedActions = &config.SelectedActionsConfig{
-					GithubOwnedAllowed: selected.GithubOwnedAllowed,
-					VerifiedAllowed:    selected.VerifiedAllowed,
-				}
-				if selected.PatternsAllowed != nil {
-					cfg.Actions.SelectedActions.PatternsAllowed = *selected.PatternsAllowed
-				}
-			}
-		}
-
-		// Get workflow permissions
-		workflowPerms, err := client.GetActionsWorkflowPermissions(ctx)
-		if err == nil {
-			perms := string(workflowPerms.DefaultWorkflowPermissions)
-			cfg.Actions.DefaultWorkflowPermissions = &perms
-			canApprove := bool(workflowPerms.CanApprovePullRequestReviews)
-			cfg.Actions.CanApprovePullRequestReviews = &canApprove
-		}
+	if err != nil {
+		return err
 	}
 
 	// Output
@@ -177,42 +103,49 @@ func runExport(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func exportToDirectory(cfg *config.Config, dir string) error {
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return err
+// exportDrifted plans the config against the repository and returns the drifted settings
+func exportDrifted(ctx context.Context, client *github.Client) (*config.Config, error) {
+	cfg, err := config.Load(config.LoadOptions{
+		Config:        exportConfig,
+		WarnConflicts: warnConflicts,
+		Context:       ctx,
+		FetchGitHub:   configSources(),
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Export repo settings
-	if cfg.Repo != nil {
-		if err := writeYAMLFile(filepath.Join(dir, "repo.yaml"), map[string]interface{}{"repo": cfg.Repo}); err != nil {
-			return err
-		}
+	configPath := exportConfig
+	if configPath == "" {
+		configPath = config.DefaultSingleFile
 	}
-
-	// Export topics
-	if len(cfg.Topics) > 0 {
-		if err := writeYAMLFile(filepath.Join(dir, "topics.yaml"), map[string]interface{}{"topics": cfg.Topics}); err != nil {
-			return err
-		}
+	dotEnvValues, err := config.LoadDotEnvForEnvironment(configPath, dotEnvEnvironment(""))
+	if err != nil {
+		logger.Debug("Failed to load .env file: %v", err)
 	}
 
-	// Export labels
-	if cfg.Labels != nil && len(cfg.Labels.Items) > 0 {
-		if err := writeYAMLFile(filepath.Join(dir, "labels.yaml"), map[string]interface{}{"labels": cfg.Labels}); err != nil {
-			return err
-		}
+	plan, err := diff.NewCalculatorWithEnv(client, cfg, dotEnvValues).CalculateWithOptions(ctx, diff.CalculateOptions{
+		CheckEnv:      cfg.Env != nil,
+		CheckSecrets:  exportIncludeSecrets,
+		SkipForbidden: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range plan.Filter(diff.Change.IsUnreadable).Changes() {
+		logger.Warn("%v; skipping it", change.New)
 	}
 
-	// Export env (includes both variables and secrets)
-	if cfg.Env != nil && (len(cfg.Env.Variables) > 0 || len(cfg.Env.Secrets) > 0) {
-		if err := writeYAMLFile(filepath.Join(dir, "env.yaml"), map[string]interface{}{"env": cfg.Env}); err != nil {
-			return err
-		}
+	return driftedConfig(cfg, plan)
+}
+
+func exportToDirectory(cfg *config.Config, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
 	}
 
-	// Export actions
-	if cfg.Actions != nil {
-		if err := writeYAMLFile(filepath.Join(dir, "actions.yaml"), map[string]interface{}{"actions": cfg.Actions}); err != nil {
+	for _, section := range directorySections(cfg) {
+		if err := writeYAMLFile(filepath.Join(dir, section.file), map[string]interface{}{section.key: section.value}); err != nil {
 			return err
 		}
 	}
@@ -236,20 +169,3 @@ func writeYAMLFile(path string, data interface{}) error {
 	}
 	return os.WriteFile(path, yamlData, 0o644)
 }
-
-// nullableToPtr converts a nullable.Nullable[string] to *string
-func nullableToPtr(n nullable.Nullable[string]) *string {
-	if !n.IsSpecified() || n.IsNull() {
-		return nil
-	}
-	s := n.MustGet()
-	return &s
-}
-
-// nullableStringVal returns the string value from a nullable.Nullable[string]
-func nullableStringVal(n nullable.Nullable[string]) string {
-	if !n.IsSpecified() || n.IsNull() {
-		return ""
-	}
-	return n.MustGet()
-}
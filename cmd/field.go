@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+	"gopkg.in/yaml.v3"
+)
+
+// Keys that --field can resolve, per section, named as in the config file
+var (
+	repoFieldKeys = []string{
+		"description", "homepage", "visibility",
+		"allow_merge_commit", "allow_rebase_merge", "allow_squash_merge",
+		"delete_branch_on_merge", "allow_update_branch",
+		"allow_auto_merge", "use_squash_pr_title_as_default",
+		"web_commit_signoff_required", "is_template",
+	}
+	actionsFieldKeys = []string{
+		"enabled", "allowed_actions",
+		"selected_actions.github_owned_allowed", "selected_actions.verified_allowed", "selected_actions.patterns_allowed",
+		"default_workflow_permissions", "can_approve_pull_request_reviews",
+		"artifact_retention_days", "access_level",
+	}
+	pagesFieldKeys = []string{
+		"build_type", "source.branch", "source.path", "cname", "https_enforced",
+	}
+	branchProtectionFieldKeys = []string{
+		"required_reviews", "dismiss_stale_reviews", "require_code_owner",
+		"require_status_checks", "status_checks", "strict_status_checks",
+		"require_linear_history", "enforce_admins", "allow_force_pushes", "allow_deletions",
+	}
+)
+
+// currentField is a parsed --field path
+type currentField struct {
+	section string // repo, topics, actions, pages or branch_protection
+	branch  string // only for branch_protection
+	key     string // key within the section, may contain dots
+}
+
+// parseCurrentField parses a field path such as "visibility", "repo.visibility",
+// "pages.cname" or "branch_protection.main.required_reviews"
+func parseCurrentField(path string) (currentField, error) {
+	section, key, _ := strings.Cut(path, ".")
+
+	switch {
+	case key == "" && contains(repoFieldKeys, section):
+		// Repository settings may be given without the "repo." prefix
+		return currentField{section: "repo", key: section}, nil
+	case path == "topics":
+		return currentField{section: "topics"}, nil
+	case section == "repo" && contains(repoFieldKeys, key),
+		section == "actions" && contains(actionsFieldKeys, key),
+		section == "pages" && contains(pagesFieldKeys, key):
+		return currentField{section: section, key: key}, nil
+	case section == "branch_protection":
+		// The branch name may itself contain dots, so the setting is the last segment
+		if i := strings.LastIndex(key, "."); i > 0 && contains(branchProtectionFieldKeys, key[i+1:]) {
+			return currentField{section: section, branch: key[:i], key: key[i+1:]}, nil
+		}
+	}
+
+	return currentField{}, fmt.Errorf("unknown field %q; supported fields:\n  %s", path, strings.Join(supportedCurrentFields(), "\n  "))
+}
+
+// supportedCurrentFields lists every path --field accepts
+func supportedCurrentFields() []string {
+	var fields []string
+	for _, key := range repoFieldKeys {
+		fields = append(fields, key, "repo."+key)
+	}
+	fields = append(fields, "topics")
+	for _, key := range actionsFieldKeys {
+		fields = append(fields, "actions."+key)
+	}
+	for _, key := range pagesFieldKeys {
+		fields = append(fields, "pages."+key)
+	}
+	for _, key := range branchProtectionFieldKeys {
+		fields = append(fields, "branch_protection.<branch>."+key)
+	}
+	return fields
+}
+
+// printCurrentField fetches only the section holding path and writes its current value to w.
+// Lists are written one item per line; a setting that isn't set is written as an empty line.
+func printCurrentField(ctx context.Context, client github.GitHubClient, w io.Writer, path string) error {
+	field, err := parseCurrentField(path)
+	if err != nil {
+		return err
+	}
+
+	value, err := resolveCurrentField(ctx, client, field)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, formatFieldValue(value))
+	return err
+}
+
+// resolveCurrentField returns the current value of field, or nil if it isn't set
+func resolveCurrentField(ctx context.Context, client github.GitHubClient, field currentField) (interface{}, error) {
+	cfg := &config.Config{}
+
+	var section interface{}
+	switch field.section {
+	case "repo", "topics":
+		if err := importRepo(ctx, client, cfg); err != nil {
+			return nil, err
+		}
+		if field.section == "topics" {
+			return cfg.Topics, nil
+		}
+		section = cfg.Repo
+	case "actions":
+		importActions(ctx, client, cfg)
+		section = cfg.Actions
+	case "pages":
+		pagesData, err := client.GetPages(ctx)
+		if err != nil {
+			if apperrors.Is(err, apperrors.ErrPagesNotEnabled) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if pagesData == nil {
+			return nil, nil
+		}
+		section = pagesConfigFromData(pagesData)
+	case "branch_protection":
+		protection, err := client.GetBranchProtection(ctx, field.branch)
+		if err != nil {
+			if apperrors.Is(err, apperrors.ErrBranchNotProtected) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if protection == nil {
+			return nil, nil
+		}
+		section = branchRuleFromProtection(protection)
+	}
+
+	return lookupConfigKey(section, field.key)
+}
+
+// lookupConfigKey looks up a dotted key in a config section by its YAML names
+func lookupConfigKey(section interface{}, key string) (interface{}, error) {
+	data, err := yaml.Marshal(section)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	for _, part := range strings.Split(key, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		value = m[part]
+	}
+	return value, nil
+}
+
+// formatFieldValue renders a field value as plain text
+func formatFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, "\n")
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, "\n")
+	}
+	return fmt.Sprint(value)
+}
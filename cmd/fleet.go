@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
+)
+
+// checkMatchFlags checks that --match and --include-archived are combined with
+// the right flags. Options that read stdin or write a single output file can't
+// serve several repositories.
+func checkMatchFlags(hasOut, fromStdin bool) error {
+	if repoMatch == "" {
+		if includeArchived {
+			return fmt.Errorf("--include-archived requires --match")
+		}
+		return nil
+	}
+	switch {
+	case org == "":
+		return fmt.Errorf("--match requires --org")
+	case repo != "":
+		return fmt.Errorf("--match and --repo cannot be used together")
+	case fromStdin:
+		return fmt.Errorf("--match can't be combined with --config-stdin")
+	case hasOut:
+		return fmt.Errorf("--match can't be combined with --out")
+	}
+	if _, err := path.Match(repoMatch, ""); err != nil {
+		return fmt.Errorf("invalid --match pattern %q: %w", repoMatch, err)
+	}
+	return nil
+}
+
+// matchOrgRepos returns the sorted names of repos matching the glob pattern.
+// Archived repositories are read-only, so they are left out unless includeArchived is set.
+func matchOrgRepos(repos []github.OrgRepoData, pattern string, includeArchived bool) ([]string, error) {
+	var names []string
+	for _, r := range repos {
+		if r.Archived && !includeArchived {
+			continue
+		}
+		ok, err := path.Match(pattern, r.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --match pattern %q: %w", pattern, err)
+		}
+		if ok {
+			names = append(names, r.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// listMatchingRepos lists the repositories of the organization that match pattern
+func listMatchingRepos(ctx context.Context, client github.OrgGitHubClient, pattern string, includeArchived bool) ([]string, error) {
+	repos, err := client.ListRepos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names, err := matchOrgRepos(repos, pattern, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no repositories in %s match %q", client.OrgName(), pattern)
+	}
+	return names, nil
+}
+
+// forEachMatchingRepo runs fn for every repository of --org that matches --match,
+// one after another. A failing repository doesn't stop the others; the failures are
+// reported together at the end. It returns the highest exit code fn returned.
+func forEachMatchingRepo(ctx context.Context, fn func(ctx context.Context, repoArg string) (int, error)) (int, error) {
+	client, err := github.NewOrgClient(org)
+	if err != nil {
+		return 0, err
+	}
+	client.SetTimeout(timeout)
+
+	names, err := listMatchingRepos(ctx, client, repoMatch, includeArchived)
+	if err != nil {
+		return 0, err
+	}
+	logger.Info("%d repositories in %s match %q", len(names), org, repoMatch)
+
+	var failed []string
+	maxCode := 0
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return maxCode, err
+		}
+
+		repoArg := org + "/" + name
+		fmt.Println()
+		logger.Info("==> %s", repoArg)
+
+		code, err := fn(ctx, repoArg)
+		if err != nil {
+			logger.Error("%s: %v", repoArg, err)
+			failed = append(failed, repoArg)
+			continue
+		}
+		if code > maxCode {
+			maxCode = code
+		}
+	}
+
+	if len(failed) > 0 {
+		return maxCode, fmt.Errorf("failed for %d of %d repositories: %s", len(failed), len(names), strings.Join(failed, ", "))
+	}
+	return maxCode, nil
+}
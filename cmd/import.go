@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+	"github.com/oapi-codegen/nullable"
+)
+
+// importedBranches are the branches whose protection rules are imported
+var importedBranches = []string{"main", "master"}
+
+// importSections are the config sections export and init --from-repo read, in the order
+// they are read
+var importSections = []string{"repo", "topics", "labels", "env", "actions", "pages", "branch_protection"}
+
+// buildConfigFromRepo reads the current settings of a repository into a Config,
+// so that planning the result against the same repository shows no changes.
+// It is shared by export and init --from-repo.
+// Optional sections that can't be read (e.g. Pages not enabled) are left out.
+func buildConfigFromRepo(ctx context.Context, client github.GitHubClient, includeSecrets bool) (*config.Config, error) {
+	return buildConfigFromRepoExcluding(ctx, client, includeSecrets, nil)
+}
+
+// buildConfigFromRepoExcluding reads the settings like buildConfigFromRepo, but neither
+// fetches nor sets the sections in exclude
+func buildConfigFromRepoExcluding(ctx context.Context, client github.GitHubClient, includeSecrets bool, exclude map[string]bool) (*config.Config, error) {
+	cfg := &config.Config{}
+
+	// Repository settings and topics come from the same request
+	if !exclude["repo"] || !exclude["topics"] {
+		if err := importRepo(ctx, client, cfg); err != nil {
+			return nil, err
+		}
+		if exclude["repo"] {
+			cfg.Repo = nil
+		}
+		if exclude["topics"] {
+			cfg.Topics = nil
+		}
+	}
+
+	// Get labels
+	if !exclude["labels"] {
+		labels, err := client.GetLabels(ctx)
+		if err == nil && len(labels) > 0 {
+			cfg.Labels = &config.LabelsConfig{
+				ReplaceDefault: false,
+				Items:          make([]config.Label, len(labels)),
+			}
+			for i, l := range labels {
+				cfg.Labels.Items[i] = config.Label{
+					Name:        l.Name,
+					Color:       l.Color,
+					Description: nonEmptyNullableToPtr(l.Description),
+				}
+			}
+		}
+	}
+
+	// Secret values can't be read via the API, so only names are exported, and only on request
+	if includeSecrets && !exclude["env"] {
+		importEnv(ctx, client, cfg)
+	}
+
+	if !exclude["actions"] {
+		importActions(ctx, client, cfg)
+	}
+	if !exclude["pages"] {
+		importPages(ctx, client, cfg)
+	}
+	if !exclude["branch_protection"] {
+		importBranchProtection(ctx, client, cfg)
+	}
+
+	return cfg, nil
+}
+
+// parseExcludeSections parses the comma-separated --exclude flag into a set of section names
+func parseExcludeSections(value string) (map[string]bool, error) {
+	exclude := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(strings.ToLower(part))
+		if part == "" {
+			continue
+		}
+		if !contains(importSections, part) {
+			return nil, fmt.Errorf("invalid --exclude section %q (valid: %s)", part, strings.Join(importSections, ", "))
+		}
+		exclude[part] = true
+	}
+	return exclude, nil
+}
+
+// importRepo reads repository settings and topics
+func importRepo(ctx context.Context, client github.GitHubClient, cfg *config.Config) error {
+	repoData, err := client.GetRepo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get repo settings: %w", err)
+	}
+
+	cfg.Repo = &config.RepoConfig{
+		Description:              nullableToPtr(repoData.Description),
+		Homepage:                 nullableToPtr(repoData.Homepage),
+		Visibility:               repoData.Visibility,
+		AllowMergeCommit:         repoData.AllowMergeCommit,
+		AllowRebaseMerge:         repoData.AllowRebaseMerge,
+		AllowSquashMerge:         repoData.AllowSquashMerge,
+		DeleteBranchOnMerge:      repoData.DeleteBranchOnMerge,
+		AllowUpdateBranch:        repoData.AllowUpdateBranch,
+		AllowAutoMerge:           repoData.AllowAutoMerge,
+		UseSquashPRTitle:         repoData.UseSquashPrTitleAsDefault,
+		WebCommitSignoffRequired: repoData.WebCommitSignoffRequired,
+		IsTemplate:               repoData.IsTemplate,
+	}
+
+	if repoData.Topics != nil && len(*repoData.Topics) > 0 {
+		cfg.Topics = *repoData.Topics
+	}
+	return nil
+}
+
+// importEnv reads secret names and variables
+func importEnv(ctx context.Context, client github.GitHubClient, cfg *config.Config) {
+	env := &config.EnvConfig{}
+
+	secrets, err := client.GetSecrets(ctx)
+	if err == nil {
+		for _, name := range secrets {
+			env.Secrets = append(env.Secrets, config.Secret{Name: name})
+		}
+	}
+
+	vars, err := client.GetVariables(ctx)
+	if err == nil && len(vars) > 0 {
+		env.Variables = make(map[string]string)
+		for _, v := range vars {
+			env.Variables[v.Name] = v.Value
+		}
+	}
+
+	// Don't export empty env config
+	if len(env.Secrets) > 0 || len(env.Variables) > 0 {
+		cfg.Env = env
+	}
+}
+
+// importActions reads actions permissions, selected actions, workflow permissions, retention and the access level
+func importActions(ctx context.Context, client github.GitHubClient, cfg *config.Config) {
+	actionsPerms, err := client.GetActionsPermissions(ctx)
+	if err != nil || actionsPerms == nil {
+		return
+	}
+
+	enabled := bool(actionsPerms.Enabled)
+	var allowedActions *string
+	if actionsPerms.AllowedActions != nil {
+		s := string(*actionsPerms.AllowedActions)
+		allowedActions = &s
+	}
+	cfg.Actions = &config.ActionsConfig{
+		Enabled:        &enabled,
+		AllowedActions: allowedActions,
+	}
+
+	// Get selected actions if applicable
+	if actionsPerms.AllowedActions != nil && *actionsPerms.AllowedActions == "selected" {
+		selected, err := client.GetActionsSelectedActions(ctx)
+		if err == nil && selected != nil {
+			cfg.Actions.SelectedActions = &config.SelectedActionsConfig{
+				GithubOwnedAllowed: selected.GithubOwnedAllowed,
+				VerifiedAllowed:    selected.VerifiedAllowed,
+			}
+			if selected.PatternsAllowed != nil {
+				cfg.Actions.SelectedActions.PatternsAllowed = *selected.PatternsAllowed
+			}
+		}
+	}
+
+	// Get workflow permissions
+	workflowPerms, err := client.GetActionsWorkflowPermissions(ctx)
+	if err == nil && workflowPerms != nil {
+		perms := string(workflowPerms.DefaultWorkflowPermissions)
+		cfg.Actions.DefaultWorkflowPermissions = &perms
+		canApprove := bool(workflowPerms.CanApprovePullRequestReviews)
+		cfg.Actions.CanApprovePullRequestReviews = &canApprove
+	}
+
+	retention, err := client.GetActionsRetention(ctx)
+	if err == nil && retention != nil && retention.Days > 0 {
+		cfg.Actions.ArtifactRetentionDays = &retention.Days
+	}
+
+	// The access level only exists for private repositories; others return an error
+	access, err := client.GetActionsAccessLevel(ctx)
+	if err == nil && access != nil && access.AccessLevel != "" {
+		cfg.Actions.AccessLevel = &access.AccessLevel
+	}
+}
+
+// importPages reads the Pages configuration.
+// Pages not enabled returns 404, which is fine to ignore.
+func importPages(ctx context.Context, client github.GitHubClient, cfg *config.Config) {
+	pagesData, err := client.GetPages(ctx)
+	if err != nil || pagesData == nil {
+		return
+	}
+	cfg.Pages = pagesConfigFromData(pagesData)
+}
+
+// pagesConfigFromData converts the Pages API response into a PagesConfig
+func pagesConfigFromData(pagesData *github.PagesData) *config.PagesConfig {
+	var buildType *string
+	if pagesData.BuildType.IsSpecified() && !pagesData.BuildType.IsNull() {
+		bt := string(pagesData.BuildType.MustGet())
+		buildType = &bt
+	}
+	pages := &config.PagesConfig{
+		BuildType:     buildType,
+		Cname:         nonEmptyNullableToPtr(pagesData.Cname),
+		HttpsEnforced: pagesData.HttpsEnforced,
+	}
+	if pagesData.Source != nil && buildType != nil && *buildType == "legacy" {
+		pages.Source = &config.PagesSourceConfig{
+			Branch: &pagesData.Source.Branch,
+			Path:   &pagesData.Source.Path,
+		}
+	}
+	return pages
+}
+
+// importBranchProtection reads protection rules for the common default branches
+func importBranchProtection(ctx context.Context, client github.GitHubClient, cfg *config.Config) {
+	importBranchProtectionFor(ctx, client, cfg, importedBranches)
+}
+
+// importBranchProtectionFor reads the protection rules of branches that are protected
+func importBranchProtectionFor(ctx context.Context, client github.GitHubClient, cfg *config.Config, branches []string) {
+	for _, branch := range branches {
+		protection, err := client.GetBranchProtection(ctx, branch)
+		if err != nil || protection == nil {
+			continue // Branch protection not enabled or branch doesn't exist
+		}
+
+		if cfg.BranchProtection == nil {
+			cfg.BranchProtection = make(map[string]*config.BranchRule)
+		}
+		cfg.BranchProtection[branch] = branchRuleFromProtection(protection)
+	}
+}
+
+// branchRuleFromProtection converts a branch's protection settings into a BranchRule
+func branchRuleFromProtection(protection *github.BranchProtectionData) *config.BranchRule {
+	rule := &config.BranchRule{}
+
+	// Required reviews
+	if protection.RequiredPullRequestReviews != nil {
+		rule.RequiredReviews = protection.RequiredPullRequestReviews.RequiredApprovingReviewCount
+		rule.DismissStaleReviews = &protection.RequiredPullRequestReviews.DismissStaleReviews
+		rule.RequireCodeOwner = &protection.RequiredPullRequestReviews.RequireCodeOwnerReviews
+	}
+
+	// Enforce admins
+	if protection.EnforceAdmins != nil {
+		rule.EnforceAdmins = &protection.EnforceAdmins.Enabled
+	}
+
+	// Required status checks
+	if protection.RequiredStatusChecks != nil {
+		requireChecks := true
+		rule.RequireStatusChecks = &requireChecks
+		rule.StrictStatusChecks = protection.RequiredStatusChecks.Strict
+		rule.StatusChecks = statusChecksFromProtection(protection)
+	}
+
+	// Linear history
+	if protection.RequiredLinearHistory != nil {
+		rule.RequireLinearHistory = protection.RequiredLinearHistory.Enabled
+	}
+
+	// Force pushes
+	if protection.AllowForcePushes != nil {
+		rule.AllowForcePushes = protection.AllowForcePushes.Enabled
+	}
+
+	// Deletions
+	if protection.AllowDeletions != nil {
+		rule.AllowDeletions = protection.AllowDeletions.Enabled
+	}
+
+	return rule
+}
+
+// statusChecksFromProtection returns the required status checks, with the app each one is
+// tied to. Older responses without the checks array only list the contexts.
+func statusChecksFromProtection(protection *github.BranchProtectionData) []config.StatusCheck {
+	required := protection.RequiredStatusChecks
+	if len(required.Checks) == 0 {
+		var checks []config.StatusCheck
+		for _, context := range required.Contexts {
+			checks = append(checks, config.StatusCheck{Context: context})
+		}
+		return checks
+	}
+
+	checks := make([]config.StatusCheck, len(required.Checks))
+	for i, check := range required.Checks {
+		checks[i] = config.StatusCheck{Context: check.Context}
+		if id, err := check.AppId.Get(); err == nil {
+			checks[i].AppID = &id
+		}
+	}
+	return checks
+}
+
+// nullableToPtr converts a nullable.Nullable[string] to *string
+func nullableToPtr(n nullable.Nullable[string]) *string {
+	if !n.IsSpecified() || n.IsNull() {
+		return nil
+	}
+	s := n.MustGet()
+	return &s
+}
+
+// nonEmptyNullableToPtr is like nullableToPtr, but also returns nil for an empty string
+// so that exported configs don't list empty label descriptions or custom domains
+func nonEmptyNullableToPtr(n nullable.Nullable[string]) *string {
+	if nullableStringVal(n) == "" {
+		return nil
+	}
+	return nullableToPtr(n)
+}
+
+// nullableStringVal returns the string value from a nullable.Nullable[string]
+func nullableStringVal(n nullable.Nullable[string]) string {
+	if !n.IsSpecified() || n.IsNull() {
+		return ""
+	}
+	return n.MustGet()
+}
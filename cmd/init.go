@@ -7,13 +7,12 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/myzkey/gh-repo-settings/internal/config"
-	"github.com/myzkey/gh-repo-settings/internal/infra/github"
 	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
-	"github.com/oapi-codegen/nullable"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +21,8 @@ var (
 	initFromRepo   string
 	initSingleFile bool
 	initDirectory  bool
+	initWorkflow   bool
+	initExclude    string
 )
 
 var initCmd = &cobra.Command{
@@ -36,7 +37,9 @@ based on a template or known-good configuration.
 Example:
   gh repo-settings init --from-repo owner/repo-template
   gh repo-settings init --from-repo owner/repo-template --single-file
-  gh repo-settings init --from-repo owner/repo-template --directory`,
+  gh repo-settings init --from-repo owner/repo-template --directory
+  gh repo-settings init --from-repo owner/repo-template --exclude labels,actions
+  gh repo-settings init --with-workflow`,
 	RunE: runInit,
 }
 
@@ -46,6 +49,8 @@ func init() {
 	initCmd.Flags().StringVar(&initFromRepo, "from-repo", "", "Import settings from an existing repository (owner/repo)")
 	initCmd.Flags().BoolVar(&initSingleFile, "single-file", false, "Output as a single YAML file (with --from-repo)")
 	initCmd.Flags().BoolVar(&initDirectory, "directory", false, "Output as directory with multiple YAML files (with --from-repo)")
+	initCmd.Flags().StringVar(&initExclude, "exclude", "", "Comma-separated sections not to import (with --from-repo): "+strings.Join(importSections, ", "))
+	initCmd.Flags().BoolVar(&initWorkflow, "with-workflow", false, "Also write "+defaultWorkflowPath+" to plan on pull requests and apply on main")
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
@@ -170,21 +175,21 @@ func runInit(cmd *cobra.Command, args []string) error {
 		case "semantic":
 			cfg.Labels = &config.LabelsConfig{
 				Items: []config.Label{
-					{Name: "feat", Color: "0e8a16", Description: "New feature"},
-					{Name: "fix", Color: "d73a4a", Description: "Bug fix"},
-					{Name: "docs", Color: "0075ca", Description: "Documentation"},
-					{Name: "refactor", Color: "cfd3d7", Description: "Code refactoring"},
-					{Name: "test", Color: "fbca04", Description: "Tests"},
-					{Name: "chore", Color: "fef2c0", Description: "Maintenance"},
+					{Name: "feat", Color: "0e8a16", Description: ptr("New feature")},
+					{Name: "fix", Color: "d73a4a", Description: ptr("Bug fix")},
+					{Name: "docs", Color: "0075ca", Description: ptr("Documentation")},
+					{Name: "refactor", Color: "cfd3d7", Description: ptr("Code refactoring")},
+					{Name: "test", Color: "fbca04", Description: ptr("Tests")},
+					{Name: "chore", Color: "fef2c0", Description: ptr("Maintenance")},
 				},
 			}
 		case "priority":
 			cfg.Labels = &config.LabelsConfig{
 				Items: []config.Label{
-					{Name: "priority: critical", Color: "b60205", Description: "Critical priority"},
-					{Name: "priority: high", Color: "d93f0b", Description: "High priority"},
-					{Name: "priority: medium", Color: "fbca04", Description: "Medium priority"},
-					{Name: "priority: low", Color: "0e8a16", Description: "Low priority"},
+					{Name: "priority: critical", Color: "b60205", Description: ptr("Critical priority")},
+					{Name: "priority: high", Color: "d93f0b", Description: ptr("High priority")},
+					{Name: "priority: medium", Color: "fbca04", Description: ptr("Medium priority")},
+					{Name: "priority: low", Color: "0e8a16", Description: ptr("Low priority")},
 				},
 			}
 		}
@@ -270,11 +275,24 @@ func runInit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Write config
-	if filepath.Ext(outputPath) == "" || outputPath[len(outputPath)-1] == '/' {
-		return writeConfigToDirectory(cfg, outputPath)
+	return writeInitOutput(cfg, outputPath, filepath.Ext(outputPath) == "" || outputPath[len(outputPath)-1] == '/')
+}
+
+// writeInitOutput writes cfg to outputPath, as a directory config when asDir is set,
+// followed by the workflow with --with-workflow
+func writeInitOutput(cfg *config.Config, outputPath string, asDir bool) error {
+	write := writeConfigToFile
+	if asDir {
+		write = writeConfigToDirectory
 	}
-	return writeConfigToFile(cfg, outputPath)
+	if err := write(cfg, outputPath); err != nil {
+		return err
+	}
+
+	if initWorkflow {
+		return writeWorkflow(defaultWorkflowPath, outputPath, asDir)
+	}
+	return nil
 }
 
 func writeConfigToFile(cfg *config.Config, path string) error {
@@ -296,52 +314,76 @@ func writeConfigToFile(cfg *config.Config, path string) error {
 	return nil
 }
 
-func writeConfigToDirectory(cfg *config.Config, dir string) error {
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+// directorySection is one file of a directory-style config
+type directorySection struct {
+	file  string
+	key   string
+	value interface{}
+}
+
+// directorySections returns the files a config is split into, mirroring the
+// file names recognized when loading a config directory. Empty sections are omitted.
+func directorySections(cfg *config.Config) []directorySection {
+	var sections []directorySection
+	add := func(file, key string, value interface{}) {
+		sections = append(sections, directorySection{file: file, key: key, value: value})
 	}
 
 	if cfg.Repo != nil {
-		data, err := marshalYAML(map[string]interface{}{"repo": cfg.Repo})
-		if err != nil {
-			return fmt.Errorf("failed to marshal repo config: %w", err)
-		}
-		if err := os.WriteFile(filepath.Join(dir, "repo.yaml"), data, 0o644); err != nil {
-			return fmt.Errorf("failed to write repo.yaml: %w", err)
-		}
+		add("repo.yaml", "repo", cfg.Repo)
 	}
-
 	if len(cfg.Topics) > 0 {
-		data, err := marshalYAML(map[string]interface{}{"topics": cfg.Topics})
-		if err != nil {
-			return fmt.Errorf("failed to marshal topics config: %w", err)
-		}
-		if err := os.WriteFile(filepath.Join(dir, "topics.yaml"), data, 0o644); err != nil {
-			return fmt.Errorf("failed to write topics.yaml: %w", err)
-		}
+		add("topics.yaml", "topics", cfg.Topics)
 	}
-
 	if cfg.Labels != nil {
-		data, err := marshalYAML(map[string]interface{}{"labels": cfg.Labels})
-		if err != nil {
-			return fmt.Errorf("failed to marshal labels config: %w", err)
-		}
-		if err := os.WriteFile(filepath.Join(dir, "labels.yaml"), data, 0o644); err != nil {
-			return fmt.Errorf("failed to write labels.yaml: %w", err)
-		}
+		add("labels.yaml", "labels", cfg.Labels)
+	}
+	if len(cfg.BranchProtection) > 0 {
+		add("branch-protection.yaml", "branch_protection", cfg.BranchProtection)
+	}
+	if cfg.Env != nil {
+		add("env.yaml", "env", cfg.Env)
+	}
+	if cfg.Actions != nil {
+		add("actions.yaml", "actions", cfg.Actions)
+	}
+	if cfg.Pages != nil {
+		add("pages.yaml", "pages", cfg.Pages)
+	}
+	if cfg.Templates != nil {
+		add("templates.yaml", "templates", cfg.Templates)
+	}
+	if cfg.Org != nil {
+		add("org.yaml", "org", cfg.Org)
 	}
 
-	if cfg.BranchProtection != nil {
-		data, err := marshalYAML(map[string]interface{}{"branch_protection": cfg.BranchProtection})
+	return sections
+}
+
+func writeConfigToDirectory(cfg *config.Config, dir string) error {
+	if err := writeDirectorySections(cfg, dir); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n✓ Configuration written to %s\n", dir)
+	return nil
+}
+
+// writeDirectorySections writes each section of cfg to its own file in dir
+func writeDirectorySections(cfg *config.Config, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	for _, section := range directorySections(cfg) {
+		data, err := marshalYAML(map[string]interface{}{section.key: section.value})
 		if err != nil {
-			return fmt.Errorf("failed to marshal branch_protection config: %w", err)
+			return fmt.Errorf("failed to marshal %s config: %w", section.key, err)
 		}
-		if err := os.WriteFile(filepath.Join(dir, "branch-protection.yaml"), data, 0o644); err != nil {
-			return fmt.Errorf("failed to write branch-protection.yaml: %w", err)
+		if err := os.WriteFile(filepath.Join(dir, section.file), data, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", section.file, err)
 		}
 	}
-
-	fmt.Printf("\n✓ Configuration written to %s\n", dir)
 	return nil
 }
 
@@ -397,6 +439,10 @@ func runInitFromRepo(cmd *cobra.Command, args []string) error {
 	if initSingleFile && initDirectory {
 		return fmt.Errorf("cannot use both --single-file and --directory flags")
 	}
+	exclude, err := parseExcludeSections(initExclude)
+	if err != nil {
+		return err
+	}
 
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -414,7 +460,7 @@ func runInitFromRepo(cmd *cobra.Command, args []string) error {
 	logger.Info("Importing settings from %s...", initFromRepo)
 
 	// Fetch settings from the source repository
-	cfg, err := fetchRepoSettings(ctx, initFromRepo)
+	cfg, err := fetchRepoSettings(ctx, initFromRepo, exclude)
 	if err != nil {
 		return fmt.Errorf("failed to fetch settings from %s: %w", initFromRepo, err)
 	}
@@ -429,191 +475,26 @@ func runInitFromRepo(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Write config
-	if initDirectory || (outputPath != "" && outputPath[len(outputPath)-1] == '/') {
-		return writeConfigToDirectory(cfg, outputPath)
-	}
-	return writeConfigToFile(cfg, outputPath)
+	return writeInitOutput(cfg, outputPath, initDirectory || outputPath[len(outputPath)-1] == '/')
 }
 
-// fetchRepoSettings fetches settings from a GitHub repository
-func fetchRepoSettings(ctx context.Context, repoArg string) (*config.Config, error) {
-	client, err := github.NewClientWithContext(ctx, repoArg)
+// fetchRepoSettings fetches settings from a GitHub repository, leaving out the sections in exclude
+func fetchRepoSettings(ctx context.Context, repoArg string, exclude map[string]bool) (*config.Config, error) {
+	client, err := newRepoClient(ctx, repoArg)
 	if err != nil {
 		return nil, err
 	}
 
-	cfg := &config.Config{}
-
-	// Get repo settings
-	repoData, err := client.GetRepo(ctx)
+	cfg, err := buildConfigFromRepoExcluding(ctx, client, false, exclude)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get repo settings: %w", err)
-	}
-
-	cfg.Repo = &config.RepoConfig{
-		Description:         initNullableToPtr(repoData.Description),
-		Homepage:            initNullableToPtr(repoData.Homepage),
-		Visibility:          repoData.Visibility,
-		AllowMergeCommit:    repoData.AllowMergeCommit,
-		AllowRebaseMerge:    repoData.AllowRebaseMerge,
-		AllowSquashMerge:    repoData.AllowSquashMerge,
-		DeleteBranchOnMerge: repoData.DeleteBranchOnMerge,
-		AllowUpdateBranch:   repoData.AllowUpdateBranch,
-	}
-
-	// Get topics
-	if repoData.Topics != nil && len(*repoData.Topics) > 0 {
-		cfg.Topics = *repoData.Topics
-	}
-
-	// Get labels
-	labels, err := client.GetLabels(ctx)
-	if err == nil && len(labels) > 0 {
-		cfg.Labels = &config.LabelsConfig{
-			ReplaceDefault: false,
-			Items:          make([]config.Label, len(labels)),
-		}
-		for i, l := range labels {
-			cfg.Labels.Items[i] = config.Label{
-				Name:        l.Name,
-				Color:       l.Color,
-				Description: initNullableStringVal(l.Description),
-			}
-		}
-	}
-
-	// Note: Secrets cannot be read via API, so we skip them
-	// Variables can be read, but we skip them by default for security
-
-	// Get actions permissions
-	actionsPerms, err := client.GetActionsPermissions(ctx)
-	if err == nil {
-		enabled := bool(actionsPerms.Enabled)
-		var allowedActions *string
-		if actionsPerms.AllowedActions != nil {
-			s := string(*actionsPerms.AllowedActions)
-			allowedActions = &s
-		}
-		cfg.Actions = &config.ActionsConfig{
-			Enabled:        &enabled,
-			AllowedActions: allowedActions,
-		}
-
-		// Get selected actions if applicable
-		if actionsPerms.AllowedActions != nil && *actionsPerms.AllowedActions == "selected" {
-			selected, err := client.GetActionsSelectedActions(ctx)
-			if err == nil {
-				cfg.Actions.SelectedActions = &config.SelectedActionsConfig{
-					GithubOwnedAllowed: selected.GithubOwnedAllowed,
-					VerifiedAllowed:    selected.VerifiedAllowed,
-				}
-				if selected.PatternsAllowed != nil {
-					cfg.Actions.SelectedActions.PatternsAllowed = *selected.PatternsAllowed
-				}
-			}
-		}
-
-		// Get workflow permissions
-		workflowPerms, err := client.GetActionsWorkflowPermissions(ctx)
-		if err == nil {
-			perms := string(workflowPerms.DefaultWorkflowPermissions)
-			cfg.Actions.DefaultWorkflowPermissions = &perms
-			canApprove := bool(workflowPerms.CanApprovePullRequestReviews)
-			cfg.Actions.CanApprovePullRequestReviews = &canApprove
-		}
-	}
-
-	// Get pages configuration
-	pagesData, err := client.GetPages(ctx)
-	if err == nil {
-		var buildType *string
-		if pagesData.BuildType.IsSpecified() && !pagesData.BuildType.IsNull() {
-			bt := string(pagesData.BuildType.MustGet())
-			buildType = &bt
-		}
-		cfg.Pages = &config.PagesConfig{
-			BuildType: buildType,
-		}
-		if pagesData.Source != nil && buildType != nil && *buildType == "legacy" {
-			cfg.Pages.Source = &config.PagesSourceConfig{
-				Branch: &pagesData.Source.Branch,
-				Path:   &pagesData.Source.Path,
-			}
-		}
-	}
-	// Note: Pages not enabled returns 404, which is fine to ignore
-
-	// Get branch protection for common branches
-	for _, branch := range []string{"main", "master"} {
-		protection, err := client.GetBranchProtection(ctx, branch)
-		if err != nil {
-			continue // Branch protection not enabled or branch doesn't exist
-		}
-
-		if cfg.BranchProtection == nil {
-			cfg.BranchProtection = make(map[string]*config.BranchRule)
-		}
-
-		rule := &config.BranchRule{}
-
-		// Required reviews
-		if protection.RequiredPullRequestReviews != nil {
-			rule.RequiredReviews = protection.RequiredPullRequestReviews.RequiredApprovingReviewCount
-			rule.DismissStaleReviews = &protection.RequiredPullRequestReviews.DismissStaleReviews
-			rule.RequireCodeOwner = &protection.RequiredPullRequestReviews.RequireCodeOwnerReviews
-		}
-
-		// Enforce admins
-		if protection.EnforceAdmins != nil {
-			rule.EnforceAdmins = &protection.EnforceAdmins.Enabled
-		}
-
-		// Required status checks
-		if protection.RequiredStatusChecks != nil {
-			requireChecks := true
-			rule.RequireStatusChecks = &requireChecks
-			rule.StrictStatusChecks = protection.RequiredStatusChecks.Strict
-			if len(protection.RequiredStatusChecks.Contexts) > 0 {
-				rule.StatusChecks = protection.RequiredStatusChecks.Contexts
-			}
-		}
-
-		// Linear history
-		if protection.RequiredLinearHistory != nil {
-			rule.RequireLinearHistory = protection.RequiredLinearHistory.Enabled
-		}
-
-		// Force pushes
-		if protection.AllowForcePushes != nil {
-			rule.AllowForcePushes = protection.AllowForcePushes.Enabled
-		}
-
-		// Deletions
-		if protection.AllowDeletions != nil {
-			rule.AllowDeletions = protection.AllowDeletions.Enabled
-		}
-
-		cfg.BranchProtection[branch] = rule
+		return nil, err
 	}
 
 	logger.Success("Fetched settings from %s/%s", client.RepoOwner(), client.RepoName())
 	return cfg, nil
 }
 
-// initNullableToPtr converts a nullable.Nullable[string] to *string
-func initNullableToPtr(n nullable.Nullable[string]) *string {
-	if !n.IsSpecified() || n.IsNull() {
-		return nil
-	}
-	s := n.MustGet()
-	return &s
-}
-
-// initNullableStringVal returns the string value from a nullable.Nullable[string]
-func initNullableStringVal(n nullable.Nullable[string]) string {
-	if !n.IsSpecified() || n.IsNull() {
-		return ""
-	}
-	return n.MustGet()
+// ptr returns a pointer to v
+func ptr[T any](v T) *T {
+	return &v
 }
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/myzkey/gh-repo-settings/internal/diff"
+)
+
+// changeDecision is the answer to an interactive apply prompt
+type changeDecision string
+
+const (
+	decisionApply changeDecision = "apply"
+	decisionSkip  changeDecision = "skip"
+	decisionQuit  changeDecision = "quit"
+)
+
+// changePrompt asks what to do with the index-th of total changes
+type changePrompt func(change diff.Change, index, total int) (changeDecision, error)
+
+// selectChanges asks prompt about every change in plan and returns the plan of confirmed changes.
+// Quitting discards the changes confirmed so far and returns ok=false, so nothing is applied.
+func selectChanges(plan *diff.Plan, prompt changePrompt) (selected *diff.Plan, ok bool, err error) {
+	changes := plan.Changes()
+	confirmed := make([]bool, len(changes))
+
+	for i, change := range changes {
+		decision, err := prompt(change, i+1, len(changes))
+		if err != nil {
+			return nil, false, err
+		}
+		switch decision {
+		case decisionApply:
+			confirmed[i] = true
+		case decisionSkip:
+		case decisionQuit:
+			return nil, false, nil
+		default:
+			return nil, false, fmt.Errorf("unknown answer %q", decision)
+		}
+	}
+
+	// Filter visits changes in order, so the position identifies each change
+	i := 0
+	selected = plan.Filter(func(diff.Change) bool {
+		keep := confirmed[i]
+		i++
+		return keep
+	})
+	return selected, true, nil
+}
+
+// Values of --interactive-by
+const (
+	interactiveByChange   = "change"
+	interactiveByCategory = "category"
+)
+
+// categoryPrompt asks whether to apply the changes of the index-th of total categories
+type categoryPrompt func(category diff.ChangeCategory, changes []diff.Change, index, total int) (bool, error)
+
+// selectCategories asks prompt about each category of plan in plan order and returns the plan
+// of the confirmed categories along with the categories that were skipped
+func selectCategories(plan *diff.Plan, prompt categoryPrompt) (selected *diff.Plan, skipped []diff.ChangeCategory, err error) {
+	categories := plan.Categories()
+	confirmed := make(map[diff.ChangeCategory]bool, len(categories))
+
+	for i, category := range categories {
+		ok, err := prompt(category, plan.FilterByCategory(category).Changes(), i+1, len(categories))
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			confirmed[category] = true
+		} else {
+			skipped = append(skipped, category)
+		}
+	}
+
+	selected = plan.Filter(func(c diff.Change) bool {
+		return confirmed[c.Category]
+	})
+	return selected, skipped, nil
+}
+
+// joinCategories lists categories for the apply summary
+func joinCategories(categories []diff.ChangeCategory) string {
+	names := make([]string, len(categories))
+	for i, c := range categories {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ", ")
+}
+
+// surveyCategoryPrompt lists a category's changes and asks about them on the terminal
+func surveyCategoryPrompt(category diff.ChangeCategory, changes []diff.Change, index, total int) (bool, error) {
+	fmt.Println()
+	for _, change := range changes {
+		fmt.Printf("  %s\n", change)
+	}
+	answer := true
+	if err := survey.AskOne(&survey.Confirm{
+		Message: fmt.Sprintf("(%d/%d) Apply %d %s change(s)?", index, total, len(changes), category),
+		Default: true,
+	}, &answer); err != nil {
+		return false, err
+	}
+	return answer, nil
+}
+
+// surveyChangePrompt asks about a change on the terminal
+func surveyChangePrompt(change diff.Change, index, total int) (changeDecision, error) {
+	var answer string
+	if err := survey.AskOne(&survey.Select{
+		Message: fmt.Sprintf("(%d/%d) %s", index, total, change),
+		Options: []string{string(decisionApply), string(decisionSkip), string(decisionQuit)},
+		Default: string(decisionApply),
+	}, &answer); err != nil {
+		return "", err
+	}
+	return changeDecision(answer), nil
+}
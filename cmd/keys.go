@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var keysJSON bool
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "List every config key with its type",
+	Long: `List every config key with its type, allowed values and description, one per line.
+"*" stands for a map key such as a branch name and "[]" for a list item. This is a flat
+view of the JSON Schema for editor tooling and grep.`,
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runKeys,
+}
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+	keysCmd.Flags().BoolVar(&keysJSON, "json", false, "Print the keys as a JSON array")
+}
+
+func runKeys(cmd *cobra.Command, args []string) error {
+	keys, err := config.DescribeKeys()
+	if err != nil {
+		return err
+	}
+	if keysJSON {
+		return printKeysJSON(os.Stdout, keys)
+	}
+	return printKeys(os.Stdout, keys)
+}
+
+// printKeys writes one key per line: path, type, allowed values and description
+func printKeys(w io.Writer, keys []config.KeyDescriptor) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, key := range keys {
+		enum := "-"
+		if len(key.Enum) > 0 {
+			enum = strings.Join(key.Enum, "|")
+		}
+		description := key.Description
+		if key.Deprecated {
+			description = "(deprecated) " + description
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", key.Path, key.Type, enum, description)
+	}
+	return tw.Flush()
+}
+
+// printKeysJSON writes the keys as an indented JSON array
+func printKeysJSON(w io.Writer, keys []config.KeyDescriptor) error {
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
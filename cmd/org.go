@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/diff"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
+)
+
+// loadOrgConfig loads the configuration and returns its org section
+func loadOrgConfig(opts config.LoadOptions) (*config.OrgConfig, error) {
+	if repo != "" {
+		return nil, fmt.Errorf("--org and --repo cannot be used together")
+	}
+
+	cfg, err := config.Load(opts)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Org == nil {
+		return nil, fmt.Errorf("no org settings in config; add an 'org' section to use --org")
+	}
+	return cfg.Org, nil
+}
+
+// runOrgPlan shows planned changes for organization settings
+func runOrgPlan(ctx context.Context, opts config.LoadOptions, failOn []diff.ChangeType) error {
+	orgCfg, err := loadOrgConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	client, err := github.NewOrgClient(org)
+	if err != nil {
+		return err
+	}
+	client.SetTimeout(timeout)
+
+	announcePlan(nil, "Planning changes for org %s...\n", client.OrgName())
+
+	plan, err := diff.NewOrgCalculator(client, orgCfg).Calculate(ctx)
+	if err != nil {
+		return err
+	}
+	announcePlan(plan, "Planning changes for org %s...\n", client.OrgName())
+
+	if err := outputPlan(plan, "No changes detected. Organization is up to date."); err != nil {
+		return err
+	}
+
+	if code := exitCodeFor(plan.Stats(), failOn); code != 0 {
+		os.Exit(code)
+	}
+	return nil
+}
+
+// runOrgApply applies organization settings, printing the plan and progress to w
+func runOrgApply(ctx context.Context, opts config.LoadOptions, w io.Writer) error {
+	orgCfg, err := loadOrgConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	client, err := github.NewOrgClient(org)
+	if err != nil {
+		return err
+	}
+	client.SetTimeout(timeout)
+
+	logger.Info("Applying changes to org %s...\n", client.OrgName())
+
+	plan, err := diff.NewOrgCalculator(client, orgCfg).Calculate(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !plan.HasChanges() {
+		logger.Success("No changes to apply. Organization is up to date.")
+		return nil
+	}
+
+	_ = renderPlan(w, plan, false, colorEnabled(w))
+
+	if !autoApprove {
+		ok, err := askYesNo("Do you want to apply these changes?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			logger.Info("Apply cancelled.")
+			return nil
+		}
+	}
+
+	fmt.Fprintln(w)
+	logger.Info("Applying changes...")
+	fmt.Fprintln(w)
+
+	if err := applyOrgActionsChanges(ctx, client, orgCfg.Actions, plan.Changes(), w); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	logger.Success("Apply complete!")
+	return nil
+}
+
+// applyOrgActionsChanges applies org_actions changes, printing progress to w and sending
+// current values for fields that aren't configured since the endpoints replace all settings
+func applyOrgActionsChanges(ctx context.Context, client github.OrgGitHubClient, cfg *config.OrgActionsConfig, changes []diff.Change, w io.Writer) error {
+	steps := newStepPrinter(w, 1, color.New(color.FgGreen).SprintFunc(), color.New(color.FgRed).SprintFunc())
+
+	needsPermissionsUpdate := false
+	needsWorkflowUpdate := false
+	for _, change := range changes {
+		if change.Category != diff.CategoryOrgActions {
+			continue
+		}
+		switch change.Key {
+		case "enabled_repositories", "allowed_actions":
+			needsPermissionsUpdate = true
+		case "default_workflow_permissions", "can_approve_pull_request_reviews":
+			needsWorkflowUpdate = true
+		}
+	}
+
+	if needsPermissionsUpdate {
+		current, err := client.GetOrgActionsPermissions(ctx)
+		if err != nil {
+			return err
+		}
+		enabledRepositories := current.EnabledRepositories
+		if cfg.EnabledRepositories != nil {
+			enabledRepositories = *cfg.EnabledRepositories
+		}
+		allowedActions := ptrStringVal(current.AllowedActions)
+		if cfg.AllowedActions != nil {
+			allowedActions = *cfg.AllowedActions
+		}
+
+		if err := steps.run("Updating org actions permissions", func() error {
+			return client.UpdateOrgActionsPermissions(ctx, enabledRepositories, allowedActions)
+		}); err != nil {
+			return describeApplyError(err, "failed to update org actions permissions")
+		}
+	}
+
+	if needsWorkflowUpdate {
+		current, err := client.GetOrgWorkflowPermissions(ctx)
+		if err != nil {
+			return err
+		}
+		permissions := string(current.DefaultWorkflowPermissions)
+		if cfg.DefaultWorkflowPermissions != nil {
+			permissions = *cfg.DefaultWorkflowPermissions
+		}
+		canApprove := bool(current.CanApprovePullRequestReviews)
+		if cfg.CanApprovePullRequestReviews != nil {
+			canApprove = *cfg.CanApprovePullRequestReviews
+		}
+
+		if err := steps.run("Updating org workflow permissions", func() error {
+			return client.UpdateOrgWorkflowPermissions(ctx, permissions, canApprove)
+		}); err != nil {
+			return describeApplyError(err, "failed to update org workflow permissions")
+		}
+	}
+
+	return nil
+}
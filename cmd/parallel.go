@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// runConcurrently calls fn for each index below n with at most limit calls in flight.
+// With a limit of 1 the calls run in order and stop at the first error; otherwise all of
+// them run and the error of the first failing index is returned, so errors follow plan order.
+// The client serializes mutations of the same resource, so fn may touch any resource.
+func runConcurrently(n, limit int, fn func(i int) error) error {
+	if limit <= 1 {
+		for i := 0; i < n; i++ {
+			if err := fn(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	errs := make([]error, n)
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stepPrinter prints the "  Creating label 'bug'... ✓" line of an apply step.
+// Sequential steps print their description before the call, so a slow call shows what
+// it is waiting for; concurrent steps print the whole line once the call returns,
+// so lines of different steps don't interleave.
+type stepPrinter struct {
+	w          io.Writer
+	concurrent bool
+	green, red func(a ...interface{}) string
+
+	mu sync.Mutex
+}
+
+// newStepPrinter returns a stepPrinter writing to w for steps run with the given concurrency
+func newStepPrinter(w io.Writer, concurrency int, green, red func(a ...interface{}) string) *stepPrinter {
+	return &stepPrinter{w: w, concurrent: concurrency > 1, green: green, red: red}
+}
+
+// run calls fn and prints description with its outcome
+func (p *stepPrinter) run(description string, fn func() error) error {
+	if !p.concurrent {
+		fmt.Fprintf(p.w, "  %s... ", description)
+		err := fn()
+		fmt.Fprintln(p.w, p.mark(err))
+		return err
+	}
+
+	err := fn()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.w, "  %s... %s\n", description, p.mark(err))
+	return err
+}
+
+// mark returns ✓ or ✗ for the outcome of a step
+func (p *stepPrinter) mark(err error) string {
+	if err != nil {
+		return p.red("✗")
+	}
+	return p.green("✓")
+}
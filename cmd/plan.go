@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
+	"text/template"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/myzkey/gh-repo-settings/internal/config"
@@ -20,13 +25,43 @@ import (
 )
 
 var (
-	planDir      string
-	planConfig   string
-	checkSecrets bool
-	checkEnv     bool
-	showCurrent  bool
-	syncDelete   bool
-	jsonOutput   bool
+	planDir                []string
+	planConfig             string
+	checkSecrets           bool
+	checkEnv               bool
+	showCurrent            bool
+	showCurrentField       string
+	syncDelete             bool
+	jsonOutput             bool
+	planFailOn             string
+	planStdin              bool
+	planEnvName            string
+	planNoState            bool
+	planOut                string
+	planForceSocialPreview bool
+	planValidateSchema     bool
+	planSummary            bool
+	planFormat             string
+	planTemplateFile       string
+	planTemplate           *template.Template // Parsed from --template-file with --format template
+	planStrict             bool
+	planReportExtra        bool
+	planStrictDrift        bool
+	planNoFetchOptional    bool
+	planQuietSuccess       bool
+	planJSONGrouped        bool
+	planPrintHash          bool
+	planWatch              bool
+	planWatchInterval      time.Duration
+	planWatchChangesOnly   bool
+	planSince              string
+	planBaseline           *diff.Plan // Read from --since
+)
+
+// Exit codes returned by plan when --fail-on matches
+const (
+	exitCodeChanges = 2 // Drift detected (add/update/delete)
+	exitCodeMissing = 3 // Required secrets or variables are missing
 )
 
 var planCmd = &cobra.Command{
@@ -38,13 +73,52 @@ var planCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(planCmd)
-	planCmd.Flags().StringVarP(&planDir, "dir", "d", "", "Config directory")
-	planCmd.Flags().StringVarP(&planConfig, "config", "c", "", "Config file path")
+	planCmd.Flags().StringArrayVarP(&planDir, "dir", "d", nil, "Config directory; repeat to merge several in order, later ones winning (with --config, they override the file)")
+	planCmd.Flags().StringVarP(&planConfig, "config", "c", "", "Config file path, or gh://owner/repo/path@ref")
 	planCmd.Flags().BoolVar(&checkSecrets, "secrets", false, "Check for required secrets")
 	planCmd.Flags().BoolVar(&checkEnv, "env", false, "Check for required environment variables")
 	planCmd.Flags().BoolVar(&showCurrent, "show-current", false, "Show current GitHub settings")
+	planCmd.Flags().StringVar(&showCurrentField, "field", "", "With --show-current, print only this value (e.g. visibility, branch_protection.main.required_reviews)")
+	planCmd.Flags().StringVar(&planFormat, "format", "text", "Output format: text, findings (security-relevant drift as a JSON array), template (with --template-file), or yaml with --show-current for a loadable config")
+	planCmd.Flags().StringVar(&planTemplateFile, "template-file", "", "With --format template, a Go text/template file to render the plan with")
 	planCmd.Flags().BoolVar(&syncDelete, "sync", false, "Show variables/secrets to delete (not in config)")
+	planCmd.Flags().BoolVar(&planReportExtra, "report-extra", false, "With --secrets/--env, list variables/secrets on GitHub that the config doesn't declare (informational, never applied)")
+	planCmd.MarkFlagsMutuallyExclusive("report-extra", "sync")
+	planCmd.Flags().BoolVar(&planStrictDrift, "strict-drift", false, "Report repo and branch protection settings that GitHub has changed from its defaults but the config doesn't mention, and exit 2 on any informational finding")
+	planCmd.Flags().BoolVar(&planNoFetchOptional, "no-fetch-optional", false, "Skip sections that take extra requests (actions.selected_actions, pages and templates) for a faster plan")
 	planCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output plan in JSON format")
+	planCmd.Flags().BoolVar(&planJSONGrouped, "json-grouped", false, "Output plan as JSON with changes grouped under \"categories\" (implies --json)")
+	planCmd.Flags().StringVar(&planEnvName, "environment", "", "Overlay .github/.env.<environment> on .github/.env (default: $ENV)")
+	planCmd.Flags().BoolVar(&planNoState, "no-state", false, "Don't use the local secret hash state file to detect rotated secrets")
+	planCmd.Flags().BoolVar(&planForceSocialPreview, "force-social-preview", false, "Include repo.social_preview_image in the plan (the current image can't be compared)")
+	planCmd.Flags().BoolVar(&planStrict, "strict", false, "Fail when the token may not read a section or the repository was renamed or transferred, instead of warning")
+	planCmd.Flags().BoolVar(&planValidateSchema, "validate-schema", false, "Validate the config against the JSON Schema before planning")
+	planCmd.Flags().BoolVar(&planStdin, "config-stdin", false, "Read YAML config from stdin")
+	planCmd.MarkFlagsMutuallyExclusive("config-stdin", "dir")
+	planCmd.MarkFlagsMutuallyExclusive("config-stdin", "config")
+	planCmd.Flags().BoolVar(&planSummary, "summary", false, "Only print the change totals and per-category counts")
+	planCmd.Flags().BoolVar(&planQuietSuccess, "quiet-success", false, "Print nothing when there are no changes; print the plan as usual when there are")
+	planCmd.MarkFlagsMutuallyExclusive("summary", "json")
+	planCmd.MarkFlagsMutuallyExclusive("format", "json")
+	planCmd.MarkFlagsMutuallyExclusive("field", "json")
+	planCmd.MarkFlagsMutuallyExclusive("summary", "json-grouped")
+	planCmd.MarkFlagsMutuallyExclusive("format", "json-grouped")
+	planCmd.MarkFlagsMutuallyExclusive("field", "json-grouped")
+	planCmd.Flags().BoolVar(&planPrintHash, "print-hash", false, "Print the plan hash after the plan, for apply --require-plan-hash")
+	planCmd.MarkFlagsMutuallyExclusive("print-hash", "json")
+	planCmd.MarkFlagsMutuallyExclusive("print-hash", "json-grouped")
+	planCmd.Flags().StringVar(&planOut, "out", "", "Write the rendered plan to a file instead of stdout")
+	planCmd.Flags().BoolVar(&planWatch, "watch", false, "Recompute the plan every --interval and print a summary of each cycle until interrupted")
+	planCmd.Flags().DurationVar(&planWatchInterval, "interval", defaultWatchInterval, "With --watch, how often to recompute the plan")
+	planCmd.Flags().BoolVar(&planWatchChangesOnly, "changes-only", false, "With --watch, print a cycle only when the plan differs from the previous one")
+	for _, flag := range []string{"json", "json-grouped", "show-current", "print-hash", "out", "quiet-success"} {
+		planCmd.MarkFlagsMutuallyExclusive("watch", flag)
+	}
+	planCmd.Flags().StringVar(&planSince, "since", "", "Compare with a plan saved with --json and print only the drift that appeared or was resolved since; fails only on new drift")
+	for _, flag := range []string{"watch", "show-current", "print-hash", "out", "quiet-success"} {
+		planCmd.MarkFlagsMutuallyExclusive("since", flag)
+	}
+	planCmd.Flags().StringVar(&planFailOn, "fail-on", "delete,missing", "Change types that cause a non-zero exit (add,update,delete,missing or none)")
 }
 
 func runPlan(cmd *cobra.Command, args []string) error {
@@ -63,27 +137,140 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
+	// Grouped JSON and findings are JSON plans in another shape; a template's output
+	// is meant for other tools too, so it is kept free of log lines like JSON
+	if planJSONGrouped || planFormat == "findings" || planFormat == "template" {
+		if planFormat != "text" && (planSummary || planPrintHash) {
+			return fmt.Errorf("--format %s can't be combined with --summary or --print-hash", planFormat)
+		}
+		jsonOutput = true
+	}
+
 	// Suppress all log output in JSON mode
 	if jsonOutput {
 		logger.SetDefaultLevel(logger.LevelQuiet)
 	}
 
 	logger.Debug("Starting plan command")
-	logger.Debug("Config dir: %s, Config file: %s", planDir, planConfig)
+	logger.Debug("Config dir: %s, Config file: %s", strings.Join(planDir, ", "), planConfig)
+
+	failOn, err := parseFailOn(planFailOn)
+	if err != nil {
+		return err
+	}
+	if planStrictDrift {
+		// Undeclared settings are informational findings, which fail the plan only here
+		failOn = append(failOn, diff.ChangeInfo)
+	}
+	if err := validatePlanFormat(planFormat, showCurrent); err != nil {
+		return err
+	}
+	if (planFormat == "template") != (planTemplateFile != "") {
+		return fmt.Errorf("--format template and --template-file must be used together")
+	}
+	if planTemplateFile != "" {
+		// Parse before any API call so a broken template fails fast
+		if planTemplate, err = loadPlanTemplate(planTemplateFile); err != nil {
+			return err
+		}
+	}
+	if planReportExtra && !checkSecrets && !checkEnv {
+		return fmt.Errorf("--report-extra requires --secrets or --env")
+	}
+	if showCurrentField != "" {
+		if !showCurrent {
+			return fmt.Errorf("--field requires --show-current")
+		}
+		if planFormat != "text" {
+			return fmt.Errorf("--field can't be combined with --format %s", planFormat)
+		}
+	}
+
+	if err := checkWatchFlags(); err != nil {
+		return err
+	}
+	if err := checkSinceFlags(); err != nil {
+		return err
+	}
+	if planSince != "" {
+		// Read before any API call so a bad baseline fails fast
+		if planBaseline, err = readSavedPlan(planSince); err != nil {
+			return err
+		}
+	}
+	if err := checkMatchFlags(planOut != "", planStdin); err != nil {
+		return err
+	}
+	if org != "" && repoMatch == "" {
+		return runOrgPlan(ctx, config.LoadOptions{
+			Dir:    planDir,
+			Config: planConfig,
+			Stdin:  configStdin(planStdin),
+
+			ValidateSchema: planValidateSchema,
+			WarnConflicts:  warnConflicts,
+
+			Context:     ctx,
+			FetchGitHub: configSources(),
+		}, failOn)
+	}
 
-	client, err := github.NewClientWithContext(ctx, repo)
+	var code int
+	if repoMatch != "" {
+		code, err = forEachMatchingRepo(ctx, func(ctx context.Context, repoArg string) (int, error) {
+			return planRepo(ctx, repoArg, failOn)
+		})
+	} else {
+		code, err = planRepo(ctx, repo, failOn)
+	}
 	if err != nil {
 		return err
 	}
+	if code != 0 {
+		os.Exit(code)
+	}
+	return nil
+}
+
+// planRepo shows planned changes for repoArg and returns the exit code for --fail-on
+func planRepo(ctx context.Context, repoArg string, failOn []diff.ChangeType) (int, error) {
+	client, err := newRepoClient(ctx, repoArg)
+	if err != nil {
+		return 0, err
+	}
 
 	logger.Debug("Connected to repository: %s/%s", client.RepoOwner(), client.RepoName())
 
+	// Current settings don't depend on the config, so they can be shown without one
+	if showCurrent {
+		switch {
+		case showCurrentField != "":
+			return 0, printCurrentField(ctx, client, os.Stdout, showCurrentField)
+		case jsonOutput:
+			return 0, printCurrentSettingsJSON(ctx, client)
+		case planFormat == "yaml":
+			return 0, printCurrentSettingsYAML(ctx, client, os.Stdout, checkSecrets || checkEnv)
+		}
+		return 0, printCurrentSettings(ctx, client)
+	}
+
+	if err := checkRepoLocation(ctx, client, planStrict); err != nil {
+		return 0, err
+	}
+
 	cfg, err := config.Load(config.LoadOptions{
 		Dir:    planDir,
 		Config: planConfig,
+		Stdin:  configStdin(planStdin),
+
+		ValidateSchema: planValidateSchema,
+		WarnConflicts:  warnConflicts,
+
+		Context:     ctx,
+		FetchGitHub: configSources(),
 	})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	logger.Debug("Loaded configuration")
@@ -100,7 +287,7 @@ func runPlan(cmd *cobra.Command, args []string) error {
 	if cfg.Env != nil && cfg.Env.Provider != nil {
 		// Collect keys to filter (empty means all keys)
 		var keysToLoad []string
-		keysToLoad = append(keysToLoad, cfg.Env.Secrets...)
+		keysToLoad = append(keysToLoad, config.SecretNames(cfg.Env.Secrets)...)
 
 		var err error
 		providerResult, err = config.LoadFromProvider(ctx, cfg.Env.Provider, keysToLoad, configPath)
@@ -112,7 +299,7 @@ func runPlan(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load .env file
-	dotEnvValues, err := config.LoadDotEnv(configPath)
+	dotEnvValues, err := config.LoadDotEnvForEnvironment(configPath, dotEnvEnvironment(planEnvName))
 	if err != nil {
 		logger.Debug("Failed to load .env file: %v", err)
 	}
@@ -127,61 +314,312 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		validateStatusChecks(cfg)
 	}
 
-	// Show current GitHub settings if requested
-	if showCurrent {
-		if jsonOutput {
-			return printCurrentSettingsJSON(ctx, client)
+	announcePlan(nil, "Planning changes for %s/%s...\n", client.RepoOwner(), client.RepoName())
+
+	calculator := diff.NewCalculatorWithEnv(client, cfg, dotEnvValues)
+	opts := diff.CalculateOptions{
+		CheckSecrets:       checkSecrets,
+		CheckEnv:           checkEnv,
+		SyncDelete:         syncDelete,
+		ReportExtra:        planReportExtra,
+		StrictDrift:        planStrictDrift,
+		SecretState:        loadSecretState(configPath, checkSecrets && !planNoState),
+		ForceSocialPreview: planForceSocialPreview,
+		SkipForbidden:      !planStrict,
+		SkipOptional:       planNoFetchOptional,
+	}
+
+	if planWatch {
+		logger.Info("Watching for drift every %s (Ctrl+C to stop)\n", planWatchInterval)
+		ticker := time.NewTicker(planWatchInterval)
+		defer ticker.Stop()
+		watcher := &planWatcher{
+			out:   os.Stdout,
+			ticks: ticker.C,
+			now:   time.Now,
+			calculate: func(ctx context.Context) (*diff.Plan, error) {
+				return calculator.CalculateWithOptions(ctx, opts)
+			},
+			onlyChanges: planWatchChangesOnly,
+			colored:     colorEnabled(os.Stdout),
 		}
-		return printCurrentSettings(ctx, client)
+		return 0, watcher.run(ctx)
 	}
 
-	logger.Info("Planning changes for %s/%s...\n", client.RepoOwner(), client.RepoName())
+	sp := newStderrSpinner(jsonOutput)
+	opts.Progress = sp.Progress
+	plan, err := calculator.CalculateWithOptions(ctx, opts)
+	sp.Stop()
+	if err != nil {
+		return 0, err
+	}
+	warnUnreadable(plan)
+	announcePlan(plan, "Planning changes for %s/%s...\n", client.RepoOwner(), client.RepoName())
 
-	calculator := diff.NewCalculatorWithEnv(client, cfg, dotEnvValues)
-	plan, err := calculator.CalculateWithOptions(ctx, diff.CalculateOptions{
-		CheckSecrets: checkSecrets,
-		CheckEnv:     checkEnv,
-		SyncDelete:   syncDelete,
-	})
+	if planBaseline != nil {
+		renderDriftReport(os.Stdout, plan, planBaseline, colorEnabled(os.Stdout))
+		return sinceExitCode(plan, planBaseline), nil
+	}
+
+	// Saved JSON plans name their repository, so rollback can't be pointed at another one
+	plan.SetRepository(github.FullName(client))
+	if err := outputPlan(plan, "No changes detected. Repository is up to date."); err != nil {
+		return 0, err
+	}
+
+	// Sections that couldn't be read are dropped by apply, so they aren't part of the hash
+	if planPrintHash {
+		fmt.Printf("Plan hash: %s\n", plan.WithoutUnreadable().Hash())
+	}
+
+	return exitCodeFor(plan.Stats(), failOn), nil
+}
+
+// checkWatchFlags rejects --watch combinations that need a single plan
+func checkWatchFlags() error {
+	if !planWatch {
+		if planWatchChangesOnly {
+			return fmt.Errorf("--changes-only requires --watch")
+		}
+		return nil
+	}
+	if planFormat != "text" {
+		return fmt.Errorf("--watch only prints the text summary and can't be combined with --format %s", planFormat)
+	}
+	if planWatchInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	if org != "" || repoMatch != "" {
+		return fmt.Errorf("--watch works on a single repository and can't be combined with --org or --match")
+	}
+	return nil
+}
+
+// parseFailOn parses the --fail-on flag value into change types.
+// "none" disables failing on any change type.
+func parseFailOn(value string) ([]diff.ChangeType, error) {
+	var types []diff.ChangeType
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(strings.ToLower(part))
+		switch part {
+		case "":
+			continue
+		case "none":
+			return nil, nil
+		case "add":
+			types = append(types, diff.ChangeAdd)
+		case "update":
+			types = append(types, diff.ChangeUpdate)
+		case "delete":
+			types = append(types, diff.ChangeDelete)
+		case "missing":
+			types = append(types, diff.ChangeMissing)
+		default:
+			return nil, fmt.Errorf("invalid --fail-on value %q (valid: add, update, delete, missing, none)", part)
+		}
+	}
+	return types, nil
+}
+
+// warnUnreadable warns about each section skipped because the token may not read it
+func warnUnreadable(plan *diff.Plan) {
+	for _, change := range plan.Filter(diff.Change.IsUnreadable).Changes() {
+		logger.Warn("%v; skipping it (use --strict to fail instead)", change.New)
+	}
+}
+
+// validatePlanFormat checks the --format value
+func validatePlanFormat(format string, showCurrent bool) error {
+	switch format {
+	case "text":
+		return nil
+	case "yaml":
+		if !showCurrent {
+			return fmt.Errorf("--format yaml requires --show-current")
+		}
+		return nil
+	case "findings", "template":
+		if showCurrent {
+			return fmt.Errorf("--format %s can't be combined with --show-current", format)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid --format value %q (valid: text, yaml, findings, template)", format)
+}
+
+// loadPlanTemplate reads and parses a --template-file
+func loadPlanTemplate(path string) (*template.Template, error) {
+	text, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to read template: %w", err)
 	}
+	return diff.ParsePlanTemplate(filepath.Base(path), string(text))
+}
 
-	// JSON output mode
-	if jsonOutput {
-		jsonBytes, err := diff.PlanMarshalIndent(plan)
-		if err != nil {
-			return fmt.Errorf("failed to marshal plan to JSON: %w", err)
+// exitCodeFor returns the exit code for a plan given the change types that should fail.
+// Missing secrets/variables take priority (3) over other changes (2); 0 means success.
+func exitCodeFor(stats diff.PlanStats, failOn []diff.ChangeType) int {
+	code := 0
+	for _, changeType := range failOn {
+		if stats.Count(changeType) == 0 {
+			continue
+		}
+		switch changeType {
+		case diff.ChangeMissing:
+			code = exitCodeMissing
+		default:
+			if code < exitCodeChanges {
+				code = exitCodeChanges
+			}
 		}
-		fmt.Println(string(jsonBytes))
+	}
+	return code
+}
+
+// configStdin returns os.Stdin when --config-stdin is set, nil otherwise
+func configStdin(enabled bool) io.Reader {
+	if !enabled {
+		return nil
+	}
+	return os.Stdin
+}
+
+// loadSecretState loads the local secret hash state, or returns nil when disabled or unreadable
+func loadSecretState(configPath string, enabled bool) *config.State {
+	if !enabled {
+		return nil
+	}
+	state, err := config.LoadState(configPath)
+	if err != nil {
+		logger.Warn("Ignoring state file: %v", err)
+		return nil
+	}
+	return state
+}
+
+// dotEnvEnvironment returns the .env overlay name from --environment, falling back to $ENV
+func dotEnvEnvironment(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	return os.Getenv("ENV")
+}
+
+// outputPlan prints the plan to stdout, or writes it to --out when set.
+// upToDate is logged when a text plan has no changes.
+func outputPlan(plan *diff.Plan, upToDate string) error {
+	return outputPlanTo(os.Stdout, plan, upToDate)
+}
 
-		// Exit codes for JSON mode
-		if plan.HasMissingSecrets() || plan.HasMissingVariables() {
-			os.Exit(3)
+// outputPlanTo is outputPlan writing to w instead of stdout.
+// With --quiet-success an empty plan prints nothing; --out is still written.
+func outputPlanTo(w io.Writer, plan *diff.Plan, upToDate string) error {
+	silent := planQuietSuccess && plan.IsEmpty()
+
+	if planOut != "" {
+		data, err := renderPlanOutput(plan, jsonOutput, planSummary)
+		if err != nil {
+			return err
+		}
+		if err := writePlanOutput(planOut, data); err != nil {
+			return err
 		}
-		if plan.HasDeletes() {
-			os.Exit(2)
+		if !silent {
+			logger.Success("Plan written to %s", planOut)
 		}
 		return nil
 	}
 
-	if !plan.HasChanges() {
-		logger.Success("No changes detected. Repository is up to date.")
+	if silent {
 		return nil
 	}
 
-	hasDeletes := printPlan(plan)
+	if jsonOutput {
+		data, err := renderPlanOutput(plan, true, false)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
 
-	// Exit with code 3 if missing secrets/env
-	if plan.HasMissingSecrets() || plan.HasMissingVariables() {
-		os.Exit(3)
+	if planSummary {
+		renderPlanSummary(w, plan, colorEnabled(w))
+		return nil
+	}
+
+	if plan.IsEmpty() {
+		logger.Success(upToDate)
+		return nil
+	}
+	// A plan of only informational findings has nothing to apply
+	_ = renderPlan(w, plan, plan.HasChanges(), colorEnabled(w))
+	return nil
+}
+
+// announcePlan logs the "Planning changes…" banner. Call it with nil before calculating
+// and again with the plan afterwards: normally only the first call logs, while with
+// --quiet-success only the second does, and only if the plan isn't empty.
+func announcePlan(plan *diff.Plan, format string, args ...interface{}) {
+	if !planQuietSuccess {
+		if plan == nil {
+			logger.Info(format, args...)
+		}
+		return
+	}
+	if plan != nil && !plan.IsEmpty() {
+		logger.Info(format, args...)
+	}
+}
+
+// renderPlanOutput renders the plan exactly as it would be printed to stdout, without colors.
+// A text plan without changes renders as a single "No changes detected." line, unless only
+// the summary is requested.
+func renderPlanOutput(plan *diff.Plan, asJSON, summary bool) ([]byte, error) {
+	if planTemplate != nil {
+		// Rendered into a buffer so a failing template prints nothing
+		var buf bytes.Buffer
+		if err := diff.ExecutePlanTemplate(&buf, planTemplate, plan); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	if asJSON {
+		marshal := diff.PlanMarshalIndent
+		switch {
+		case planJSONGrouped:
+			marshal = diff.PlanMarshalIndentGrouped
+		case planFormat == "findings":
+			marshal = diff.PlanMarshalFindings
+		}
+		jsonBytes, err := marshal(plan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal plan to JSON: %w", err)
+		}
+		return append(jsonBytes, '\n'), nil
 	}
 
-	// Exit with code 2 if there are deletes (warning)
-	if hasDeletes {
-		os.Exit(2)
+	var buf bytes.Buffer
+	if summary {
+		renderPlanSummary(&buf, plan, false)
+		return buf.Bytes(), nil
 	}
+	if plan.IsEmpty() {
+		buf.WriteString("No changes detected.\n")
+		return buf.Bytes(), nil
+	}
+	_ = renderPlan(&buf, plan, plan.HasChanges(), false)
+	return buf.Bytes(), nil
+}
 
+// writePlanOutput writes rendered plan output to path, creating parent directories as needed
+func writePlanOutput(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write plan output: %w", err)
+	}
 	return nil
 }
 
@@ -190,75 +628,131 @@ func printPlan(plan *diff.Plan) (hasDeletes bool) {
 }
 
 func printPlanWithOptions(plan *diff.Plan, showApplyHint bool) (hasDeletes bool) {
-	green := color.New(color.FgGreen).SprintFunc()
-	yellow := color.New(color.FgYellow).SprintFunc()
-	red := color.New(color.FgRed).SprintFunc()
-	magenta := color.New(color.FgMagenta).SprintFunc()
-	cyan := color.New(color.FgCyan).SprintFunc()
+	return renderPlan(os.Stdout, plan, showApplyHint, colorEnabled(os.Stdout))
+}
 
-	var adds, updates, deletes, missing int
+// planSprint returns a color formatter for attr that colors only when colored is set
+func planSprint(colored bool) func(attr color.Attribute) func(a ...interface{}) string {
+	return func(attr color.Attribute) func(a ...interface{}) string {
+		c := color.New(attr)
+		if colored {
+			c.EnableColor()
+		} else {
+			c.DisableColor()
+		}
+		return c.SprintFunc()
+	}
+}
+
+// renderPlan writes the human-readable plan to w, using colors only when colored is set
+func renderPlan(w io.Writer, plan *diff.Plan, showApplyHint, colored bool) (hasDeletes bool) {
+	sprint := planSprint(colored)
+	green := sprint(color.FgGreen)
+	yellow := sprint(color.FgYellow)
+	red := sprint(color.FgRed)
+	magenta := sprint(color.FgMagenta)
+	cyan := sprint(color.FgCyan)
+	blue := sprint(color.FgBlue)
 
-	fmt.Println("Planned changes:")
-	fmt.Println()
+	fmt.Fprintln(w, "Planned changes:")
+	fmt.Fprintln(w)
 
 	currentCategory := diff.ChangeCategory("")
 	for _, change := range plan.Changes() {
 		if change.Category != currentCategory {
 			if currentCategory != "" {
-				fmt.Println()
+				fmt.Fprintln(w)
 			}
-			fmt.Printf("%s:\n", cyan(change.Category.String()))
+			fmt.Fprintf(w, "%s:\n", cyan(change.Category.String()))
 			currentCategory = change.Category
 		}
 
 		switch change.Type {
 		case diff.ChangeAdd:
-			fmt.Printf("  %s %s\n", green("+"), change.Key)
+			fmt.Fprintf(w, "  %s %s\n", green("+"), change.Key)
 			if change.New != nil {
-				fmt.Printf("      → %v\n", change.New)
+				fmt.Fprintf(w, "      → %v\n", change.New)
 			}
-			adds++
 		case diff.ChangeUpdate:
-			fmt.Printf("  %s %s\n", yellow("~"), change.Key)
-			fmt.Printf("      %v → %v\n", change.Old, change.New)
-			updates++
+			fmt.Fprintf(w, "  %s %s\n", yellow("~"), change.Key)
+			fmt.Fprintf(w, "      %v → %v\n", change.Old, change.New)
 		case diff.ChangeDelete:
-			fmt.Printf("  %s %s\n", red("-"), change.Key)
+			fmt.Fprintf(w, "  %s %s\n", red("-"), change.Key)
 			if change.Old != nil {
-				fmt.Printf("      ← %v\n", change.Old)
+				fmt.Fprintf(w, "      ← %v\n", change.Old)
 			}
-			deletes++
 		case diff.ChangeMissing:
-			fmt.Printf("  %s %s\n", magenta("!"), change.Key)
+			fmt.Fprintf(w, "  %s %s\n", magenta("!"), change.Key)
 			if change.New != nil {
-				fmt.Printf("      %v\n", change.New)
+				fmt.Fprintf(w, "      %v\n", change.New)
 			}
-			missing++
+		case diff.ChangeInfo:
+			fmt.Fprintf(w, "  %s %s\n", blue("i"), change.Key)
+			if change.New != nil {
+				fmt.Fprintf(w, "      %v\n", change.New)
+			}
+		}
+		if change.IsDisruptive() {
+			fmt.Fprintf(w, "      %s %s\n", red("⚠"), change.Warning)
+		}
+		if change.Reason != "" {
+			fmt.Fprintf(w, "      # reason: %s\n", change.Reason)
 		}
 	}
 
-	fmt.Println()
-	fmt.Printf("Plan: %s to add, %s to change, %s to destroy",
-		green(fmt.Sprintf("%d", adds)),
-		yellow(fmt.Sprintf("%d", updates)),
-		red(fmt.Sprintf("%d", deletes)),
-	)
-	if missing > 0 {
-		fmt.Printf(", %s missing", magenta(fmt.Sprintf("%d", missing)))
-	}
-	fmt.Println(".")
-	fmt.Println()
+	stats := plan.Stats()
 
-	if missing > 0 {
-		fmt.Printf("%s Some required secrets or environment variables are not configured.\n", magenta("Warning:"))
-		fmt.Println()
+	fmt.Fprintln(w)
+	renderPlanStats(w, stats, sprint)
+	fmt.Fprintln(w)
+
+	if plan.HasMissingSecrets() || plan.HasMissingVariables() {
+		fmt.Fprintf(w, "%s Some required secrets or environment variables are not configured.\n", magenta("Warning:"))
+		fmt.Fprintln(w)
+	}
+	if plan.HasUnreadable() {
+		fmt.Fprintf(w, "%s Some settings could not be read with the current token and were not compared.\n", magenta("Warning:"))
+		fmt.Fprintln(w)
+	}
+	if !plan.Disruptive().IsEmpty() {
+		fmt.Fprintf(w, "%s Changes marked %s may be disruptive; apply asks to confirm them.\n", red("Warning:"), red("⚠"))
+		fmt.Fprintln(w)
 	}
 
 	if showApplyHint {
-		fmt.Printf("Run %s to apply these changes.\n", cyan("gh repo-settings apply"))
+		fmt.Fprintf(w, "Run %s to apply these changes.\n", cyan("gh repo-settings apply"))
+	}
+
+	return stats.Delete > 0
+}
+
+// renderPlanSummary writes only the plan totals and the number of changes per category
+func renderPlanSummary(w io.Writer, plan *diff.Plan, colored bool) {
+	sprint := planSprint(colored)
+	cyan := sprint(color.FgCyan)
+
+	renderPlanStats(w, plan.Stats(), sprint)
+
+	counts := plan.CountByCategory()
+	for _, category := range plan.Categories() {
+		fmt.Fprintf(w, "  %s: %d\n", cyan(category.String()), counts[category])
 	}
+}
 
-	return deletes > 0
+// renderPlanStats writes the "Plan: X to add, Y to change, Z to destroy." line
+func renderPlanStats(w io.Writer, stats diff.PlanStats, sprint func(attr color.Attribute) func(a ...interface{}) string) {
+	fmt.Fprintf(w, "Plan: %s to add, %s to change, %s to destroy",
+		sprint(color.FgGreen)(fmt.Sprintf("%d", stats.Add)),
+		sprint(color.FgYellow)(fmt.Sprintf("%d", stats.Update)),
+		sprint(color.FgRed)(fmt.Sprintf("%d", stats.Delete)),
+	)
+	if stats.Missing > 0 {
+		fmt.Fprintf(w, ", %s missing", sprint(color.FgMagenta)(fmt.Sprintf("%d", stats.Missing)))
+	}
+	if stats.Info > 0 {
+		fmt.Fprintf(w, ", %s info", sprint(color.FgBlue)(fmt.Sprintf("%d", stats.Info)))
+	}
+	fmt.Fprintln(w, ".")
 }
 
 func validateStatusChecks(cfg *config.Config) {
@@ -270,7 +764,7 @@ func validateStatusChecks(cfg *config.Config) {
 	var allStatusChecks []string
 	for _, rule := range cfg.BranchProtection {
 		if rule != nil && len(rule.StatusChecks) > 0 {
-			allStatusChecks = append(allStatusChecks, rule.StatusChecks...)
+			allStatusChecks = append(allStatusChecks, config.StatusCheckContexts(rule.StatusChecks)...)
 		}
 	}
 
@@ -297,6 +791,21 @@ func validateStatusChecks(cfg *config.Config) {
 	}
 }
 
+// printCurrentSettingsYAML writes the current settings as a loadable repo-settings config,
+// read the same way as export. Secret names and variables are included only with includeEnv.
+func printCurrentSettingsYAML(ctx context.Context, client github.GitHubClient, w io.Writer, includeEnv bool) error {
+	cfg, err := buildConfigFromRepo(ctx, client, includeEnv)
+	if err != nil {
+		return err
+	}
+	data, err := marshalYAML(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal current settings: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
 func printCurrentSettingsJSON(ctx context.Context, client *github.Client) error {
 	settings := &github.CurrentSettings{}
 
@@ -307,12 +816,16 @@ func printCurrentSettingsJSON(ctx context.Context, client *github.Client) error
 	}
 
 	settings.Repo = &github.CurrentRepoSettings{
-		Visibility:          ptrStringVal(repo.Visibility),
-		AllowMergeCommit:    ptrBoolValDefault(repo.AllowMergeCommit),
-		AllowRebaseMerge:    ptrBoolValDefault(repo.AllowRebaseMerge),
-		AllowSquashMerge:    ptrBoolValDefault(repo.AllowSquashMerge),
-		DeleteBranchOnMerge: ptrBoolValDefault(repo.DeleteBranchOnMerge),
-		AllowUpdateBranch:   ptrBoolValDefault(repo.AllowUpdateBranch),
+		Visibility:               ptrStringVal(repo.Visibility),
+		AllowMergeCommit:         ptrBoolValDefault(repo.AllowMergeCommit),
+		AllowRebaseMerge:         ptrBoolValDefault(repo.AllowRebaseMerge),
+		AllowSquashMerge:         ptrBoolValDefault(repo.AllowSquashMerge),
+		DeleteBranchOnMerge:      ptrBoolValDefault(repo.DeleteBranchOnMerge),
+		AllowUpdateBranch:        ptrBoolValDefault(repo.AllowUpdateBranch),
+		AllowAutoMerge:           ptrBoolValDefault(repo.AllowAutoMerge),
+		UseSquashPRTitle:         ptrBoolValDefault(repo.UseSquashPrTitleAsDefault),
+		WebCommitSignoffRequired: ptrBoolValDefault(repo.WebCommitSignoffRequired),
+		IsTemplate:               ptrBoolValDefault(repo.IsTemplate),
 	}
 	settings.Repo.Description = planNullableStringVal(repo.Description)
 	settings.Repo.Homepage = planNullableStringVal(repo.Homepage)
@@ -458,6 +971,10 @@ func printCurrentSettings(ctx context.Context, client *github.Client) error {
 	fmt.Printf("  allow_squash_merge: %v\n", ptrBoolValDefault(repo.AllowSquashMerge))
 	fmt.Printf("  delete_branch_on_merge: %v\n", ptrBoolValDefault(repo.DeleteBranchOnMerge))
 	fmt.Printf("  allow_update_branch: %v\n", ptrBoolValDefault(repo.AllowUpdateBranch))
+	fmt.Printf("  allow_auto_merge: %v\n", ptrBoolValDefault(repo.AllowAutoMerge))
+	fmt.Printf("  use_squash_pr_title_as_default: %v\n", ptrBoolValDefault(repo.UseSquashPrTitleAsDefault))
+	fmt.Printf("  web_commit_signoff_required: %v\n", ptrBoolValDefault(repo.WebCommitSignoffRequired))
+	fmt.Printf("  is_template: %v\n", ptrBoolValDefault(repo.IsTemplate))
 
 	// Topics
 	if repo.Topics != nil && len(*repo.Topics) > 0 {
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff"
+)
+
+// Outcomes of a planned change in the apply --json summary
+const (
+	outcomeApplied = "applied"
+	outcomeFailed  = "failed"
+	outcomeSkipped = "skipped"
+)
+
+// applyReport records what happened to each planned change during apply --json.
+// A nil report records nothing.
+type applyReport struct {
+	mu       sync.Mutex
+	outcomes map[applyReportKey]applyOutcome
+}
+
+// applyReportKey identifies a planned change
+type applyReportKey struct {
+	category diff.ChangeCategory
+	key      string
+}
+
+// applyOutcome is the result of applying one planned change
+type applyOutcome struct {
+	status string
+	err    error
+}
+
+// newApplyReport creates an empty applyReport
+func newApplyReport() *applyReport {
+	return &applyReport{outcomes: make(map[applyReportKey]applyOutcome)}
+}
+
+// record marks changes as applied, or as failed with err. Changes that were sent
+// together in one request share its outcome.
+func (r *applyReport) record(changes []diff.Change, err error) {
+	if r == nil {
+		return
+	}
+	outcome := applyOutcome{status: outcomeApplied}
+	if err != nil {
+		outcome = applyOutcome{status: outcomeFailed, err: err}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, change := range changes {
+		r.outcomes[applyReportKey{change.Category, change.Key}] = outcome
+	}
+}
+
+// applySummary is the JSON object apply --json prints after applying
+type applySummary struct {
+	Changes []applySummaryChange `json:"changes"`
+	Applied int                  `json:"applied"`
+	Failed  int                  `json:"failed"`
+	Skipped int                  `json:"skipped"`
+}
+
+// applySummaryChange is the outcome of one planned change
+type applySummaryChange struct {
+	Category string `json:"category"`
+	Type     string `json:"type"`
+	Key      string `json:"key"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// summary returns the outcome of each change in plan, in plan order. Changes that
+// weren't attempted, because they were skipped or an earlier change failed, are skipped.
+func (r *applyReport) summary(plan *diff.Plan) applySummary {
+	s := applySummary{Changes: []applySummaryChange{}}
+	for _, change := range plan.Changes() {
+		if change.IsInfo() {
+			continue
+		}
+		outcome := applyOutcome{status: outcomeSkipped}
+		if r != nil {
+			r.mu.Lock()
+			if recorded, ok := r.outcomes[applyReportKey{change.Category, change.Key}]; ok {
+				outcome = recorded
+			}
+			r.mu.Unlock()
+		}
+
+		entry := applySummaryChange{
+			Category: change.Category.String(),
+			Type:     change.Type.String(),
+			Key:      change.Key,
+			Status:   outcome.status,
+		}
+		switch outcome.status {
+		case outcomeApplied:
+			s.Applied++
+		case outcomeFailed:
+			s.Failed++
+			entry.Error = outcome.err.Error()
+		default:
+			s.Skipped++
+		}
+		s.Changes = append(s.Changes, entry)
+	}
+	return s
+}
+
+// writeApplySummary prints the outcomes of plan as indented JSON
+func writeApplySummary(w io.Writer, report *applyReport, plan *diff.Plan) error {
+	data, err := json.MarshalIndent(report.summary(plan), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode apply summary: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/diff"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <plan.json>",
+	Short: "Undo the changes of a saved plan",
+	Long: `Apply the inverse of a plan saved with plan --json --out: updated settings get their
+old values back, created labels, variables and secrets are deleted, and deleted labels and
+variables are recreated from the values the plan recorded.
+
+Some changes can't be undone and are skipped with a warning: secret values (GitHub never
+returns them), branch protection that the plan added, and Pages, template, social preview
+and organization changes. Pass - to read the plan from stdin (requires --yes).
+
+The plan must be for the target repository: it records the repository it was calculated for.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRollback,
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+	rollbackCmd.Flags().BoolVarP(&autoApprove, "yes", "y", false, "Auto-approve changes")
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if args[0] == "-" && !autoApprove {
+		return fmt.Errorf("reading the plan from stdin requires --yes")
+	}
+	saved, err := readSavedPlan(args[0])
+	if err != nil {
+		return err
+	}
+
+	client, err := newRepoClient(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := checkRollbackRepository(client, saved); err != nil {
+		return err
+	}
+
+	inverse, cfg, skipped, err := rollbackPlan(ctx, client, saved)
+	if err != nil {
+		return err
+	}
+	for _, skip := range skipped {
+		logger.Warn("Can't undo %s: %s", skip.change, skip.reason)
+	}
+	if !inverse.HasChanges() {
+		logger.Success("Nothing to roll back.")
+		return nil
+	}
+
+	logger.Info("Rolling back %s/%s...\n", client.RepoOwner(), client.RepoName())
+	_ = printPlanWithOptions(inverse, false)
+
+	if !autoApprove {
+		ok, err := askYesNo("Do you want to roll back these changes?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			logger.Info("Rollback cancelled.")
+			return nil
+		}
+	}
+
+	fmt.Println()
+	logger.Info("Applying changes...")
+	fmt.Println()
+
+	if err := applyChanges(ctx, client, cfg, inverse, nil, nil, nil); err != nil {
+		return err
+	}
+	fmt.Println()
+	logger.Success("Rollback complete!")
+	return nil
+}
+
+// checkRollbackRepository returns an error unless saved was planned for the client's
+// repository. Plans that don't name their repository are refused too: undoing them
+// against the wrong repository would overwrite its settings.
+func checkRollbackRepository(client github.GitHubClient, saved *diff.Plan) error {
+	target := github.FullName(client)
+	switch planned := saved.Repository(); {
+	case planned == "":
+		return fmt.Errorf("the plan doesn't name its repository; save it again with plan --json --out")
+	case !strings.EqualFold(planned, target):
+		return fmt.Errorf("the plan is for %s, not %s; pass --repo %s to roll it back", planned, target, planned)
+	}
+	return nil
+}
+
+// rollbackSkip is a change of a saved plan that rollback can't undo
+type rollbackSkip struct {
+	change diff.Change
+	reason string
+}
+
+// Reasons a change can't be undone
+const (
+	skipSecretValue   = "secret values can't be read from GitHub, so the previous value can't be restored"
+	skipProtectionAdd = "branch protection can't be removed"
+	skipUnsupported   = "rollback doesn't support this change"
+	skipUnknownValue  = "the plan doesn't record the previous value"
+)
+
+// rollbackPlan inverts saved and returns the inverse plan, the config its changes are applied
+// from, and the changes of saved that can't be undone. apply reads most values from the config
+// rather than the plan, so the config holds the old values, on top of the current settings for
+// sections that are sent whole (branch protection rules and Actions permissions).
+func rollbackPlan(ctx context.Context, client github.GitHubClient, saved *diff.Plan) (*diff.Plan, *config.Config, []rollbackSkip, error) {
+	cfg := &config.Config{}
+	rules := make(map[string]*config.BranchRule)
+	var skipped []rollbackSkip
+
+	originals := saved.Changes()
+	inverted := saved.Invert()
+	keep := make([]bool, len(originals))
+	for i, change := range inverted.Changes() {
+		if change.IsInfo() || change.Type == diff.ChangeMissing {
+			continue
+		}
+		reason, err := addRollbackValue(ctx, client, cfg, rules, change)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if reason != "" {
+			skipped = append(skipped, rollbackSkip{change: originals[i], reason: reason})
+			continue
+		}
+		keep[i] = true
+	}
+
+	if len(rules) > 0 {
+		cfg.BranchProtection = rules
+	}
+
+	// Filter visits changes in order, so the position identifies each change
+	i := 0
+	inverse := inverted.Filter(func(diff.Change) bool {
+		ok := keep[i]
+		i++
+		return ok
+	})
+	return inverse, cfg, skipped, nil
+}
+
+// addRollbackValue records the value an inverted change restores in cfg. If the change
+// can't be applied, it returns the reason instead.
+func addRollbackValue(ctx context.Context, client github.GitHubClient, cfg *config.Config, rules map[string]*config.BranchRule, change diff.Change) (string, error) {
+	switch change.Category {
+	case diff.CategoryRepo:
+		// apply sends the repository settings straight from the plan
+		if change.Type != diff.ChangeUpdate {
+			return skipUnsupported, nil
+		}
+		return "", nil
+
+	case diff.CategoryTopics:
+		topics, ok := stringList(change.New)
+		if !ok {
+			return skipUnknownValue, nil
+		}
+		cfg.Topics = topics
+		return "", nil
+
+	case diff.CategoryLabels:
+		if change.Type == diff.ChangeDelete {
+			return "", nil
+		}
+		label, ok := parseLabelValue(change.Key, change.New)
+		if !ok {
+			return skipUnknownValue, nil
+		}
+		if cfg.Labels == nil {
+			cfg.Labels = &config.LabelsConfig{}
+		}
+		cfg.Labels.Items = append(cfg.Labels.Items, label)
+		return "", nil
+
+	case diff.CategoryBranchProtection:
+		if change.Type == diff.ChangeDelete {
+			return skipProtectionAdd, nil
+		}
+		if change.Type != diff.ChangeUpdate || change.Field == "" {
+			return skipUnsupported, nil
+		}
+		rule, err := currentBranchRule(ctx, client, rules, change.Branch)
+		if err != nil {
+			return "", err
+		}
+		value := change.New
+		if change.Field == "status_checks" {
+			value = statusCheckValues(change.New)
+		}
+		return "", patchConfigKey(rule, change.Field, value)
+
+	case diff.CategoryActions:
+		if change.Type != diff.ChangeUpdate {
+			return skipUnsupported, nil
+		}
+		if cfg.Actions == nil {
+			importActions(ctx, client, cfg)
+			if cfg.Actions == nil {
+				return skipUnsupported, nil
+			}
+		}
+		key := change.Key
+		if key == "github_owned_allowed" || key == "verified_allowed" || key == "patterns_allowed" {
+			key = "selected_actions." + key
+		}
+		return "", patchConfigKey(cfg.Actions, key, change.New)
+
+	case diff.CategoryVariables:
+		if change.Type == diff.ChangeDelete {
+			return "", nil
+		}
+		if cfg.Env == nil {
+			cfg.Env = &config.EnvConfig{}
+		}
+		if cfg.Env.Variables == nil {
+			cfg.Env.Variables = make(map[string]string)
+		}
+		cfg.Env.Variables[change.Key] = fmt.Sprint(change.New)
+		return "", nil
+
+	case diff.CategorySecrets:
+		// Deleting a secret the plan created is the only undo that needs no value
+		if change.Type != diff.ChangeDelete {
+			return skipSecretValue, nil
+		}
+		return "", nil
+	}
+	return skipUnsupported, nil
+}
+
+// currentBranchRule returns the current protection rule of branch, reading it once
+func currentBranchRule(ctx context.Context, client github.GitHubClient, rules map[string]*config.BranchRule, branch string) (*config.BranchRule, error) {
+	if rule, ok := rules[branch]; ok {
+		return rule, nil
+	}
+	protection, err := client.GetBranchProtection(ctx, branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read branch protection for %s: %w", branch, err)
+	}
+	rule := branchRuleFromProtection(protection)
+	rules[branch] = rule
+	return rule, nil
+}
+
+// patchConfigKey sets a dotted key in a config section by its YAML names.
+// It is the write side of lookupConfigKey.
+func patchConfigKey(section interface{}, key string, value interface{}) error {
+	data, err := yaml.Marshal(section)
+	if err != nil {
+		return err
+	}
+	fields := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	parts := strings.Split(key, ".")
+	m := fields
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[part] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+
+	if data, err = yaml.Marshal(fields); err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, section); err != nil {
+		return fmt.Errorf("can't restore %s to %v: %w", key, value, err)
+	}
+	return nil
+}
+
+// stringList converts a list value from a plan, which is []interface{} when read from JSON
+func stringList(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case []string:
+		return append([]string{}, v...), true
+	case []interface{}:
+		list := make([]string, len(v))
+		for i, item := range v {
+			list[i] = fmt.Sprint(item)
+		}
+		return list, true
+	}
+	return nil, false
+}
+
+// parseLabelValue reads back a label value written as "color=<color>, description=<description>"
+func parseLabelValue(name string, value interface{}) (config.Label, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return config.Label{}, false
+	}
+	color, description, ok := strings.Cut(strings.TrimPrefix(s, "color="), ", description=")
+	if !ok {
+		return config.Label{}, false
+	}
+	return config.Label{Name: name, Color: color, Description: &description}, true
+}
+
+// statusCheckValues converts status checks listed as "context (app <id>)" back to
+// their config form
+func statusCheckValues(value interface{}) interface{} {
+	list, ok := stringList(value)
+	if !ok {
+		return value
+	}
+	checks := make([]interface{}, len(list))
+	for i, item := range list {
+		checks[i] = item
+		context, app, found := strings.Cut(item, " (app ")
+		if !found || !strings.HasSuffix(app, ")") {
+			continue
+		}
+		if id, err := strconv.Atoi(strings.TrimSuffix(app, ")")); err == nil {
+			checks[i] = map[string]interface{}{"context": context, "app_id": id}
+		}
+	}
+	return checks
+}
@@ -1,16 +1,31 @@
 package cmd
 
 import (
+	"context"
 	"os"
+	"time"
 
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
 	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
-	verbose bool
-	quiet   bool
-	repo    string
+	verbose   bool
+	quiet     bool
+	logFormat string
+	repo      string
+	org       string
+
+	repoMatch       string
+	includeArchived bool
+
+	warnConflicts bool
+
+	cacheDir string
+	noCache  bool
+	timeout  time.Duration
 
 	// Version is set by main.go from version.go
 	Version = "dev"
@@ -20,7 +35,7 @@ var rootCmd = &cobra.Command{
 	Use:   "gh-repo-settings",
 	Short: "Manage GitHub repository settings via YAML configuration",
 	Long:  `A GitHub CLI extension to manage repository settings via YAML configuration. Inspired by Terraform's workflow.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Set log level based on flags
 		if quiet {
 			logger.SetDefaultLevel(logger.LevelQuiet)
@@ -29,6 +44,14 @@ var rootCmd = &cobra.Command{
 		} else {
 			logger.SetDefaultLevel(logger.LevelNormal)
 		}
+
+		format, err := logger.ParseFormat(logFormat)
+		if err != nil {
+			return err
+		}
+		logger.SetDefaultFormat(format)
+		setupColor()
+		return nil
 	},
 }
 
@@ -46,5 +69,57 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show debug output")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only show errors")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format: text or json (one JSON object per line)")
 	rootCmd.PersistentFlags().StringVarP(&repo, "repo", "r", "", "Target repository (default: current repo)")
+	rootCmd.PersistentFlags().StringVar(&org, "org", "", "Target organization for org-level settings (plan/apply only)")
+	rootCmd.PersistentFlags().StringVar(&repoMatch, "match", "", "With --org, target every repository whose name matches this glob, e.g. 'svc-*' (plan/apply only)")
+	rootCmd.PersistentFlags().BoolVar(&includeArchived, "include-archived", false, "Include archived repositories in --match")
+	rootCmd.PersistentFlags().BoolVar(&warnConflicts, "warn-conflicts", false, "Warn when a later extends source overrides a setting an earlier one sets to a different value")
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "Directory for cached API responses (default: user cache dir)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Disable ETag-based caching of API responses")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 30*time.Second, "Maximum time for each GitHub API call (0 disables the limit, alias: --api-timeout)")
+	rootCmd.SetGlobalNormalizationFunc(flagAliases)
+}
+
+// flagAliases maps alternative flag names to the flags they stand for
+func flagAliases(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	switch name {
+	case "api-timeout":
+		name = "timeout"
+	}
+	return pflag.NormalizedName(name)
+}
+
+// newRepoClient creates a client for repoArg using the response cache
+// configured by --cache-dir and --no-cache and the --timeout per call
+func newRepoClient(ctx context.Context, repoArg string) (*github.Client, error) {
+	client, err := github.NewClientWithContext(ctx, repoArg)
+	if err != nil {
+		return nil, err
+	}
+	client.SetResponseCache(responseCache())
+	client.SetTimeout(timeout)
+	return client, nil
+}
+
+// configSources returns the LoadOptions.FetchGitHub that reads gh:// config sources
+// with the --timeout per call
+func configSources() func(ctx context.Context, endpoint string) ([]byte, error) {
+	return github.ContentsFetcher(timeout)
+}
+
+// responseCache returns the configured response cache, or nil when caching is disabled
+func responseCache() *github.ResponseCache {
+	if noCache {
+		return nil
+	}
+	dir := cacheDir
+	if dir == "" {
+		var err error
+		if dir, err = github.DefaultCacheDir(); err != nil {
+			logger.Debug("Response cache disabled: %v", err)
+			return nil
+		}
+	}
+	return github.NewResponseCache(dir)
 }
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
+	"github.com/spf13/cobra"
+)
+
+var schemaOutput string
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for the configuration",
+	Long:  `Print the JSON Schema for the YAML configuration, for editor integration or to diff in CI.`,
+	RunE:  runSchema,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.Flags().StringVarP(&schemaOutput, "output", "o", "", "Write the schema to a file instead of stdout")
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	if schemaOutput != "" {
+		if err := writeSchema(schemaOutput); err != nil {
+			return err
+		}
+		logger.Success("Schema written to %s", schemaOutput)
+		return nil
+	}
+	return printSchema(os.Stdout)
+}
+
+// printSchema writes the generated JSON Schema to w
+func printSchema(w io.Writer) error {
+	data, err := config.GenerateSchema()
+	if err != nil {
+		return fmt.Errorf("failed to generate schema: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// writeSchema writes the generated JSON Schema to path
+func writeSchema(path string) error {
+	data, err := config.GenerateSchema()
+	if err != nil {
+		return fmt.Errorf("failed to generate schema: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	return nil
+}
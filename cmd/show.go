@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff"
+	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <plan.json>",
+	Short: "Print a saved JSON plan",
+	Long: `Print a plan saved with plan --json (or --json-grouped) the same way plan prints it,
+without contacting GitHub. Pass - to read the plan from stdin.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runShow,
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	plan, err := readSavedPlan(args[0])
+	if err != nil {
+		return err
+	}
+	return showPlan(os.Stdout, plan)
+}
+
+// readSavedPlan reads a JSON plan from path, or from stdin if path is "-"
+func readSavedPlan(path string) (*diff.Plan, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	plan, err := diff.UnmarshalPlan(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w (save plans with plan --json --out)", path, err)
+	}
+	return plan, nil
+}
+
+// showPlan renders a saved plan like plan does, without the apply hint
+func showPlan(w io.Writer, plan *diff.Plan) error {
+	if plan.IsEmpty() {
+		logger.Success("No changes. The saved plan is empty.")
+		return nil
+	}
+	_ = renderPlan(w, plan, false, colorEnabled(w))
+	return nil
+}
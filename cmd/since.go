@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+	"github.com/myzkey/gh-repo-settings/internal/diff"
+)
+
+// checkSinceFlags rejects --since combinations that print the plan itself
+func checkSinceFlags() error {
+	if planSince == "" {
+		return nil
+	}
+	if planFormat != "text" || jsonOutput || planJSONGrouped || planSummary {
+		return fmt.Errorf("--since prints a text drift report and can't be combined with --json, --json-grouped, --summary or --format %s", planFormat)
+	}
+	if org != "" || repoMatch != "" {
+		return fmt.Errorf("--since works on a single repository and can't be combined with --org or --match")
+	}
+	return nil
+}
+
+// renderDriftReport writes the drift that appeared since a baseline plan and the drift
+// that was resolved. A change whose value moved on is listed in both sections.
+func renderDriftReport(w io.Writer, plan, baseline *diff.Plan, colored bool) {
+	appeared, resolved := plan.Diff(baseline)
+	sprint := planSprint(colored)
+
+	if appeared.IsEmpty() && resolved.IsEmpty() {
+		fmt.Fprintln(w, "No drift since the baseline.")
+		return
+	}
+
+	renderDriftSection(w, sprint(color.FgYellow)("New drift"), appeared)
+	renderDriftSection(w, sprint(color.FgGreen)("Resolved drift"), resolved)
+}
+
+func renderDriftSection(w io.Writer, title string, plan *diff.Plan) {
+	fmt.Fprintf(w, "%s (%d):\n", title, plan.Size())
+	if plan.IsEmpty() {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, change := range plan.Changes() {
+		fmt.Fprintf(w, "  %s\n", change)
+	}
+}
+
+// sinceExitCode fails only when drift appeared since the baseline
+func sinceExitCode(plan, baseline *diff.Plan) int {
+	appeared, _ := plan.Diff(baseline)
+	if appeared.HasChanges() {
+		return exitCodeChanges
+	}
+	return 0
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const spinnerInterval = 100 * time.Millisecond
+
+// spinner shows the current operation on a single, continuously redrawn line.
+// A disabled spinner ignores every call, so callers don't need to check.
+type spinner struct {
+	w       io.Writer
+	enabled bool
+
+	mu      sync.Mutex
+	message string
+	frame   int
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// newSpinner creates a spinner writing to w
+func newSpinner(w io.Writer, enabled bool) *spinner {
+	return &spinner{w: w, enabled: enabled}
+}
+
+// newStderrSpinner creates a spinner on stderr, so stdout stays clean for piping.
+// It is disabled for JSON output, in quiet mode and when stderr is not a terminal.
+func newStderrSpinner(jsonMode bool) *spinner {
+	return newSpinner(os.Stderr, spinnerEnabled(os.Stderr, jsonMode))
+}
+
+// spinnerEnabled reports whether a spinner should be drawn on f
+func spinnerEnabled(f *os.File, jsonMode bool) bool {
+	if jsonMode || logger.DefaultLevel() < logger.LevelNormal {
+		return false
+	}
+	return isTerminal(f)
+}
+
+// Update shows message as the current operation, starting the spinner if needed
+func (s *spinner) Update(message string) {
+	if !s.enabled {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.message = message
+	s.draw()
+
+	if s.stop == nil {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.run(s.stop, s.done)
+	}
+}
+
+// Progress is a diff.CalculateOptions.Progress callback
+func (s *spinner) Progress(step string) {
+	s.Update(fmt.Sprintf("Fetching %s…", step))
+}
+
+// Stop stops the spinner and clears its line. It is safe to call more than once.
+func (s *spinner) Stop() {
+	if !s.enabled {
+		return
+	}
+
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = fmt.Fprint(s.w, "\r\033[K")
+}
+
+func (s *spinner) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			s.frame = (s.frame + 1) % len(spinnerFrames)
+			s.draw()
+			s.mu.Unlock()
+		}
+	}
+}
+
+// draw redraws the spinner line; the caller must hold s.mu
+func (s *spinner) draw() {
+	_, _ = fmt.Fprintf(s.w, "\r\033[K%s %s", spinnerFrames[s.frame], s.message)
+}
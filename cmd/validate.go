@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateDir    []string
+	validateConfig string
+	validateStdin  bool
+	validateSchema bool
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration without contacting GitHub",
+	Long:  `Load the local YAML configuration, resolve extends and check it against the JSON Schema and the built-in validation rules.`,
+	RunE:  runValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringArrayVarP(&validateDir, "dir", "d", nil, "Config directory; repeat to merge several in order, later ones winning (with --config, they override the file)")
+	validateCmd.Flags().StringVarP(&validateConfig, "config", "c", "", "Config file path, or gh://owner/repo/path@ref")
+	validateCmd.Flags().BoolVar(&validateSchema, "schema", true, "Validate the config against the JSON Schema (--schema=false to skip)")
+	validateCmd.Flags().BoolVar(&validateStdin, "config-stdin", false, "Read YAML config from stdin")
+	validateCmd.MarkFlagsMutuallyExclusive("config-stdin", "dir")
+	validateCmd.MarkFlagsMutuallyExclusive("config-stdin", "config")
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	logger.Debug("Starting validate command")
+
+	if _, err := config.Load(config.LoadOptions{
+		Dir:    validateDir,
+		Config: validateConfig,
+		Stdin:  configStdin(validateStdin),
+
+		ValidateSchema: validateSchema,
+		WarnConflicts:  warnConflicts,
+
+		Context:     cmd.Context(),
+		FetchGitHub: configSources(),
+	}); err != nil {
+		return err
+	}
+
+	logger.Success("Configuration is valid")
+	return nil
+}
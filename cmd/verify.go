@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
+)
+
+// changeVerifier re-reads current state right before a mutation (--verify)
+// and rejects planned changes whose recorded Old value no longer matches GitHub.
+// A nil verifier accepts every change.
+type changeVerifier struct {
+	calculator *diff.Calculator
+	opts       diff.CalculateOptions
+	skip       bool // Drop conflicting changes with a warning instead of aborting
+}
+
+// newChangeVerifier creates a verifier that recalculates with the same options as the plan
+func newChangeVerifier(calculator *diff.Calculator, opts diff.CalculateOptions, skip bool) *changeVerifier {
+	return &changeVerifier{
+		calculator: calculator,
+		opts:       opts,
+		skip:       skip,
+	}
+}
+
+// filter returns the changes that still match current state.
+// On a conflict it returns an error wrapping ErrPlanConflict, or drops the
+// change with a warning when skipping is enabled.
+func (v *changeVerifier) filter(ctx context.Context, changes []diff.Change) ([]diff.Change, error) {
+	if v == nil || len(changes) == 0 {
+		return changes, nil
+	}
+
+	fresh := make(map[diff.ChangeCategory]*diff.Plan)
+	var kept []diff.Change
+	for _, change := range changes {
+		current, ok := fresh[change.Category]
+		if !ok {
+			var err error
+			current, err = v.calculator.CalculateCategory(ctx, change.Category, v.opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to verify %s: %w", change.Category, err)
+			}
+			fresh[change.Category] = current
+		}
+
+		if err := verifyChange(current, change); err != nil {
+			if !v.skip {
+				return nil, err
+			}
+			logger.Warn("Skipping %v", err)
+			continue
+		}
+		kept = append(kept, change)
+	}
+	return kept, nil
+}
+
+// verifyChange checks that a freshly calculated plan still contains the planned change
+// with the same type and Old value
+func verifyChange(current *diff.Plan, planned diff.Change) error {
+	for _, c := range current.Changes() {
+		if c.Key != planned.Key {
+			continue
+		}
+		if c.Type == planned.Type && reflect.DeepEqual(c.Old, planned.Old) {
+			return nil
+		}
+		return fmt.Errorf("%w: %s %s: planned from %v, now %v",
+			apperrors.ErrPlanConflict, planned.Category, planned.Key, describeOld(planned), describeOld(c))
+	}
+	return fmt.Errorf("%w: %s %s: no longer differs from the configuration",
+		apperrors.ErrPlanConflict, planned.Category, planned.Key)
+}
+
+// describeOld formats a change's Old value for conflict messages
+func describeOld(c diff.Change) string {
+	if c.Old == nil {
+		return "(absent)"
+	}
+	return fmt.Sprintf("%v", c.Old)
+}
+
+// verifyConverged recalculates the plan after an apply (--verify-after) and fails
+// if anything still differs, printing the residual changes to w. The social preview
+// can't be compared, so it is never reported as residual.
+func verifyConverged(ctx context.Context, calculator *diff.Calculator, opts diff.CalculateOptions, w io.Writer) error {
+	opts.ForceSocialPreview = false
+
+	residual, err := calculator.CalculateWithOptions(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to re-plan after apply: %w", err)
+	}
+	// Sections that couldn't be read were skipped, and were warned about before applying
+	residual = residual.WithoutUnreadable()
+	if residual.IsClean() {
+		logger.Success("Verified: repository matches the configuration")
+		return nil
+	}
+
+	logger.Warn("Some settings still differ after apply:")
+	_ = renderPlan(w, residual, false, colorEnabled(w))
+	return fmt.Errorf("%w: %d change(s) remain", apperrors.ErrNotConverged, residual.Size())
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff"
+)
+
+// defaultWatchInterval is how often plan --watch recomputes the plan
+const defaultWatchInterval = 5 * time.Minute
+
+// planWatcher recomputes a plan on every tick and prints a summary of each cycle
+type planWatcher struct {
+	out       io.Writer
+	ticks     <-chan time.Time
+	now       func() time.Time
+	calculate func(ctx context.Context) (*diff.Plan, error)
+
+	// onlyChanges skips cycles whose plan is the same as the previous one
+	onlyChanges bool
+	colored     bool
+}
+
+// run calculates a plan right away and again on every tick until ctx is done.
+// A failing cycle is reported and retried on the next tick, so a transient API error
+// doesn't end the watch. Cancelling ctx is the normal way to stop, so it isn't an error.
+func (w *planWatcher) run(ctx context.Context) error {
+	var previous *diff.Plan
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		plan, err := w.calculate(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			fmt.Fprintf(w.out, "[%s] Plan failed: %v\n", w.timestamp(), err)
+		default:
+			w.report(plan, previous)
+			previous = plan
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.ticks:
+		}
+	}
+}
+
+// report prints the summary of plan, followed by the changes that appeared or were
+// resolved since the previous cycle
+func (w *planWatcher) report(plan, previous *diff.Plan) {
+	var appeared, resolved *diff.Plan
+	if previous != nil {
+		appeared, resolved = plan.Diff(previous)
+		if w.onlyChanges && appeared.IsEmpty() && resolved.IsEmpty() {
+			return
+		}
+	}
+
+	fmt.Fprintf(w.out, "[%s] ", w.timestamp())
+	renderPlanSummary(w.out, plan, w.colored)
+	if previous == nil {
+		return
+	}
+	for _, change := range appeared.Changes() {
+		fmt.Fprintf(w.out, "  new: %s\n", change)
+	}
+	for _, change := range resolved.Changes() {
+		fmt.Fprintf(w.out, "  resolved: %s\n", change)
+	}
+}
+
+func (w *planWatcher) timestamp() string {
+	return w.now().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
+)
+
+// defaultWorkflowPath is where init --with-workflow writes the workflow
+const defaultWorkflowPath = ".github/workflows/repo-settings.yml"
+
+// workflowTemplate plans config changes on pull requests and applies them on main.
+// __CONFIG_FLAG__ and __CONFIG_PATHS__ are replaced with the generated config's location.
+const workflowTemplate = `# Generated by gh repo-settings init --with-workflow.
+# Add a REPO_SETTINGS_TOKEN secret with admin access to this repository:
+# the default GITHUB_TOKEN can't read or change most repository settings.
+name: Repo Settings
+
+on:
+  pull_request:
+    paths:
+__CONFIG_PATHS__
+  push:
+    branches: [main]
+    paths:
+__CONFIG_PATHS__
+
+permissions:
+  contents: read
+
+jobs:
+  plan:
+    if: github.event_name == 'pull_request'
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+
+      - name: Install gh-repo-settings
+        run: gh extension install myzkey/gh-repo-settings
+        env:
+          GH_TOKEN: ${{ secrets.GITHUB_TOKEN }}
+
+      - name: Plan
+        run: gh repo-settings plan __CONFIG_FLAG__
+        env:
+          GH_TOKEN: ${{ secrets.REPO_SETTINGS_TOKEN }}
+
+  apply:
+    if: github.event_name == 'push'
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+
+      - name: Install gh-repo-settings
+        run: gh extension install myzkey/gh-repo-settings
+        env:
+          GH_TOKEN: ${{ secrets.GITHUB_TOKEN }}
+
+      - name: Apply
+        run: gh repo-settings apply -y __CONFIG_FLAG__
+        env:
+          GH_TOKEN: ${{ secrets.REPO_SETTINGS_TOKEN }}
+`
+
+// renderWorkflow returns the workflow for a config at configPath,
+// which is a directory config when isDir is set
+func renderWorkflow(configPath string, isDir bool) []byte {
+	configPath = filepath.ToSlash(filepath.Clean(configPath))
+
+	flag := "-c " + configPath
+	pattern := configPath
+	if isDir {
+		flag = "-d " + configPath
+		pattern = configPath + "/**"
+	}
+
+	var paths strings.Builder
+	for _, p := range []string{pattern, defaultWorkflowPath} {
+		fmt.Fprintf(&paths, "      - %q\n", p)
+	}
+
+	return []byte(strings.NewReplacer(
+		"__CONFIG_FLAG__", flag,
+		"__CONFIG_PATHS__\n", paths.String(),
+	).Replace(workflowTemplate))
+}
+
+// writeWorkflow writes the workflow for configPath to path.
+// An existing workflow is left alone with a warning.
+func writeWorkflow(path, configPath string, isDir bool) error {
+	if _, err := os.Stat(path); err == nil {
+		logger.Warn("%s already exists; not overwriting it", path)
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, renderWorkflow(configPath, isDir), 0o644); err != nil {
+		return fmt.Errorf("failed to write workflow: %w", err)
+	}
+
+	fmt.Printf("✓ Workflow written to %s\n", path)
+	return nil
+}
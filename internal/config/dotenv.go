@@ -20,9 +20,37 @@ type DotEnvValues struct {
 // LoadDotEnv loads and parses the .github/.env file
 // Returns empty DotEnvValues if file doesn't exist (not an error)
 func LoadDotEnv(configPath string) (*DotEnvValues, error) {
-	// Determine .env path based on config path
-	envPath := resolveDotEnvPath(configPath)
+	return parseDotEnvFile(resolveDotEnvPath(configPath))
+}
+
+// LoadDotEnvForEnvironment loads .github/.env and then overlays
+// .github/.env.<environment>, whose values take precedence.
+// An empty environment behaves like LoadDotEnv, and a missing overlay is not an error.
+func LoadDotEnvForEnvironment(configPath, environment string) (*DotEnvValues, error) {
+	basePath := resolveDotEnvPath(configPath)
+
+	base, err := parseDotEnvFile(basePath)
+	if err != nil {
+		return nil, err
+	}
+	if environment == "" {
+		return base, nil
+	}
+
+	overlay, err := parseDotEnvFile(basePath + "." + environment)
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge keeps existing values, so merging the base into the overlay
+	// lets the environment-specific file win
+	overlay.Merge(base)
+	return overlay, nil
+}
 
+// parseDotEnvFile parses a single .env file
+// Returns empty DotEnvValues if file doesn't exist (not an error)
+func parseDotEnvFile(envPath string) (*DotEnvValues, error) {
 	values := &DotEnvValues{
 		Values: make(map[string]string),
 	}
@@ -37,6 +65,7 @@ func LoadDotEnv(configPath string) (*DotEnvValues, error) {
 	}
 	defer func() { _ = file.Close() }()
 
+	name := filepath.Base(envPath)
 	scanner := bufio.NewScanner(file)
 	lineNum := 0
 	for scanner.Scan() {
@@ -51,7 +80,7 @@ func LoadDotEnv(configPath string) (*DotEnvValues, error) {
 		// Parse KEY=VALUE
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) != 2 {
-			logger.Warn(".env:%d: skipping malformed line (missing '='): %s", lineNum, line)
+			logger.Warn("%s:%d: skipping malformed line (missing '='): %s", name, lineNum, line)
 			continue
 		}
 
@@ -73,6 +102,11 @@ func LoadDotEnv(configPath string) (*DotEnvValues, error) {
 
 // resolveDotEnvPath determines the .env file path based on config path
 func resolveDotEnvPath(configPath string) string {
+	// A config read from another repository has no .env next to it
+	if isGitHubRef(configPath) {
+		return filepath.Join(".github", ".env")
+	}
+
 	// If configPath is a directory, look for .env in that directory
 	info, err := os.Stat(configPath)
 	if err == nil && info.IsDir() {
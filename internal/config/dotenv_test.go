@@ -135,6 +135,74 @@ func TestLoadDotEnvNonExistent(t *testing.T) {
 	}
 }
 
+func TestLoadDotEnvForEnvironment(t *testing.T) {
+	tests := []struct {
+		name        string
+		base        string
+		overlay     string // empty means no .env.<environment> file
+		environment string
+		wantValues  map[string]string
+	}{
+		{
+			name:        "base only",
+			base:        "KEY1=base1\nKEY2=base2\n",
+			environment: "",
+			wantValues: map[string]string{
+				"KEY1": "base1",
+				"KEY2": "base2",
+			},
+		},
+		{
+			name:        "overlay overrides base",
+			base:        "KEY1=base1\nKEY2=base2\n",
+			overlay:     "KEY2=prod2\nKEY3=prod3\n",
+			environment: "production",
+			wantValues: map[string]string{
+				"KEY1": "base1",
+				"KEY2": "prod2",
+				"KEY3": "prod3",
+			},
+		},
+		{
+			name:        "missing overlay falls back to base",
+			base:        "KEY1=base1\n",
+			environment: "staging",
+			wantValues: map[string]string{
+				"KEY1": "base1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(tmpDir, ".env"), []byte(tt.base), 0o644); err != nil {
+				t.Fatalf("failed to write .env file: %v", err)
+			}
+			if tt.overlay != "" {
+				overlayPath := filepath.Join(tmpDir, ".env."+tt.environment)
+				if err := os.WriteFile(overlayPath, []byte(tt.overlay), 0o644); err != nil {
+					t.Fatalf("failed to write overlay file: %v", err)
+				}
+			}
+
+			got, err := LoadDotEnvForEnvironment(tmpDir, tt.environment)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(got.Values) != len(tt.wantValues) {
+				t.Errorf("got %d values, want %d", len(got.Values), len(tt.wantValues))
+			}
+			for k, v := range tt.wantValues {
+				if got.Values[k] != v {
+					t.Errorf("key %q: got %q, want %q", k, got.Values[k], v)
+				}
+			}
+		})
+	}
+}
+
 func TestDotEnvValuesGetVariable(t *testing.T) {
 	d := &DotEnvValues{
 		Values: map[string]string{
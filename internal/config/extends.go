@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -13,47 +16,127 @@ import (
 )
 
 // resolveExtends resolves extends references and merges configurations
-func resolveExtends(config *Config, basePath string, visited map[string]bool) (*Config, error) {
+func resolveExtends(fetch contentsFetcher, config *Config, basePath string, visited map[string]bool) (*Config, error) {
+	merged, _, err := resolveExtendsWithConflicts(fetch, config, basePath, visited)
+	return merged, err
+}
+
+// resolveExtendsWithConflicts resolves extends like resolveExtends and also returns the
+// settings that a later extends source set to a different value than an earlier one.
+// The local config overriding its bases is what extends is for, so it is never a conflict.
+func resolveExtendsWithConflicts(fetch contentsFetcher, config *Config, basePath string, visited map[string]bool) (*Config, []extendsConflict, error) {
 	if len(config.Extends) == 0 {
-		return config, nil
+		return config, nil, nil
 	}
 
 	// Start with empty base config
 	merged := &Config{}
+	var conflicts []extendsConflict
+	setBy := make(map[string]string) // setting -> extends reference that set it
 
 	// Process each extend in order (later ones override earlier ones)
 	for _, extendRef := range config.Extends {
 		// Normalize the reference for cycle detection
 		normalizedRef := normalizeRef(extendRef, basePath)
 		if visited[normalizedRef] {
-			return nil, fmt.Errorf("circular reference detected: %s", extendRef)
+			return nil, nil, fmt.Errorf("circular reference detected: %s", extendRef)
 		}
 		visited[normalizedRef] = true
 
 		// Load the extended config
-		extConfig, newBasePath, err := loadExtendedConfig(extendRef, basePath)
+		extConfig, newBasePath, err := loadExtendedConfig(fetch, extendRef, basePath)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load extended config %s: %w", extendRef, err)
+			return nil, nil, fmt.Errorf("failed to load extended config %s: %w", extendRef, err)
 		}
 
 		// Recursively resolve extends in the loaded config
 		if len(extConfig.Extends) > 0 {
-			extConfig, err = resolveExtends(extConfig, newBasePath, visited)
+			var nested []extendsConflict
+			extConfig, nested, err = resolveExtendsWithConflicts(fetch, extConfig, newBasePath, visited)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
+			}
+			conflicts = append(conflicts, nested...)
+		}
+
+		// Imported labels are relative to the extended config, so resolve them before merging
+		if extConfig.Labels != nil && extConfig.Labels.From != "" {
+			if err := resolveLabelsFrom(fetch, extConfig.Labels, newBasePath, make(map[string]bool)); err != nil {
+				return nil, nil, err
 			}
 		}
 
-		// Merge extended config into base
+		// Merge extended config into base, unless it overrides a setting an earlier one locked
+		if err := checkLocked(merged, extConfig); err != nil {
+			return nil, nil, fmt.Errorf("extended config %s: %w", extendRef, err)
+		}
+		overrides, err := overriddenSettings(merged, extConfig, extendRef, setBy)
+		if err != nil {
+			return nil, nil, err
+		}
+		conflicts = append(conflicts, overrides...)
 		mergeConfigs(merged, extConfig)
 	}
 
 	// Finally, merge the local config (highest priority)
 	localConfig := *config
 	localConfig.Extends = nil // Clear extends to avoid infinite loop
+	if err := checkLocked(merged, &localConfig); err != nil {
+		return nil, nil, err
+	}
 	mergeConfigs(merged, &localConfig)
 
-	return merged, nil
+	return merged, conflicts, nil
+}
+
+// extendsConflict is a setting that two extends sources set to different values
+type extendsConflict struct {
+	Setting      string // Dotted path, e.g. "repo.visibility"
+	Earlier      string // Extends reference whose value is overridden
+	EarlierValue interface{}
+	Later        string // Extends reference whose value wins
+	LaterValue   interface{}
+}
+
+// String describes the conflict for a warning
+func (c extendsConflict) String() string {
+	return fmt.Sprintf("extends conflict: %s is %v in %s but %v in %s, which wins",
+		c.Setting, c.EarlierValue, c.Earlier, c.LaterValue, c.Later)
+}
+
+// overriddenSettings returns the settings that merging src from extends reference ref
+// would change in dst. setBy tells which reference set each setting of dst and is
+// updated with the settings of src. Lists are compared as a whole, since they replace.
+func overriddenSettings(dst, src *Config, ref string, setBy map[string]string) ([]extendsConflict, error) {
+	dstValues, err := settingValues(dst)
+	if err != nil {
+		return nil, err
+	}
+	srcValues, err := settingValues(src)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]string, 0, len(srcValues))
+	for setting := range srcValues {
+		settings = append(settings, setting)
+	}
+	sort.Strings(settings)
+
+	var conflicts []extendsConflict
+	for _, setting := range settings {
+		if earlier, set := dstValues[setting]; set && !reflect.DeepEqual(earlier, srcValues[setting]) {
+			conflicts = append(conflicts, extendsConflict{
+				Setting:      setting,
+				Earlier:      setBy[setting],
+				EarlierValue: earlier,
+				Later:        ref,
+				LaterValue:   srcValues[setting],
+			})
+		}
+		setBy[setting] = ref
+	}
+	return conflicts, nil
 }
 
 // normalizeRef normalizes a reference for comparison
@@ -61,9 +144,15 @@ func normalizeRef(ref, basePath string) string {
 	if isURL(ref) {
 		return ref
 	}
-	if filepath.IsAbs(ref) {
+	if filepath.IsAbs(ref) || isGitHubRef(ref) {
 		return ref
 	}
+	// Relative to a gh:// config, a path stays in the same repository and ref
+	if isGitHubRef(basePath) {
+		if base, err := parseGitHubRef(basePath); err == nil {
+			return base.join(ref).String()
+		}
+	}
 	return filepath.Join(basePath, ref)
 }
 
@@ -73,26 +162,41 @@ func isURL(s string) bool {
 }
 
 // loadExtendedConfig loads a config from URL or file path
-func loadExtendedConfig(ref, basePath string) (*Config, string, error) {
+func loadExtendedConfig(fetch contentsFetcher, ref, basePath string) (*Config, string, error) {
 	if isURL(ref) {
 		config, err := loadFromURL(ref)
 		return config, "", err
 	}
 
 	// Resolve relative path
-	var filePath string
-	if filepath.IsAbs(ref) {
-		filePath = ref
-	} else {
-		filePath = filepath.Join(basePath, ref)
-	}
-
-	config, err := loadSingleFile(filePath)
-	return config, filepath.Dir(filePath), err
+	filePath := normalizeRef(ref, basePath)
+	config, err := loadSingleFile(fetch, filePath)
+	return config, configDir(filePath), err
 }
 
 // loadFromURL loads a config from a URL
 func loadFromURL(url string) (*Config, error) {
+	data, err := fetchURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		if err == io.EOF {
+			return &config, nil
+		}
+		return nil, fmt.Errorf("failed to parse config from %s: %w", url, err)
+	}
+
+	config.documents = []schemaDocument{{name: url, data: data}}
+	return &config, nil
+}
+
+// fetchURL reads the body of a URL
+func fetchURL(url string) ([]byte, error) {
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 	}
@@ -111,16 +215,83 @@ func loadFromURL(url string) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response from %s: %w", url, err)
 	}
+	return data, nil
+}
 
-	var config Config
-	decoder := yaml.NewDecoder(bytes.NewReader(data))
-	decoder.KnownFields(true)
-	if err := decoder.Decode(&config); err != nil {
-		if err == io.EOF {
-			return &config, nil
+// labelsDocument is a file referenced by labels.from. Like labels.yaml in a
+// directory config, the section may be written bare or under a labels key.
+type labelsDocument struct {
+	Labels       *LabelsConfig `yaml:"labels"`
+	LabelsConfig `yaml:",inline"`
+}
+
+// resolveLabelsFrom imports the labels referenced by labels.from into labels.
+// Imported files may have a from of their own, resolved against their location.
+// Inline items replace imported items with the same name, and exclude patterns add up.
+func resolveLabelsFrom(fetch contentsFetcher, labels *LabelsConfig, basePath string, visited map[string]bool) error {
+	if labels == nil || labels.From == "" {
+		return nil
+	}
+
+	ref := labels.From
+	normalizedRef := normalizeRef(ref, basePath)
+	if visited[normalizedRef] {
+		return fmt.Errorf("circular reference detected: %s", ref)
+	}
+	visited[normalizedRef] = true
+
+	imported, newBasePath, err := loadLabelsDocument(fetch, ref, basePath)
+	if err != nil {
+		return fmt.Errorf("failed to load labels from %s: %w", ref, err)
+	}
+	if err := resolveLabelsFrom(fetch, imported, newBasePath, visited); err != nil {
+		return err
+	}
+
+	inline := make(map[string]bool, len(labels.Items))
+	for _, label := range labels.Items {
+		inline[strings.ToLower(label.Name)] = true
+	}
+	var items []Label
+	for _, label := range imported.Items {
+		if !inline[strings.ToLower(label.Name)] {
+			items = append(items, label)
 		}
-		return nil, fmt.Errorf("failed to parse config from %s: %w", url, err)
 	}
 
-	return &config, nil
+	labels.Items = append(items, labels.Items...)
+	labels.Exclude = append(imported.Exclude, labels.Exclude...)
+	labels.ReplaceDefault = labels.ReplaceDefault || imported.ReplaceDefault
+	labels.From = ""
+	return nil
+}
+
+// loadLabelsDocument loads a labels file from URL or file path
+func loadLabelsDocument(fetch contentsFetcher, ref, basePath string) (*LabelsConfig, string, error) {
+	var data []byte
+	var newBasePath string
+	var err error
+	if isURL(ref) {
+		data, err = fetchURL(ref)
+	} else if path := normalizeRef(ref, basePath); isGitHubRef(path) {
+		data, err = fetchGitHubRef(fetch, path)
+		newBasePath = configDir(path)
+	} else {
+		data, err = os.ReadFile(path)
+		newBasePath = filepath.Dir(path)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var doc labelsDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&doc); err != nil && err != io.EOF {
+		return nil, "", fmt.Errorf("failed to parse labels: %w", err)
+	}
+	if doc.Labels != nil {
+		return doc.Labels, newBasePath, nil
+	}
+	return &doc.LabelsConfig, newBasePath, nil
 }
@@ -6,8 +6,11 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 )
 
 func TestIsURL(t *testing.T) {
@@ -47,6 +50,8 @@ func TestNormalizeRef(t *testing.T) {
 		{"../base.yaml", "/some/path", "/some/base.yaml"},
 		{"/absolute/config.yaml", "/some/path", "/absolute/config.yaml"},
 		{"base.yaml", "/some/path", "/some/path/base.yaml"},
+		{"../base.yaml", "gh://acme/settings/teams@v1", "gh://acme/settings/base.yaml@v1"},
+		{"gh://acme/other/base.yaml", "/some/path", "gh://acme/other/base.yaml"},
 	}
 
 	for _, tt := range tests {
@@ -155,7 +160,7 @@ branch_protection:
 	}
 
 	visited := make(map[string]bool)
-	result, err := resolveExtends(config, tmpDir, visited)
+	result, err := resolveExtends(nil, config, tmpDir, visited)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -220,7 +225,7 @@ repo:
 	}
 
 	visited := make(map[string]bool)
-	result, err := resolveExtends(config, tmpDir, visited)
+	result, err := resolveExtends(nil, config, tmpDir, visited)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -246,6 +251,47 @@ repo:
 	}
 }
 
+func TestResolveExtendsConflicts(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"base-a.yaml": "repo:\n  visibility: public\n  allow_auto_merge: true\n",
+		"base-b.yaml": "repo:\n  visibility: private\n  allow_auto_merge: true\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	// The local config overriding its bases is intended, so it isn't a conflict
+	config := &Config{
+		Extends: []string{"./base-a.yaml", "./base-b.yaml"},
+		Repo:    &RepoConfig{Visibility: ptr("internal")},
+	}
+	result, conflicts, err := resolveExtendsWithConflicts(nil, config, tmpDir, make(map[string]bool))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *result.Repo.Visibility != "internal" {
+		t.Errorf("expected the local visibility to win, got %s", *result.Repo.Visibility)
+	}
+
+	want := []extendsConflict{{
+		Setting:      "repo.visibility",
+		Earlier:      "./base-a.yaml",
+		EarlierValue: "public",
+		Later:        "./base-b.yaml",
+		LaterValue:   "private",
+	}}
+	if !reflect.DeepEqual(conflicts, want) {
+		t.Fatalf("conflicts = %+v, want %+v", conflicts, want)
+	}
+	wantMsg := "extends conflict: repo.visibility is public in ./base-a.yaml but private in ./base-b.yaml, which wins"
+	if got := conflicts[0].String(); got != wantMsg {
+		t.Errorf("String() = %q, want %q", got, wantMsg)
+	}
+}
+
 func TestResolveExtendsNested(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "extends-nested-test")
 	if err != nil {
@@ -282,7 +328,7 @@ repo:
 	}
 
 	visited := make(map[string]bool)
-	result, err := resolveExtends(config, tmpDir, visited)
+	result, err := resolveExtends(nil, config, tmpDir, visited)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -342,7 +388,7 @@ repo:
 	}
 
 	visited := make(map[string]bool)
-	_, err = resolveExtends(config, tmpDir, visited)
+	_, err = resolveExtends(nil, config, tmpDir, visited)
 	if err == nil {
 		t.Error("expected circular reference error")
 	}
@@ -374,7 +420,7 @@ repo:
 	}
 
 	visited := make(map[string]bool)
-	_, err = resolveExtends(config, tmpDir, visited)
+	_, err = resolveExtends(nil, config, tmpDir, visited)
 	if err == nil {
 		t.Error("expected circular reference error for self-reference")
 	}
@@ -395,7 +441,7 @@ func TestResolveExtendsFileNotFound(t *testing.T) {
 	}
 
 	visited := make(map[string]bool)
-	_, err = resolveExtends(config, tmpDir, visited)
+	_, err = resolveExtends(nil, config, tmpDir, visited)
 	if err == nil {
 		t.Error("expected error for nonexistent file")
 	}
@@ -421,7 +467,7 @@ repo:
 	}
 
 	visited := make(map[string]bool)
-	result, err := resolveExtends(config, "", visited)
+	result, err := resolveExtends(nil, config, "", visited)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -458,7 +504,7 @@ repo:
 	}
 
 	visited := make(map[string]bool)
-	result, err := resolveExtends(config, "/different/path", visited)
+	result, err := resolveExtends(nil, config, "/different/path", visited)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -477,7 +523,7 @@ func TestResolveExtendsEmptyList(t *testing.T) {
 	}
 
 	visited := make(map[string]bool)
-	result, err := resolveExtends(config, "", visited)
+	result, err := resolveExtends(nil, config, "", visited)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -497,7 +543,7 @@ func TestLoadExtendedConfigURL(t *testing.T) {
 	}))
 	defer server.Close()
 
-	cfg, basePath, err := loadExtendedConfig(server.URL, "/some/path")
+	cfg, basePath, err := loadExtendedConfig(nil, server.URL, "/some/path")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -523,7 +569,7 @@ func TestLoadExtendedConfigRelativePath(t *testing.T) {
 		t.Fatalf("failed to write file: %v", err)
 	}
 
-	cfg, basePath, err := loadExtendedConfig("./base.yaml", tmpDir)
+	cfg, basePath, err := loadExtendedConfig(nil, "./base.yaml", tmpDir)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -552,7 +598,7 @@ func TestResolveExtendsInvalidYAMLInBase(t *testing.T) {
 	}
 
 	visited := make(map[string]bool)
-	_, err = resolveExtends(config, tmpDir, visited)
+	_, err = resolveExtends(nil, config, tmpDir, visited)
 	if err == nil {
 		t.Error("expected error for invalid YAML")
 	}
@@ -592,7 +638,7 @@ repo:
 	}
 
 	visited := make(map[string]bool)
-	result, err := resolveExtends(config, tmpDir, visited)
+	result, err := resolveExtends(nil, config, tmpDir, visited)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -633,7 +679,7 @@ repo:
 	}
 
 	visited := make(map[string]bool)
-	_, err := resolveExtends(config, "", visited)
+	_, err := resolveExtends(nil, config, "", visited)
 	if err == nil {
 		t.Error("expected circular reference error for URL")
 	}
@@ -641,3 +687,212 @@ repo:
 		t.Errorf("expected circular reference error, got: %v", err)
 	}
 }
+
+func TestResolveLabelsFromLocalFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	shared := `
+items:
+  - name: bug
+    color: d73a4a
+  - name: enhancement
+    color: a2eeef
+exclude:
+  - dependencies
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "labels.yaml"), []byte(shared), 0o644); err != nil {
+		t.Fatalf("failed to write labels: %v", err)
+	}
+
+	labels := &LabelsConfig{
+		From: "./labels.yaml",
+		Items: []Label{
+			{Name: "Bug", Color: "ff0000"},
+			{Name: "docs", Color: "0075ca"},
+		},
+	}
+	if err := resolveLabelsFrom(nil, labels, tmpDir, make(map[string]bool)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Label{
+		{Name: "enhancement", Color: "a2eeef"},
+		{Name: "Bug", Color: "ff0000"},
+		{Name: "docs", Color: "0075ca"},
+	}
+	if len(labels.Items) != len(want) {
+		t.Fatalf("expected %d labels, got %+v", len(want), labels.Items)
+	}
+	for i, label := range want {
+		if labels.Items[i].Name != label.Name || labels.Items[i].Color != label.Color {
+			t.Errorf("label %d: expected %+v, got %+v", i, label, labels.Items[i])
+		}
+	}
+	if len(labels.Exclude) != 1 || labels.Exclude[0] != "dependencies" {
+		t.Errorf("expected imported exclude patterns, got %v", labels.Exclude)
+	}
+	if labels.From != "" {
+		t.Errorf("expected from to be cleared, got %q", labels.From)
+	}
+}
+
+func TestResolveLabelsFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"labels": {"items": [{"name": "bug", "color": "d73a4a"}, {"name": "question", "color": "d876e3"}]}}`))
+	}))
+	defer server.Close()
+
+	labels := &LabelsConfig{
+		From:  server.URL,
+		Items: []Label{{Name: "question", Color: "ffffff", Description: ptr("Ask away")}},
+	}
+	if err := resolveLabelsFrom(nil, labels, "", make(map[string]bool)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(labels.Items) != 2 {
+		t.Fatalf("expected 2 labels, got %+v", labels.Items)
+	}
+	if labels.Items[0].Name != "bug" || labels.Items[0].Color != "d73a4a" {
+		t.Errorf("expected imported bug label first, got %+v", labels.Items[0])
+	}
+	if labels.Items[1].Color != "ffffff" || labels.Items[1].Description == nil {
+		t.Errorf("expected inline question label to win, got %+v", labels.Items[1])
+	}
+}
+
+func TestResolveLabelsFromCircularReference(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "a.yaml"), []byte("from: ./b.yaml\n"), 0o644); err != nil {
+		t.Fatalf("failed to write a.yaml: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "b.yaml"), []byte("from: ./a.yaml\n"), 0o644); err != nil {
+		t.Fatalf("failed to write b.yaml: %v", err)
+	}
+
+	labels := &LabelsConfig{From: "./a.yaml"}
+	err := resolveLabelsFrom(nil, labels, tmpDir, make(map[string]bool))
+	if err == nil || !strings.Contains(err.Error(), "circular reference") {
+		t.Errorf("expected circular reference error, got: %v", err)
+	}
+}
+
+func TestResolveLabelsFromUnknownField(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "labels.yaml"), []byte("repo:\n  visibility: public\n"), 0o644); err != nil {
+		t.Fatalf("failed to write labels: %v", err)
+	}
+
+	labels := &LabelsConfig{From: "labels.yaml"}
+	if err := resolveLabelsFrom(nil, labels, tmpDir, make(map[string]bool)); err == nil {
+		t.Error("expected error for a file that isn't a labels section")
+	}
+}
+
+func TestResolveExtendsLocked(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	base := `
+locked:
+  - repo.visibility
+  - branch_protection.*
+repo:
+  visibility: private
+  allow_squash_merge: true
+branch_protection:
+  main:
+    required_reviews: 2
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "base.yaml"), []byte(base), 0o644); err != nil {
+		t.Fatalf("failed to write base file: %v", err)
+	}
+	// A config in between doesn't lift the lock
+	if err := os.WriteFile(filepath.Join(tmpDir, "team.yaml"), []byte("extends:\n  - ./base.yaml\n"), 0o644); err != nil {
+		t.Fatalf("failed to write team file: %v", err)
+	}
+
+	t.Run("locked values are kept", func(t *testing.T) {
+		config := &Config{
+			Extends: []string{"./team.yaml"},
+			Repo: &RepoConfig{
+				Visibility:       ptr("private"), // Same value as the base
+				AllowSquashMerge: ptrBool(false),
+			},
+		}
+		result, err := resolveExtends(nil, config, tmpDir, make(map[string]bool))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Repo.Visibility == nil || *result.Repo.Visibility != "private" {
+			t.Errorf("expected visibility 'private', got %v", result.Repo.Visibility)
+		}
+		if result.Repo.AllowSquashMerge == nil || *result.Repo.AllowSquashMerge {
+			t.Errorf("expected unlocked allow_squash_merge to be overridden, got %v", result.Repo.AllowSquashMerge)
+		}
+		if rule := result.BranchProtection["main"]; rule == nil || rule.RequiredReviews == nil || *rule.RequiredReviews != 2 {
+			t.Errorf("expected required_reviews 2, got %+v", rule)
+		}
+		if len(result.Locked) != 2 {
+			t.Errorf("expected the locks to be inherited, got %v", result.Locked)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		config *Config
+		field  string
+	}{
+		{
+			name:   "override locked setting",
+			config: &Config{Repo: &RepoConfig{Visibility: ptr("public")}},
+			field:  "repo.visibility",
+		},
+		{
+			name: "override setting under a locked pattern",
+			config: &Config{BranchProtection: map[string]*BranchRule{
+				"main": {RequiredReviews: ptrInt(0)},
+			}},
+			field: "branch_protection.main.required_reviews",
+		},
+		{
+			name: "set locked setting the base leaves unset",
+			config: &Config{BranchProtection: map[string]*BranchRule{
+				"release": {RequiredReviews: ptrInt(1)},
+			}},
+			field: "branch_protection.release.required_reviews",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Extends = []string{"./team.yaml"}
+			_, err := resolveExtends(nil, tt.config, tmpDir, make(map[string]bool))
+			var validationErr *apperrors.ValidationError
+			if !apperrors.As(err, &validationErr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if validationErr.Field != tt.field {
+				t.Errorf("field = %q, want %q", validationErr.Field, tt.field)
+			}
+		})
+	}
+}
+
+func TestResolveExtendsLockedBySibling(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "security.yaml"), []byte("locked: [repo]\nrepo:\n  visibility: private\n"), 0o644); err != nil {
+		t.Fatalf("failed to write security file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "defaults.yaml"), []byte("repo:\n  allow_merge_commit: false\n"), 0o644); err != nil {
+		t.Fatalf("failed to write defaults file: %v", err)
+	}
+
+	config := &Config{Extends: []string{"./security.yaml", "./defaults.yaml"}}
+	_, err := resolveExtends(nil, config, tmpDir, make(map[string]bool))
+	if err == nil || !strings.Contains(err.Error(), "./defaults.yaml") || !strings.Contains(err.Error(), "repo.allow_merge_commit") {
+		t.Errorf("expected a lock conflict naming defaults.yaml, got %v", err)
+	}
+}
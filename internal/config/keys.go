@@ -0,0 +1,154 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// KeyDescriptor describes one config key, for editor tooling and `gh repo-settings keys`
+type KeyDescriptor struct {
+	// Path is the dotted key, with "*" for map keys such as branch names and
+	// "[]" for list items, e.g. "branch_protection.*.required_reviews"
+	Path string `json:"path"`
+	// Type is the JSON Schema type, "array<item type>" for lists, or the
+	// alternatives joined by "|" for keys that accept several forms
+	Type        string   `json:"type"`
+	Enum        []string `json:"enum,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+}
+
+// DescribeKeys lists every key of the config file, sorted by path. It is derived from
+// the embedded JSON Schema, so it follows the config types like the schema does.
+func DescribeKeys() ([]KeyDescriptor, error) {
+	schema, err := parsedSchema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse embedded schema: %w", err)
+	}
+
+	v := &schemaResolver{root: schema}
+	var keys []KeyDescriptor
+	describeProperties(v, schema, "", &keys)
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Path < keys[j].Path })
+	return keys, nil
+}
+
+// describeProperties adds the keys of an object schema, and of the objects below it
+func describeProperties(v *schemaResolver, s *jsonSchema, path string, keys *[]KeyDescriptor) {
+	s = v.resolve(s)
+	for _, alt := range s.AnyOf {
+		describeProperties(v, alt, path, keys)
+	}
+	for name, prop := range s.Properties {
+		describeKey(v, prop, joinSchemaPath(path, name), keys)
+	}
+	if additional := additionalSchema(s); additional != nil {
+		describeKey(v, additional, joinSchemaPath(path, "*"), keys)
+	}
+}
+
+// describeKey adds the key at path and the keys nested in it
+func describeKey(v *schemaResolver, prop *jsonSchema, path string, keys *[]KeyDescriptor) {
+	// Descriptions sit next to a $ref, so read them before resolving it
+	key := KeyDescriptor{Path: path, Description: prop.Description, Deprecated: prop.Deprecated}
+	s := v.resolve(prop)
+	if key.Description == "" {
+		key.Description = s.Description
+	}
+	key.Deprecated = key.Deprecated || s.Deprecated
+	key.Type = schemaTypeName(v, s)
+	for _, e := range s.Enum {
+		key.Enum = append(key.Enum, fmt.Sprint(e))
+	}
+	*keys = append(*keys, key)
+
+	describeProperties(v, s, path, keys)
+	if s.Items != nil {
+		describeProperties(v, s.Items, path+"[]", keys)
+	}
+}
+
+// schemaTypeName names the type of a resolved schema
+func schemaTypeName(v *schemaResolver, s *jsonSchema) string {
+	if len(s.AnyOf) > 0 {
+		types := make([]string, len(s.AnyOf))
+		for i, alt := range s.AnyOf {
+			types[i] = schemaTypeName(v, v.resolve(alt))
+		}
+		return strings.Join(types, "|")
+	}
+	if s.Type == "array" && s.Items != nil {
+		return "array<" + schemaTypeName(v, v.resolve(s.Items)) + ">"
+	}
+	return s.Type
+}
+
+// additionalSchema returns the schema of the values of a map, or nil if s isn't a map
+func additionalSchema(s *jsonSchema) *jsonSchema {
+	if len(s.AdditionalProperties) == 0 || string(s.AdditionalProperties) == "false" || string(s.AdditionalProperties) == "true" {
+		return nil
+	}
+	additional := &jsonSchema{}
+	if err := json.Unmarshal(s.AdditionalProperties, additional); err != nil {
+		return nil
+	}
+	return additional
+}
+
+// jsonSchema is the subset of JSON Schema emitted by GenerateSchema that describes keys
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Defs                 map[string]*jsonSchema `json:"$defs"`
+	Type                 string                 `json:"type"`
+	Description          string                 `json:"description"`
+	Deprecated           bool                   `json:"deprecated"`
+	Enum                 []interface{}          `json:"enum"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	AnyOf                []*jsonSchema          `json:"anyOf"`
+}
+
+var (
+	parseSchemaOnce sync.Once
+	rootSchema      *jsonSchema
+	parseSchemaErr  error
+)
+
+// parsedSchema parses the embedded schema once
+func parsedSchema() (*jsonSchema, error) {
+	parseSchemaOnce.Do(func() {
+		rootSchema = &jsonSchema{}
+		parseSchemaErr = json.Unmarshal(schemaJSON, rootSchema)
+	})
+	return rootSchema, parseSchemaErr
+}
+
+// schemaResolver follows the references of a parsed schema
+type schemaResolver struct {
+	root *jsonSchema
+}
+
+// resolve follows a local "#/$defs/<name>" reference
+func (v *schemaResolver) resolve(s *jsonSchema) *jsonSchema {
+	for s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/$defs/")
+		def, ok := v.root.Defs[name]
+		if !ok {
+			return s
+		}
+		s = def
+	}
+	return s
+}
+
+func joinSchemaPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
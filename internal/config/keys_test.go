@@ -0,0 +1,66 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestDescribeKeys(t *testing.T) {
+	keys, err := DescribeKeys()
+	if err != nil {
+		t.Fatalf("DescribeKeys() error: %v", err)
+	}
+
+	byPath := make(map[string]KeyDescriptor, len(keys))
+	for _, key := range keys {
+		if _, dup := byPath[key.Path]; dup {
+			t.Errorf("duplicate key %s", key.Path)
+		}
+		byPath[key.Path] = key
+	}
+	if !sort.SliceIsSorted(keys, func(i, j int) bool { return keys[i].Path < keys[j].Path }) {
+		t.Error("keys are not sorted by path")
+	}
+
+	tests := []struct {
+		path     string
+		typ      string
+		enum     []string
+		describe bool
+	}{
+		{path: "actions.allowed_actions", typ: "string", enum: []string{"all", "local_only", "selected"}, describe: true},
+		{path: "actions.selected_actions", typ: "object", describe: true},
+		{path: "actions.selected_actions.patterns_allowed", typ: "array<string>", describe: true},
+		{path: "repo.visibility", typ: "string", enum: []string{"public", "private", "internal"}, describe: true},
+		{path: "topics", typ: "array<string>", describe: true},
+		{path: "labels.items", typ: "array<object>", describe: true},
+		{path: "labels.items[].color", typ: "string", describe: true},
+		{path: "branch_protection.*", typ: "object"},
+		{path: "branch_protection.*.required_reviews", typ: "integer", describe: true},
+		{path: "branch_protection.*.status_checks", typ: "array<string|object>", describe: true},
+		{path: "branch_protection.*.status_checks[].app_id", typ: "integer", describe: true},
+		{path: "env.variables.*", typ: "string"},
+		{path: "org.actions.enabled_repositories", typ: "string", enum: []string{"all", "none", "selected"}, describe: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			key, ok := byPath[tt.path]
+			if !ok {
+				t.Fatalf("key %s not listed", tt.path)
+			}
+			if key.Type != tt.typ {
+				t.Errorf("Type = %q, want %q", key.Type, tt.typ)
+			}
+			if !reflect.DeepEqual(key.Enum, tt.enum) {
+				t.Errorf("Enum = %v, want %v", key.Enum, tt.enum)
+			}
+			if tt.describe && key.Description == "" {
+				t.Error("Description is empty")
+			}
+			if key.Deprecated {
+				t.Error("Deprecated = true, want false")
+			}
+		})
+	}
+}
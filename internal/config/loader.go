@@ -2,24 +2,50 @@ package config
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/myzkey/gh-repo-settings/internal/infra/logger"
 	"gopkg.in/yaml.v3"
 )
 
 const (
 	DefaultDir        = ".github/repo-settings"
 	DefaultSingleFile = ".github/repo-settings.yaml"
+
+	// DefaultSingleFileYML is the .yml variant of DefaultSingleFile
+	DefaultSingleFileYML = ".github/repo-settings.yml"
+
+	// StdinName is the pseudo file name used in messages for stdin configs
+	StdinName = "<stdin>"
 )
 
-// LoadOptions represents options for loading config
+// LoadOptions represents options for loading config.
+// Directories in Dir are merged in order, later ones winning. When Config is set too,
+// it is the base and the sections in Dir override it.
 type LoadOptions struct {
-	Dir    string
+	Dir    []string
 	Config string
+	Stdin  io.Reader // If set, config is read from this reader and discovery is skipped
+
+	// ValidateSchema additionally checks every document the config is loaded from,
+	// extended configs included, against the embedded JSON Schema
+	ValidateSchema bool
+
+	// WarnConflicts warns about settings that a later extends source overrides
+	// with a different value than an earlier one
+	WarnConflicts bool
+
+	// Context bounds the requests for gh:// sources; nil means context.Background()
+	Context context.Context
+
+	// FetchGitHub reads a GitHub API endpoint for gh:// sources, reporting a missing
+	// file as ErrFileNotFound. Without it, gh:// sources can't be loaded.
+	FetchGitHub func(ctx context.Context, endpoint string) ([]byte, error)
 }
 
 // Load loads configuration from file or directory
@@ -27,22 +53,30 @@ func Load(opts LoadOptions) (*Config, error) {
 	var config *Config
 	var basePath string
 	var err error
+	fetch := newContentsFetcher(opts.Context, opts.FetchGitHub)
 
-	// Priority: --dir > --config > default dir > default single file
+	// Priority: stdin > --config with --dir > --dir > --config > default dir > default single file
 	switch {
-	case opts.Dir != "":
-		config, err = loadFromDirectory(opts.Dir)
-		basePath = opts.Dir
+	case opts.Stdin != nil:
+		config, err = loadFromReader(opts.Stdin, StdinName)
+		// Relative extends are resolved against the working directory
+		basePath = "."
+	case len(opts.Dir) > 0 && opts.Config != "":
+		config, err = loadLayered(fetch, opts.Config, opts.Dir)
+		basePath = configDir(opts.Config)
+	case len(opts.Dir) > 0:
+		config, err = loadFromDirectories(fetch, opts.Dir)
+		basePath = opts.Dir[len(opts.Dir)-1]
 	case opts.Config != "":
-		config, err = loadSingleFile(opts.Config)
-		basePath = filepath.Dir(opts.Config)
+		config, err = loadSingleFile(fetch, opts.Config)
+		basePath = configDir(opts.Config)
 	default:
 		if info, statErr := os.Stat(DefaultDir); statErr == nil && info.IsDir() {
 			config, err = loadFromDirectory(DefaultDir)
 			basePath = DefaultDir
-		} else if _, statErr := os.Stat(DefaultSingleFile); statErr == nil {
-			config, err = loadSingleFile(DefaultSingleFile)
-			basePath = filepath.Dir(DefaultSingleFile)
+		} else if path, ok := findDefaultSingleFile(); ok {
+			config, err = loadSingleFile(fetch, path)
+			basePath = filepath.Dir(path)
 		} else {
 			return nil, fmt.Errorf("no config found. Create %s/ or %s", DefaultDir, DefaultSingleFile)
 		}
@@ -55,20 +89,103 @@ func Load(opts LoadOptions) (*Config, error) {
 	// Resolve extends
 	if len(config.Extends) > 0 {
 		visited := make(map[string]bool)
-		config, err = resolveExtends(config, basePath, visited)
+		var conflicts []extendsConflict
+		config, conflicts, err = resolveExtendsWithConflicts(fetch, config, basePath, visited)
 		if err != nil {
 			return nil, err
 		}
+		if opts.WarnConflicts {
+			for _, conflict := range conflicts {
+				logger.Warn("%s", conflict)
+			}
+		}
+	}
+
+	// Resolve labels.from
+	if config.Labels != nil && config.Labels.From != "" {
+		if err := resolveLabelsFrom(fetch, config.Labels, basePath, make(map[string]bool)); err != nil {
+			return nil, err
+		}
 	}
 
+	if opts.ValidateSchema {
+		for _, doc := range config.documents {
+			if err := validateDocument(doc); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// The documents are only kept until they are validated
+	config.documents = nil
+
 	// Validate config
 	if err := config.Validate(); err != nil {
 		return nil, err
 	}
+	for _, warning := range config.Warnings() {
+		logger.Warn("%s", warning)
+	}
 
 	return config, nil
 }
 
+// loadLayered loads the single file at filePath and merges the sections of the
+// directories in dirPaths on top, so a directory section overrides the same settings
+// in the file, e.g. a repo.yaml setting visibility wins over repo.visibility in the file.
+// Extends can only come from the file, as directories have no extends section.
+func loadLayered(fetch contentsFetcher, filePath string, dirPaths []string) (*Config, error) {
+	base, err := loadSingleFile(fetch, filePath)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := loadFromDirectories(fetch, dirPaths)
+	if err != nil {
+		return nil, err
+	}
+	mergeConfigs(base, overrides)
+	return base, nil
+}
+
+// loadFromDirectories loads each directory in dirPaths and merges them in order,
+// later directories winning like later extends do. A directory's labels.from is
+// resolved against that directory before merging.
+func loadFromDirectories(fetch contentsFetcher, dirPaths []string) (*Config, error) {
+	var merged *Config
+	for _, dirPath := range dirPaths {
+		config, err := loadFromDirectory(dirPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := resolveLabelsFrom(fetch, config.Labels, dirPath, make(map[string]bool)); err != nil {
+			return nil, err
+		}
+		if merged == nil {
+			merged = config
+		} else {
+			mergeConfigs(merged, config)
+		}
+	}
+	return merged, nil
+}
+
+// findDefaultSingleFile returns the default single config file, preferring
+// .yaml over .yml when both exist
+func findDefaultSingleFile() (string, bool) {
+	_, yamlErr := os.Stat(DefaultSingleFile)
+	_, ymlErr := os.Stat(DefaultSingleFileYML)
+
+	switch {
+	case yamlErr == nil && ymlErr == nil:
+		logger.Warn("both %s and %s exist; using %s", DefaultSingleFile, DefaultSingleFileYML, DefaultSingleFile)
+		return DefaultSingleFile, true
+	case yamlErr == nil:
+		return DefaultSingleFile, true
+	case ymlErr == nil:
+		return DefaultSingleFileYML, true
+	}
+	return "", false
+}
+
 // ToYAML converts config to YAML string with 2-space indentation
 func (c *Config) ToYAML() (string, error) {
 	var buf bytes.Buffer
@@ -80,12 +197,34 @@ func (c *Config) ToYAML() (string, error) {
 	return buf.String(), nil
 }
 
-func loadSingleFile(filePath string) (*Config, error) {
+func loadSingleFile(fetch contentsFetcher, filePath string) (*Config, error) {
+	if isGitHubRef(filePath) {
+		data, err := fetchGitHubRef(fetch, filePath)
+		if err != nil {
+			return nil, err
+		}
+		return decodeConfig(data, filePath)
+	}
+
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file %s: %w", filePath, err)
 	}
 
+	return decodeConfig(data, filePath)
+}
+
+func loadFromReader(r io.Reader, name string) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config from %s: %w", name, err)
+	}
+
+	return decodeConfig(data, name)
+}
+
+// decodeConfig parses YAML data with strict unknown-field checking
+func decodeConfig(data []byte, filePath string) (*Config, error) {
 	var config Config
 	decoder := yaml.NewDecoder(bytes.NewReader(data))
 	decoder.KnownFields(true)
@@ -97,6 +236,7 @@ func loadSingleFile(filePath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", filePath, err)
 	}
 
+	config.documents = []schemaDocument{{name: filePath, data: data}}
 	return &config, nil
 }
 
@@ -108,16 +248,7 @@ func loadFromDirectory(dirPath string) (*Config, error) {
 
 	config := &Config{}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		name := entry.Name()
-		if !strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml") {
-			continue
-		}
-
+	for _, name := range configFileNames(entries, dirPath) {
 		filePath := filepath.Join(dirPath, name)
 		data, err := os.ReadFile(filePath)
 		if err != nil {
@@ -125,6 +256,11 @@ func loadFromDirectory(dirPath string) (*Config, error) {
 		}
 
 		baseName := strings.TrimSuffix(strings.TrimSuffix(name, ".yaml"), ".yml")
+		config.documents = append(config.documents, schemaDocument{
+			name:    filePath,
+			section: strings.ReplaceAll(baseName, "-", "_"),
+			data:    data,
+		})
 
 		switch baseName {
 		case "repo":
@@ -199,10 +335,91 @@ func loadFromDirectory(dirPath string) (*Config, error) {
 					config.Actions = &actions
 				}
 			}
+		case "pages":
+			var wrapper struct {
+				Pages *PagesConfig `yaml:"pages"`
+			}
+			if err := yaml.Unmarshal(data, &wrapper); err == nil && wrapper.Pages != nil {
+				config.Pages = wrapper.Pages
+			} else {
+				var pages PagesConfig
+				if err := yaml.Unmarshal(data, &pages); err == nil {
+					config.Pages = &pages
+				}
+			}
+		case "templates":
+			var wrapper struct {
+				Templates *TemplatesConfig `yaml:"templates"`
+			}
+			if err := yaml.Unmarshal(data, &wrapper); err == nil && wrapper.Templates != nil {
+				config.Templates = wrapper.Templates
+			} else {
+				var templates TemplatesConfig
+				if err := yaml.Unmarshal(data, &templates); err == nil {
+					config.Templates = &templates
+				}
+			}
+		case "org":
+			var wrapper struct {
+				Org *OrgConfig `yaml:"org"`
+			}
+			if err := yaml.Unmarshal(data, &wrapper); err == nil && wrapper.Org != nil {
+				config.Org = wrapper.Org
+			} else {
+				var org OrgConfig
+				if err := yaml.Unmarshal(data, &org); err == nil {
+					config.Org = &org
+				}
+			}
+		case "ignore":
+			var wrapper struct {
+				Ignore []string `yaml:"ignore"`
+			}
+			if err := yaml.Unmarshal(data, &wrapper); err == nil && wrapper.Ignore != nil {
+				config.Ignore = wrapper.Ignore
+			} else {
+				var ignore []string
+				if err := yaml.Unmarshal(data, &ignore); err == nil {
+					config.Ignore = ignore
+				}
+			}
 		default:
-			return nil, fmt.Errorf("unknown config file: %s (valid names: repo, topics, labels, branch-protection, env, actions)", name)
+			return nil, fmt.Errorf("unknown config file: %s (valid names: repo, topics, labels, branch-protection, env, actions, pages, templates, org, ignore)", name)
 		}
 	}
 
 	return config, nil
 }
+
+// configFileNames returns the YAML file names in a config directory.
+// When the same section exists as both .yaml and .yml, the .yaml file wins
+// and the .yml file is skipped with a warning.
+func configFileNames(entries []os.DirEntry, dirPath string) []string {
+	present := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			present[entry.Name()] = true
+		}
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		switch {
+		case strings.HasSuffix(name, ".yaml"):
+			names = append(names, name)
+		case strings.HasSuffix(name, ".yml"):
+			yamlName := strings.TrimSuffix(name, ".yml") + ".yaml"
+			if present[yamlName] {
+				logger.Warn("both %s and %s exist in %s; using %s", yamlName, name, dirPath, yamlName)
+				continue
+			}
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -166,6 +167,10 @@ branch_protection:
 		"actions.yaml": `
 actions:
   enabled: true
+`,
+		"pages.yaml": `
+pages:
+  build_type: workflow
 `,
 		"ignored.txt": "should be ignored",
 	}
@@ -176,7 +181,7 @@ actions:
 		}
 	}
 
-	cfg, err := Load(LoadOptions{Dir: tmpDir})
+	cfg, err := Load(LoadOptions{Dir: []string{tmpDir}})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -196,6 +201,9 @@ actions:
 	if cfg.Actions == nil || !*cfg.Actions.Enabled {
 		t.Error("expected actions to be enabled")
 	}
+	if cfg.Pages == nil || cfg.Pages.BuildType == nil || *cfg.Pages.BuildType != "workflow" {
+		t.Error("expected pages build_type 'workflow'")
+	}
 }
 
 func TestLoadDirectFormat(t *testing.T) {
@@ -214,7 +222,7 @@ visibility: public
 		t.Fatalf("failed to write file: %v", err)
 	}
 
-	cfg, err := Load(LoadOptions{Dir: tmpDir})
+	cfg, err := Load(LoadOptions{Dir: []string{tmpDir}})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -285,6 +293,94 @@ repo:
 	}
 }
 
+func TestLoadDefaultYMLFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "config-yml-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	oldWd, _ := os.Getwd()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+	defer os.Chdir(oldWd)
+
+	githubDir := filepath.Join(tmpDir, ".github")
+	if err := os.MkdirAll(githubDir, 0o755); err != nil {
+		t.Fatalf("failed to create .github dir: %v", err)
+	}
+
+	ymlContent := `
+repo:
+  description: "From yml"
+`
+	if err := os.WriteFile(filepath.Join(githubDir, "repo-settings.yml"), []byte(ymlContent), 0o644); err != nil {
+		t.Fatalf("failed to write yml file: %v", err)
+	}
+
+	// Only .yml exists
+	cfg, err := Load(LoadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Repo == nil || *cfg.Repo.Description != "From yml" {
+		t.Error("expected to load from default .yml file")
+	}
+
+	yamlContent := `
+repo:
+  description: "From yaml"
+`
+	if err := os.WriteFile(filepath.Join(githubDir, "repo-settings.yaml"), []byte(yamlContent), 0o644); err != nil {
+		t.Fatalf("failed to write yaml file: %v", err)
+	}
+
+	// Both exist: .yaml takes precedence
+	cfg, err = Load(LoadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Repo == nil || *cfg.Repo.Description != "From yaml" {
+		t.Error("expected .yaml to take precedence over .yml")
+	}
+}
+
+func TestLoadDirectoryMixedExtensions(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "config-mixed-ext-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"repo.yaml":   "repo:\n  description: \"From yaml\"\n",
+		"repo.yml":    "repo:\n  description: \"From yml\"\n",
+		"topics.yml":  "topics:\n  - go\n",
+		"labels.yaml": "labels:\n  items:\n    - name: bug\n      color: d73a4a\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg, err := Load(LoadOptions{Dir: []string{tmpDir}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Repo == nil || *cfg.Repo.Description != "From yaml" {
+		t.Error("expected repo.yaml to take precedence over repo.yml")
+	}
+	if len(cfg.Topics) != 1 || cfg.Topics[0] != "go" {
+		t.Errorf("expected topics from topics.yml, got %v", cfg.Topics)
+	}
+	if cfg.Labels == nil || len(cfg.Labels.Items) != 1 {
+		t.Error("expected labels from labels.yaml")
+	}
+}
+
 func TestLoadUnknownFieldError(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "config-unknown-field-test")
 	if err != nil {
@@ -309,6 +405,119 @@ unknown_field:
 	}
 }
 
+func TestLoadStatusCheckApps(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "config.yaml")
+	content := `branch_protection:
+  main:
+    status_checks:
+      - lint
+      - context: test
+        app_id: 15368
+      - context: build
+`
+	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(LoadOptions{Config: filePath, ValidateSchema: true})
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	checks := cfg.BranchProtection["main"].StatusChecks
+	if len(checks) != 3 {
+		t.Fatalf("expected 3 status checks, got %v", checks)
+	}
+	if checks[0].Context != "lint" || checks[0].AppID != nil {
+		t.Errorf("checks[0] = %v, want lint from any app", checks[0])
+	}
+	if checks[1].Context != "test" || checks[1].AppID == nil || *checks[1].AppID != 15368 {
+		t.Errorf("checks[1] = %v, want test (app 15368)", checks[1])
+	}
+	if checks[2].Context != "build" || checks[2].AppID != nil {
+		t.Errorf("checks[2] = %v, want build from any app", checks[2])
+	}
+
+	// Checks without an app are written back as plain names
+	data, err := cfg.ToYAML()
+	if err != nil {
+		t.Fatalf("ToYAML() unexpected error: %v", err)
+	}
+	for _, want := range []string{"- lint\n", "- context: test\n", "app_id: 15368\n", "- build\n"} {
+		if !strings.Contains(data, want) {
+			t.Errorf("ToYAML() = %s, want it to contain %q", data, want)
+		}
+	}
+
+	bad := `branch_protection:
+  main:
+    status_checks:
+      - context: test
+        app: 15368
+`
+	if err := os.WriteFile(filePath, []byte(bad), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(LoadOptions{Config: filePath}); err == nil || !strings.Contains(err.Error(), "field app not found") {
+		t.Errorf("Load() error = %v, want unknown field app", err)
+	}
+}
+
+func TestLoadInlineSecrets(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "config.yaml")
+	content := `env:
+  secrets:
+    - API_TOKEN
+    - name: DEMO_KEY
+      value: not-really-secret
+`
+	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(LoadOptions{Config: filePath, ValidateSchema: true})
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	secrets := cfg.Env.Secrets
+	if len(secrets) != 2 {
+		t.Fatalf("expected 2 secrets, got %v", secrets)
+	}
+	if secrets[0].Name != "API_TOKEN" || secrets[0].Value != nil {
+		t.Errorf("secrets[0] = %+v, want API_TOKEN without a value", secrets[0])
+	}
+	if value, ok := cfg.Env.InlineSecret("DEMO_KEY"); !ok || value != "not-really-secret" {
+		t.Errorf("InlineSecret(DEMO_KEY) = %q, %v, want the inline value", value, ok)
+	}
+	if _, ok := cfg.Env.InlineSecret("API_TOKEN"); ok {
+		t.Error("InlineSecret(API_TOKEN) found a value for a name-only secret")
+	}
+
+	// Secrets without a value are written back as plain names
+	data, err := cfg.ToYAML()
+	if err != nil {
+		t.Fatalf("ToYAML() unexpected error: %v", err)
+	}
+	for _, want := range []string{"- API_TOKEN\n", "- name: DEMO_KEY\n", "value: not-really-secret\n"} {
+		if !strings.Contains(data, want) {
+			t.Errorf("ToYAML() = %s, want it to contain %q", data, want)
+		}
+	}
+
+	bad := `env:
+  secrets:
+    - name: DEMO_KEY
+      val: oops
+`
+	if err := os.WriteFile(filePath, []byte(bad), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(LoadOptions{Config: filePath}); err == nil || !strings.Contains(err.Error(), "field val not found") {
+		t.Errorf("Load() error = %v, want unknown field val", err)
+	}
+}
+
 func TestLoadUnknownFileInDirectory(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "config-unknown-file-test")
 	if err != nil {
@@ -321,7 +530,7 @@ func TestLoadUnknownFileInDirectory(t *testing.T) {
 		t.Fatalf("failed to write file: %v", err)
 	}
 
-	_, err = Load(LoadOptions{Dir: tmpDir})
+	_, err = Load(LoadOptions{Dir: []string{tmpDir}})
 	if err == nil {
 		t.Error("expected error for unknown file, got nil")
 	}
@@ -405,6 +614,239 @@ repo:
 	}
 }
 
+func TestLoadFileWithDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Extends resolve relative to the base file
+	sharedContent := `
+repo:
+  allow_merge_commit: false
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "shared.yaml"), []byte(sharedContent), 0o644); err != nil {
+		t.Fatalf("failed to write shared file: %v", err)
+	}
+
+	baseContent := `
+extends:
+  - ./shared.yaml
+repo:
+  description: "From file"
+  visibility: public
+topics:
+  - from-file
+branch_protection:
+  main:
+    required_reviews: 1
+`
+	basePath := filepath.Join(tmpDir, "base.yaml")
+	if err := os.WriteFile(basePath, []byte(baseContent), 0o644); err != nil {
+		t.Fatalf("failed to write base file: %v", err)
+	}
+
+	overridesDir := filepath.Join(tmpDir, "overrides")
+	if err := os.MkdirAll(overridesDir, 0o755); err != nil {
+		t.Fatalf("failed to create overrides dir: %v", err)
+	}
+	files := map[string]string{
+		"repo.yaml": `
+repo:
+  visibility: private
+`,
+		"labels.yaml": `
+labels:
+  items:
+    - name: bug
+      color: ff0000
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(overridesDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg, err := Load(LoadOptions{Config: basePath, Dir: []string{overridesDir}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Directory section overrides the file
+	if cfg.Repo.Visibility == nil || *cfg.Repo.Visibility != "private" {
+		t.Errorf("expected visibility 'private', got %v", cfg.Repo.Visibility)
+	}
+
+	// File settings the directory doesn't set are kept
+	if cfg.Repo.Description == nil || *cfg.Repo.Description != "From file" {
+		t.Errorf("expected description 'From file', got %v", cfg.Repo.Description)
+	}
+	if len(cfg.Topics) != 1 || cfg.Topics[0] != "from-file" {
+		t.Errorf("expected topics from file, got %v", cfg.Topics)
+	}
+	if cfg.BranchProtection["main"] == nil || cfg.BranchProtection["main"].RequiredReviews == nil || *cfg.BranchProtection["main"].RequiredReviews != 1 {
+		t.Error("expected branch protection from file")
+	}
+
+	// Sections only in the directory are added
+	if cfg.Labels == nil || len(cfg.Labels.Items) != 1 || cfg.Labels.Items[0].Name != "bug" {
+		t.Errorf("expected labels from directory, got %+v", cfg.Labels)
+	}
+
+	// Extends of the file are resolved
+	if cfg.Repo.AllowMergeCommit == nil || *cfg.Repo.AllowMergeCommit {
+		t.Errorf("expected allow_merge_commit false from extends, got %v", cfg.Repo.AllowMergeCommit)
+	}
+
+	// Without the directory, the file alone is used
+	cfg, err = Load(LoadOptions{Config: basePath})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Repo.Visibility == nil || *cfg.Repo.Visibility != "public" {
+		t.Errorf("expected visibility 'public', got %v", cfg.Repo.Visibility)
+	}
+}
+
+func TestLoadMultipleDirectories(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"base/repo.yaml": `
+repo:
+  description: "From base"
+  visibility: public
+  allow_merge_commit: false
+`,
+		"base/topics.yaml": `
+topics:
+  - from-base
+`,
+		"base/labels.yaml": `
+labels:
+  from: ../shared-labels.yaml
+`,
+		"shared-labels.yaml": `
+items:
+  - name: bug
+    color: d73a4a
+`,
+		"overrides/repo.yaml": `
+repo:
+  visibility: private
+  allow_merge_commit: true
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg, err := Load(LoadOptions{Dir: []string{filepath.Join(tmpDir, "base"), filepath.Join(tmpDir, "overrides")}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The later directory wins
+	if cfg.Repo.Visibility == nil || *cfg.Repo.Visibility != "private" {
+		t.Errorf("expected visibility 'private', got %v", cfg.Repo.Visibility)
+	}
+	if cfg.Repo.AllowMergeCommit == nil || !*cfg.Repo.AllowMergeCommit {
+		t.Errorf("expected allow_merge_commit true, got %v", cfg.Repo.AllowMergeCommit)
+	}
+
+	// Settings only the first directory sets are kept
+	if cfg.Repo.Description == nil || *cfg.Repo.Description != "From base" {
+		t.Errorf("expected description 'From base', got %v", cfg.Repo.Description)
+	}
+	if len(cfg.Topics) != 1 || cfg.Topics[0] != "from-base" {
+		t.Errorf("expected topics from base, got %v", cfg.Topics)
+	}
+
+	// labels.from is relative to the directory that references it
+	if cfg.Labels == nil || len(cfg.Labels.Items) != 1 || cfg.Labels.Items[0].Name != "bug" {
+		t.Errorf("expected labels imported by base, got %+v", cfg.Labels)
+	}
+}
+
+func TestLoadFromStdin(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "config-stdin-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	baseContent := `
+repo:
+  visibility: public
+  allow_merge_commit: false
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "base.yaml"), []byte(baseContent), 0o644); err != nil {
+		t.Fatalf("failed to write base file: %v", err)
+	}
+
+	// A default config on disk must be ignored when reading stdin
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".github"), 0o755); err != nil {
+		t.Fatalf("failed to create .github dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, DefaultSingleFile), []byte("topics:\n  - from-file\n"), 0o644); err != nil {
+		t.Fatalf("failed to write default config: %v", err)
+	}
+
+	oldWd, _ := os.Getwd()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+	defer os.Chdir(oldWd)
+
+	t.Run("reads config and resolves extends from cwd", func(t *testing.T) {
+		stdin := strings.NewReader(`
+extends:
+  - ./base.yaml
+repo:
+  description: "From stdin"
+topics:
+  - piped
+`)
+		cfg, err := Load(LoadOptions{Stdin: stdin})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Repo == nil || cfg.Repo.Description == nil || *cfg.Repo.Description != "From stdin" {
+			t.Errorf("expected description 'From stdin', got %+v", cfg.Repo)
+		}
+		if cfg.Repo.Visibility == nil || *cfg.Repo.Visibility != "public" {
+			t.Errorf("expected visibility 'public' from base, got %v", cfg.Repo.Visibility)
+		}
+		if len(cfg.Topics) != 1 || cfg.Topics[0] != "piped" {
+			t.Errorf("expected topics [piped], got %v", cfg.Topics)
+		}
+	})
+
+	t.Run("rejects unknown fields", func(t *testing.T) {
+		_, err := Load(LoadOptions{Stdin: strings.NewReader("repo:\n  unknown_field: true\n")})
+		if err == nil {
+			t.Fatal("expected error for unknown field, got nil")
+		}
+		if !strings.Contains(err.Error(), StdinName) {
+			t.Errorf("expected error to mention %s, got %v", StdinName, err)
+		}
+	})
+
+	t.Run("empty input is an empty config", func(t *testing.T) {
+		cfg, err := Load(LoadOptions{Stdin: strings.NewReader("")})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Repo != nil || len(cfg.Topics) != 0 {
+			t.Errorf("expected empty config, got %+v", cfg)
+		}
+	})
+}
+
 func TestToYAML(t *testing.T) {
 	desc := "Test description"
 	visibility := "public"
@@ -425,3 +867,39 @@ func TestToYAML(t *testing.T) {
 		t.Error("expected non-empty YAML output")
 	}
 }
+
+func TestLoadLabelsFrom(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "shared"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	shared := `labels:
+  items:
+    - name: bug
+      color: d73a4a
+    - name: triage
+      color: ededed
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "shared", "labels.yaml"), []byte(shared), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	filePath := filepath.Join(tmpDir, "config.yaml")
+	content := `labels:
+  from: shared/labels.yaml
+  items:
+    - name: triage
+      color: fbca04
+`
+	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(LoadOptions{Config: filePath, ValidateSchema: true})
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	items := cfg.Labels.Items
+	if len(items) != 2 || items[0].Name != "bug" || items[1].Name != "triage" || items[1].Color != "fbca04" {
+		t.Errorf("labels = %+v, want imported bug and inline triage", items)
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// checkLocked returns a ValidationError if override changes a setting that base locks.
+// A lock covers the paths it matches and everything below them, so "repo" locks every
+// repository setting. Setting a locked path to the value base already has is allowed.
+func checkLocked(base, override *Config) error {
+	if len(base.Locked) == 0 {
+		return nil
+	}
+	baseValues, err := settingValues(base)
+	if err != nil {
+		return err
+	}
+	overrideValues, err := settingValues(override)
+	if err != nil {
+		return err
+	}
+
+	settings := make([]string, 0, len(overrideValues))
+	for setting := range overrideValues {
+		settings = append(settings, setting)
+	}
+	sort.Strings(settings)
+
+	for _, setting := range settings {
+		pattern, ok := lockingPattern(base.Locked, setting)
+		if !ok {
+			continue
+		}
+		if baseValue, set := baseValues[setting]; set && reflect.DeepEqual(baseValue, overrideValues[setting]) {
+			continue
+		}
+		return apperrors.NewValidationError(setting, fmt.Sprintf("locked by %q in an extended config and can't be overridden", pattern))
+	}
+	return nil
+}
+
+// lockingPattern returns the lock pattern matching setting or one of its parents
+func lockingPattern(locked []string, setting string) (string, bool) {
+	for _, pattern := range locked {
+		for prefix := setting; ; {
+			if ok, err := matchIgnorePattern(pattern, prefix); err == nil && ok {
+				return pattern, true
+			}
+			i := strings.LastIndex(prefix, ".")
+			if i < 0 {
+				break
+			}
+			prefix = prefix[:i]
+		}
+	}
+	return "", false
+}
+
+// settingValues flattens the settings of cfg into dotted paths by their YAML names,
+// e.g. "branch_protection.main.required_reviews". Lists are single values.
+func settingValues(cfg *Config) (map[string]interface{}, error) {
+	settings := *cfg
+	settings.Extends = nil
+	settings.Locked = nil
+
+	data, err := yaml.Marshal(&settings)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	flattenSettings(fields, "", values)
+	return values, nil
+}
+
+func flattenSettings(fields map[string]interface{}, prefix string, values map[string]interface{}) {
+	for key, value := range fields {
+		setting := key
+		if prefix != "" {
+			setting = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenSettings(nested, setting, values)
+			continue
+		}
+		values[setting] = value
+	}
+}
+
+// appendUnique appends the values that list doesn't contain yet
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -45,6 +45,43 @@ func mergeConfigs(dst, src *Config) {
 		}
 		mergeActionsConfig(dst.Actions, src.Actions)
 	}
+
+	if src.Pages != nil {
+		if dst.Pages == nil {
+			dst.Pages = &PagesConfig{}
+		}
+		mergePagesConfig(dst.Pages, src.Pages)
+	}
+
+	if src.Templates != nil {
+		if dst.Templates == nil {
+			dst.Templates = &TemplatesConfig{}
+		}
+		mergeTemplatesConfig(dst.Templates, src.Templates)
+	}
+
+	if src.Org != nil {
+		if dst.Org == nil {
+			dst.Org = &OrgConfig{}
+		}
+		mergeOrgConfig(dst.Org, src.Org)
+	}
+
+	if len(src.Ignore) > 0 {
+		dst.Ignore = src.Ignore
+	}
+
+	// Locks add up, so a config can't drop a lock it inherited
+	dst.Locked = appendUnique(dst.Locked, src.Locked...)
+
+	for setting, reason := range src.Meta {
+		if dst.Meta == nil {
+			dst.Meta = make(map[string]string)
+		}
+		dst.Meta[setting] = reason
+	}
+
+	dst.documents = append(dst.documents, src.documents...)
 }
 
 // mergeRepoConfig merges repo configurations
@@ -73,20 +110,47 @@ func mergeRepoConfig(dst, src *RepoConfig) {
 	if src.AllowUpdateBranch != nil {
 		dst.AllowUpdateBranch = src.AllowUpdateBranch
 	}
+	if src.AllowAutoMerge != nil {
+		dst.AllowAutoMerge = src.AllowAutoMerge
+	}
+	if src.UseSquashPRTitle != nil {
+		dst.UseSquashPRTitle = src.UseSquashPRTitle
+	}
+	if src.WebCommitSignoffRequired != nil {
+		dst.WebCommitSignoffRequired = src.WebCommitSignoffRequired
+	}
+	if src.IsTemplate != nil {
+		dst.IsTemplate = src.IsTemplate
+	}
+	if src.SocialPreviewImage != nil {
+		dst.SocialPreviewImage = src.SocialPreviewImage
+	}
+	if src.Archived != nil {
+		dst.Archived = src.Archived
+	}
 }
 
 // mergeLabelsConfig merges labels configurations
 func mergeLabelsConfig(dst, src *LabelsConfig) {
+	if src.From != "" {
+		dst.From = src.From
+	}
 	if src.ReplaceDefault {
 		dst.ReplaceDefault = src.ReplaceDefault
 	}
 	if len(src.Items) > 0 {
 		dst.Items = src.Items
 	}
+	if len(src.Exclude) > 0 {
+		dst.Exclude = src.Exclude
+	}
 }
 
 // mergeBranchRule merges branch protection rules
 func mergeBranchRule(dst, src *BranchRule) {
+	if src.RequireReviews != nil {
+		dst.RequireReviews = src.RequireReviews
+	}
 	if src.RequiredReviews != nil {
 		dst.RequiredReviews = src.RequiredReviews
 	}
@@ -105,6 +169,9 @@ func mergeBranchRule(dst, src *BranchRule) {
 	if src.StrictStatusChecks != nil {
 		dst.StrictStatusChecks = src.StrictStatusChecks
 	}
+	if src.StatusChecksOrdered != nil {
+		dst.StatusChecksOrdered = src.StatusChecksOrdered
+	}
 	if len(src.RequiredDeployments) > 0 {
 		dst.RequiredDeployments = src.RequiredDeployments
 	}
@@ -174,4 +241,66 @@ func mergeActionsConfig(dst, src *ActionsConfig) {
 	if src.CanApprovePullRequestReviews != nil {
 		dst.CanApprovePullRequestReviews = src.CanApprovePullRequestReviews
 	}
+	if src.ArtifactRetentionDays != nil {
+		dst.ArtifactRetentionDays = src.ArtifactRetentionDays
+	}
+	if src.AccessLevel != nil {
+		dst.AccessLevel = src.AccessLevel
+	}
+}
+
+// mergePagesConfig merges Pages configurations
+func mergePagesConfig(dst, src *PagesConfig) {
+	if src.BuildType != nil {
+		dst.BuildType = src.BuildType
+	}
+	if src.Source != nil {
+		if dst.Source == nil {
+			dst.Source = &PagesSourceConfig{}
+		}
+		if src.Source.Branch != nil {
+			dst.Source.Branch = src.Source.Branch
+		}
+		if src.Source.Path != nil {
+			dst.Source.Path = src.Source.Path
+		}
+	}
+	if src.Cname != nil {
+		dst.Cname = src.Cname
+	}
+	if src.HttpsEnforced != nil {
+		dst.HttpsEnforced = src.HttpsEnforced
+	}
+}
+
+// mergeTemplatesConfig merges template configurations
+func mergeTemplatesConfig(dst, src *TemplatesConfig) {
+	if src.Message != "" {
+		dst.Message = src.Message
+	}
+	if len(src.Files) > 0 {
+		dst.Files = src.Files
+	}
+}
+
+// mergeOrgConfig merges organization configurations
+func mergeOrgConfig(dst, src *OrgConfig) {
+	if src.Actions == nil {
+		return
+	}
+	if dst.Actions == nil {
+		dst.Actions = &OrgActionsConfig{}
+	}
+	if src.Actions.EnabledRepositories != nil {
+		dst.Actions.EnabledRepositories = src.Actions.EnabledRepositories
+	}
+	if src.Actions.AllowedActions != nil {
+		dst.Actions.AllowedActions = src.Actions.AllowedActions
+	}
+	if src.Actions.DefaultWorkflowPermissions != nil {
+		dst.Actions.DefaultWorkflowPermissions = src.Actions.DefaultWorkflowPermissions
+	}
+	if src.Actions.CanApprovePullRequestReviews != nil {
+		dst.Actions.CanApprovePullRequestReviews = src.Actions.CanApprovePullRequestReviews
+	}
 }
@@ -1,6 +1,9 @@
 package config
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func ptr(s string) *string {
 	return &s
@@ -68,7 +71,7 @@ func TestMergeConfigsNilDst(t *testing.T) {
 		},
 		Env: &EnvConfig{
 			Variables: map[string]string{"NODE_ENV": "production"},
-			Secrets:   []string{"SECRET_KEY"},
+			Secrets:   []Secret{{Name: "SECRET_KEY"}},
 		},
 		Actions: &ActionsConfig{
 			Enabled: ptrBool(true),
@@ -111,17 +114,29 @@ func TestMergeConfigsTopics(t *testing.T) {
 	}
 }
 
+func TestMergeConfigsMeta(t *testing.T) {
+	dst := &Config{Meta: map[string]string{"repo.visibility": "base reason", "repo.homepage": "kept"}}
+	src := &Config{Meta: map[string]string{"repo.visibility": "local reason"}}
+
+	mergeConfigs(dst, src)
+
+	want := map[string]string{"repo.visibility": "local reason", "repo.homepage": "kept"}
+	if !reflect.DeepEqual(dst.Meta, want) {
+		t.Errorf("Meta = %v, want %v", dst.Meta, want)
+	}
+}
+
 func TestMergeConfigsEnv(t *testing.T) {
 	dst := &Config{
 		Env: &EnvConfig{
 			Variables: map[string]string{"OLD_VAR": "old"},
-			Secrets:   []string{"OLD_SECRET"},
+			Secrets:   []Secret{{Name: "OLD_SECRET"}},
 		},
 	}
 	src := &Config{
 		Env: &EnvConfig{
 			Variables: map[string]string{"NEW_VAR": "new", "OLD_VAR": "updated"},
-			Secrets:   []string{"NEW_SECRET_1", "NEW_SECRET_2"},
+			Secrets:   []Secret{{Name: "NEW_SECRET_1"}, {Name: "NEW_SECRET_2"}},
 		},
 	}
 
@@ -166,6 +181,8 @@ func TestMergeRepoConfig(t *testing.T) {
 				AllowSquashMerge:    ptrBool(false),
 				DeleteBranchOnMerge: ptrBool(true),
 				AllowUpdateBranch:   ptrBool(true),
+				AllowAutoMerge:      ptrBool(true),
+				UseSquashPRTitle:    ptrBool(true),
 			},
 			checkDst: func(t *testing.T, dst *RepoConfig) {
 				if *dst.Description != "new" {
@@ -192,6 +209,12 @@ func TestMergeRepoConfig(t *testing.T) {
 				if *dst.AllowUpdateBranch != true {
 					t.Error("AllowUpdateBranch not overridden")
 				}
+				if dst.AllowAutoMerge == nil || *dst.AllowAutoMerge != true {
+					t.Error("AllowAutoMerge not merged")
+				}
+				if dst.UseSquashPRTitle == nil || *dst.UseSquashPRTitle != true {
+					t.Error("UseSquashPRTitle not merged")
+				}
 			},
 		},
 		{
@@ -291,14 +314,14 @@ func TestMergeBranchRule(t *testing.T) {
 			dst: &BranchRule{
 				RequiredReviews:     ptrInt(1),
 				DismissStaleReviews: ptrBool(false),
-				StatusChecks:        []string{"test"},
+				StatusChecks:        []StatusCheck{{Context: "test"}},
 			},
 			src: &BranchRule{
 				RequiredReviews:      ptrInt(2),
 				DismissStaleReviews:  ptrBool(true),
 				RequireCodeOwner:     ptrBool(true),
 				RequireStatusChecks:  ptrBool(true),
-				StatusChecks:         []string{"build", "lint"},
+				StatusChecks:         []StatusCheck{{Context: "build"}, {Context: "lint"}},
 				StrictStatusChecks:   ptrBool(true),
 				RequiredDeployments:  []string{"staging"},
 				RequireSignedCommits: ptrBool(true),
@@ -322,7 +345,7 @@ func TestMergeBranchRule(t *testing.T) {
 				if !*dst.RequireStatusChecks {
 					t.Error("RequireStatusChecks not set")
 				}
-				if len(dst.StatusChecks) != 2 || dst.StatusChecks[0] != "build" {
+				if len(dst.StatusChecks) != 2 || dst.StatusChecks[0].Context != "build" {
 					t.Error("StatusChecks not overridden")
 				}
 				if !*dst.StrictStatusChecks {
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GitHubRefScheme prefixes config sources read from another repository through the
+// contents API, e.g. gh://owner/repo/.github/repo-settings.yaml@v1.2.0
+const GitHubRefScheme = "gh://"
+
+// githubRef is a file in a repository at a branch, tag or commit.
+// An empty Ref means the repository's default branch.
+type githubRef struct {
+	Owner string
+	Repo  string
+	Path  string
+	Ref   string
+}
+
+// contentsFetcher reads a contents API endpoint and returns the response body.
+// A missing file is reported as ErrFileNotFound.
+type contentsFetcher func(endpoint string) ([]byte, error)
+
+// newContentsFetcher binds LoadOptions.FetchGitHub to ctx. Without FetchGitHub,
+// the returned fetcher is nil and gh:// sources can't be loaded.
+func newContentsFetcher(ctx context.Context, fetch func(ctx context.Context, endpoint string) ([]byte, error)) contentsFetcher {
+	if fetch == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return func(endpoint string) ([]byte, error) {
+		return fetch(ctx, endpoint)
+	}
+}
+
+// isGitHubRef reports whether s is a gh:// config source
+func isGitHubRef(s string) bool {
+	return strings.HasPrefix(s, GitHubRefScheme)
+}
+
+// parseGitHubRef parses gh://owner/repo/path[@ref]
+func parseGitHubRef(s string) (githubRef, error) {
+	rest := strings.TrimPrefix(s, GitHubRefScheme)
+	var ref string
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		rest, ref = rest[:i], rest[i+1:]
+		if ref == "" {
+			return githubRef{}, fmt.Errorf("invalid config source %q: empty ref after '@'", s)
+		}
+	}
+
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) < 3 || parts[0] == "" || parts[1] == "" || strings.Trim(parts[2], "/") == "" {
+		return githubRef{}, fmt.Errorf("invalid config source %q: expected %sowner/repo/path[@ref]", s, GitHubRefScheme)
+	}
+	return githubRef{Owner: parts[0], Repo: parts[1], Path: strings.Trim(parts[2], "/"), Ref: ref}, nil
+}
+
+// String formats r as gh://owner/repo/path[@ref]
+func (r githubRef) String() string {
+	s := GitHubRefScheme + r.Owner + "/" + r.Repo + "/" + r.Path
+	if r.Ref != "" {
+		s += "@" + r.Ref
+	}
+	return s
+}
+
+// dir returns the directory holding r, in the same repository and ref
+func (r githubRef) dir() githubRef {
+	r.Path = path.Dir(r.Path)
+	return r
+}
+
+// join resolves rel against r as a directory, in the same repository and ref
+func (r githubRef) join(rel string) githubRef {
+	r.Path = path.Join(r.Path, rel)
+	return r
+}
+
+// endpoint returns the contents API endpoint of r
+func (r githubRef) endpoint() string {
+	segments := strings.Split(r.Path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	endpoint := fmt.Sprintf("repos/%s/%s/contents/%s", r.Owner, r.Repo, strings.Join(segments, "/"))
+	if r.Ref != "" {
+		endpoint += "?ref=" + url.QueryEscape(r.Ref)
+	}
+	return endpoint
+}
+
+// fetchGitHubRef reads the file at a gh:// source and decodes its content
+func fetchGitHubRef(fetch contentsFetcher, source string) ([]byte, error) {
+	r, err := parseGitHubRef(source)
+	if err != nil {
+		return nil, err
+	}
+	if fetch == nil {
+		return nil, fmt.Errorf("failed to fetch %s: %s sources aren't supported by this command", source, GitHubRefScheme)
+	}
+
+	body, err := fetch(r.endpoint())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", source, err)
+	}
+
+	var file struct {
+		Type     string `json:"type"`
+		Encoding string `json:"encoding"`
+		Content  string `json:"content"`
+	}
+	if err := json.Unmarshal(body, &file); err != nil {
+		// The contents API lists a directory as an array
+		if strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+			return nil, fmt.Errorf("failed to fetch %s: it is a directory, not a file", source)
+		}
+		return nil, fmt.Errorf("failed to parse contents of %s: %w", source, err)
+	}
+	if file.Type != "file" {
+		return nil, fmt.Errorf("failed to fetch %s: it is a %s, not a file", source, file.Type)
+	}
+	// Files over 1 MB come without content
+	if file.Encoding != "base64" {
+		return nil, fmt.Errorf("failed to fetch %s: unsupported content encoding %q", source, file.Encoding)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode contents of %s: %w", source, err)
+	}
+	return data, nil
+}
+
+// configDir returns the base path relative extends and labels.from are resolved against:
+// the directory of a local config file, or of a gh:// source in the same repository and ref
+func configDir(configPath string) string {
+	if isGitHubRef(configPath) {
+		if r, err := parseGitHubRef(configPath); err == nil {
+			return r.dir().String()
+		}
+		return configPath
+	}
+	return filepath.Dir(configPath)
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+)
+
+// stubContents returns a LoadOptions.FetchGitHub for a contents API that serves files
+// by endpoint, and the endpoints that were requested
+func stubContents(files map[string]string) (func(ctx context.Context, endpoint string) ([]byte, error), *[]string) {
+	var requested []string
+	fetch := func(ctx context.Context, endpoint string) ([]byte, error) {
+		requested = append(requested, endpoint)
+		content, ok := files[endpoint]
+		if !ok {
+			return nil, apperrors.ErrFileNotFound
+		}
+		encoded := base64.StdEncoding.EncodeToString([]byte(content))
+		return []byte(fmt.Sprintf(`{"type":"file","encoding":"base64","content":%q}`, encoded+"\n")), nil
+	}
+	return fetch, &requested
+}
+
+func TestParseGitHubRef(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected githubRef
+		wantErr  bool
+	}{
+		{"gh://acme/settings/.github/repo-settings.yaml@v1", githubRef{"acme", "settings", ".github/repo-settings.yaml", "v1"}, false},
+		{"gh://acme/settings/base.yaml", githubRef{"acme", "settings", "base.yaml", ""}, false},
+		{"gh://acme/settings/base.yaml@feature/x", githubRef{"acme", "settings", "base.yaml", "feature/x"}, false},
+		{"gh://acme/settings/base.yaml@", githubRef{}, true},
+		{"gh://acme/settings", githubRef{}, true},
+		{"gh://acme//base.yaml", githubRef{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			result, err := parseGitHubRef(tt.source)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseGitHubRef(%q) expected error", tt.source)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("parseGitHubRef(%q) = %+v, want %+v", tt.source, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadFromGitHubRef(t *testing.T) {
+	t.Run("ref selects the version", func(t *testing.T) {
+		fetch, requested := stubContents(map[string]string{
+			"repos/acme/settings/contents/.github/repo-settings.yaml?ref=v1": "repo:\n  visibility: private\n",
+			"repos/acme/settings/contents/.github/repo-settings.yaml":        "repo:\n  visibility: public\n",
+		})
+
+		cfg, err := Load(LoadOptions{Config: "gh://acme/settings/.github/repo-settings.yaml@v1", FetchGitHub: fetch})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Repo == nil || cfg.Repo.Visibility == nil || *cfg.Repo.Visibility != "private" {
+			t.Errorf("expected visibility 'private' from ref v1")
+		}
+		if len(*requested) != 1 {
+			t.Errorf("expected 1 request, got %v", *requested)
+		}
+	})
+
+	t.Run("default branch without ref", func(t *testing.T) {
+		fetch, _ := stubContents(map[string]string{
+			"repos/acme/settings/contents/.github/repo-settings.yaml": "repo:\n  visibility: public\n",
+		})
+
+		cfg, err := Load(LoadOptions{Config: "gh://acme/settings/.github/repo-settings.yaml", FetchGitHub: fetch})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Repo == nil || cfg.Repo.Visibility == nil || *cfg.Repo.Visibility != "public" {
+			t.Errorf("expected visibility 'public'")
+		}
+	})
+
+	t.Run("relative extends resolve in the same repository and ref", func(t *testing.T) {
+		fetch, requested := stubContents(map[string]string{
+			"repos/acme/settings/contents/teams/web.yaml?ref=v2": "extends:\n  - ../base.yaml\nrepo:\n  allow_auto_merge: true\n",
+			"repos/acme/settings/contents/base.yaml?ref=v2":      "repo:\n  visibility: private\n  allow_auto_merge: false\n",
+		})
+
+		cfg, err := Load(LoadOptions{Config: "gh://acme/settings/teams/web.yaml@v2", FetchGitHub: fetch})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Repo == nil || cfg.Repo.Visibility == nil || *cfg.Repo.Visibility != "private" {
+			t.Errorf("expected visibility 'private' from base")
+		}
+		if cfg.Repo.AllowAutoMerge == nil || !*cfg.Repo.AllowAutoMerge {
+			t.Errorf("expected allow_auto_merge from the local config to win")
+		}
+		if got := strings.Join(*requested, ","); !strings.Contains(got, "contents/base.yaml?ref=v2") {
+			t.Errorf("expected base to be fetched at ref v2, got %s", got)
+		}
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		fetch, _ := stubContents(map[string]string{})
+
+		_, err := Load(LoadOptions{Config: "gh://acme/settings/missing.yaml@main", FetchGitHub: fetch})
+		if err == nil {
+			t.Fatal("expected error for missing path")
+		}
+		if !errors.Is(err, apperrors.ErrFileNotFound) {
+			t.Errorf("expected ErrFileNotFound, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "gh://acme/settings/missing.yaml@main") {
+			t.Errorf("expected error to name the source, got %v", err)
+		}
+	})
+
+	t.Run("directory instead of file", func(t *testing.T) {
+		fetch := func(ctx context.Context, endpoint string) ([]byte, error) {
+			return []byte(`[{"type":"file","name":"repo.yaml"}]`), nil
+		}
+
+		_, err := Load(LoadOptions{Config: "gh://acme/settings/.github/repo-settings", FetchGitHub: fetch})
+		if err == nil || !strings.Contains(err.Error(), "directory") {
+			t.Errorf("expected directory error, got %v", err)
+		}
+	})
+
+	t.Run("requests use the load context", func(t *testing.T) {
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "load")
+		serve, _ := stubContents(map[string]string{
+			"repos/acme/settings/contents/base.yaml": "repo:\n  visibility: public\n",
+		})
+		var got interface{}
+		fetch := func(ctx context.Context, endpoint string) ([]byte, error) {
+			got = ctx.Value(ctxKey{})
+			return serve(ctx, endpoint)
+		}
+
+		if _, err := Load(LoadOptions{Config: "gh://acme/settings/base.yaml", Context: ctx, FetchGitHub: fetch}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "load" {
+			t.Errorf("fetch got context value %v, want the load context", got)
+		}
+	})
+
+	t.Run("without a fetcher", func(t *testing.T) {
+		_, err := Load(LoadOptions{Config: "gh://acme/settings/base.yaml"})
+		if err == nil || !strings.Contains(err.Error(), "aren't supported") {
+			t.Errorf("expected an unsupported source error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/invopop/jsonschema"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+	validator "github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// Schema metadata published with schema.json
+const (
+	SchemaID          = "https://raw.githubusercontent.com/myzkey/gh-repo-settings/main/schema.json"
+	SchemaTitle       = "gh-repo-settings configuration"
+	schemaDescription = "Configuration schema for gh-repo-settings - GitHub repository settings management tool"
+)
+
+// schemaJSON is a copy of the repository's schema.json, kept in sync by `make schema`
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+// GenerateSchema reflects the JSON Schema for Config, formatted as in schema.json
+func GenerateSchema() ([]byte, error) {
+	r := new(jsonschema.Reflector)
+	r.ExpandedStruct = true
+
+	schema := r.Reflect(&Config{})
+	schema.ID = SchemaID
+	schema.Title = SchemaTitle
+	schema.Description = schemaDescription
+
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// schemaDocument is a raw config document as read, kept for schema validation.
+// Section is set for a section file of a config directory, whose content may be
+// written bare or under the section's key.
+type schemaDocument struct {
+	name    string
+	section string
+	data    []byte
+}
+
+var (
+	compileSchemaOnce sync.Once
+	compiledSchema    *validator.Schema
+	compileSchemaErr  error
+)
+
+// embeddedSchema compiles the embedded schema once
+func embeddedSchema() (*validator.Schema, error) {
+	compileSchemaOnce.Do(func() {
+		compiler := validator.NewCompiler()
+		compiler.Draft = validator.Draft2020
+		if err := compiler.AddResource(SchemaID, bytes.NewReader(schemaJSON)); err != nil {
+			compileSchemaErr = err
+			return
+		}
+		compiledSchema, compileSchemaErr = compiler.Compile(SchemaID)
+	})
+	return compiledSchema, compileSchemaErr
+}
+
+// ValidateSchema validates a raw YAML config document against the embedded JSON Schema,
+// so keys the config types don't know are reported too. name identifies the document in
+// the error. All violations are reported together, one per path.
+func ValidateSchema(name string, data []byte) error {
+	return validateDocument(schemaDocument{name: name, data: data})
+}
+
+// validateDocument validates doc against the embedded JSON Schema
+func validateDocument(doc schemaDocument) error {
+	schema, err := embeddedSchema()
+	if err != nil {
+		return fmt.Errorf("failed to compile embedded schema: %w", err)
+	}
+
+	value, err := schemaValue(doc)
+	if err != nil {
+		return apperrors.NewConfigError(doc.name, err.Error(), apperrors.ErrInvalidConfig)
+	}
+	if value == nil {
+		// Empty document
+		return nil
+	}
+
+	err = schema.Validate(value)
+	var validationErr *validator.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	return apperrors.NewConfigError(
+		doc.name,
+		"schema validation failed: "+strings.Join(schemaViolations(validationErr), "; "),
+		apperrors.ErrInvalidConfig,
+	)
+}
+
+// schemaValue converts a YAML document to the JSON value the schema describes.
+// A section file's content is put under its key unless it already is.
+func schemaValue(doc schemaDocument) (interface{}, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(doc.data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config for schema validation: %w", err)
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	raw = jsonCompatible(raw)
+	if doc.section != "" {
+		if obj, ok := raw.(map[string]interface{}); !ok || obj[doc.section] == nil {
+			raw = map[string]interface{}{doc.section: raw}
+		}
+	}
+
+	// A JSON round trip turns YAML scalars (ints, timestamps) into JSON ones
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config for schema validation: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to encode config for schema validation: %w", err)
+	}
+	return value, nil
+}
+
+// jsonCompatible converts the maps of a decoded YAML value to string-keyed maps
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = jsonCompatible(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			obj[fmt.Sprint(k)] = jsonCompatible(item)
+		}
+		return obj
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonCompatible(item)
+		}
+		return v
+	}
+	return value
+}
+
+// schemaViolations returns the innermost causes of err as "path message" lines,
+// sorted so they are reported in a stable order
+func schemaViolations(err *validator.ValidationError) []string {
+	if len(err.Causes) == 0 {
+		return []string{schemaPath(err.InstanceLocation) + " " + err.Message}
+	}
+	var violations []string
+	for _, cause := range err.Causes {
+		violations = append(violations, schemaViolations(cause)...)
+	}
+	sort.Strings(violations)
+	return violations
+}
+
+// schemaPath converts a JSON pointer to the dotted path used in config messages,
+// e.g. /labels/items/0/color to labels.items[0].color
+func schemaPath(pointer string) string {
+	if pointer == "" {
+		return "(root)"
+	}
+	var path strings.Builder
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		if _, err := strconv.Atoi(token); err == nil {
+			fmt.Fprintf(&path, "[%s]", token)
+			continue
+		}
+		if path.Len() > 0 {
+			path.WriteByte('.')
+		}
+		path.WriteString(token)
+	}
+	return path.String()
+}
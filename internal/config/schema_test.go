@@ -0,0 +1,186 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+)
+
+func TestEmbeddedSchemaMatchesRepositorySchema(t *testing.T) {
+	repoSchema, err := os.ReadFile(filepath.Join("..", "..", "schema.json"))
+	if err != nil {
+		t.Fatalf("failed to read schema.json: %v", err)
+	}
+	if !bytes.Equal(repoSchema, schemaJSON) {
+		t.Error("internal/config/schema.json is out of date; run `make schema`")
+	}
+
+	generated, err := GenerateSchema()
+	if err != nil {
+		t.Fatalf("GenerateSchema() error = %v", err)
+	}
+	if !bytes.Equal(generated, repoSchema) {
+		t.Error("schema.json does not match the config types; run `make schema`")
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr []string
+	}{
+		{
+			name: "valid config",
+			yaml: `repo:
+  visibility: private
+topics: [go, cli]
+labels:
+  items:
+    - name: bug
+      color: d73a4a
+actions:
+  allowed_actions: selected
+`,
+		},
+		{
+			name: "empty config",
+			yaml: "",
+		},
+		{
+			name:    "enum violation",
+			yaml:    "actions:\n  allowed_actions: everything\n",
+			wantErr: []string{"actions.allowed_actions value must be one of"},
+		},
+		{
+			name:    "pattern violation",
+			yaml:    "labels:\n  items:\n    - name: bug\n      color: red\n",
+			wantErr: []string{"labels.items[0].color does not match pattern"},
+		},
+		{
+			name:    "maximum violation in branch rule",
+			yaml:    "branch_protection:\n  main:\n    required_reviews: 7\n",
+			wantErr: []string{"branch_protection.main.required_reviews must be <= 6"},
+		},
+		{
+			name: "status checks by name and by app",
+			yaml: `branch_protection:
+  main:
+    status_checks:
+      - lint
+      - context: test
+        app_id: 15368
+`,
+		},
+		{
+			name:    "unknown key",
+			yaml:    "repo:\n  visibility: private\n  visiblity: public\n",
+			wantErr: []string{"repo additionalProperties 'visiblity' not allowed"},
+		},
+		{
+			name:    "multiple violations are all reported",
+			yaml:    "repo:\n  visibility: secret\nactions:\n  allowed_actions: everything\n",
+			wantErr: []string{"actions.allowed_actions value must be one of", "repo.visibility value must be one of"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSchema("config.yaml", []byte(tt.yaml))
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("ValidateSchema() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("ValidateSchema() expected error, got nil")
+			}
+			if !apperrors.Is(err, apperrors.ErrInvalidConfig) {
+				t.Errorf("ValidateSchema() error should wrap ErrInvalidConfig: %v", err)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("ValidateSchema() error = %q, want it to contain %q", err.Error(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadValidateSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "config.yaml")
+	content := `branch_protection:
+  main:
+    required_reviews: 7
+`
+	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Schema validation is opt-in; Validate doesn't check review counts
+	if _, err := Load(LoadOptions{Config: filePath}); err != nil {
+		t.Fatalf("Load() without ValidateSchema unexpected error: %v", err)
+	}
+
+	_, err := Load(LoadOptions{Config: filePath, ValidateSchema: true})
+	if err == nil {
+		t.Fatal("Load() with ValidateSchema expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "required_reviews") {
+		t.Errorf("Load() error = %q, want schema maximum violation", err.Error())
+	}
+}
+
+func TestLoadValidateSchemaDirectory(t *testing.T) {
+	// Section files are decoded leniently, so only the schema catches unknown keys
+	dir := t.TempDir()
+	content := "repo:\n  visibility: private\n  delete_branch_on_marge: true\n"
+	if err := os.WriteFile(filepath.Join(dir, "repo.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bare := "main:\n  required_reviews: 7\n"
+	if err := os.WriteFile(filepath.Join(dir, "branch-protection.yaml"), []byte(bare), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(LoadOptions{Dir: []string{dir}}); err != nil {
+		t.Fatalf("Load() without ValidateSchema unexpected error: %v", err)
+	}
+
+	_, err := Load(LoadOptions{Dir: []string{dir}, ValidateSchema: true})
+	if err == nil {
+		t.Fatal("Load() with ValidateSchema expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "branch_protection.main.required_reviews") {
+		t.Errorf("Load() error = %q, want the bare section validated under its key", err.Error())
+	}
+
+	if err := os.Remove(filepath.Join(dir, "branch-protection.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load(LoadOptions{Dir: []string{dir}, ValidateSchema: true})
+	if err == nil || !strings.Contains(err.Error(), "delete_branch_on_marge") {
+		t.Errorf("Load() error = %v, want the unknown key reported", err)
+	}
+}
+
+func TestRepositoryConfigsMatchSchema(t *testing.T) {
+	tests := []LoadOptions{
+		{Config: filepath.Join("..", "..", ".github", "repo-settings.yaml")},
+		{Config: filepath.Join("..", "..", "examples", "repo-settings.yaml")},
+		{Dir: []string{filepath.Join("..", "..", "examples", "repo-settings")}},
+	}
+
+	for _, opts := range tests {
+		opts.ValidateSchema = true
+		if _, err := Load(opts); err != nil {
+			t.Errorf("Load(config=%q, dir=%q) error: %v", opts.Config, opts.Dir, err)
+		}
+	}
+}
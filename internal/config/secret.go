@@ -0,0 +1,129 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// Secret is a secret to manage. In the config it is either the secret's name, whose value
+// comes from .env or a provider, or a mapping with an inline value:
+//
+//	secrets:
+//	  - API_TOKEN
+//	  - name: DEMO_KEY
+//	    value: not-really-secret
+//
+// Inline values are stored in plain text with the config, so they are only meant for
+// throwaway repositories. A value in .env or from a provider takes precedence.
+type Secret struct {
+	Name  string
+	Value *string // nil reads the value from .env or the provider
+}
+
+// secretObject is the mapping form of a Secret
+type secretObject struct {
+	Name  string  `yaml:"name" json:"name"`
+	Value *string `yaml:"value,omitempty" json:"value,omitempty"`
+}
+
+// String returns the secret's name; the value is never printed
+func (s Secret) String() string {
+	return s.Name
+}
+
+// UnmarshalYAML accepts a plain name or a {name, value} mapping
+func (s *Secret) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = Secret{}
+		return node.Decode(&s.Name)
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: secret must be a name or a mapping with name and value", node.Line)
+	}
+	// The loader's unknown field check doesn't reach into custom unmarshalers
+	for i := 0; i < len(node.Content); i += 2 {
+		if key := node.Content[i].Value; key != "name" && key != "value" {
+			return fmt.Errorf("line %d: field %s not found in type config.Secret", node.Content[i].Line, key)
+		}
+	}
+	var obj secretObject
+	if err := node.Decode(&obj); err != nil {
+		return err
+	}
+	*s = Secret(obj)
+	return nil
+}
+
+// MarshalYAML writes a secret without an inline value as its plain name
+func (s Secret) MarshalYAML() (interface{}, error) {
+	if s.Value == nil {
+		return s.Name, nil
+	}
+	return secretObject(s), nil
+}
+
+// UnmarshalJSON accepts a plain name or a {name, value} object
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*s = Secret{Name: name}
+		return nil
+	}
+	var obj secretObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("secret must be a name or an object with name and value: %w", err)
+	}
+	*s = Secret(obj)
+	return nil
+}
+
+// MarshalJSON writes a secret without an inline value as its plain name
+func (s Secret) MarshalJSON() ([]byte, error) {
+	if s.Value == nil {
+		return json.Marshal(s.Name)
+	}
+	return json.Marshal(secretObject(s))
+}
+
+// JSONSchema describes both forms of a secret
+func (Secret) JSONSchema() *jsonschema.Schema {
+	properties := jsonschema.NewProperties()
+	properties.Set("name", &jsonschema.Schema{Type: "string", Description: "Secret name"})
+	properties.Set("value", &jsonschema.Schema{Type: "string", Description: "Inline secret value stored in plain text (insecure; prefer .env or a provider)"})
+	return &jsonschema.Schema{
+		AnyOf: []*jsonschema.Schema{
+			{Type: "string", Description: "Secret name"},
+			{
+				Type:                 "object",
+				Properties:           properties,
+				Required:             []string{"name"},
+				AdditionalProperties: jsonschema.FalseSchema,
+			},
+		},
+	}
+}
+
+// SecretNames returns the names of secrets
+func SecretNames(secrets []Secret) []string {
+	if secrets == nil {
+		return nil
+	}
+	names := make([]string, len(secrets))
+	for i, s := range secrets {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// InlineSecret returns the inline value of the secret named name, if the config has one
+func (e *EnvConfig) InlineSecret(name string) (string, bool) {
+	for _, s := range e.Secrets {
+		if s.Name == name && s.Value != nil {
+			return *s.Value, true
+		}
+	}
+	return "", false
+}
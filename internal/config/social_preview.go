@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+)
+
+// MaxSocialPreviewSize is the largest social preview image GitHub accepts (1 MB)
+const MaxSocialPreviewSize = 1 << 20
+
+// socialPreviewExtensions lists the image formats GitHub accepts for social previews
+var socialPreviewExtensions = []string{".png", ".jpg", ".jpeg", ".gif"}
+
+// LoadSocialPreviewImage reads the social preview image at path.
+// Relative paths are resolved from the current directory.
+func LoadSocialPreviewImage(path string) ([]byte, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if !slices.Contains(socialPreviewExtensions, ext) {
+		return nil, apperrors.NewValidationError(
+			"repo.social_preview_image",
+			fmt.Sprintf("unsupported image type %q (valid: %s)", ext, strings.Join(socialPreviewExtensions, ", ")),
+		)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read social preview image: %w", err)
+	}
+	if info.Size() == 0 {
+		return nil, apperrors.NewValidationError("repo.social_preview_image", fmt.Sprintf("%s is empty", path))
+	}
+	if info.Size() > MaxSocialPreviewSize {
+		return nil, apperrors.NewValidationError(
+			"repo.social_preview_image",
+			fmt.Sprintf("%s is %d bytes, larger than the 1 MB limit", path, info.Size()),
+		)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read social preview image: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+)
+
+func TestLoadSocialPreviewImage(t *testing.T) {
+	dir := t.TempDir()
+	png := []byte("\x89PNG\r\n\x1a\nimage-data")
+
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	t.Run("valid image", func(t *testing.T) {
+		got, err := LoadSocialPreviewImage(write("preview.png", png))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(got, png) {
+			t.Errorf("image bytes mismatch")
+		}
+	})
+
+	t.Run("extension is case-insensitive", func(t *testing.T) {
+		if _, err := LoadSocialPreviewImage(write("preview.JPG", png)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	tests := []struct {
+		name string
+		path func() string
+	}{
+		{name: "unsupported extension", path: func() string { return write("preview.svg", png) }},
+		{name: "empty file", path: func() string { return write("empty.png", nil) }},
+		{name: "too large", path: func() string { return write("large.png", make([]byte, MaxSocialPreviewSize+1)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadSocialPreviewImage(tt.path())
+			var validationErr *apperrors.ValidationError
+			if !apperrors.As(err, &validationErr) {
+				t.Errorf("expected ValidationError, got %v", err)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := LoadSocialPreviewImage(filepath.Join(dir, "missing.png")); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// StateFileName is the name of the local apply state file
+const StateFileName = ".repo-settings-state.json"
+
+// State holds local bookkeeping written by apply.
+// Secret values can't be read back from GitHub, so apply records a salted
+// hash of each value it sets; plaintext values are never persisted.
+//
+// One state file serves every repository a config is applied to, so hashes are
+// kept per repository ("owner/repo"): setting a secret in one repository says
+// nothing about its value in another. Hashes under the older "secrets" key,
+// which weren't per repository, are ignored.
+type State struct {
+	Secrets map[string]map[string]SecretHash `json:"repo_secrets,omitempty"`
+}
+
+// SecretHash is a salted SHA-256 hash of a secret value
+type SecretHash struct {
+	Salt string `json:"salt"`
+	Hash string `json:"hash"`
+}
+
+// NewState creates an empty State
+func NewState() *State {
+	return &State{Secrets: make(map[string]map[string]SecretHash)}
+}
+
+// LoadState loads the state file next to the config
+// Returns empty State if file doesn't exist (not an error)
+func LoadState(configPath string) (*State, error) {
+	statePath := ResolveStatePath(configPath)
+
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewState(), nil
+		}
+		return nil, fmt.Errorf("failed to read state file %s: %w", statePath, err)
+	}
+
+	state := NewState()
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", statePath, err)
+	}
+	if state.Secrets == nil {
+		state.Secrets = make(map[string]map[string]SecretHash)
+	}
+	return state, nil
+}
+
+// Save writes the state file next to the config
+func (s *State) Save(configPath string) error {
+	statePath := ResolveStatePath(configPath)
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(statePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(statePath, append(data, '\n'), 0o600)
+}
+
+// ResolveStatePath determines the state file path, using the same
+// directory as the .env file
+func ResolveStatePath(configPath string) string {
+	return filepath.Join(filepath.Dir(resolveDotEnvPath(configPath)), StateFileName)
+}
+
+// RecordSecret stores a freshly salted hash of the value of secret name in repo ("owner/repo")
+func (s *State) RecordSecret(repo, name, value string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+	saltHex := hex.EncodeToString(salt)
+	if s.Secrets[repo] == nil {
+		s.Secrets[repo] = make(map[string]SecretHash)
+	}
+	s.Secrets[repo][name] = SecretHash{
+		Salt: saltHex,
+		Hash: hashSecret(saltHex, value),
+	}
+	return nil
+}
+
+// ForgetSecret removes the recorded hash of secret name in repo
+func (s *State) ForgetSecret(repo, name string) {
+	delete(s.Secrets[repo], name)
+	if len(s.Secrets[repo]) == 0 {
+		delete(s.Secrets, repo)
+	}
+}
+
+// SecretChanged reports whether value differs from the value last applied to
+// secret name in repo. known is false if no hash was recorded for it there.
+func (s *State) SecretChanged(repo, name, value string) (changed, known bool) {
+	recorded, ok := s.Secrets[repo][name]
+	if !ok {
+		return false, false
+	}
+	return hashSecret(recorded.Salt, value) != recorded.Hash, true
+}
+
+func hashSecret(salt, value string) string {
+	sum := sha256.Sum256([]byte(salt + value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStateSecretChanged(t *testing.T) {
+	state := NewState()
+	if err := state.RecordSecret("owner/repo", "API_KEY", "s3cret"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		repo        string
+		secret      string
+		value       string
+		wantChanged bool
+		wantKnown   bool
+	}{
+		{name: "hash match", repo: "owner/repo", secret: "API_KEY", value: "s3cret", wantChanged: false, wantKnown: true},
+		{name: "hash mismatch", repo: "owner/repo", secret: "API_KEY", value: "rotated", wantChanged: true, wantKnown: true},
+		{name: "unknown secret", repo: "owner/repo", secret: "OTHER", value: "s3cret", wantChanged: false, wantKnown: false},
+		{name: "other repository", repo: "owner/other", secret: "API_KEY", value: "rotated", wantChanged: false, wantKnown: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, known := state.SecretChanged(tt.repo, tt.secret, tt.value)
+			if changed != tt.wantChanged || known != tt.wantKnown {
+				t.Errorf("SecretChanged(%q, %q, %q) = (%v, %v), want (%v, %v)",
+					tt.repo, tt.secret, tt.value, changed, known, tt.wantChanged, tt.wantKnown)
+			}
+		})
+	}
+}
+
+func TestStateRecordSecretSalts(t *testing.T) {
+	a := NewState()
+	b := NewState()
+	if err := a.RecordSecret("owner/repo", "API_KEY", "same"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := b.RecordSecret("owner/repo", "API_KEY", "same"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.Secrets["owner/repo"]["API_KEY"].Hash == b.Secrets["owner/repo"]["API_KEY"].Hash {
+		t.Error("expected different hashes for independently salted records")
+	}
+}
+
+func TestStateSaveAndLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Missing file is an empty state
+	state, err := LoadState(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(state.Secrets) != 0 {
+		t.Errorf("expected empty state, got %d secrets", len(state.Secrets))
+	}
+
+	if err := state.RecordSecret("owner/repo", "API_KEY", "plaintext-value"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := state.Save(tmpDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, StateFileName))
+	if err != nil {
+		t.Fatalf("failed to read state file: %v", err)
+	}
+	if strings.Contains(string(data), "plaintext-value") {
+		t.Error("state file must not contain plaintext secret values")
+	}
+
+	loaded, err := LoadState(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changed, known := loaded.SecretChanged("owner/repo", "API_KEY", "plaintext-value"); changed || !known {
+		t.Errorf("expected recorded secret to match after reload, got changed=%v known=%v", changed, known)
+	}
+
+	loaded.ForgetSecret("owner/repo", "API_KEY")
+	if _, known := loaded.SecretChanged("owner/repo", "API_KEY", "plaintext-value"); known {
+		t.Error("expected secret to be forgotten")
+	}
+}
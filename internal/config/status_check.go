@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// StatusCheck is a required status check. In the config it is either the check's name or,
+// to require that a specific GitHub App reports it, a mapping with the app's ID:
+//
+//	status_checks:
+//	  - lint
+//	  - context: test
+//	    app_id: 15368
+type StatusCheck struct {
+	Context string
+	AppID   *int // nil accepts the check from any app
+}
+
+// statusCheckObject is the mapping form of a StatusCheck
+type statusCheckObject struct {
+	Context string `yaml:"context" json:"context"`
+	AppID   *int   `yaml:"app_id,omitempty" json:"app_id,omitempty"`
+}
+
+// String returns the check's name, followed by its app when one is required
+func (s StatusCheck) String() string {
+	if s.AppID == nil {
+		return s.Context
+	}
+	return fmt.Sprintf("%s (app %d)", s.Context, *s.AppID)
+}
+
+// UnmarshalYAML accepts a plain name or a {context, app_id} mapping
+func (s *StatusCheck) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = StatusCheck{}
+		return node.Decode(&s.Context)
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: status check must be a name or a mapping with context and app_id", node.Line)
+	}
+	// The loader's unknown field check doesn't reach into custom unmarshalers
+	for i := 0; i < len(node.Content); i += 2 {
+		if key := node.Content[i].Value; key != "context" && key != "app_id" {
+			return fmt.Errorf("line %d: field %s not found in type config.StatusCheck", node.Content[i].Line, key)
+		}
+	}
+	var obj statusCheckObject
+	if err := node.Decode(&obj); err != nil {
+		return err
+	}
+	*s = StatusCheck(obj)
+	return nil
+}
+
+// MarshalYAML writes a check without an app as its plain name
+func (s StatusCheck) MarshalYAML() (interface{}, error) {
+	if s.AppID == nil {
+		return s.Context, nil
+	}
+	return statusCheckObject(s), nil
+}
+
+// UnmarshalJSON accepts a plain name or a {context, app_id} object
+func (s *StatusCheck) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*s = StatusCheck{Context: name}
+		return nil
+	}
+	var obj statusCheckObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("status check must be a name or an object with context and app_id: %w", err)
+	}
+	*s = StatusCheck(obj)
+	return nil
+}
+
+// MarshalJSON writes a check without an app as its plain name
+func (s StatusCheck) MarshalJSON() ([]byte, error) {
+	if s.AppID == nil {
+		return json.Marshal(s.Context)
+	}
+	return json.Marshal(statusCheckObject(s))
+}
+
+// JSONSchema describes both forms of a status check
+func (StatusCheck) JSONSchema() *jsonschema.Schema {
+	properties := jsonschema.NewProperties()
+	properties.Set("context", &jsonschema.Schema{Type: "string", Description: "Status check name"})
+	properties.Set("app_id", &jsonschema.Schema{Type: "integer", Description: "ID of the GitHub App that must report the check (-1 for any app)"})
+	return &jsonschema.Schema{
+		AnyOf: []*jsonschema.Schema{
+			{Type: "string", Description: "Status check name"},
+			{
+				Type:                 "object",
+				Properties:           properties,
+				Required:             []string{"context"},
+				AdditionalProperties: jsonschema.FalseSchema,
+			},
+		},
+	}
+}
+
+// StatusCheckContexts returns the names of checks
+func StatusCheckContexts(checks []StatusCheck) []string {
+	if checks == nil {
+		return nil
+	}
+	contexts := make([]string, len(checks))
+	for i, c := range checks {
+		contexts[i] = c.Context
+	}
+	return contexts
+}
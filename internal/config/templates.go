@@ -0,0 +1,16 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// LoadContent reads the local source file of a template.
+// Relative paths are resolved from the current directory.
+func (f TemplateFile) LoadContent() ([]byte, error) {
+	data, err := os.ReadFile(f.Source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", f.Source, err)
+	}
+	return data, nil
+}
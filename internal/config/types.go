@@ -4,50 +4,68 @@ package config
 type Config struct {
 	Extends          []string               `yaml:"extends,omitempty" json:"extends,omitempty" jsonschema:"description=List of preset URLs or file paths to inherit from"`
 	Repo             *RepoConfig            `yaml:"repo,omitempty" json:"repo,omitempty" jsonschema:"description=Repository settings"`
-	Topics           []string               `yaml:"topics,omitempty" json:"topics,omitempty" jsonschema:"description=Repository topics"`
+	Topics           []string               `yaml:"topics,omitempty" json:"topics,omitempty" jsonschema:"description=Repository topics (lowercase letters/numbers/hyphens; max 50 characters each),maxItems=20"`
 	Labels           *LabelsConfig          `yaml:"labels,omitempty" json:"labels,omitempty" jsonschema:"description=Issue labels configuration"`
 	BranchProtection map[string]*BranchRule `yaml:"branch_protection,omitempty" json:"branch_protection,omitempty" jsonschema:"description=Branch protection rules keyed by branch name"`
 	Env              *EnvConfig             `yaml:"env,omitempty" json:"env,omitempty" jsonschema:"description=Environment variables and secrets configuration"`
 	Actions          *ActionsConfig         `yaml:"actions,omitempty" json:"actions,omitempty" jsonschema:"description=GitHub Actions permissions configuration"`
 	Pages            *PagesConfig           `yaml:"pages,omitempty" json:"pages,omitempty" jsonschema:"description=GitHub Pages configuration"`
+	Templates        *TemplatesConfig       `yaml:"templates,omitempty" json:"templates,omitempty" jsonschema:"description=Issue and pull request template files to sync into the repository"`
+	Org              *OrgConfig             `yaml:"org,omitempty" json:"org,omitempty" jsonschema:"description=Organization-level settings (used with --org)"`
+	Ignore           []string               `yaml:"ignore,omitempty" json:"ignore,omitempty" jsonschema:"description=Setting paths (e.g. repo.homepage or branch_protection.*.enforce_admins; globs allowed) whose drift is never planned or applied"`
+	Locked           []string               `yaml:"locked,omitempty" json:"locked,omitempty" jsonschema:"description=Setting paths (e.g. repo.visibility or branch_protection.*; globs allowed) that configs extending this one can't override"`
+	Meta             map[string]string      `yaml:"_meta,omitempty" json:"_meta,omitempty" jsonschema:"description=Reasons keyed by setting path (e.g. repo.visibility) that plan shows next to the matching change"`
+
+	// documents are the raw documents the config was loaded from, for schema validation
+	documents []schemaDocument
 }
 
 // RepoConfig represents repository settings
 type RepoConfig struct {
-	Description         *string `yaml:"description,omitempty" json:"description,omitempty" jsonschema:"description=Repository description"`
-	Homepage            *string `yaml:"homepage,omitempty" json:"homepage,omitempty" jsonschema:"description=Homepage URL"`
-	Visibility          *string `yaml:"visibility,omitempty" json:"visibility,omitempty" jsonschema:"description=Repository visibility,enum=public,enum=private,enum=internal"`
-	AllowMergeCommit    *bool   `yaml:"allow_merge_commit,omitempty" json:"allow_merge_commit,omitempty" jsonschema:"description=Allow merge commits"`
-	AllowRebaseMerge    *bool   `yaml:"allow_rebase_merge,omitempty" json:"allow_rebase_merge,omitempty" jsonschema:"description=Allow rebase merging"`
-	AllowSquashMerge    *bool   `yaml:"allow_squash_merge,omitempty" json:"allow_squash_merge,omitempty" jsonschema:"description=Allow squash merging"`
-	DeleteBranchOnMerge *bool   `yaml:"delete_branch_on_merge,omitempty" json:"delete_branch_on_merge,omitempty" jsonschema:"description=Auto-delete head branches after merge"`
-	AllowUpdateBranch   *bool   `yaml:"allow_update_branch,omitempty" json:"allow_update_branch,omitempty" jsonschema:"description=Allow updating PR branches"`
+	Description              *string `yaml:"description,omitempty" json:"description,omitempty" jsonschema:"description=Repository description"`
+	Homepage                 *string `yaml:"homepage,omitempty" json:"homepage,omitempty" jsonschema:"description=Homepage URL"`
+	Visibility               *string `yaml:"visibility,omitempty" json:"visibility,omitempty" jsonschema:"description=Repository visibility,enum=public,enum=private,enum=internal"`
+	AllowMergeCommit         *bool   `yaml:"allow_merge_commit,omitempty" json:"allow_merge_commit,omitempty" jsonschema:"description=Allow merge commits"`
+	AllowRebaseMerge         *bool   `yaml:"allow_rebase_merge,omitempty" json:"allow_rebase_merge,omitempty" jsonschema:"description=Allow rebase merging"`
+	AllowSquashMerge         *bool   `yaml:"allow_squash_merge,omitempty" json:"allow_squash_merge,omitempty" jsonschema:"description=Allow squash merging"`
+	DeleteBranchOnMerge      *bool   `yaml:"delete_branch_on_merge,omitempty" json:"delete_branch_on_merge,omitempty" jsonschema:"description=Auto-delete head branches after merge"`
+	AllowUpdateBranch        *bool   `yaml:"allow_update_branch,omitempty" json:"allow_update_branch,omitempty" jsonschema:"description=Allow updating PR branches"`
+	AllowAutoMerge           *bool   `yaml:"allow_auto_merge,omitempty" json:"allow_auto_merge,omitempty" jsonschema:"description=Allow auto-merge on pull requests"`
+	UseSquashPRTitle         *bool   `yaml:"use_squash_pr_title_as_default,omitempty" json:"use_squash_pr_title_as_default,omitempty" jsonschema:"description=Use the pull request title as the default squash merge commit message"`
+	WebCommitSignoffRequired *bool   `yaml:"web_commit_signoff_required,omitempty" json:"web_commit_signoff_required,omitempty" jsonschema:"description=Require contributors to sign off on commits made through the web interface"`
+	IsTemplate               *bool   `yaml:"is_template,omitempty" json:"is_template,omitempty" jsonschema:"description=Make the repository a template that new repositories can be generated from"`
+	SocialPreviewImage       *string `yaml:"social_preview_image,omitempty" json:"social_preview_image,omitempty" jsonschema:"description=Path to a local PNG/JPG/GIF social preview image (uploaded only with --force-social-preview)"`
+	Archived                 *bool   `yaml:"archived,omitempty" json:"archived,omitempty" jsonschema:"description=Archive the repository (false unarchives it before other settings are applied)"`
 }
 
 // LabelsConfig represents label configuration
 type LabelsConfig struct {
-	ReplaceDefault bool    `yaml:"replace_default,omitempty" json:"replace_default,omitempty" jsonschema:"description=Delete labels not in config"`
-	Items          []Label `yaml:"items,omitempty" json:"items,omitempty" jsonschema:"description=List of label definitions"`
+	From           string   `yaml:"from,omitempty" json:"from,omitempty" jsonschema:"description=URL or path of a labels file whose items are imported. Inline items override imported ones with the same name"`
+	ReplaceDefault bool     `yaml:"replace_default,omitempty" json:"replace_default,omitempty" jsonschema:"description=Delete labels not in config"`
+	Items          []Label  `yaml:"items,omitempty" json:"items,omitempty" jsonschema:"description=List of label definitions"`
+	Exclude        []string `yaml:"exclude,omitempty" json:"exclude,omitempty" jsonschema:"description=Name patterns (glob or /regex/) of existing labels that are never updated or deleted"`
 }
 
 // Label represents a single label
 type Label struct {
-	Name        string `yaml:"name" json:"name" jsonschema:"description=Label name,required"`
-	Color       string `yaml:"color" json:"color" jsonschema:"description=Hex color without #,required,pattern=^[0-9a-fA-F]{6}$"`
-	Description string `yaml:"description,omitempty" json:"description,omitempty" jsonschema:"description=Label description"`
+	Name        string  `yaml:"name" json:"name" jsonschema:"description=Label name,required"`
+	Color       string  `yaml:"color" json:"color" jsonschema:"description=Hex color without #,required,pattern=^[0-9a-fA-F]{6}$"`
+	Description *string `yaml:"description,omitempty" json:"description,omitempty" jsonschema:"description=Label description. Omit to leave the current description unchanged; an empty string clears it"`
 }
 
 // BranchRule represents branch protection rules
 type BranchRule struct {
 	// Pull request reviews
+	RequireReviews      *bool `yaml:"require_reviews,omitempty" json:"require_reviews,omitempty" jsonschema:"description=Require pull request reviews. false removes the review requirement; unset leaves it to the other review settings"`
 	RequiredReviews     *int  `yaml:"required_reviews,omitempty" json:"required_reviews,omitempty" jsonschema:"description=Number of required approving reviews,minimum=0,maximum=6"`
 	DismissStaleReviews *bool `yaml:"dismiss_stale_reviews,omitempty" json:"dismiss_stale_reviews,omitempty" jsonschema:"description=Dismiss approvals when new commits are pushed"`
 	RequireCodeOwner    *bool `yaml:"require_code_owner,omitempty" json:"require_code_owner,omitempty" jsonschema:"description=Require review from CODEOWNERS"`
 
 	// Status checks
-	RequireStatusChecks *bool    `yaml:"require_status_checks,omitempty" json:"require_status_checks,omitempty" jsonschema:"description=Require status checks to pass"`
-	StatusChecks        []string `yaml:"status_checks,omitempty" json:"status_checks,omitempty" jsonschema:"description=List of required status check names"`
-	StrictStatusChecks  *bool    `yaml:"strict_status_checks,omitempty" json:"strict_status_checks,omitempty" jsonschema:"description=Require branches to be up to date"`
+	RequireStatusChecks *bool         `yaml:"require_status_checks,omitempty" json:"require_status_checks,omitempty" jsonschema:"description=Require status checks to pass"`
+	StatusChecks        []StatusCheck `yaml:"status_checks,omitempty" json:"status_checks,omitempty" jsonschema:"description=List of required status checks: a name or a mapping with context and app_id to require a specific GitHub App"`
+	StrictStatusChecks  *bool         `yaml:"strict_status_checks,omitempty" json:"strict_status_checks,omitempty" jsonschema:"description=Require branches to be up to date"`
+	StatusChecksOrdered *bool         `yaml:"status_checks_ordered,omitempty" json:"status_checks_ordered,omitempty" jsonschema:"description=Treat a reordered status_checks list as a change (by default order is ignored)"`
 
 	// Deployments
 	RequiredDeployments []string `yaml:"required_deployments,omitempty" json:"required_deployments,omitempty" jsonschema:"description=Required deployment environments"`
@@ -67,7 +85,7 @@ type BranchRule struct {
 // EnvConfig represents environment variables and secrets configuration
 type EnvConfig struct {
 	Variables map[string]string `yaml:"variables,omitempty" json:"variables,omitempty" jsonschema:"description=Repository variables with optional default values"`
-	Secrets   []string          `yaml:"secrets,omitempty" json:"secrets,omitempty" jsonschema:"description=List of secret names to manage"`
+	Secrets   []Secret          `yaml:"secrets,omitempty" json:"secrets,omitempty" jsonschema:"description=List of secrets to manage: a name whose value comes from .env or a provider or a mapping with an inline value"`
 	Provider  *ProviderConfig   `yaml:"provider,omitempty" json:"provider,omitempty" jsonschema:"description=External secret provider configuration"`
 }
 
@@ -88,6 +106,10 @@ type ActionsConfig struct {
 
 	DefaultWorkflowPermissions   *string `yaml:"default_workflow_permissions,omitempty" json:"default_workflow_permissions,omitempty" jsonschema:"description=Default GITHUB_TOKEN permissions,enum=read,enum=write"`
 	CanApprovePullRequestReviews *bool   `yaml:"can_approve_pull_request_reviews,omitempty" json:"can_approve_pull_request_reviews,omitempty" jsonschema:"description=Allow GitHub Actions to create and approve pull requests"`
+
+	ArtifactRetentionDays *int `yaml:"artifact_retention_days,omitempty" json:"artifact_retention_days,omitempty" jsonschema:"description=Days to keep workflow artifacts and logs,minimum=1,maximum=90"`
+
+	AccessLevel *string `yaml:"access_level,omitempty" json:"access_level,omitempty" jsonschema:"description=Which repositories may use this private repository's actions and reusable workflows,enum=none,enum=organization,enum=enterprise"`
 }
 
 // SelectedActionsConfig represents the configuration for selected actions
@@ -99,8 +121,10 @@ type SelectedActionsConfig struct {
 
 // PagesConfig represents GitHub Pages configuration
 type PagesConfig struct {
-	BuildType *string            `yaml:"build_type,omitempty" json:"build_type,omitempty" jsonschema:"description=Build type for GitHub Pages,enum=workflow,enum=legacy"`
-	Source    *PagesSourceConfig `yaml:"source,omitempty" json:"source,omitempty" jsonschema:"description=Source configuration (for legacy build type)"`
+	BuildType     *string            `yaml:"build_type,omitempty" json:"build_type,omitempty" jsonschema:"description=Build type for GitHub Pages,enum=workflow,enum=legacy"`
+	Source        *PagesSourceConfig `yaml:"source,omitempty" json:"source,omitempty" jsonschema:"description=Source configuration (for legacy build type)"`
+	Cname         *string            `yaml:"cname,omitempty" json:"cname,omitempty" jsonschema:"description=Custom domain for the site (an empty string removes it)"`
+	HttpsEnforced *bool              `yaml:"https_enforced,omitempty" json:"https_enforced,omitempty" jsonschema:"description=Whether HTTPS is enforced for the site"`
 }
 
 // PagesSourceConfig represents the source configuration for GitHub Pages
@@ -108,3 +132,29 @@ type PagesSourceConfig struct {
 	Branch *string `yaml:"branch,omitempty" json:"branch,omitempty" jsonschema:"description=Branch name for Pages source"`
 	Path   *string `yaml:"path,omitempty" json:"path,omitempty" jsonschema:"description=Path within the branch (/ or /docs),enum=/,enum=/docs"`
 }
+
+// TemplatesConfig represents template files synced into the repository
+type TemplatesConfig struct {
+	Message string         `yaml:"message,omitempty" json:"message,omitempty" jsonschema:"description=Commit message used when creating or updating files"`
+	Files   []TemplateFile `yaml:"files,omitempty" json:"files,omitempty" jsonschema:"description=Files to sync"`
+}
+
+// TemplateFile maps a local file to a path in the repository
+type TemplateFile struct {
+	Path   string `yaml:"path" json:"path" jsonschema:"description=Target path in the repository (e.g. .github/PULL_REQUEST_TEMPLATE.md),required"`
+	Source string `yaml:"source" json:"source" jsonschema:"description=Local file path relative to the current directory,required"`
+}
+
+// OrgConfig represents organization-level settings
+type OrgConfig struct {
+	Actions *OrgActionsConfig `yaml:"actions,omitempty" json:"actions,omitempty" jsonschema:"description=Organization GitHub Actions permissions"`
+}
+
+// OrgActionsConfig represents organization GitHub Actions permissions
+type OrgActionsConfig struct {
+	EnabledRepositories *string `yaml:"enabled_repositories,omitempty" json:"enabled_repositories,omitempty" jsonschema:"description=Repositories allowed to run GitHub Actions,enum=all,enum=none,enum=selected"`
+	AllowedActions      *string `yaml:"allowed_actions,omitempty" json:"allowed_actions,omitempty" jsonschema:"description=Which actions are allowed,enum=all,enum=local_only,enum=selected"`
+
+	DefaultWorkflowPermissions   *string `yaml:"default_workflow_permissions,omitempty" json:"default_workflow_permissions,omitempty" jsonschema:"description=Default GITHUB_TOKEN permissions,enum=read,enum=write"`
+	CanApprovePullRequestReviews *bool   `yaml:"can_approve_pull_request_reviews,omitempty" json:"can_approve_pull_request_reviews,omitempty" jsonschema:"description=Allow GitHub Actions to create and approve pull requests"`
+}
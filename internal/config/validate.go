@@ -1,8 +1,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"path"
 	"regexp"
+	"sort"
+	"strings"
 
 	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 )
@@ -12,43 +16,362 @@ import (
 // Cannot start with GITHUB_ prefix (reserved)
 var envNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 
-// Validate validates the configuration and returns an error if invalid
+// GitHub topic rules: lowercase letters, numbers and hyphens, starting with a letter or number
+var topicRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Label colors are six hex digits; a leading '#' is accepted as it's stripped before comparing
+var labelColorRegex = regexp.MustCompile(`^#?[0-9a-fA-F]{6}$`)
+
+// GitHub topic limits
+const (
+	// MaxTopics is the most topics GitHub keeps for a repository
+	MaxTopics      = 20
+	maxTopicLength = 50
+)
+
+// Artifact and log retention limits for repositories
+const (
+	minRetentionDays = 1
+	maxRetentionDays = 90
+)
+
+// Validate runs the semantic checks on the configuration: names, enum values,
+// label colors and conflicting settings. All problems are returned together,
+// joined with errors.Join; Load calls it on every config it loads.
 func (c *Config) Validate() error {
+	errs := []error{validateTopics(c.Topics)}
+	if c.Repo != nil {
+		errs = append(errs, validateEnum("repo.visibility", c.Repo.Visibility, "public", "private", "internal"))
+	}
+	if c.Labels != nil {
+		errs = append(errs, c.Labels.Validate())
+	}
+	if c.Actions != nil {
+		errs = append(errs, c.Actions.Validate())
+	}
+	if c.Pages != nil {
+		errs = append(errs, c.Pages.Validate())
+	}
 	if c.Env != nil {
-		if err := c.Env.Validate(); err != nil {
-			return err
+		errs = append(errs, c.Env.Validate())
+	}
+	if c.Templates != nil {
+		errs = append(errs, c.Templates.Validate())
+	}
+	branches := make([]string, 0, len(c.BranchProtection))
+	for branch := range c.BranchProtection {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+	for _, branch := range branches {
+		if rule := c.BranchProtection[branch]; rule != nil {
+			errs = append(errs, rule.Validate(branch))
+		}
+	}
+	if c.Org != nil {
+		errs = append(errs, c.Org.Validate())
+	}
+	for i, pattern := range c.Ignore {
+		if _, err := matchIgnorePattern(pattern, ""); err != nil {
+			errs = append(errs, apperrors.NewValidationError(fmt.Sprintf("ignore[%d]", i), err.Error()))
+		}
+	}
+	for i, pattern := range c.Locked {
+		if _, err := matchIgnorePattern(pattern, ""); err != nil {
+			errs = append(errs, apperrors.NewValidationError(fmt.Sprintf("locked[%d]", i), err.Error()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Warnings returns problems in the configuration that don't make it invalid,
+// such as settings that will be ignored
+func (c *Config) Warnings() []string {
+	var warnings []string
+	if c.Actions != nil {
+		warnings = append(warnings, c.Actions.Warnings()...)
+	}
+	if c.Env != nil {
+		warnings = append(warnings, c.Env.Warnings()...)
+	}
+	return warnings
+}
+
+// Warnings returns problems in the ActionsConfig that don't make it invalid
+func (a *ActionsConfig) Warnings() []string {
+	if a.SelectedActions == nil {
+		return nil
+	}
+	switch {
+	case a.AllowedActions == nil:
+		return []string{`actions.selected_actions only applies while the repository's allowed actions are "selected"; set actions.allowed_actions: selected to be sure it is applied`}
+	case *a.AllowedActions != "selected":
+		return []string{fmt.Sprintf(`actions.selected_actions is ignored because actions.allowed_actions is %q, not "selected"`, *a.AllowedActions)}
+	}
+	return nil
+}
+
+// Warnings returns a warning for each secret whose value is written in the config
+func (e *EnvConfig) Warnings() []string {
+	var warnings []string
+	for _, secret := range e.Secrets {
+		if secret.Value != nil {
+			warnings = append(warnings, fmt.Sprintf("env.secrets: %s has an inline value, which is stored in plain text; prefer .env or a provider", secret.Name))
+		}
+	}
+	return warnings
+}
+
+// validateTopics checks the topic count and each topic name against GitHub's rules
+func validateTopics(topics []string) error {
+	var errs []error
+	if len(topics) > MaxTopics {
+		errs = append(errs, apperrors.NewValidationError(
+			"topics",
+			fmt.Sprintf("too many topics (%d): GitHub allows at most %d", len(topics), MaxTopics),
+		))
+	}
+
+	for i, topic := range topics {
+		field := fmt.Sprintf("topics[%d]", i)
+		switch {
+		case topic == "":
+			errs = append(errs, apperrors.NewValidationError(field, "topic cannot be empty"))
+		case len(topic) > maxTopicLength:
+			errs = append(errs, apperrors.NewValidationError(
+				field,
+				fmt.Sprintf("topic %q is %d characters long (max %d)", topic, len(topic), maxTopicLength),
+			))
+		case strings.ToLower(topic) != topic:
+			errs = append(errs, apperrors.NewValidationError(
+				field,
+				fmt.Sprintf("topic %q must be lowercase (use %q)", topic, strings.ToLower(topic)),
+			))
+		case !topicRegex.MatchString(topic):
+			errs = append(errs, apperrors.NewValidationError(
+				field,
+				fmt.Sprintf("invalid topic %q: must contain only lowercase letters, numbers and hyphens, and start with a letter or number", topic),
+			))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate validates the LabelsConfig
+func (l *LabelsConfig) Validate() error {
+	var errs []error
+	for i, label := range l.Items {
+		if !labelColorRegex.MatchString(label.Color) {
+			errs = append(errs, apperrors.NewValidationError(
+				fmt.Sprintf("labels.items[%d].color", i),
+				fmt.Sprintf("invalid color %q for label %q: must be a 6-digit hex color", label.Color, label.Name),
+			))
+		}
+	}
+	for i, pattern := range l.Exclude {
+		if _, err := matchLabelPattern(pattern, ""); err != nil {
+			errs = append(errs, apperrors.NewValidationError(fmt.Sprintf("labels.exclude[%d]", i), err.Error()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// IsExcluded reports whether an existing label is left unmanaged by an exclude pattern.
+// Invalid patterns never match; Validate reports them.
+func (l *LabelsConfig) IsExcluded(name string) bool {
+	for _, pattern := range l.Exclude {
+		if ok, err := matchLabelPattern(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchLabelPattern matches a label name against an exclude pattern, ignoring case.
+// A pattern wrapped in slashes (e.g. "/^dependabot/") is a regular expression,
+// anything else is a glob (e.g. "github-*").
+func matchLabelPattern(pattern, name string) (bool, error) {
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile("(?i)" + pattern[1:len(pattern)-1])
+		if err != nil {
+			return false, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
 		}
+		return re.MatchString(name), nil
+	}
+	if pattern == "" {
+		return false, fmt.Errorf("pattern cannot be empty")
+	}
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	if err != nil {
+		return false, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return ok, nil
+}
+
+// IsIgnored reports whether the setting at category.key matches an ignore pattern,
+// e.g. "repo.homepage" or "branch_protection.main.enforce_admins".
+// Invalid patterns never match; Validate reports them.
+func (c *Config) IsIgnored(category, key string) bool {
+	setting := category + "." + key
+	for _, pattern := range c.Ignore {
+		if ok, err := matchIgnorePattern(pattern, setting); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Reason returns the _meta reason for the setting at category.key, e.g. "repo.visibility",
+// or "" when the config gives none
+func (c *Config) Reason(category, key string) string {
+	return c.Meta[category+"."+key]
+}
+
+// matchIgnorePattern matches a setting path against an ignore glob.
+// A '*' matches any characters except '/', so "branch_protection.*.enforce_admins"
+// covers every branch whose name has no slash.
+func matchIgnorePattern(pattern, setting string) (bool, error) {
+	if pattern == "" {
+		return false, fmt.Errorf("pattern cannot be empty")
+	}
+	ok, err := path.Match(pattern, setting)
+	if err != nil {
+		return false, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return ok, nil
+}
+
+// Validate validates the ActionsConfig
+func (a *ActionsConfig) Validate() error {
+	errs := []error{
+		validateEnum("actions.allowed_actions", a.AllowedActions, "all", "local_only", "selected"),
+		validateEnum("actions.default_workflow_permissions", a.DefaultWorkflowPermissions, "read", "write"),
+		validateEnum("actions.access_level", a.AccessLevel, "none", "organization", "enterprise"),
+	}
+	if a.ArtifactRetentionDays != nil {
+		days := *a.ArtifactRetentionDays
+		if days < minRetentionDays || days > maxRetentionDays {
+			errs = append(errs, apperrors.NewValidationError(
+				"actions.artifact_retention_days",
+				fmt.Sprintf("%d is out of range (%d-%d days)", days, minRetentionDays, maxRetentionDays),
+			))
+		}
+	}
+	// Without selected_actions, switching to "selected" leaves whatever list GitHub has,
+	// possibly an empty one that blocks every workflow
+	if a.AllowedActions != nil && *a.AllowedActions == "selected" && a.SelectedActions == nil {
+		errs = append(errs, apperrors.NewValidationError("actions.selected_actions", `required when allowed_actions is "selected"`))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate validates the BranchRule of branch
+func (r *BranchRule) Validate(branch string) error {
+	// Review settings can't be applied once the review requirement is removed
+	if r.RequireReviews != nil && !*r.RequireReviews &&
+		(r.RequiredReviews != nil || r.DismissStaleReviews != nil || r.RequireCodeOwner != nil) {
+		return apperrors.NewValidationError(
+			fmt.Sprintf("branch_protection.%s.require_reviews", branch),
+			"required_reviews, dismiss_stale_reviews and require_code_owner can't be set when require_reviews is false",
+		)
 	}
 	return nil
 }
 
+// Validate validates the PagesConfig
+func (p *PagesConfig) Validate() error {
+	errs := []error{validateEnum("pages.build_type", p.BuildType, "workflow", "legacy")}
+	if p.Source != nil {
+		errs = append(errs, validateEnum("pages.source.path", p.Source.Path, "/", "/docs"))
+	}
+	// A legacy build publishes from a branch, so switching to it without one leaves the site without a source
+	if p.BuildType != nil && *p.BuildType == "legacy" && (p.Source == nil || p.Source.Branch == nil || *p.Source.Branch == "") {
+		errs = append(errs, apperrors.NewValidationError("pages.source.branch", `required when build_type is "legacy"`))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate validates the TemplatesConfig
+func (t *TemplatesConfig) Validate() error {
+	var errs []error
+	seen := make(map[string]bool, len(t.Files))
+	for i, f := range t.Files {
+		field := fmt.Sprintf("templates.files[%d]", i)
+		if f.Path == "" || f.Source == "" {
+			errs = append(errs, apperrors.NewValidationError(field, "path and source are required"))
+			continue
+		}
+		clean := path.Clean(f.Path)
+		if path.IsAbs(f.Path) || clean == ".." || strings.HasPrefix(clean, "../") {
+			errs = append(errs, apperrors.NewValidationError(field, fmt.Sprintf("path %q must be relative to the repository root", f.Path)))
+			continue
+		}
+		if seen[clean] {
+			errs = append(errs, apperrors.NewValidationError(field, fmt.Sprintf("duplicate path %q", f.Path)))
+		}
+		seen[clean] = true
+	}
+	return errors.Join(errs...)
+}
+
+// Validate validates the OrgConfig
+func (o *OrgConfig) Validate() error {
+	if o.Actions == nil {
+		return nil
+	}
+	return errors.Join(
+		validateEnum("org.actions.enabled_repositories", o.Actions.EnabledRepositories, "all", "none", "selected"),
+		validateEnum("org.actions.allowed_actions", o.Actions.AllowedActions, "all", "local_only", "selected"),
+		validateEnum("org.actions.default_workflow_permissions", o.Actions.DefaultWorkflowPermissions, "read", "write"),
+	)
+}
+
+// validateEnum checks that an optional value is one of the allowed values
+func validateEnum(field string, value *string, allowed ...string) error {
+	if value == nil {
+		return nil
+	}
+	for _, a := range allowed {
+		if *value == a {
+			return nil
+		}
+	}
+	return apperrors.NewValidationError(
+		field,
+		fmt.Sprintf("invalid value %q (valid: %s)", *value, strings.Join(allowed, ", ")),
+	)
+}
+
 // Validate validates the EnvConfig
 func (e *EnvConfig) Validate() error {
-	// Validate variable names
+	var errs []error
+
+	// Validate variable names, in a stable order
+	names := make([]string, 0, len(e.Variables))
 	for name := range e.Variables {
-		if err := validateEnvName(name, "variable"); err != nil {
-			return err
-		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errs = append(errs, validateEnvName(name, "variable"))
 	}
 
 	// Validate secret names
-	for _, name := range e.Secrets {
-		if err := validateEnvName(name, "secret"); err != nil {
-			return err
-		}
+	for _, secret := range e.Secrets {
+		errs = append(errs, validateEnvName(secret.Name, "secret"))
 	}
 
 	// Check for name conflicts between variables and secrets
-	for _, secretName := range e.Secrets {
-		if _, exists := e.Variables[secretName]; exists {
-			return apperrors.NewValidationError(
+	for _, secret := range e.Secrets {
+		if _, exists := e.Variables[secret.Name]; exists {
+			errs = append(errs, apperrors.NewValidationError(
 				"env",
-				fmt.Sprintf("name %q is defined as both a variable and a secret", secretName),
-			)
+				fmt.Sprintf("name %q is defined as both a variable and a secret", secret.Name),
+			))
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // validateEnvName validates a variable or secret name
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 )
 
 func TestValidateEnvName(t *testing.T) {
@@ -134,7 +138,7 @@ func TestEnvConfigValidate(t *testing.T) {
 					"API_URL": "https://api.example.com",
 					"DEBUG":   "true",
 				},
-				Secrets: []string{"API_KEY", "DB_PASSWORD"},
+				Secrets: []Secret{{Name: "API_KEY"}, {Name: "DB_PASSWORD"}},
 			},
 			wantErr: false,
 		},
@@ -142,7 +146,7 @@ func TestEnvConfigValidate(t *testing.T) {
 			name: "empty config is valid",
 			env: &EnvConfig{
 				Variables: map[string]string{},
-				Secrets:   []string{},
+				Secrets:   []Secret{},
 			},
 			wantErr: false,
 		},
@@ -163,7 +167,7 @@ func TestEnvConfigValidate(t *testing.T) {
 		{
 			name: "invalid secret name",
 			env: &EnvConfig{
-				Secrets: []string{"INVALID-SECRET"},
+				Secrets: []Secret{{Name: "INVALID-SECRET"}},
 			},
 			wantErr: true,
 		},
@@ -179,7 +183,7 @@ func TestEnvConfigValidate(t *testing.T) {
 		{
 			name: "reserved GITHUB_ secret",
 			env: &EnvConfig{
-				Secrets: []string{"GITHUB_SECRET"},
+				Secrets: []Secret{{Name: "GITHUB_SECRET"}},
 			},
 			wantErr: true,
 		},
@@ -189,7 +193,7 @@ func TestEnvConfigValidate(t *testing.T) {
 				Variables: map[string]string{
 					"MY_VAR": "value",
 				},
-				Secrets: []string{"MY_VAR"},
+				Secrets: []Secret{{Name: "MY_VAR"}},
 			},
 			wantErr: true,
 		},
@@ -227,7 +231,7 @@ func TestConfigValidate(t *testing.T) {
 			config: &Config{
 				Env: &EnvConfig{
 					Variables: map[string]string{"MY_VAR": "value"},
-					Secrets:   []string{"MY_SECRET"},
+					Secrets:   []Secret{{Name: "MY_SECRET"}},
 				},
 			},
 			wantErr: false,
@@ -241,6 +245,169 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "config with valid org actions",
+			config: &Config{
+				Org: &OrgConfig{
+					Actions: &OrgActionsConfig{
+						EnabledRepositories:        ptr("selected"),
+						AllowedActions:             ptr("local_only"),
+						DefaultWorkflowPermissions: ptr("read"),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "config with invalid org enabled_repositories",
+			config: &Config{
+				Org: &OrgConfig{
+					Actions: &OrgActionsConfig{EnabledRepositories: ptr("some")},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with invalid org workflow permissions",
+			config: &Config{
+				Org: &OrgConfig{
+					Actions: &OrgActionsConfig{DefaultWorkflowPermissions: ptr("admin")},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with valid templates",
+			config: &Config{
+				Templates: &TemplatesConfig{
+					Files: []TemplateFile{
+						{Path: ".github/PULL_REQUEST_TEMPLATE.md", Source: "templates/pr.md"},
+						{Path: ".github/ISSUE_TEMPLATE/bug.md", Source: "templates/bug.md"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "config with template missing source",
+			config: &Config{
+				Templates: &TemplatesConfig{
+					Files: []TemplateFile{{Path: ".github/PULL_REQUEST_TEMPLATE.md"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with template path outside repository",
+			config: &Config{
+				Templates: &TemplatesConfig{
+					Files: []TemplateFile{{Path: "../outside.md", Source: "templates/pr.md"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with duplicate template paths",
+			config: &Config{
+				Templates: &TemplatesConfig{
+					Files: []TemplateFile{
+						{Path: ".github/PULL_REQUEST_TEMPLATE.md", Source: "templates/a.md"},
+						{Path: "./.github/PULL_REQUEST_TEMPLATE.md", Source: "templates/b.md"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "config with valid actions access level",
+			config:  &Config{Actions: &ActionsConfig{AccessLevel: ptr("organization")}},
+			wantErr: false,
+		},
+		{
+			name:    "config with invalid actions access level",
+			config:  &Config{Actions: &ActionsConfig{AccessLevel: ptr("public")}},
+			wantErr: true,
+		},
+		{
+			name:    "config with artifact retention in range",
+			config:  &Config{Actions: &ActionsConfig{ArtifactRetentionDays: ptrInt(90)}},
+			wantErr: false,
+		},
+		{
+			name:    "config with artifact retention out of range",
+			config:  &Config{Actions: &ActionsConfig{ArtifactRetentionDays: ptrInt(91)}},
+			wantErr: true,
+		},
+		{
+			name:    "config with zero artifact retention",
+			config:  &Config{Actions: &ActionsConfig{ArtifactRetentionDays: ptrInt(0)}},
+			wantErr: true,
+		},
+		{
+			name:    "config with invalid visibility",
+			config:  &Config{Repo: &RepoConfig{Visibility: ptr("secret")}},
+			wantErr: true,
+		},
+		{
+			name:    "config with invalid pages build type",
+			config:  &Config{Pages: &PagesConfig{BuildType: ptr("jekyll")}},
+			wantErr: true,
+		},
+		{
+			name:    "config with invalid pages source path",
+			config:  &Config{Pages: &PagesConfig{Source: &PagesSourceConfig{Path: ptr("/site")}}},
+			wantErr: true,
+		},
+		{
+			name:    "config with legacy pages and a source branch",
+			config:  &Config{Pages: &PagesConfig{BuildType: ptr("legacy"), Source: &PagesSourceConfig{Branch: ptr("gh-pages")}}},
+			wantErr: false,
+		},
+		{
+			name:    "config with legacy pages without a source",
+			config:  &Config{Pages: &PagesConfig{BuildType: ptr("legacy")}},
+			wantErr: true,
+		},
+		{
+			name:    "config with legacy pages without a source branch",
+			config:  &Config{Pages: &PagesConfig{BuildType: ptr("legacy"), Source: &PagesSourceConfig{Path: ptr("/docs")}}},
+			wantErr: true,
+		},
+		{
+			name:    "config with selected actions and their settings",
+			config:  &Config{Actions: &ActionsConfig{AllowedActions: ptr("selected"), SelectedActions: &SelectedActionsConfig{GithubOwnedAllowed: ptrBool(true)}}},
+			wantErr: false,
+		},
+		{
+			name:    "config with selected actions without selected_actions",
+			config:  &Config{Actions: &ActionsConfig{AllowedActions: ptr("selected")}},
+			wantErr: true,
+		},
+		{
+			name:    "config with all actions without selected_actions",
+			config:  &Config{Actions: &ActionsConfig{AllowedActions: ptr("all")}},
+			wantErr: false,
+		},
+		{
+			name:    "config with label color with leading #",
+			config:  &Config{Labels: &LabelsConfig{Items: []Label{{Name: "bug", Color: "#D73A4A"}}}},
+			wantErr: false,
+		},
+		{
+			name:    "config with invalid label color",
+			config:  &Config{Labels: &LabelsConfig{Items: []Label{{Name: "bug", Color: "red"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "config with reviews disabled",
+			config:  &Config{BranchProtection: map[string]*BranchRule{"main": {RequireReviews: ptrBool(false), EnforceAdmins: ptrBool(true)}}},
+			wantErr: false,
+		},
+		{
+			name:    "config with reviews disabled and a review count",
+			config:  &Config{BranchProtection: map[string]*BranchRule{"main": {RequireReviews: ptrBool(false), RequiredReviews: ptrInt(1)}}},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -258,3 +425,210 @@ func TestConfigValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigValidateReportsAllErrors(t *testing.T) {
+	cfg := &Config{
+		Repo:   &RepoConfig{Visibility: ptr("secret")},
+		Topics: []string{"go", "Go"},
+		Labels: &LabelsConfig{Items: []Label{{Name: "bug", Color: "red"}}},
+		Actions: &ActionsConfig{
+			AllowedActions:             ptr("everything"),
+			DefaultWorkflowPermissions: ptr("admin"),
+		},
+		Env: &EnvConfig{
+			Variables: map[string]string{"1INVALID": "value", "SHARED": "value"},
+			Secrets:   []Secret{{Name: "SHARED"}},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	want := []string{
+		"topics[1]",
+		"repo.visibility",
+		"labels.items[0].color",
+		"actions.allowed_actions",
+		"actions.default_workflow_permissions",
+		`"1INVALID"`,
+		`name "SHARED" is defined as both a variable and a secret`,
+	}
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("error = %q, want it to mention %s", err.Error(), w)
+		}
+	}
+
+	var valErr *apperrors.ValidationError
+	if !apperrors.As(err, &valErr) {
+		t.Errorf("expected a ValidationError in %v", err)
+	}
+	if got := len(strings.Split(err.Error(), "\n")); got != len(want) {
+		t.Errorf("expected %d errors, got %d: %v", len(want), got, err)
+	}
+}
+
+func TestValidateTopics(t *testing.T) {
+	tooMany := make([]string, 21)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("topic-%d", i)
+	}
+
+	tests := []struct {
+		name    string
+		topics  []string
+		wantErr string
+	}{
+		{name: "valid topics", topics: []string{"go", "cli", "github-actions", "k8s"}},
+		{name: "no topics", topics: nil},
+		{name: "exactly 20 topics", topics: tooMany[:20]},
+		{name: "too many topics", topics: tooMany, wantErr: "validation error: topics: too many topics (21): GitHub allows at most 20"},
+		{name: "uppercase", topics: []string{"go", "GitHub"}, wantErr: `validation error: topics[1]: topic "GitHub" must be lowercase (use "github")`},
+		{name: "space", topics: []string{"github actions"}, wantErr: `validation error: topics[0]: invalid topic "github actions": must contain only lowercase letters, numbers and hyphens, and start with a letter or number`},
+		{name: "leading hyphen", topics: []string{"-go"}, wantErr: `validation error: topics[0]: invalid topic "-go": must contain only lowercase letters, numbers and hyphens, and start with a letter or number`},
+		{name: "too long", topics: []string{strings.Repeat("a", 51)}, wantErr: `validation error: topics[0]: topic "` + strings.Repeat("a", 51) + `" is 51 characters long (max 50)`},
+		{name: "empty", topics: []string{""}, wantErr: "validation error: topics[0]: topic cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Config{Topics: tt.topics}).Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error %q, got nil", tt.wantErr)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("error = %q, want %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLabelsExclude(t *testing.T) {
+	labels := &LabelsConfig{Exclude: []string{"dependencies", "github-*", "/^bot:/"}}
+	if err := labels.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "dependencies", want: true},
+		{name: "Dependencies", want: true},
+		{name: "github-actions", want: true},
+		{name: "bot:renovate", want: true},
+		{name: "robot:renovate", want: false},
+		{name: "bug", want: false},
+	}
+	for _, tt := range tests {
+		if got := labels.IsExcluded(tt.name); got != tt.want {
+			t.Errorf("IsExcluded(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	invalid := []struct {
+		pattern string
+		wantErr string
+	}{
+		{pattern: "/(/", wantErr: `validation error: labels.exclude[0]: invalid regular expression "/(/"`},
+		{pattern: "[", wantErr: `validation error: labels.exclude[0]: invalid glob "["`},
+		{pattern: "", wantErr: "validation error: labels.exclude[0]: pattern cannot be empty"},
+	}
+	for _, tt := range invalid {
+		err := (&Config{Labels: &LabelsConfig{Exclude: []string{tt.pattern}}}).Validate()
+		if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+			t.Errorf("Validate() with %q = %v, want prefix %q", tt.pattern, err, tt.wantErr)
+		}
+	}
+}
+
+func TestConfigIgnore(t *testing.T) {
+	cfg := &Config{Ignore: []string{"repo.homepage", "branch_protection.*.enforce_admins", "labels.*"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		category string
+		key      string
+		want     bool
+	}{
+		{category: "repo", key: "homepage", want: true},
+		{category: "repo", key: "description", want: false},
+		{category: "branch_protection", key: "main.enforce_admins", want: true},
+		{category: "branch_protection", key: "develop.enforce_admins", want: true},
+		{category: "branch_protection", key: "main.required_reviews", want: false},
+		{category: "labels", key: "bug", want: true},
+		{category: "topics", key: "topics", want: false},
+	}
+	for _, tt := range tests {
+		if got := cfg.IsIgnored(tt.category, tt.key); got != tt.want {
+			t.Errorf("IsIgnored(%q, %q) = %v, want %v", tt.category, tt.key, got, tt.want)
+		}
+	}
+
+	invalid := []struct {
+		pattern string
+		wantErr string
+	}{
+		{pattern: "repo.[", wantErr: `validation error: ignore[0]: invalid glob "repo.["`},
+		{pattern: "", wantErr: "validation error: ignore[0]: pattern cannot be empty"},
+	}
+	for _, tt := range invalid {
+		err := (&Config{Ignore: []string{tt.pattern}}).Validate()
+		if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+			t.Errorf("Validate() with %q = %v, want prefix %q", tt.pattern, err, tt.wantErr)
+		}
+	}
+}
+
+func TestConfigWarningsSelectedActions(t *testing.T) {
+	selected := &SelectedActionsConfig{GithubOwnedAllowed: ptrBool(true)}
+
+	tests := []struct {
+		name    string
+		actions *ActionsConfig
+		wantMsg string // empty means no warning
+	}{
+		{name: "selected", actions: &ActionsConfig{AllowedActions: ptr("selected"), SelectedActions: selected}},
+		{name: "no selected_actions", actions: &ActionsConfig{AllowedActions: ptr("all")}},
+		{name: "allowed_actions all", actions: &ActionsConfig{AllowedActions: ptr("all"), SelectedActions: selected}, wantMsg: `is ignored because actions.allowed_actions is "all"`},
+		{name: "allowed_actions unset", actions: &ActionsConfig{SelectedActions: selected}, wantMsg: "set actions.allowed_actions: selected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := (&Config{Actions: tt.actions}).Warnings()
+			if tt.wantMsg == "" {
+				if len(warnings) != 0 {
+					t.Errorf("unexpected warnings: %v", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tt.wantMsg) {
+				t.Errorf("Warnings() = %v, want one containing %q", warnings, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestConfigWarningsInlineSecrets(t *testing.T) {
+	env := &EnvConfig{Secrets: []Secret{{Name: "API_TOKEN"}, {Name: "DEMO_KEY", Value: ptr("not-really-secret")}}}
+
+	warnings := (&Config{Env: env}).Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "DEMO_KEY has an inline value") {
+		t.Errorf("Warnings() = %v, want one about DEMO_KEY", warnings)
+	}
+	for _, warning := range warnings {
+		if strings.Contains(warning, "not-really-secret") {
+			t.Errorf("warning %q reveals the secret value", warning)
+		}
+	}
+}
@@ -2,11 +2,11 @@ package diff
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/myzkey/gh-repo-settings/internal/config"
 	"github.com/myzkey/gh-repo-settings/internal/diff/domain/comparator"
 	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 	"github.com/myzkey/gh-repo-settings/internal/infra/github"
 )
 
@@ -37,9 +37,21 @@ func NewCalculatorWithEnv(client github.GitHubClient, cfg *config.Config, dotEnv
 
 // CalculateOptions contains options for calculating diff
 type CalculateOptions struct {
-	CheckSecrets bool
-	CheckEnv     bool
-	SyncDelete   bool // If true, show variables/secrets to delete that are not in config
+	CheckSecrets       bool
+	CheckEnv           bool
+	SyncDelete         bool              // If true, show variables/secrets to delete that are not in config
+	PruneLabels        bool              // If true, delete labels not in config as if labels.replace_default were set
+	ReportExtra        bool              // If true and not SyncDelete, report variables/secrets not in config as info changes
+	StrictDrift        bool              // If true, report repo and branch protection settings not in config that differ from GitHub's defaults as info changes
+	SecretState        *config.State     // If set, existing secrets whose .env value changed since the last apply are updated
+	ForceSocialPreview bool              // If true, upload repo.social_preview_image; the current image can't be compared
+	SkipOptional       bool              // If true, skip sections that take extra requests: selected actions, Pages and template files
+	Progress           func(step string) // If set, called before each comparator runs, e.g. "branch protection"
+
+	// SkipForbidden turns a permission-denied error while reading an optional section
+	// (branch protection, env, actions, pages, templates) into an unreadable missing change
+	// instead of failing the whole calculation
+	SkipForbidden bool
 }
 
 // Calculate calculates the diff with default options
@@ -50,79 +62,232 @@ func (c *Calculator) Calculate(ctx context.Context) (*model.Plan, error) {
 // CalculateWithOptions calculates the diff with specified options
 func (c *Calculator) CalculateWithOptions(ctx context.Context, opts CalculateOptions) (*model.Plan, error) {
 	plan := model.NewPlan()
+	for _, step := range c.comparatorSteps(opts) {
+		if opts.Progress != nil {
+			opts.Progress(step.name)
+		}
+		stepPlan, err := step.comparator.Compare(ctx)
+		if err != nil {
+			if opts.SkipForbidden && step.optional && apperrors.IsPermissionDenied(err) {
+				plan.Add(model.NewUnreadableChange(step.categories[0], step.name))
+				continue
+			}
+			return nil, step.error(err)
+		}
+		if step.custom {
+			stepPlan = stepPlan.FilterByCategory(step.categories[0])
+		}
+		plan.AddAll(stepPlan.Changes())
+	}
+	return c.withReasons(c.withoutIgnored(plan)), nil
+}
 
-	// Compare repo settings
-	if c.config.Repo != nil {
-		repoComparator := comparator.NewRepoComparator(c.client, c.config.Repo)
-		repoPlan, err := repoComparator.Compare(ctx)
+// CalculateCategory re-reads current state and calculates the diff for a single category.
+// It runs only the comparator responsible for that category, so apply can check
+// that a planned change still matches GitHub right before mutating it.
+func (c *Calculator) CalculateCategory(ctx context.Context, category model.ChangeCategory, opts CalculateOptions) (*model.Plan, error) {
+	for _, step := range c.comparatorSteps(opts) {
+		if !step.covers(category) {
+			continue
+		}
+		stepPlan, err := step.comparator.Compare(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to compare repo settings: %w", err)
+			return nil, step.error(err)
 		}
-		plan.AddAll(repoPlan.Changes())
+		return c.withReasons(c.withoutIgnored(stepPlan.FilterByCategory(category))), nil
+	}
+	return model.NewPlan(), nil
+}
+
+// withoutIgnored drops the changes to settings matched by the config's ignore patterns
+func (c *Calculator) withoutIgnored(plan *model.Plan) *model.Plan {
+	if len(c.config.Ignore) == 0 {
+		return plan
+	}
+	return plan.Filter(func(change model.Change) bool {
+		return !c.config.IsIgnored(string(change.Category), change.Key)
+	})
+}
+
+// withReasons annotates the changes to settings the config's _meta gives a reason for
+func (c *Calculator) withReasons(plan *model.Plan) *model.Plan {
+	if len(c.config.Meta) == 0 {
+		return plan
+	}
+	changes := make([]model.Change, 0, len(plan.Changes()))
+	for _, change := range plan.Changes() {
+		if reason := c.config.Reason(string(change.Category), change.Key); reason != "" {
+			change = change.WithReason(reason)
+		}
+		changes = append(changes, change)
+	}
+	return model.NewPlanFromChanges(changes)
+}
+
+// comparatorStep is a comparator together with the categories it produces changes for
+type comparatorStep struct {
+	name       string // Used in error messages, e.g. "repo settings"
+	categories []model.ChangeCategory
+	comparator comparator.Comparator
+	optional   bool // Can be skipped with SkipForbidden when the token may not read it
+	custom     bool // Registered with RegisterComparator; only changes in its category are kept
+}
+
+// error wraps a failure of the step's comparator with the category it compares
+func (s comparatorStep) error(err error) *ComparatorError {
+	return &ComparatorError{Category: s.categories[0], Step: s.name, Err: err}
+}
+
+// covers reports whether the step produces changes for category
+func (s comparatorStep) covers(category model.ChangeCategory) bool {
+	for _, c := range s.categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// comparatorSteps returns the comparators for the configured settings, in plan order
+func (c *Calculator) comparatorSteps(opts CalculateOptions) []comparatorStep {
+	var steps []comparatorStep
+
+	// Compare repo settings
+	if c.config.Repo != nil {
+		steps = append(steps, comparatorStep{
+			name:       "repo settings",
+			categories: []model.ChangeCategory{model.CategoryRepo},
+			comparator: comparator.NewRepoComparatorWithOptions(c.client, c.config.Repo, comparator.RepoComparatorOptions{
+				ReportUndeclared: opts.StrictDrift,
+			}),
+		})
 	}
 
 	// Compare topics
 	if c.config.Topics != nil {
-		topicsComparator := comparator.NewTopicsComparator(c.client, c.config.Topics)
-		topicsPlan, err := topicsComparator.Compare(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to compare topics: %w", err)
-		}
-		plan.AddAll(topicsPlan.Changes())
+		steps = append(steps, comparatorStep{
+			name:       "topics",
+			categories: []model.ChangeCategory{model.CategoryTopics},
+			comparator: comparator.NewTopicsComparator(c.client, c.config.Topics),
+		})
 	}
 
 	// Compare labels
 	if c.config.Labels != nil {
-		labelsComparator := comparator.NewLabelsComparator(c.client, c.config.Labels)
-		labelsPlan, err := labelsComparator.Compare(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to compare labels: %w", err)
+		labels := c.config.Labels
+		if opts.PruneLabels {
+			pruned := *labels
+			pruned.ReplaceDefault = true
+			labels = &pruned
 		}
-		plan.AddAll(labelsPlan.Changes())
+		steps = append(steps, comparatorStep{
+			name:       "labels",
+			categories: []model.ChangeCategory{model.CategoryLabels},
+			comparator: comparator.NewLabelsComparator(c.client, labels),
+		})
 	}
 
 	// Compare branch protection
 	if c.config.BranchProtection != nil {
-		branchComparator := comparator.NewBranchProtectionComparatorWithClient(c.client, c.config.BranchProtection)
-		branchPlan, err := branchComparator.Compare(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to compare branch protection: %w", err)
-		}
-		plan.AddAll(branchPlan.Changes())
+		steps = append(steps, comparatorStep{
+			name:       "branch protection",
+			categories: []model.ChangeCategory{model.CategoryBranchProtection},
+			optional:   true,
+			comparator: comparator.NewBranchProtectionComparatorWithOptions(c.client, c.config.BranchProtection, comparator.BranchProtectionComparatorOptions{
+				ReportUndeclared: opts.StrictDrift,
+			}),
+		})
 	}
 
 	// Compare secrets and variables (if requested)
 	if (opts.CheckSecrets || opts.CheckEnv) && c.config.Env != nil {
-		envComparator := comparator.NewEnvComparator(c.client, c.config.Env, c.dotEnvValues, comparator.EnvComparatorOptions{
-			CheckSecrets: opts.CheckSecrets,
-			CheckVars:    opts.CheckEnv,
-			SyncDelete:   opts.SyncDelete,
+		steps = append(steps, comparatorStep{
+			name:       "env",
+			categories: []model.ChangeCategory{model.CategoryVariables, model.CategorySecrets},
+			optional:   true,
+			comparator: comparator.NewEnvComparator(c.client, c.config.Env, c.dotEnvValues, comparator.EnvComparatorOptions{
+				CheckSecrets: opts.CheckSecrets,
+				CheckVars:    opts.CheckEnv,
+				SyncDelete:   opts.SyncDelete,
+				ReportExtra:  opts.ReportExtra,
+				State:        opts.SecretState,
+			}),
 		})
-		envPlan, err := envComparator.Compare(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to compare env: %w", err)
-		}
-		plan.AddAll(envPlan.Changes())
 	}
 
 	// Compare actions permissions
 	if c.config.Actions != nil {
-		actionsComparator := comparator.NewActionsComparator(c.client, c.config.Actions)
-		actionsPlan, err := actionsComparator.Compare(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to compare actions permissions: %w", err)
-		}
-		plan.AddAll(actionsPlan.Changes())
+		steps = append(steps, comparatorStep{
+			name:       "actions permissions",
+			categories: []model.ChangeCategory{model.CategoryActions},
+			optional:   true,
+			comparator: comparator.NewActionsComparatorWithOptions(c.client, c.config.Actions, comparator.ActionsComparatorOptions{
+				SkipSelectedActions: opts.SkipOptional,
+			}),
+		})
 	}
 
 	// Compare pages settings
-	if c.config.Pages != nil {
-		pagesComparator := comparator.NewPagesComparator(c.client, c.config.Pages)
-		pagesPlan, err := pagesComparator.Compare(ctx)
+	if c.config.Pages != nil && !opts.SkipOptional {
+		steps = append(steps, comparatorStep{
+			name:       "pages settings",
+			categories: []model.ChangeCategory{model.CategoryPages},
+			optional:   true,
+			comparator: comparator.NewPagesComparator(c.client, c.config.Pages),
+		})
+	}
+
+	// Compare template files, which takes a request per file
+	if c.config.Templates != nil && !opts.SkipOptional {
+		steps = append(steps, comparatorStep{
+			name:       "templates",
+			categories: []model.ChangeCategory{model.CategoryTemplates},
+			optional:   true,
+			comparator: comparator.NewTemplatesComparator(c.client, c.config.Templates),
+		})
+	}
+
+	// Compare social preview image (only when explicitly forced)
+	if c.config.Repo != nil {
+		steps = append(steps, comparatorStep{
+			name:       "social preview image",
+			categories: []model.ChangeCategory{model.CategorySocialPreview},
+			comparator: comparator.NewSocialPreviewComparator(c.config.Repo.SocialPreviewImage, opts.ForceSocialPreview),
+		})
+	}
+
+	// Custom comparators registered by code embedding this package run last
+	steps = append(steps, c.customComparatorSteps()...)
+
+	return steps
+}
+
+// OrgCalculator orchestrates the comparison of organization settings
+type OrgCalculator struct {
+	client github.OrgGitHubClient
+	config *config.OrgConfig
+}
+
+// NewOrgCalculator creates a new diff calculator for organization settings
+func NewOrgCalculator(client github.OrgGitHubClient, cfg *config.OrgConfig) *OrgCalculator {
+	return &OrgCalculator{
+		client: client,
+		config: cfg,
+	}
+}
+
+// Calculate calculates the diff for organization settings
+func (c *OrgCalculator) Calculate(ctx context.Context) (*model.Plan, error) {
+	plan := model.NewPlan()
+
+	// Compare org actions permissions
+	if c.config.Actions != nil {
+		actionsComparator := comparator.NewOrgActionsComparator(c.client, c.config.Actions)
+		actionsPlan, err := actionsComparator.Compare(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to compare pages settings: %w", err)
+			return nil, &ComparatorError{Category: model.CategoryOrgActions, Step: "org actions permissions", Err: err}
 		}
-		plan.AddAll(pagesPlan.Changes())
+		plan.AddAll(actionsPlan.Changes())
 	}
 
 	return plan, nil
@@ -2,9 +2,13 @@ package diff
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
 	"github.com/myzkey/gh-repo-settings/internal/infra/github"
 )
 
@@ -211,3 +215,89 @@ func TestCompareActionsWithPatternsAllowed(t *testing.T) {
 		t.Error("expected patterns_allowed change")
 	}
 }
+
+func TestCalculatorSkipsUnconfiguredActionsReads(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.ActionsConfig
+		want   map[string]int
+	}{
+		{
+			name:   "retention only",
+			config: &config.ActionsConfig{ArtifactRetentionDays: ptr(30)},
+			want:   map[string]int{},
+		},
+		{
+			name:   "permissions only",
+			config: &config.ActionsConfig{Enabled: ptr(true)},
+			want:   map[string]int{"GetActionsPermissions": 1},
+		},
+		{
+			name:   "selected actions without allowed_actions selected",
+			config: &config.ActionsConfig{SelectedActions: &config.SelectedActionsConfig{VerifiedAllowed: ptr(true)}},
+			want:   map[string]int{"GetActionsPermissions": 1},
+		},
+		{
+			name:   "workflow permissions only",
+			config: &config.ActionsConfig{CanApprovePullRequestReviews: ptr(false)},
+			want:   map[string]int{"GetActionsWorkflowPermissions": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newCountingClient(github.NewMockClient())
+			if _, err := NewCalculator(client, &config.Config{Actions: tt.config}).Calculate(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(client.calls, tt.want) {
+				t.Errorf("calls = %v, want %v", client.calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculatorSkipOptional(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "CODEOWNERS")
+	if err := os.WriteFile(source, []byte("* @me\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		Labels: &config.LabelsConfig{Items: []config.Label{{Name: "bug", Color: "d73a4a"}}},
+		Actions: &config.ActionsConfig{
+			AllowedActions:  ptr("selected"),
+			SelectedActions: &config.SelectedActionsConfig{VerifiedAllowed: ptr(true)},
+		},
+		Pages:     &config.PagesConfig{BuildType: ptr("workflow")},
+		Templates: &config.TemplatesConfig{Files: []config.TemplateFile{{Path: ".github/CODEOWNERS", Source: source}}},
+	}
+
+	client := newCountingClient(github.NewMockClient())
+	plan, err := NewCalculator(client, cfg).CalculateWithOptions(context.Background(), CalculateOptions{SkipOptional: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, method := range []string{"GetActionsSelectedActions", "GetPages", "GetFile"} {
+		if client.calls[method] != 0 {
+			t.Errorf("%s called %d times, want 0", method, client.calls[method])
+		}
+	}
+	for _, change := range plan.Changes() {
+		if change.Category == model.CategoryPages || change.Category == model.CategoryTemplates || change.Key == "verified_allowed" {
+			t.Errorf("skipped section produced a change: %s", change)
+		}
+	}
+
+	// Without the option every configured section is read
+	mock := github.NewMockClient()
+	mock.PagesData = &github.PagesData{BuildType: nullBuildType("workflow")}
+	client = newCountingClient(mock)
+	if _, err := NewCalculator(client, cfg).Calculate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, method := range []string{"GetActionsSelectedActions", "GetPages", "GetFile"} {
+		if client.calls[method] != 1 {
+			t.Errorf("%s called %d times, want 1", method, client.calls[method])
+		}
+	}
+}
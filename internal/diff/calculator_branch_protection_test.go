@@ -2,6 +2,9 @@ package diff
 
 import (
 	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/myzkey/gh-repo-settings/internal/config"
@@ -97,3 +100,283 @@ func TestCalculatorCompareBranchProtection(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculatorBranchProtectionMissingBranch(t *testing.T) {
+	tests := []struct {
+		name        string
+		missing     []string
+		wantWarning bool
+	}{
+		{
+			name:        "existing branch has no warning",
+			missing:     nil,
+			wantWarning: false,
+		},
+		{
+			name:        "missing branch is annotated",
+			missing:     []string{"develop"},
+			wantWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			mock.GetBranchProtectionError = apperrors.ErrBranchNotProtected
+			mock.MissingBranches = tt.missing
+
+			cfg := &config.Config{BranchProtection: map[string]*config.BranchRule{
+				"develop": {RequiredReviews: ptr(1)},
+			}}
+
+			plan, err := NewCalculator(mock, cfg).Calculate(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if plan.Size() != 1 {
+				t.Fatalf("expected 1 change, got %d", plan.Size())
+			}
+
+			desc, _ := plan.Changes()[0].New.(string)
+			hasWarning := strings.Contains(desc, "branch 'develop' does not exist")
+			if hasWarning != tt.wantWarning {
+				t.Errorf("warning present = %v, want %v (description: %q)", hasWarning, tt.wantWarning, desc)
+			}
+		})
+	}
+
+	t.Run("BranchExists error is returned", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.GetBranchProtectionError = apperrors.ErrBranchNotProtected
+		mock.BranchExistsError = apperrors.ErrPermissionDenied
+
+		cfg := &config.Config{BranchProtection: map[string]*config.BranchRule{
+			"develop": {RequiredReviews: ptr(1)},
+		}}
+
+		_, err := NewCalculator(mock, cfg).Calculate(context.Background())
+		if !apperrors.Is(err, apperrors.ErrPermissionDenied) {
+			t.Errorf("expected ErrPermissionDenied, got %v", err)
+		}
+	})
+}
+
+func TestCalculatorBranchProtectionStatusChecksOrdered(t *testing.T) {
+	tests := []struct {
+		name          string
+		ordered       *bool
+		expectedCount int
+	}{
+		{
+			name:          "reorder ignored by default",
+			ordered:       nil,
+			expectedCount: 0,
+		},
+		{
+			name:          "reorder detected with status_checks_ordered",
+			ordered:       ptr(true),
+			expectedCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			mock.BranchProtections = map[string]*github.BranchProtectionData{
+				"main": {
+					RequiredStatusChecks: &githubopenapi.ProtectedBranchRequiredStatusCheck{
+						Contexts: []string{"build", "test"},
+					},
+				},
+			}
+
+			cfg := &config.Config{BranchProtection: map[string]*config.BranchRule{
+				"main": {
+					StatusChecks:        []config.StatusCheck{{Context: "test"}, {Context: "build"}},
+					StatusChecksOrdered: tt.ordered,
+				},
+			}}
+
+			plan, err := NewCalculator(mock, cfg).Calculate(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if plan.Size() != tt.expectedCount {
+				t.Errorf("expected %d changes, got %d: %+v", tt.expectedCount, plan.Size(), plan.Changes())
+			}
+		})
+	}
+}
+
+func TestCalculatorBranchProtectionStatusChecksNilVsEmpty(t *testing.T) {
+	tests := []struct {
+		name    string
+		current *githubopenapi.ProtectedBranchRequiredStatusCheck
+		desired []config.StatusCheck
+		ordered *bool
+	}{
+		{
+			name:    "empty desired, no current contexts",
+			current: &githubopenapi.ProtectedBranchRequiredStatusCheck{},
+			desired: []config.StatusCheck{},
+		},
+		{
+			name:    "empty desired, status checks not required",
+			current: nil,
+			desired: []config.StatusCheck{},
+		},
+		{
+			name:    "unset desired, empty current contexts",
+			current: &githubopenapi.ProtectedBranchRequiredStatusCheck{Contexts: []string{}},
+			desired: nil,
+		},
+		{
+			name:    "empty desired, empty current contexts, ordered",
+			current: &githubopenapi.ProtectedBranchRequiredStatusCheck{Contexts: []string{}},
+			desired: []config.StatusCheck{},
+			ordered: ptr(true),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			mock.BranchProtections = map[string]*github.BranchProtectionData{
+				"main": {RequiredStatusChecks: tt.current},
+			}
+
+			cfg := &config.Config{BranchProtection: map[string]*config.BranchRule{
+				"main": {
+					StatusChecks:        tt.desired,
+					StatusChecksOrdered: tt.ordered,
+				},
+			}}
+
+			plan, err := NewCalculator(mock, cfg).Calculate(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !plan.IsEmpty() {
+				t.Errorf("expected no changes, got %+v", plan.Changes())
+			}
+		})
+	}
+}
+
+func TestCalculatorBranchProtectionStatusCheckApps(t *testing.T) {
+	var required githubopenapi.ProtectedBranchRequiredStatusCheck
+	if err := json.Unmarshal([]byte(`{
+		"contexts": ["lint", "test"],
+		"checks": [{"context": "lint", "app_id": null}, {"context": "test", "app_id": 15368}]
+	}`), &required); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		checks        []config.StatusCheck
+		expectedCount int
+	}{
+		{
+			name:          "names only ignore apps",
+			checks:        []config.StatusCheck{{Context: "lint"}, {Context: "test"}},
+			expectedCount: 0,
+		},
+		{
+			name:          "same app",
+			checks:        []config.StatusCheck{{Context: "lint"}, {Context: "test", AppID: ptr(15368)}},
+			expectedCount: 0,
+		},
+		{
+			name:          "different app",
+			checks:        []config.StatusCheck{{Context: "lint"}, {Context: "test", AppID: ptr(42)}},
+			expectedCount: 1,
+		},
+		{
+			name:          "check reported by any app gets pinned",
+			checks:        []config.StatusCheck{{Context: "lint", AppID: ptr(42)}, {Context: "test"}},
+			expectedCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			mock.BranchProtections = map[string]*github.BranchProtectionData{
+				"main": {RequiredStatusChecks: &required},
+			}
+			cfg := &config.Config{BranchProtection: map[string]*config.BranchRule{
+				"main": {StatusChecks: tt.checks},
+			}}
+
+			plan, err := NewCalculator(mock, cfg).Calculate(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if plan.Size() != tt.expectedCount {
+				t.Errorf("expected %d changes, got %d: %+v", tt.expectedCount, plan.Size(), plan.Changes())
+			}
+		})
+	}
+}
+
+func TestCalculatorBranchProtectionUsesBranchList(t *testing.T) {
+	cfg := &config.Config{BranchProtection: map[string]*config.BranchRule{
+		"main":      {RequiredReviews: ptr(1)},
+		"develop":   {RequiredReviews: ptr(1)},
+		"release":   {RequiredReviews: ptr(1)},
+		"feature-x": {RequiredReviews: ptr(1)},
+	}}
+	reviews := 1
+
+	t.Run("only protected branches are read", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.Branches = []github.BranchData{
+			{Name: "main", Protected: true},
+			{Name: "develop"},
+			{Name: "release"},
+		}
+		mock.BranchProtections["main"] = &github.BranchProtectionData{
+			RequiredPullRequestReviews: &githubopenapi.ProtectedBranchPullRequestReview{RequiredApprovingReviewCount: &reviews},
+		}
+
+		plan, err := NewCalculator(mock, cfg).Calculate(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if mock.ListBranchesCalls != 1 {
+			t.Errorf("ListBranches called %d times, want 1", mock.ListBranchesCalls)
+		}
+		if !reflect.DeepEqual(mock.GetBranchProtectionCalls, []string{"main"}) {
+			t.Errorf("GetBranchProtection called for %v, want only main", mock.GetBranchProtectionCalls)
+		}
+
+		added := map[string]string{}
+		for _, c := range plan.Changes() {
+			if c.Type == ChangeAdd {
+				added[c.Branch], _ = c.New.(string)
+			}
+		}
+		if len(added) != 3 {
+			t.Fatalf("added protection for %v, want develop, release and feature-x", added)
+		}
+		for branch, desc := range added {
+			wantWarning := branch == "feature-x"
+			if hasWarning := strings.Contains(desc, "does not exist"); hasWarning != wantWarning {
+				t.Errorf("%s: warning present = %v, want %v (description: %q)", branch, hasWarning, wantWarning, desc)
+			}
+		}
+	})
+
+	t.Run("each branch is probed when branches can't be listed", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.GetBranchProtectionError = apperrors.ErrBranchNotProtected
+
+		if _, err := NewCalculator(mock, cfg).Calculate(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mock.GetBranchProtectionCalls) != len(cfg.BranchProtection) {
+			t.Errorf("GetBranchProtection called for %v, want every configured branch", mock.GetBranchProtectionCalls)
+		}
+	})
+}
@@ -12,25 +12,25 @@ func TestCalculatorCheckSecrets(t *testing.T) {
 	tests := []struct {
 		name           string
 		currentSecrets []string
-		configSecrets  []string
+		configSecrets  []config.Secret
 		expectMissing  int
 	}{
 		{
 			name:           "all secrets present",
 			currentSecrets: []string{"API_KEY", "DEPLOY_TOKEN"},
-			configSecrets:  []string{"API_KEY", "DEPLOY_TOKEN"},
+			configSecrets:  []config.Secret{{Name: "API_KEY"}, {Name: "DEPLOY_TOKEN"}},
 			expectMissing:  0,
 		},
 		{
 			name:           "some secrets missing",
 			currentSecrets: []string{"API_KEY"},
-			configSecrets:  []string{"API_KEY", "DEPLOY_TOKEN", "SECRET_KEY"},
+			configSecrets:  []config.Secret{{Name: "API_KEY"}, {Name: "DEPLOY_TOKEN"}, {Name: "SECRET_KEY"}},
 			expectMissing:  2,
 		},
 		{
 			name:           "all secrets missing",
 			currentSecrets: []string{},
-			configSecrets:  []string{"API_KEY"},
+			configSecrets:  []config.Secret{{Name: "API_KEY"}},
 			expectMissing:  1,
 		},
 	}
@@ -127,7 +127,7 @@ func TestCalculatorCompareEnvSyncDelete(t *testing.T) {
 		name           string
 		currentSecrets []string
 		currentVars    []github.VariableData
-		configSecrets  []string
+		configSecrets  []config.Secret
 		configVars     map[string]string
 		syncDelete     bool
 		expectDeletes  int
@@ -135,14 +135,14 @@ func TestCalculatorCompareEnvSyncDelete(t *testing.T) {
 		{
 			name:           "delete secrets with syncDelete",
 			currentSecrets: []string{"KEEP", "DELETE_ME"},
-			configSecrets:  []string{"KEEP"},
+			configSecrets:  []config.Secret{{Name: "KEEP"}},
 			syncDelete:     true,
 			expectDeletes:  1,
 		},
 		{
 			name:           "no delete without syncDelete",
 			currentSecrets: []string{"KEEP", "DELETE_ME"},
-			configSecrets:  []string{"KEEP"},
+			configSecrets:  []config.Secret{{Name: "KEEP"}},
 			syncDelete:     false,
 			expectDeletes:  0,
 		},
@@ -213,7 +213,7 @@ func TestCalculatorCompareEnvWithDotEnv(t *testing.T) {
 
 	cfg := &config.Config{
 		Env: &config.EnvConfig{
-			Secrets:   []string{"SECRET1", "SECRET2"},
+			Secrets:   []config.Secret{{Name: "SECRET1"}, {Name: "SECRET2"}},
 			Variables: map[string]string{"VAR1": "yaml_default", "VAR2": "yaml_only"},
 		},
 	}
@@ -2,6 +2,8 @@ package diff
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/myzkey/gh-repo-settings/internal/config"
@@ -53,7 +55,7 @@ func TestCalculatorErrors(t *testing.T) {
 		mock := github.NewMockClient()
 		mock.GetSecretsError = apperrors.ErrPermissionDenied
 
-		cfg := &config.Config{Env: &config.EnvConfig{Secrets: []string{"KEY"}}}
+		cfg := &config.Config{Env: &config.EnvConfig{Secrets: []config.Secret{{Name: "KEY"}}}}
 		calc := NewCalculator(mock, cfg)
 
 		_, err := calc.CalculateWithOptions(context.Background(), CalculateOptions{CheckSecrets: true})
@@ -93,7 +95,7 @@ func TestCalculatorErrors(t *testing.T) {
 		mock.ActionsPermissions = &github.ActionsPermissionsData{Enabled: true}
 		mock.GetActionsWorkflowPermissionsError = apperrors.ErrPermissionDenied
 
-		cfg := &config.Config{Actions: &config.ActionsConfig{Enabled: ptr(true)}}
+		cfg := &config.Config{Actions: &config.ActionsConfig{Enabled: ptr(true), CanApprovePullRequestReviews: ptr(false)}}
 		calc := NewCalculator(mock, cfg)
 
 		_, err := calc.Calculate(context.Background())
@@ -102,3 +104,128 @@ func TestCalculatorErrors(t *testing.T) {
 		}
 	})
 }
+
+func TestCalculatorSkipForbidden(t *testing.T) {
+	newMock := func() *github.MockClient {
+		mock := github.NewMockClient()
+		mock.RepoData = &github.RepoData{Description: nullStr("old")}
+		mock.GetSecretsError = apperrors.ErrPermissionDenied
+		mock.GetActionsPermissionsError = apperrors.NewAPIError("GET", "repos/o/r/actions/permissions", 403, "Resource not accessible by integration", nil)
+		return mock
+	}
+	cfg := &config.Config{
+		Repo:    &config.RepoConfig{Description: ptr("new")},
+		Env:     &config.EnvConfig{Secrets: []config.Secret{{Name: "KEY"}}},
+		Actions: &config.ActionsConfig{Enabled: ptr(true)},
+	}
+
+	t.Run("optional sections are skipped", func(t *testing.T) {
+		plan, err := NewCalculator(newMock(), cfg).CalculateWithOptions(context.Background(), CalculateOptions{CheckSecrets: true, SkipForbidden: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if plan.FilterByCategory(CategoryRepo).Size() != 1 {
+			t.Errorf("expected the repo description change, got %+v", plan.Changes())
+		}
+		unreadable := plan.Filter(Change.IsUnreadable).Changes()
+		if len(unreadable) != 2 {
+			t.Fatalf("expected 2 unreadable sections, got %+v", plan.Changes())
+		}
+		if unreadable[0].Key != "env" || unreadable[1].Key != "actions permissions" {
+			t.Errorf("unexpected unreadable sections: %+v", unreadable)
+		}
+		if got := fmt.Sprint(unreadable[1].New); got != "insufficient permissions to read actions permissions" {
+			t.Errorf("unexpected description %q", got)
+		}
+		if plan.HasMissingSecrets() || plan.HasMissingVariables() {
+			t.Error("unreadable sections should not count as missing secrets or variables")
+		}
+		if plan.Stats().Missing != 2 {
+			t.Errorf("expected unreadable sections to count as missing, got %+v", plan.Stats())
+		}
+	})
+
+	t.Run("strict fails fast", func(t *testing.T) {
+		_, err := NewCalculator(newMock(), cfg).CalculateWithOptions(context.Background(), CalculateOptions{CheckSecrets: true})
+		if !apperrors.Is(err, apperrors.ErrPermissionDenied) {
+			t.Errorf("expected permission denied error, got %v", err)
+		}
+	})
+
+	t.Run("required sections still fail", func(t *testing.T) {
+		mock := newMock()
+		mock.GetLabelsError = apperrors.ErrPermissionDenied
+		labelsCfg := &config.Config{Labels: &config.LabelsConfig{Items: []config.Label{{Name: "bug", Color: "d73a4a"}}}}
+
+		_, err := NewCalculator(mock, labelsCfg).CalculateWithOptions(context.Background(), CalculateOptions{SkipForbidden: true})
+		if err == nil {
+			t.Error("expected error for unreadable labels")
+		}
+	})
+
+	t.Run("other errors still fail", func(t *testing.T) {
+		mock := newMock()
+		mock.GetSecretsError = apperrors.ErrNetworkError
+
+		_, err := NewCalculator(mock, cfg).CalculateWithOptions(context.Background(), CalculateOptions{CheckSecrets: true, SkipForbidden: true})
+		if !apperrors.Is(err, apperrors.ErrNetworkError) {
+			t.Errorf("expected network error, got %v", err)
+		}
+	})
+}
+
+func TestCalculatorComparatorError(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(mock *github.MockClient)
+		cfg          *config.Config
+		wantCategory ChangeCategory
+	}{
+		{
+			name:         "labels",
+			setup:        func(mock *github.MockClient) { mock.GetLabelsError = apperrors.ErrPermissionDenied },
+			cfg:          &config.Config{Labels: &config.LabelsConfig{Items: []config.Label{{Name: "bug", Color: "d73a4a"}}}},
+			wantCategory: CategoryLabels,
+		},
+		{
+			name:         "branch protection",
+			setup:        func(mock *github.MockClient) { mock.GetBranchProtectionError = apperrors.ErrPermissionDenied },
+			cfg:          &config.Config{BranchProtection: map[string]*config.BranchRule{"main": {RequiredReviews: ptr(1)}}},
+			wantCategory: CategoryBranchProtection,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			tt.setup(mock)
+
+			_, err := NewCalculator(mock, tt.cfg).Calculate(context.Background())
+
+			var cerr *ComparatorError
+			if !errors.As(err, &cerr) {
+				t.Fatalf("expected a ComparatorError, got %T: %v", err, err)
+			}
+			if cerr.Category != tt.wantCategory {
+				t.Errorf("Category = %s, want %s", cerr.Category, tt.wantCategory)
+			}
+			if !apperrors.Is(err, apperrors.ErrPermissionDenied) {
+				t.Errorf("expected the comparator's error to stay wrapped, got %v", err)
+			}
+		})
+	}
+
+	t.Run("org actions", func(t *testing.T) {
+		mock := github.NewMockOrgClient()
+		mock.GetOrgActionsPermissionsError = apperrors.ErrPermissionDenied
+
+		cfg := &config.OrgConfig{Actions: &config.OrgActionsConfig{AllowedActions: ptr("local_only")}}
+		_, err := NewOrgCalculator(mock, cfg).Calculate(context.Background())
+
+		var cerr *ComparatorError
+		if !errors.As(err, &cerr) || cerr.Category != CategoryOrgActions {
+			t.Errorf("expected a ComparatorError for org_actions, got %v", err)
+		}
+	})
+}
@@ -43,7 +43,7 @@ func TestCalculatorCompareLabels(t *testing.T) {
 			},
 			config: &config.LabelsConfig{
 				Items: []config.Label{
-					{Name: "bug", Color: "ff0000", Description: "New description"},
+					{Name: "bug", Color: "ff0000", Description: ptr("New description")},
 				},
 			},
 			expected: struct {
@@ -88,6 +88,38 @@ func TestCalculatorCompareLabels(t *testing.T) {
 				deletes int
 			}{adds: 0, updates: 0, deletes: 0},
 		},
+		{
+			name: "omitted description is left unchanged",
+			current: []github.LabelData{
+				{Name: "bug", Color: "d73a4a", Description: nullStr("Something isn't working")},
+			},
+			config: &config.LabelsConfig{
+				Items: []config.Label{
+					{Name: "bug", Color: "d73a4a"},
+				},
+			},
+			expected: struct {
+				adds    int
+				updates int
+				deletes int
+			}{adds: 0, updates: 0, deletes: 0},
+		},
+		{
+			name: "empty description clears it",
+			current: []github.LabelData{
+				{Name: "bug", Color: "d73a4a", Description: nullStr("Something isn't working")},
+			},
+			config: &config.LabelsConfig{
+				Items: []config.Label{
+					{Name: "bug", Color: "d73a4a", Description: ptr("")},
+				},
+			},
+			expected: struct {
+				adds    int
+				updates int
+				deletes int
+			}{adds: 0, updates: 1, deletes: 0},
+		},
 	}
 
 	for _, tt := range tests {
@@ -127,3 +159,38 @@ func TestCalculatorCompareLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculatorPruneLabels(t *testing.T) {
+	mock := github.NewMockClient()
+	mock.Labels = []github.LabelData{
+		{Name: "bug", Color: "d73a4a"},
+		{Name: "old-label", Color: "000000"},
+		{Name: "dependencies", Color: "0366d6"},
+	}
+	cfg := &config.Config{Labels: &config.LabelsConfig{
+		ReplaceDefault: false,
+		Items:          []config.Label{{Name: "bug", Color: "d73a4a"}},
+		Exclude:        []string{"dependencies"},
+	}}
+
+	plan, err := NewCalculator(mock, cfg).CalculateWithOptions(context.Background(), CalculateOptions{PruneLabels: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changes := plan.Changes()
+	if len(changes) != 1 || changes[0].Type != ChangeDelete || changes[0].Key != "old-label" {
+		t.Errorf("changes = %v, want only old-label deleted", changes)
+	}
+
+	// The flag only applies to this calculation
+	if cfg.Labels.ReplaceDefault {
+		t.Error("PruneLabels modified the config")
+	}
+	plan, err = NewCalculator(mock, cfg).Calculate(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.HasChanges() {
+		t.Errorf("changes without PruneLabels = %v, want none", plan.Changes())
+	}
+}
@@ -2,10 +2,13 @@ package diff
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/myzkey/gh-repo-settings/internal/config"
 	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+	"github.com/myzkey/gh-repo-settings/internal/infra/githubopenapi"
 )
 
 func TestCalculatorCompareRepo(t *testing.T) {
@@ -177,3 +180,157 @@ func TestCalculatorCompareRepoAllFields(t *testing.T) {
 		t.Errorf("missing repo changes: %v", expectedKeys)
 	}
 }
+
+func TestCalculator_Ignore(t *testing.T) {
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{
+		Description: nullStr("old"),
+		Homepage:    nullStr("https://old.com"),
+	}
+	mock.BranchProtections = map[string]*github.BranchProtectionData{
+		"main":    {RequiredPullRequestReviews: &githubopenapi.ProtectedBranchPullRequestReview{RequiredApprovingReviewCount: ptr(1)}},
+		"develop": {},
+	}
+
+	cfg := &config.Config{
+		Repo: &config.RepoConfig{
+			Description: ptr("new"),
+			Homepage:    ptr("https://new.com"),
+		},
+		BranchProtection: map[string]*config.BranchRule{
+			"main":    {RequiredReviews: ptr(2), EnforceAdmins: ptr(true)},
+			"develop": {EnforceAdmins: ptr(true)},
+		},
+		Ignore: []string{"repo.homepage", "branch_protection.*.enforce_admins"},
+	}
+	calc := NewCalculator(mock, cfg)
+
+	plan, err := calc.Calculate(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, c := range plan.Changes() {
+		got = append(got, string(c.Category)+"."+c.Key)
+	}
+	sort.Strings(got)
+	want := []string{"branch_protection.main.required_reviews", "repo.description"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+
+	categoryPlan, err := calc.CalculateCategory(context.Background(), CategoryRepo, CalculateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if categoryPlan.Size() != 1 || categoryPlan.Changes()[0].Key != "description" {
+		t.Errorf("CalculateCategory(repo) = %v, want only description", categoryPlan.Changes())
+	}
+}
+
+func TestCalculatorStrictDrift(t *testing.T) {
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{
+		Description:         nullStr("same"),
+		AllowMergeCommit:    ptr(true),
+		AllowRebaseMerge:    ptr(true),
+		AllowSquashMerge:    ptr(true),
+		DeleteBranchOnMerge: ptr(true),
+	}
+	mock.BranchProtections = map[string]*github.BranchProtectionData{
+		"main": {
+			RequiredPullRequestReviews: &githubopenapi.ProtectedBranchPullRequestReview{RequiredApprovingReviewCount: ptr(1)},
+			EnforceAdmins:              &githubopenapi.ProtectedBranchAdminEnforced{Enabled: true},
+		},
+	}
+
+	cfg := &config.Config{
+		Repo: &config.RepoConfig{Description: ptr("same")},
+		BranchProtection: map[string]*config.BranchRule{
+			"main": {RequiredReviews: ptr(1)},
+		},
+	}
+
+	plan, err := NewCalculator(mock, cfg).Calculate(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Size() != 0 {
+		t.Errorf("without StrictDrift, plan = %v, want no changes", plan.Changes())
+	}
+
+	plan, err = NewCalculator(mock, cfg).CalculateWithOptions(context.Background(), CalculateOptions{StrictDrift: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, c := range plan.Changes() {
+		if !c.IsInfo() {
+			t.Errorf("change %s should be informational", c.Key)
+		}
+		got = append(got, string(c.Category)+"."+c.Key)
+	}
+	sort.Strings(got)
+	want := []string{"branch_protection.main.enforce_admins", "repo.delete_branch_on_merge"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+}
+
+func TestCalculator_CalculateCategory(t *testing.T) {
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{
+		Visibility: ptr("private"),
+		Topics:     &[]string{"old"},
+	}
+
+	cfg := &config.Config{
+		Repo:   &config.RepoConfig{Visibility: ptr("public")},
+		Topics: []string{"new"},
+	}
+	calc := NewCalculator(mock, cfg)
+
+	t.Run("returns only the requested category", func(t *testing.T) {
+		plan, err := calc.CalculateCategory(context.Background(), CategoryTopics, CalculateOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if plan.Size() != 1 {
+			t.Fatalf("expected 1 change, got %d", plan.Size())
+		}
+		if plan.Changes()[0].Category != CategoryTopics {
+			t.Errorf("category = %s, want topics", plan.Changes()[0].Category)
+		}
+	})
+
+	t.Run("unconfigured category is empty", func(t *testing.T) {
+		plan, err := calc.CalculateCategory(context.Background(), CategoryLabels, CalculateOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if plan.HasChanges() {
+			t.Errorf("expected no changes, got %d", plan.Size())
+		}
+	})
+}
+
+func TestCalculator_Progress(t *testing.T) {
+	mock := github.NewMockClient()
+	cfg := &config.Config{
+		Repo:   &config.RepoConfig{Description: ptr("desc")},
+		Labels: &config.LabelsConfig{},
+	}
+
+	var steps []string
+	_, err := NewCalculator(mock, cfg).CalculateWithOptions(context.Background(), CalculateOptions{
+		Progress: func(step string) { steps = append(steps, step) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"repo settings", "labels", "social preview image"}
+	if strings.Join(steps, ",") != strings.Join(want, ",") {
+		t.Errorf("progress steps = %v, want %v", steps, want)
+	}
+}
@@ -1,6 +1,9 @@
 package diff
 
 import (
+	"context"
+
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
 	"github.com/myzkey/gh-repo-settings/internal/infra/githubopenapi"
 	"github.com/oapi-codegen/nullable"
 )
@@ -25,3 +28,38 @@ func allowedActions(s string) *githubopenapi.AllowedActions {
 func nullBuildType(s string) nullable.Nullable[githubopenapi.GithubPageBuildType] {
 	return nullable.NewNullableWithValue(githubopenapi.GithubPageBuildType(s))
 }
+
+// countingClient counts the reads of sections that take extra requests
+type countingClient struct {
+	*github.MockClient
+	calls map[string]int
+}
+
+func newCountingClient(mock *github.MockClient) *countingClient {
+	return &countingClient{MockClient: mock, calls: make(map[string]int)}
+}
+
+func (c *countingClient) GetActionsPermissions(ctx context.Context) (*github.ActionsPermissionsData, error) {
+	c.calls["GetActionsPermissions"]++
+	return c.MockClient.GetActionsPermissions(ctx)
+}
+
+func (c *countingClient) GetActionsSelectedActions(ctx context.Context) (*github.ActionsSelectedData, error) {
+	c.calls["GetActionsSelectedActions"]++
+	return c.MockClient.GetActionsSelectedActions(ctx)
+}
+
+func (c *countingClient) GetActionsWorkflowPermissions(ctx context.Context) (*github.ActionsWorkflowPermissionsData, error) {
+	c.calls["GetActionsWorkflowPermissions"]++
+	return c.MockClient.GetActionsWorkflowPermissions(ctx)
+}
+
+func (c *countingClient) GetPages(ctx context.Context) (*github.PagesData, error) {
+	c.calls["GetPages"]++
+	return c.MockClient.GetPages(ctx)
+}
+
+func (c *countingClient) GetFile(ctx context.Context, path string) (*github.FileData, error) {
+	c.calls["GetFile"]++
+	return c.MockClient.GetFile(ctx, path)
+}
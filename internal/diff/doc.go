@@ -29,10 +29,12 @@
 //	│   (Domain Models)      │  │   (Domain Services)    │
 //	│                        │  │                        │
 //	│ - Change, ChangeType   │  │ - CompareBranchRule    │
-//	│ - ChangeCategory       │  │ - Pure comparison      │
-//	│ - Plan                 │  │   logic with no        │
-//	│ - BranchProtection*    │  │   infrastructure deps  │
-//	│ - Helper functions     │  │                        │
+//	│ - ChangeCategory       │  │ - CompareRepo          │
+//	│ - Plan                 │  │ - CompareLabels        │
+//	│ - BranchProtection*    │  │ - CompareTemplates     │
+//	│ - Repo*, Label,        │  │ - Pure comparison      │
+//	│   TemplateFile         │  │   logic with no        │
+//	│ - Helper functions     │  │   infrastructure deps  │
 //	└────────────────────────┘  └────────────────────────┘
 //	                              │
 //	                              ▼
@@ -48,7 +50,7 @@
 //   - ChangeCategory is a typed enum to prevent typos and enable safe filtering
 //   - Gateway pattern isolates infrastructure (GitHub API) from domain logic
 //   - Comparators are application services, not domain services
-//   - Domain services (CompareBranchRule) are pure functions with no side effects
+//   - Domain services (CompareBranchRule, CompareRepo, CompareLabels, CompareTemplates) are pure functions with no side effects
 //
 // # Usage
 //
@@ -62,5 +64,32 @@
 //	    }
 //	}
 //
+// Bots that only need a single decision can use the higher-level predicates:
+//
+//	switch {
+//	case plan.IsClean():
+//	    // nothing to do
+//	case plan.NeedsAttention():
+//	    // missing secrets/variables or deletions: ask a human
+//	default:
+//	    // safe to apply unattended
+//	}
+//
+// # Custom comparators
+//
+// Organization-specific checks can be added with RegisterComparator. A comparator
+// is given the same client and configuration as the built-in ones, must only read
+// from GitHub, and reports its findings as changes in its own category:
+//
+//	func init() {
+//	    _ = diff.RegisterComparator("description policy", "policy",
+//	        func(client github.GitHubClient, cfg *config.Config) diff.Comparator {
+//	            return descriptionPolicy{client: client}
+//	        })
+//	}
+//
+// Custom comparators run after the built-in ones. plan shows their changes and
+// counts them for exit codes; apply doesn't act on them.
+//
 // Domain models are re-exported via types.go for backward compatibility.
 package diff
@@ -11,15 +11,27 @@ import (
 
 // ActionsComparator compares GitHub Actions permissions
 type ActionsComparator struct {
-	client github.GitHubClient
-	config *config.ActionsConfig
+	client  github.GitHubClient
+	config  *config.ActionsConfig
+	options ActionsComparatorOptions
+}
+
+// ActionsComparatorOptions contains options for ActionsComparator
+type ActionsComparatorOptions struct {
+	SkipSelectedActions bool // Don't read or compare selected_actions, which takes an extra request
 }
 
 // NewActionsComparator creates a new ActionsComparator
 func NewActionsComparator(client github.GitHubClient, cfg *config.ActionsConfig) *ActionsComparator {
+	return NewActionsComparatorWithOptions(client, cfg, ActionsComparatorOptions{})
+}
+
+// NewActionsComparatorWithOptions creates a new ActionsComparator with options
+func NewActionsComparatorWithOptions(client github.GitHubClient, cfg *config.ActionsConfig, opts ActionsComparatorOptions) *ActionsComparator {
 	return &ActionsComparator{
-		client: client,
-		config: cfg,
+		client:  client,
+		config:  cfg,
+		options: opts,
 	}
 }
 
@@ -27,38 +39,70 @@ func NewActionsComparator(client github.GitHubClient, cfg *config.ActionsConfig)
 func (c *ActionsComparator) Compare(ctx context.Context) (*model.Plan, error) {
 	plan := model.NewPlan()
 
-	// Compare permissions
-	permsPlan, err := c.comparePermissions(ctx)
-	if err != nil {
-		return nil, err
-	}
-	plan.AddAll(permsPlan.Changes())
+	// Each group of settings is read only when the config sets one of them
+	compareSelected := c.config.SelectedActions != nil && !c.options.SkipSelectedActions
 
-	// Compare selected actions
-	if c.config.SelectedActions != nil {
-		selectedPlan, err := c.compareSelectedActions(ctx)
+	// Compare permissions, which are also needed to know whether selected actions apply
+	if c.config.Enabled != nil || c.config.AllowedActions != nil || compareSelected {
+		permsPlan, allowedActions, err := c.comparePermissions(ctx)
 		if err != nil {
 			return nil, err
 		}
-		plan.AddAll(selectedPlan.Changes())
+		plan.AddAll(permsPlan.Changes())
+
+		// Compare selected actions; GitHub rejects writes to them unless allowed_actions is "selected"
+		if compareSelected && allowedActions == "selected" {
+			selectedPlan, err := c.compareSelectedActions(ctx)
+			if err != nil {
+				return nil, err
+			}
+			plan.AddAll(selectedPlan.Changes())
+		}
 	}
 
 	// Compare workflow permissions
-	workflowPlan, err := c.compareWorkflowPermissions(ctx)
-	if err != nil {
-		return nil, err
+	if c.config.DefaultWorkflowPermissions != nil || c.config.CanApprovePullRequestReviews != nil {
+		workflowPlan, err := c.compareWorkflowPermissions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		plan.AddAll(workflowPlan.Changes())
+	}
+
+	// Compare artifact and log retention
+	if c.config.ArtifactRetentionDays != nil {
+		retentionPlan, err := c.compareRetention(ctx)
+		if err != nil {
+			return nil, err
+		}
+		plan.AddAll(retentionPlan.Changes())
+	}
+
+	// Compare access level; it only exists for private repositories, so it is read only when configured
+	if c.config.AccessLevel != nil {
+		accessPlan, err := c.compareAccessLevel(ctx)
+		if err != nil {
+			return nil, err
+		}
+		plan.AddAll(accessPlan.Changes())
 	}
-	plan.AddAll(workflowPlan.Changes())
 
 	return plan, nil
 }
 
-func (c *ActionsComparator) comparePermissions(ctx context.Context) (*model.Plan, error) {
+// comparePermissions also returns the effective allowed_actions after apply:
+// the configured value, or the current one when it isn't configured
+func (c *ActionsComparator) comparePermissions(ctx context.Context) (*model.Plan, string, error) {
 	plan := model.NewPlan()
 
 	currentPerms, err := c.client.GetActionsPermissions(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	currentAllowed := ""
+	if currentPerms.AllowedActions != nil {
+		currentAllowed = string(*currentPerms.AllowedActions)
 	}
 
 	// Compare enabled
@@ -72,11 +116,9 @@ func (c *ActionsComparator) comparePermissions(ctx context.Context) (*model.Plan
 	}
 
 	// Compare allowed_actions
+	effectiveAllowed := currentAllowed
 	if c.config.AllowedActions != nil {
-		currentAllowed := ""
-		if currentPerms.AllowedActions != nil {
-			currentAllowed = string(*currentPerms.AllowedActions)
-		}
+		effectiveAllowed = *c.config.AllowedActions
 		if *c.config.AllowedActions != currentAllowed {
 			plan.Add(model.NewUpdateChange(
 				model.CategoryActions,
@@ -87,7 +129,7 @@ func (c *ActionsComparator) comparePermissions(ctx context.Context) (*model.Plan
 		}
 	}
 
-	return plan, nil
+	return plan, effectiveAllowed, nil
 }
 
 func (c *ActionsComparator) compareSelectedActions(ctx context.Context) (*model.Plan, error) {
@@ -165,3 +207,43 @@ func (c *ActionsComparator) compareWorkflowPermissions(ctx context.Context) (*mo
 
 	return plan, nil
 }
+
+func (c *ActionsComparator) compareAccessLevel(ctx context.Context) (*model.Plan, error) {
+	plan := model.NewPlan()
+
+	current, err := c.client.GetActionsAccessLevel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if *c.config.AccessLevel != current.AccessLevel {
+		plan.Add(model.NewUpdateChange(
+			model.CategoryActions,
+			"access_level",
+			current.AccessLevel,
+			*c.config.AccessLevel,
+		))
+	}
+
+	return plan, nil
+}
+
+func (c *ActionsComparator) compareRetention(ctx context.Context) (*model.Plan, error) {
+	plan := model.NewPlan()
+
+	current, err := c.client.GetActionsRetention(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if *c.config.ArtifactRetentionDays != current.Days {
+		plan.Add(model.NewUpdateChange(
+			model.CategoryActions,
+			"artifact_retention_days",
+			current.Days,
+			*c.config.ArtifactRetentionDays,
+		))
+	}
+
+	return plan, nil
+}
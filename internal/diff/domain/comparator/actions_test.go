@@ -178,6 +178,55 @@ func TestActionsComparator_CompareSelectedActions(t *testing.T) {
 	}
 }
 
+func TestActionsComparator_SelectedActionsGuard(t *testing.T) {
+	tests := []struct {
+		name           string
+		currentAllowed string
+		configAllowed  *string
+		expectSelected bool
+	}{
+		{name: "switching to selected compares selected actions", currentAllowed: "all", configAllowed: ptr("selected"), expectSelected: true},
+		{name: "already selected and not configured", currentAllowed: "selected", expectSelected: true},
+		{name: "switching away from selected skips them", currentAllowed: "selected", configAllowed: ptr("all")},
+		{name: "not selected and not configured skips them", currentAllowed: "local_only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			mock.ActionsPermissions = &github.ActionsPermissionsData{
+				Enabled:        true,
+				AllowedActions: allowedActions(tt.currentAllowed),
+			}
+			mock.ActionsSelected = &github.ActionsSelectedData{GithubOwnedAllowed: ptr(true)}
+			mock.ActionsWorkflowPerms = &github.ActionsWorkflowPermissionsData{
+				DefaultWorkflowPermissions: "read",
+			}
+
+			comparator := NewActionsComparator(mock, &config.ActionsConfig{
+				AllowedActions: tt.configAllowed,
+				SelectedActions: &config.SelectedActionsConfig{
+					GithubOwnedAllowed: ptr(false),
+				},
+			})
+			plan, err := comparator.Compare(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			gotSelected := false
+			for _, c := range plan.Changes() {
+				if c.Key == "github_owned_allowed" {
+					gotSelected = true
+				}
+			}
+			if gotSelected != tt.expectSelected {
+				t.Errorf("selected actions compared = %v, want %v", gotSelected, tt.expectSelected)
+			}
+		})
+	}
+}
+
 func TestActionsComparator_CompareWorkflowPermissions(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -272,7 +321,8 @@ func TestActionsComparator_Errors(t *testing.T) {
 		mock.GetActionsWorkflowPermissionsError = apperrors.ErrPermissionDenied
 
 		comparator := NewActionsComparator(mock, &config.ActionsConfig{
-			Enabled: ptr(true),
+			Enabled:                    ptr(true),
+			DefaultWorkflowPermissions: ptr("read"),
 		})
 
 		_, err := comparator.Compare(context.Background())
@@ -281,3 +331,76 @@ func TestActionsComparator_Errors(t *testing.T) {
 		}
 	})
 }
+
+func TestActionsComparator_CompareAccessLevel(t *testing.T) {
+	tests := []struct {
+		name         string
+		current      string
+		config       *string
+		expectChange bool
+	}{
+		{name: "not configured is not read", current: "none", config: nil},
+		{name: "matches", current: "organization", config: ptr("organization")},
+		{name: "change detected", current: "none", config: ptr("organization"), expectChange: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			mock.ActionsAccess = &github.ActionsAccessData{AccessLevel: tt.current}
+			if tt.config == nil {
+				// Reading the access level fails for public repositories
+				mock.GetActionsAccessLevelError = apperrors.NewAPIError("GET", "repos/owner/repo/actions/permissions/access", 422, "not a private repository", nil)
+			}
+
+			comparator := NewActionsComparator(mock, &config.ActionsConfig{AccessLevel: tt.config})
+			plan, err := comparator.Compare(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var change *model.Change
+			for _, c := range plan.Changes() {
+				if c.Key == "access_level" {
+					c := c
+					change = &c
+				}
+			}
+			if (change != nil) != tt.expectChange {
+				t.Fatalf("access_level change = %v, want %v", change != nil, tt.expectChange)
+			}
+			if change != nil && (change.Old != tt.current || change.New != *tt.config) {
+				t.Errorf("unexpected change: %+v", change)
+			}
+		})
+	}
+}
+
+func TestActionsComparator_CompareRetention(t *testing.T) {
+	mock := github.NewMockClient()
+	mock.ActionsRetention = &github.ActionsRetentionData{Days: 90, MaximumAllowedDays: 90}
+
+	comparator := NewActionsComparator(mock, &config.ActionsConfig{ArtifactRetentionDays: ptr(30)})
+	plan, err := comparator.Compare(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if plan.Size() != 1 {
+		t.Fatalf("expected 1 change, got %d: %+v", plan.Size(), plan.Changes())
+	}
+	change := plan.Changes()[0]
+	if change.Key != "artifact_retention_days" || change.Old != 90 || change.New != 30 {
+		t.Errorf("unexpected change: %+v", change)
+	}
+
+	// Not configured: the retention period isn't read
+	mock.GetActionsRetentionError = apperrors.NewAPIError("GET", "repos/owner/repo/actions/permissions/artifact-and-log-retention", 500, "boom", nil)
+	plan, err = NewActionsComparator(mock, &config.ActionsConfig{}).Compare(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Size() != 0 {
+		t.Errorf("expected no changes, got %+v", plan.Changes())
+	}
+}
@@ -2,6 +2,7 @@ package comparator
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/myzkey/gh-repo-settings/internal/config"
 	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
@@ -14,6 +15,9 @@ import (
 // BranchProtectionGateway provides access to branch protection data
 type BranchProtectionGateway interface {
 	GetBranchProtection(ctx context.Context, branch string) (model.BranchProtectionCurrent, error)
+	BranchExists(ctx context.Context, branch string) (bool, error)
+	// ListBranches returns whether each branch of the repository is protected
+	ListBranches(ctx context.Context) (map[string]bool, error)
 }
 
 // BranchProtectionComparator compares branch protection rules
@@ -21,6 +25,12 @@ type BranchProtectionGateway interface {
 type BranchProtectionComparator struct {
 	gateway BranchProtectionGateway
 	rules   map[string]*config.BranchRule
+	options BranchProtectionComparatorOptions
+}
+
+// BranchProtectionComparatorOptions contains options for BranchProtectionComparator
+type BranchProtectionComparatorOptions struct {
+	ReportUndeclared bool // Report enabled protection settings the config doesn't mention as info changes
 }
 
 // NewBranchProtectionComparator creates a new BranchProtectionComparator
@@ -34,9 +44,15 @@ func NewBranchProtectionComparator(gateway BranchProtectionGateway, rules map[st
 // NewBranchProtectionComparatorWithClient creates a comparator with a GitHub client
 // This is a convenience constructor that creates the gateway internally
 func NewBranchProtectionComparatorWithClient(client github.GitHubClient, rules map[string]*config.BranchRule) *BranchProtectionComparator {
+	return NewBranchProtectionComparatorWithOptions(client, rules, BranchProtectionComparatorOptions{})
+}
+
+// NewBranchProtectionComparatorWithOptions creates a comparator with a GitHub client and options
+func NewBranchProtectionComparatorWithOptions(client github.GitHubClient, rules map[string]*config.BranchRule, opts BranchProtectionComparatorOptions) *BranchProtectionComparator {
 	return &BranchProtectionComparator{
 		gateway: &githubBranchProtectionGateway{client: client},
 		rules:   rules,
+		options: opts,
 	}
 }
 
@@ -44,16 +60,31 @@ func NewBranchProtectionComparatorWithClient(client github.GitHubClient, rules m
 func (c *BranchProtectionComparator) Compare(ctx context.Context) (*model.Plan, error) {
 	plan := model.NewPlan()
 
+	// One branch list tells which branches exist and are protected, so only protected
+	// branches need their rules read. If it can't be read, each branch is probed instead.
+	branches, err := c.gateway.ListBranches(ctx)
+	if err != nil {
+		branches = nil
+	}
+
 	for branchName, rule := range c.rules {
+		if protected, exists := branches[branchName]; branches != nil && !protected {
+			plan.Add(newProtectionAddChange(branchName, rule, exists))
+			continue
+		}
+
 		current, err := c.gateway.GetBranchProtection(ctx, branchName)
 		if err != nil {
 			if apperrors.Is(err, apperrors.ErrBranchNotProtected) {
-				// Branch protection doesn't exist, will be added
-				plan.Add(model.NewAddChange(
-					model.CategoryBranchProtection,
-					branchName,
-					presentation.FormatBranchRule(rule),
-				))
+				// Protection can't be applied to a branch that doesn't exist
+				exists := branches != nil
+				if !exists {
+					exists, err = c.gateway.BranchExists(ctx, branchName)
+					if err != nil {
+						return nil, err
+					}
+				}
+				plan.Add(newProtectionAddChange(branchName, rule, exists))
 				continue
 			}
 			return nil, err
@@ -65,19 +96,49 @@ func (c *BranchProtectionComparator) Compare(ctx context.Context) (*model.Plan,
 		// Use pure domain service for comparison
 		branchChanges := service.CompareBranchRule(branchName, current, desired)
 		plan.AddAll(branchChanges)
+		if c.options.ReportUndeclared {
+			plan.AddAll(service.UndeclaredBranchRuleSettings(branchName, current, desired))
+		}
 	}
 
 	return plan, nil
 }
 
+// newProtectionAddChange describes protection that doesn't exist yet and will be added
+func newProtectionAddChange(branchName string, rule *config.BranchRule, exists bool) model.Change {
+	description := presentation.FormatBranchRule(rule)
+	if !exists {
+		description += fmt.Sprintf(" (warning: branch '%s' does not exist)", branchName)
+	}
+	return model.NewBranchProtectionAddChange(branchName, description)
+}
+
+// desiredStatusCheckApps returns the apps the config pins status checks to
+func desiredStatusCheckApps(checks []config.StatusCheck) map[string]int {
+	var apps map[string]int
+	for _, check := range checks {
+		if check.AppID == nil {
+			continue
+		}
+		if apps == nil {
+			apps = make(map[string]int)
+		}
+		apps[check.Context] = *check.AppID
+	}
+	return apps
+}
+
 // mapBranchRuleToDomain converts config.BranchRule to domain model
 func mapBranchRuleToDomain(rule *config.BranchRule) model.BranchProtectionDesired {
 	return model.BranchProtectionDesired{
+		RequireReviews:       rule.RequireReviews,
 		RequiredReviews:      rule.RequiredReviews,
 		DismissStaleReviews:  rule.DismissStaleReviews,
 		RequireCodeOwner:     rule.RequireCodeOwner,
 		StrictStatusChecks:   rule.StrictStatusChecks,
-		StatusChecks:         rule.StatusChecks,
+		StatusChecks:         config.StatusCheckContexts(rule.StatusChecks),
+		StatusCheckApps:      desiredStatusCheckApps(rule.StatusChecks),
+		StatusChecksOrdered:  model.PtrBoolVal(rule.StatusChecksOrdered),
 		EnforceAdmins:        rule.EnforceAdmins,
 		RequireLinearHistory: rule.RequireLinearHistory,
 		AllowForcePushes:     rule.AllowForcePushes,
@@ -101,11 +162,13 @@ func (g *githubBranchProtectionGateway) GetBranchProtection(
 	}
 
 	return model.BranchProtectionCurrent{
+		ReviewsRequired:      data.RequiredPullRequestReviews != nil,
 		RequiredReviews:      extractRequiredReviews(data),
 		DismissStaleReviews:  extractDismissStaleReviews(data),
 		RequireCodeOwner:     extractRequireCodeOwner(data),
 		StrictStatusChecks:   extractStrictStatusChecks(data),
 		StatusChecks:         extractStatusChecks(data),
+		StatusCheckApps:      extractStatusCheckApps(data),
 		EnforceAdmins:        extractEnforceAdmins(data),
 		RequireLinearHistory: extractRequireLinearHistory(data),
 		AllowForcePushes:     extractAllowForcePushes(data),
@@ -114,6 +177,22 @@ func (g *githubBranchProtectionGateway) GetBranchProtection(
 	}, nil
 }
 
+func (g *githubBranchProtectionGateway) BranchExists(ctx context.Context, branch string) (bool, error) {
+	return g.client.BranchExists(ctx, branch)
+}
+
+func (g *githubBranchProtectionGateway) ListBranches(ctx context.Context) (map[string]bool, error) {
+	branches, err := g.client.ListBranches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	protected := make(map[string]bool, len(branches))
+	for _, b := range branches {
+		protected[b.Name] = b.Protected
+	}
+	return protected, nil
+}
+
 func extractRequiredReviews(data *github.BranchProtectionData) int {
 	if data.RequiredPullRequestReviews != nil && data.RequiredPullRequestReviews.RequiredApprovingReviewCount != nil {
 		return *data.RequiredPullRequestReviews.RequiredApprovingReviewCount
@@ -149,6 +228,25 @@ func extractStatusChecks(data *github.BranchProtectionData) []string {
 	return nil
 }
 
+// extractStatusCheckApps reads the app each check is tied to from the checks array
+func extractStatusCheckApps(data *github.BranchProtectionData) map[string]int {
+	if data.RequiredStatusChecks == nil {
+		return nil
+	}
+	var apps map[string]int
+	for _, check := range data.RequiredStatusChecks.Checks {
+		id, err := check.AppId.Get()
+		if err != nil {
+			continue // null: any app
+		}
+		if apps == nil {
+			apps = make(map[string]int)
+		}
+		apps[check.Context] = id
+	}
+	return apps
+}
+
 func extractEnforceAdmins(data *github.BranchProtectionData) bool {
 	if data.EnforceAdmins != nil {
 		return data.EnforceAdmins.Enabled
@@ -17,6 +17,8 @@
 //   - EnvComparator: Environment variables and secrets
 //   - ActionsComparator: GitHub Actions permissions
 //   - PagesComparator: GitHub Pages settings
+//   - TemplatesComparator: Issue/PR template files
+//   - SocialPreviewComparator: Social preview image (only when forced)
 //
 // # Gateway Pattern
 //
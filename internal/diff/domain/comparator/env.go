@@ -8,11 +8,16 @@ import (
 	"github.com/myzkey/gh-repo-settings/internal/infra/github"
 )
 
+// extraDescription describes a secret or variable that exists on GitHub but not in the config
+const extraDescription = "exists on GitHub but is not in the config"
+
 // EnvComparatorOptions contains options for comparing environment settings
 type EnvComparatorOptions struct {
 	CheckSecrets bool
 	CheckVars    bool
 	SyncDelete   bool
+	ReportExtra  bool          // Report secrets/variables not in config as info changes (ignored with SyncDelete)
+	State        *config.State // Hashes recorded by apply; nil disables secret rotation detection
 }
 
 // EnvComparator compares environment variables and secrets
@@ -66,47 +71,90 @@ func (c *EnvComparator) compareSecrets(ctx context.Context) (*model.Plan, error)
 
 	secretSet := model.ToStringSet(currentSecrets)
 
-	// Check for secrets that need to be added
-	for _, secretName := range c.config.Secrets {
-		if !secretSet[secretName] {
-			// Check if value exists in .env
-			hasValue := false
-			if c.dotEnvValues != nil {
-				_, hasValue = c.dotEnvValues.GetSecret(secretName)
-			}
-			if hasValue {
-				plan.Add(model.NewAddChange(
+	// Check for secrets that need to be added or rotated
+	for _, secretName := range config.SecretNames(c.config.Secrets) {
+		_, source, hasValue := c.secretValue(secretName)
+		if secretSet[secretName] {
+			if c.secretRotated(secretName) {
+				plan.Add(model.NewUpdateChange(
 					model.CategorySecrets,
 					secretName,
-					"(will be set from .env)",
-				))
-			} else {
-				plan.Add(model.NewMissingChange(
-					model.CategorySecrets,
-					secretName,
-					"not in .github/.env (will prompt)",
+					"(previous value)",
+					"(will be set from "+source+")",
 				))
 			}
+			continue
+		}
+
+		if hasValue {
+			plan.Add(model.NewAddChange(
+				model.CategorySecrets,
+				secretName,
+				"(will be set from "+source+")",
+			))
+		} else {
+			plan.Add(model.NewMissingChange(
+				model.CategorySecrets,
+				secretName,
+				"not in .github/.env (will prompt)",
+			))
 		}
 	}
 
 	// Check for secrets to delete (if syncDelete)
 	if c.options.SyncDelete {
-		configSecretSet := model.ToStringSet(c.config.Secrets)
+		configSecretSet := model.ToStringSet(config.SecretNames(c.config.Secrets))
 		for _, s := range currentSecrets {
 			if !configSecretSet[s] {
+				// Secret values can't be read back, so Old records that the secret exists
 				plan.Add(model.NewDeleteChange(
 					model.CategorySecrets,
 					s,
-					nil,
+					"(existing secret)",
 				))
 			}
 		}
+	} else if c.options.ReportExtra {
+		configSecretSet := model.ToStringSet(config.SecretNames(c.config.Secrets))
+		for _, s := range currentSecrets {
+			if !configSecretSet[s] {
+				plan.Add(model.NewInfoChange(model.CategorySecrets, s, extraDescription))
+			}
+		}
 	}
 
 	return plan, nil
 }
 
+// secretValue returns the value a secret is set from and where it comes from:
+// .env (which includes provider values), then an inline value in the config
+func (c *EnvComparator) secretValue(name string) (value, source string, ok bool) {
+	if c.dotEnvValues != nil {
+		if value, ok := c.dotEnvValues.GetSecret(name); ok {
+			return value, ".env", true
+		}
+	}
+	if value, ok := c.config.InlineSecret(name); ok {
+		return value, "config", true
+	}
+	return "", "", false
+}
+
+// secretRotated reports whether the value of an existing secret differs
+// from the hash recorded at the last apply. Without a recorded hash the
+// secret is assumed unchanged.
+func (c *EnvComparator) secretRotated(name string) bool {
+	if c.options.State == nil {
+		return false
+	}
+	value, _, ok := c.secretValue(name)
+	if !ok {
+		return false
+	}
+	changed, _ := c.options.State.SecretChanged(github.FullName(c.client), name, value)
+	return changed
+}
+
 func (c *EnvComparator) compareVariables(ctx context.Context) (*model.Plan, error) {
 	plan := model.NewPlan()
 
@@ -156,6 +204,12 @@ func (c *EnvComparator) compareVariables(ctx context.Context) (*model.Plan, erro
 				))
 			}
 		}
+	} else if c.options.ReportExtra {
+		for _, v := range currentVars {
+			if _, exists := c.config.Variables[v.Name]; !exists {
+				plan.Add(model.NewInfoChange(model.CategoryVariables, v.Name, extraDescription))
+			}
+		}
 	}
 
 	return plan, nil
@@ -14,7 +14,7 @@ func TestEnvComparator_CompareSecrets(t *testing.T) {
 	tests := []struct {
 		name          string
 		currentSecrets []string
-		configSecrets []string
+		configSecrets []config.Secret
 		dotEnv        *config.DotEnvValues
 		syncDelete    bool
 		expectAdds    int
@@ -24,7 +24,7 @@ func TestEnvComparator_CompareSecrets(t *testing.T) {
 		{
 			name:           "all secrets present - no changes",
 			currentSecrets: []string{"API_KEY", "SECRET"},
-			configSecrets:  []string{"API_KEY", "SECRET"},
+			configSecrets:  []config.Secret{{Name: "API_KEY"}, {Name: "SECRET"}},
 			expectAdds:     0,
 			expectMissing:  0,
 			expectDeletes:  0,
@@ -32,7 +32,7 @@ func TestEnvComparator_CompareSecrets(t *testing.T) {
 		{
 			name:           "missing secret without dotenv",
 			currentSecrets: []string{},
-			configSecrets:  []string{"API_KEY"},
+			configSecrets:  []config.Secret{{Name: "API_KEY"}},
 			expectAdds:     0,
 			expectMissing:  1,
 			expectDeletes:  0,
@@ -40,7 +40,7 @@ func TestEnvComparator_CompareSecrets(t *testing.T) {
 		{
 			name:           "missing secret with dotenv value",
 			currentSecrets: []string{},
-			configSecrets:  []string{"API_KEY"},
+			configSecrets:  []config.Secret{{Name: "API_KEY"}},
 			dotEnv: &config.DotEnvValues{
 				Values: map[string]string{"API_KEY": "secret_value"},
 			},
@@ -51,7 +51,7 @@ func TestEnvComparator_CompareSecrets(t *testing.T) {
 		{
 			name:           "delete secret with syncDelete",
 			currentSecrets: []string{"API_KEY", "OLD_SECRET"},
-			configSecrets:  []string{"API_KEY"},
+			configSecrets:  []config.Secret{{Name: "API_KEY"}},
 			syncDelete:     true,
 			expectAdds:     0,
 			expectMissing:  0,
@@ -60,7 +60,7 @@ func TestEnvComparator_CompareSecrets(t *testing.T) {
 		{
 			name:           "no delete without syncDelete",
 			currentSecrets: []string{"API_KEY", "OLD_SECRET"},
-			configSecrets:  []string{"API_KEY"},
+			configSecrets:  []config.Secret{{Name: "API_KEY"}},
 			syncDelete:     false,
 			expectAdds:     0,
 			expectMissing:  0,
@@ -221,7 +221,7 @@ func TestEnvComparator_OptionsFlags(t *testing.T) {
 		mock.Secrets = []string{}
 
 		comparator := NewEnvComparator(mock, &config.EnvConfig{
-			Secrets: []string{"API_KEY"},
+			Secrets: []config.Secret{{Name: "API_KEY"}},
 		}, nil, EnvComparatorOptions{
 			CheckSecrets: false,
 		})
@@ -257,13 +257,143 @@ func TestEnvComparator_OptionsFlags(t *testing.T) {
 	})
 }
 
+func TestEnvComparator_SecretRotation(t *testing.T) {
+	recorded := config.NewState()
+	if err := recorded.RecordSecret("owner/repo", "API_KEY", "old-value"); err != nil {
+		t.Fatalf("failed to record secret: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		state         *config.State
+		dotEnvValue   string
+		expectUpdates int
+	}{
+		{
+			name:          "hash match produces no change",
+			state:         recorded,
+			dotEnvValue:   "old-value",
+			expectUpdates: 0,
+		},
+		{
+			name:          "hash mismatch produces update",
+			state:         recorded,
+			dotEnvValue:   "new-value",
+			expectUpdates: 1,
+		},
+		{
+			name:          "unknown secret is assumed unchanged",
+			state:         config.NewState(),
+			dotEnvValue:   "new-value",
+			expectUpdates: 0,
+		},
+		{
+			name:          "nil state disables detection",
+			state:         nil,
+			dotEnvValue:   "new-value",
+			expectUpdates: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			mock.Owner, mock.Name = "owner", "repo"
+			mock.Secrets = []string{"API_KEY"}
+
+			dotEnv := &config.DotEnvValues{Values: map[string]string{"API_KEY": tt.dotEnvValue}}
+			comparator := NewEnvComparator(mock, &config.EnvConfig{
+				Secrets: []config.Secret{{Name: "API_KEY"}},
+			}, dotEnv, EnvComparatorOptions{
+				CheckSecrets: true,
+				State:        tt.state,
+			})
+
+			plan, err := comparator.Compare(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			updates := 0
+			for _, c := range plan.Changes() {
+				if c.Type == model.ChangeUpdate && c.Category == model.CategorySecrets && c.Key == "API_KEY" {
+					updates++
+				}
+			}
+			if updates != tt.expectUpdates {
+				t.Errorf("expected %d updates, got %d", tt.expectUpdates, updates)
+			}
+		})
+	}
+}
+
+func TestEnvComparator_InlineSecrets(t *testing.T) {
+	inline := "inline-value"
+	cfg := &config.EnvConfig{
+		Secrets: []config.Secret{{Name: "DEMO_KEY", Value: &inline}, {Name: "API_KEY", Value: &inline}},
+	}
+
+	recorded := config.NewState()
+	if err := recorded.RecordSecret("owner/repo", "EXISTING", "old-value"); err != nil {
+		t.Fatalf("failed to record secret: %v", err)
+	}
+
+	t.Run("inline value is set without .env", func(t *testing.T) {
+		mock := github.NewMockClient()
+		plan, err := NewEnvComparator(mock, cfg, nil, EnvComparatorOptions{CheckSecrets: true}).Compare(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, c := range plan.Changes() {
+			if c.Type != model.ChangeAdd || c.New != "(will be set from config)" {
+				t.Errorf("change = %+v, want an add from the config", c)
+			}
+		}
+		if plan.HasMissingSecrets() {
+			t.Error("inline secrets reported as missing")
+		}
+	})
+
+	t.Run(".env takes precedence", func(t *testing.T) {
+		mock := github.NewMockClient()
+		dotEnv := &config.DotEnvValues{Values: map[string]string{"API_KEY": "from-env"}}
+		plan, err := NewEnvComparator(mock, cfg, dotEnv, EnvComparatorOptions{CheckSecrets: true}).Compare(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, c := range plan.Changes() {
+			want := "(will be set from config)"
+			if c.Key == "API_KEY" {
+				want = "(will be set from .env)"
+			}
+			if c.New != want {
+				t.Errorf("%s: New = %v, want %q", c.Key, c.New, want)
+			}
+		}
+	})
+
+	t.Run("changed inline value is a rotation", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.Owner, mock.Name = "owner", "repo"
+		mock.Secrets = []string{"EXISTING"}
+		rotated := &config.EnvConfig{Secrets: []config.Secret{{Name: "EXISTING", Value: &inline}}}
+		plan, err := NewEnvComparator(mock, rotated, nil, EnvComparatorOptions{CheckSecrets: true, State: recorded}).Compare(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stats := plan.Stats(); stats.Update != 1 {
+			t.Errorf("stats = %+v, want one update", stats)
+		}
+	})
+}
+
 func TestEnvComparator_Errors(t *testing.T) {
 	t.Run("GetSecrets error", func(t *testing.T) {
 		mock := github.NewMockClient()
 		mock.GetSecretsError = apperrors.ErrPermissionDenied
 
 		comparator := NewEnvComparator(mock, &config.EnvConfig{
-			Secrets: []string{"KEY"},
+			Secrets: []config.Secret{{Name: "KEY"}},
 		}, nil, EnvComparatorOptions{CheckSecrets: true})
 
 		_, err := comparator.Compare(context.Background())
@@ -286,3 +416,52 @@ func TestEnvComparator_Errors(t *testing.T) {
 		}
 	})
 }
+
+func TestEnvComparator_ReportExtra(t *testing.T) {
+	mock := github.NewMockClient()
+	mock.Secrets = []string{"API_KEY", "LEGACY_TOKEN"}
+	mock.Variables = []github.VariableData{{Name: "ENV", Value: "prod"}, {Name: "OLD_VAR", Value: "x"}}
+
+	cfg := &config.EnvConfig{
+		Secrets:   []config.Secret{{Name: "API_KEY"}},
+		Variables: map[string]string{"ENV": "prod"},
+	}
+
+	plan, err := NewEnvComparator(mock, cfg, nil, EnvComparatorOptions{
+		CheckSecrets: true,
+		CheckVars:    true,
+		ReportExtra:  true,
+	}).Compare(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stats := plan.Stats()
+	if stats.Info != 2 || stats.Delete != 0 {
+		t.Errorf("stats = %+v, want 2 info and no deletes", stats)
+	}
+	if plan.HasChanges() || plan.HasDeletes() {
+		t.Error("extra secrets and variables should not count as changes")
+	}
+	keys := map[string]bool{}
+	for _, c := range plan.Changes() {
+		keys[string(c.Category)+"."+c.Key] = c.IsInfo()
+	}
+	if !keys["secrets.LEGACY_TOKEN"] || !keys["variables.OLD_VAR"] {
+		t.Errorf("unexpected changes: %+v", plan.Changes())
+	}
+
+	// With sync they are deletes instead
+	plan, err = NewEnvComparator(mock, cfg, nil, EnvComparatorOptions{
+		CheckSecrets: true,
+		CheckVars:    true,
+		SyncDelete:   true,
+		ReportExtra:  true,
+	}).Compare(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats := plan.Stats(); stats.Info != 0 || stats.Delete != 2 {
+		t.Errorf("stats with sync = %+v, want 2 deletes", stats)
+	}
+}
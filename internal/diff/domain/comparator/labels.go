@@ -2,10 +2,10 @@ package comparator
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/myzkey/gh-repo-settings/internal/config"
 	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/service"
 	"github.com/myzkey/gh-repo-settings/internal/infra/github"
 )
 
@@ -30,57 +30,29 @@ func (c *LabelsComparator) Compare(ctx context.Context) (*model.Plan, error) {
 		return nil, err
 	}
 
-	plan := model.NewPlan()
-
-	currentMap := make(map[string]github.LabelData)
-	for _, l := range currentLabels {
-		currentMap[l.Name] = l
-	}
-
-	configMap := make(map[string]config.Label)
-	for _, l := range c.config.Items {
-		configMap[l.Name] = l
-	}
-
-	// Check for additions and updates
-	for _, cfgLabel := range c.config.Items {
-		if current, exists := currentMap[cfgLabel.Name]; exists {
-			// Check for updates
-			currentDesc := model.NullableStringVal(current.Description)
-			if cfgLabel.Color != current.Color || cfgLabel.Description != currentDesc {
-				plan.Add(model.NewUpdateChange(
-					model.CategoryLabels,
-					cfgLabel.Name,
-					formatLabel(current.Color, currentDesc),
-					formatLabel(cfgLabel.Color, cfgLabel.Description),
-				))
-			}
-		} else {
-			// Addition
-			plan.Add(model.NewAddChange(
-				model.CategoryLabels,
-				cfgLabel.Name,
-				formatLabel(cfgLabel.Color, cfgLabel.Description),
-			))
+	// Map infrastructure and config types to domain models
+	current := make([]model.Label, len(currentLabels))
+	for i, l := range currentLabels {
+		current[i] = model.Label{
+			Name:        l.Name,
+			Color:       l.Color,
+			Description: model.NullableStringVal(l.Description),
 		}
 	}
 
-	// Check for deletions (only if replace_default is true)
-	if c.config.ReplaceDefault {
-		for _, currentLabel := range currentLabels {
-			if _, exists := configMap[currentLabel.Name]; !exists {
-				plan.Add(model.NewDeleteChange(
-					model.CategoryLabels,
-					currentLabel.Name,
-					formatLabel(currentLabel.Color, model.NullableStringVal(currentLabel.Description)),
-				))
-			}
+	desired := make([]model.Label, len(c.config.Items))
+	for i, l := range c.config.Items {
+		desired[i] = model.Label{
+			Name:            l.Name,
+			Color:           l.Color,
+			Description:     model.PtrStringVal(l.Description),
+			KeepDescription: l.Description == nil,
 		}
 	}
 
-	return plan, nil
-}
+	// Use pure domain service for comparison
+	plan := model.NewPlan()
+	plan.AddAll(service.CompareLabels(current, desired, c.config.ReplaceDefault, c.config.IsExcluded))
 
-func formatLabel(color, description string) string {
-	return fmt.Sprintf("color=%s, description=%s", color, description)
+	return plan, nil
 }
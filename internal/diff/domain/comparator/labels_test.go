@@ -27,7 +27,7 @@ func TestLabelsComparator_Compare(t *testing.T) {
 			},
 			config: &config.LabelsConfig{
 				Items: []config.Label{
-					{Name: "bug", Color: "d73a4a", Description: "Bug report"},
+					{Name: "bug", Color: "d73a4a", Description: ptr("Bug report")},
 				},
 			},
 			expectAdds: 0,
@@ -39,7 +39,7 @@ func TestLabelsComparator_Compare(t *testing.T) {
 			current: []github.LabelData{},
 			config: &config.LabelsConfig{
 				Items: []config.Label{
-					{Name: "bug", Color: "d73a4a", Description: "Bug report"},
+					{Name: "bug", Color: "d73a4a", Description: ptr("Bug report")},
 				},
 			},
 			expectAdds: 1,
@@ -53,7 +53,7 @@ func TestLabelsComparator_Compare(t *testing.T) {
 			},
 			config: &config.LabelsConfig{
 				Items: []config.Label{
-					{Name: "bug", Color: "ff0000", Description: "Bug report"},
+					{Name: "bug", Color: "ff0000", Description: ptr("Bug report")},
 				},
 			},
 			expectAdds: 0,
@@ -67,7 +67,7 @@ func TestLabelsComparator_Compare(t *testing.T) {
 			},
 			config: &config.LabelsConfig{
 				Items: []config.Label{
-					{Name: "bug", Color: "d73a4a", Description: "New description"},
+					{Name: "bug", Color: "d73a4a", Description: ptr("New description")},
 				},
 			},
 			expectAdds: 0,
@@ -184,29 +184,3 @@ func TestLabelsComparator_GetLabelsError(t *testing.T) {
 		t.Error("expected error, got nil")
 	}
 }
-
-func TestFormatLabel(t *testing.T) {
-	tests := []struct {
-		color       string
-		description string
-		expected    string
-	}{
-		{
-			color:       "d73a4a",
-			description: "Bug report",
-			expected:    "color=d73a4a, description=Bug report",
-		},
-		{
-			color:       "ffffff",
-			description: "",
-			expected:    "color=ffffff, description=",
-		},
-	}
-
-	for _, tt := range tests {
-		result := formatLabel(tt.color, tt.description)
-		if result != tt.expected {
-			t.Errorf("formatLabel(%q, %q) = %q, want %q", tt.color, tt.description, result, tt.expected)
-		}
-	}
-}
@@ -0,0 +1,84 @@
+package comparator
+
+import (
+	"context"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+)
+
+// OrgActionsComparator compares organization GitHub Actions permissions
+type OrgActionsComparator struct {
+	client github.OrgGitHubClient
+	config *config.OrgActionsConfig
+}
+
+// NewOrgActionsComparator creates a new OrgActionsComparator
+func NewOrgActionsComparator(client github.OrgGitHubClient, cfg *config.OrgActionsConfig) *OrgActionsComparator {
+	return &OrgActionsComparator{
+		client: client,
+		config: cfg,
+	}
+}
+
+// Compare compares the current org actions settings with the desired configuration
+func (c *OrgActionsComparator) Compare(ctx context.Context) (*model.Plan, error) {
+	plan := model.NewPlan()
+
+	// Compare permissions
+	if c.config.EnabledRepositories != nil || c.config.AllowedActions != nil {
+		currentPerms, err := c.client.GetOrgActionsPermissions(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.config.EnabledRepositories != nil && *c.config.EnabledRepositories != currentPerms.EnabledRepositories {
+			plan.Add(model.NewUpdateChange(
+				model.CategoryOrgActions,
+				"enabled_repositories",
+				currentPerms.EnabledRepositories,
+				*c.config.EnabledRepositories,
+			))
+		}
+
+		if c.config.AllowedActions != nil && !model.PtrStringEqual(c.config.AllowedActions, currentPerms.AllowedActions) {
+			plan.Add(model.NewUpdateChange(
+				model.CategoryOrgActions,
+				"allowed_actions",
+				model.PtrVal(currentPerms.AllowedActions),
+				*c.config.AllowedActions,
+			))
+		}
+	}
+
+	// Compare workflow permissions
+	if c.config.DefaultWorkflowPermissions != nil || c.config.CanApprovePullRequestReviews != nil {
+		currentWorkflow, err := c.client.GetOrgWorkflowPermissions(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		currentPerms := string(currentWorkflow.DefaultWorkflowPermissions)
+		if c.config.DefaultWorkflowPermissions != nil && *c.config.DefaultWorkflowPermissions != currentPerms {
+			plan.Add(model.NewUpdateChange(
+				model.CategoryOrgActions,
+				"default_workflow_permissions",
+				currentPerms,
+				*c.config.DefaultWorkflowPermissions,
+			))
+		}
+
+		currentCanApprove := bool(currentWorkflow.CanApprovePullRequestReviews)
+		if c.config.CanApprovePullRequestReviews != nil && *c.config.CanApprovePullRequestReviews != currentCanApprove {
+			plan.Add(model.NewUpdateChange(
+				model.CategoryOrgActions,
+				"can_approve_pull_request_reviews",
+				currentCanApprove,
+				*c.config.CanApprovePullRequestReviews,
+			))
+		}
+	}
+
+	return plan, nil
+}
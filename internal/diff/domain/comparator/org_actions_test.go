@@ -0,0 +1,125 @@
+package comparator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+)
+
+func TestOrgActionsComparator_Compare(t *testing.T) {
+	tests := []struct {
+		name            string
+		currentPerms    *github.OrgActionsPermissionsData
+		currentWorkflow *github.ActionsWorkflowPermissionsData
+		config          *config.OrgActionsConfig
+		expectedKeys    []string
+	}{
+		{
+			name:            "no changes when config matches",
+			currentPerms:    &github.OrgActionsPermissionsData{EnabledRepositories: "all", AllowedActions: ptr("selected")},
+			currentWorkflow: &github.ActionsWorkflowPermissionsData{DefaultWorkflowPermissions: "read"},
+			config: &config.OrgActionsConfig{
+				EnabledRepositories:          ptr("all"),
+				AllowedActions:               ptr("selected"),
+				DefaultWorkflowPermissions:   ptr("read"),
+				CanApprovePullRequestReviews: ptr(false),
+			},
+			expectedKeys: []string{},
+		},
+		{
+			name:            "enabled_repositories change detected",
+			currentPerms:    &github.OrgActionsPermissionsData{EnabledRepositories: "all"},
+			currentWorkflow: &github.ActionsWorkflowPermissionsData{},
+			config:          &config.OrgActionsConfig{EnabledRepositories: ptr("selected")},
+			expectedKeys:    []string{"enabled_repositories"},
+		},
+		{
+			name:            "allowed_actions change detected",
+			currentPerms:    &github.OrgActionsPermissionsData{EnabledRepositories: "all", AllowedActions: ptr("all")},
+			currentWorkflow: &github.ActionsWorkflowPermissionsData{},
+			config:          &config.OrgActionsConfig{AllowedActions: ptr("local_only")},
+			expectedKeys:    []string{"allowed_actions"},
+		},
+		{
+			name:            "workflow permission changes detected",
+			currentPerms:    &github.OrgActionsPermissionsData{EnabledRepositories: "all"},
+			currentWorkflow: &github.ActionsWorkflowPermissionsData{DefaultWorkflowPermissions: "write", CanApprovePullRequestReviews: true},
+			config: &config.OrgActionsConfig{
+				DefaultWorkflowPermissions:   ptr("read"),
+				CanApprovePullRequestReviews: ptr(false),
+			},
+			expectedKeys: []string{"default_workflow_permissions", "can_approve_pull_request_reviews"},
+		},
+		{
+			name:            "nil config fields produce no changes",
+			currentPerms:    &github.OrgActionsPermissionsData{EnabledRepositories: "none"},
+			currentWorkflow: &github.ActionsWorkflowPermissionsData{DefaultWorkflowPermissions: "write"},
+			config:          &config.OrgActionsConfig{},
+			expectedKeys:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockOrgClient()
+			mock.ActionsPermissions = tt.currentPerms
+			mock.ActionsWorkflowPerms = tt.currentWorkflow
+
+			comparator := NewOrgActionsComparator(mock, tt.config)
+			plan, err := comparator.Compare(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			changes := plan.Changes()
+			if len(changes) != len(tt.expectedKeys) {
+				t.Fatalf("expected %d changes, got %d: %+v", len(tt.expectedKeys), len(changes), changes)
+			}
+			for i, key := range tt.expectedKeys {
+				if changes[i].Key != key {
+					t.Errorf("change[%d].Key = %s, want %s", i, changes[i].Key, key)
+				}
+				if changes[i].Category != model.CategoryOrgActions {
+					t.Errorf("change[%d].Category = %s, want %s", i, changes[i].Category, model.CategoryOrgActions)
+				}
+			}
+		})
+	}
+}
+
+func TestOrgActionsComparator_Errors(t *testing.T) {
+	t.Run("GetOrgActionsPermissions error", func(t *testing.T) {
+		mock := github.NewMockOrgClient()
+		mock.GetOrgActionsPermissionsError = apperrors.ErrPermissionDenied
+
+		comparator := NewOrgActionsComparator(mock, &config.OrgActionsConfig{EnabledRepositories: ptr("all")})
+		if _, err := comparator.Compare(context.Background()); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("GetOrgWorkflowPermissions error", func(t *testing.T) {
+		mock := github.NewMockOrgClient()
+		mock.GetOrgWorkflowPermissionsError = apperrors.ErrPermissionDenied
+
+		comparator := NewOrgActionsComparator(mock, &config.OrgActionsConfig{DefaultWorkflowPermissions: ptr("read")})
+		if _, err := comparator.Compare(context.Background()); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("unconfigured sections are not fetched", func(t *testing.T) {
+		mock := github.NewMockOrgClient()
+		mock.GetOrgActionsPermissionsError = apperrors.ErrPermissionDenied
+		mock.GetOrgWorkflowPermissionsError = apperrors.ErrPermissionDenied
+
+		comparator := NewOrgActionsComparator(mock, &config.OrgActionsConfig{})
+		if _, err := comparator.Compare(context.Background()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/myzkey/gh-repo-settings/internal/infra/github"
 )
 
+// buildTypeSwitchWarning explains why switching the Pages build type needs confirmation
+const buildTypeSwitchWarning = "switching the build type can take the live site down until the new source publishes it"
+
 // PagesComparator compares GitHub Pages settings
 type PagesComparator struct {
 	client github.GitHubClient
@@ -53,12 +56,17 @@ func (c *PagesComparator) Compare(ctx context.Context) (*model.Plan, error) {
 			currentBuildType = string(current.BuildType.MustGet())
 		}
 		if *c.config.BuildType != currentBuildType {
-			plan.Add(model.NewUpdateChange(
+			change := model.NewUpdateChange(
 				model.CategoryPages,
 				"build_type",
 				currentBuildType,
 				*c.config.BuildType,
-			))
+			)
+			// Switching between legacy and workflow builds changes what the live site is published from
+			if currentBuildType != "" {
+				change = change.WithWarning(buildTypeSwitchWarning)
+			}
+			plan.Add(change)
 		}
 	}
 
@@ -82,5 +90,36 @@ func (c *PagesComparator) Compare(ctx context.Context) (*model.Plan, error) {
 		}
 	}
 
+	// Compare custom domain; an empty string in the config removes it
+	if c.config.Cname != nil {
+		currentCname := ""
+		if current.Cname.IsSpecified() && !current.Cname.IsNull() {
+			currentCname = current.Cname.MustGet()
+		}
+		if *c.config.Cname != currentCname {
+			switch {
+			case currentCname == "":
+				plan.Add(model.NewAddChange(model.CategoryPages, "cname", *c.config.Cname))
+			case *c.config.Cname == "":
+				plan.Add(model.NewDeleteChange(model.CategoryPages, "cname", currentCname))
+			default:
+				plan.Add(model.NewUpdateChange(model.CategoryPages, "cname", currentCname, *c.config.Cname))
+			}
+		}
+	}
+
+	// Compare HTTPS enforcement
+	if c.config.HttpsEnforced != nil {
+		currentHTTPS := current.HttpsEnforced != nil && *current.HttpsEnforced
+		if *c.config.HttpsEnforced != currentHTTPS {
+			plan.Add(model.NewUpdateChange(
+				model.CategoryPages,
+				"https_enforced",
+				currentHTTPS,
+				*c.config.HttpsEnforced,
+			))
+		}
+	}
+
 	return plan, nil
 }
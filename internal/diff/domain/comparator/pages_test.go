@@ -8,6 +8,7 @@ import (
 	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
 	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+	"github.com/oapi-codegen/nullable"
 )
 
 func TestPagesComparator_Compare(t *testing.T) {
@@ -169,6 +170,37 @@ func TestPagesComparator_AddPagesDefaultBuildType(t *testing.T) {
 	}
 }
 
+func TestPagesComparator_BuildTypeSwitchIsDisruptive(t *testing.T) {
+	tests := []struct {
+		name           string
+		current        *github.PagesData
+		want           string
+		wantDisruptive bool
+	}{
+		{name: "legacy to workflow", current: &github.PagesData{BuildType: nullBuildType("legacy")}, want: "workflow", wantDisruptive: true},
+		{name: "workflow to legacy", current: &github.PagesData{BuildType: nullBuildType("workflow")}, want: "legacy", wantDisruptive: true},
+		{name: "unknown build type", current: &github.PagesData{}, want: "workflow", wantDisruptive: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			mock.PagesData = tt.current
+
+			plan, err := NewPagesComparator(mock, &config.PagesConfig{BuildType: ptr(tt.want)}).Compare(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if plan.Size() != 1 {
+				t.Fatalf("expected 1 change, got %+v", plan.Changes())
+			}
+			if got := plan.Changes()[0].IsDisruptive(); got != tt.wantDisruptive {
+				t.Errorf("IsDisruptive() = %v, want %v", got, tt.wantDisruptive)
+			}
+		})
+	}
+}
+
 func TestPagesComparator_GetPagesError(t *testing.T) {
 	mock := github.NewMockClient()
 	mock.GetPagesError = apperrors.ErrPermissionDenied
@@ -182,3 +214,81 @@ func TestPagesComparator_GetPagesError(t *testing.T) {
 		t.Error("expected error, got nil")
 	}
 }
+
+func TestPagesComparator_CustomDomain(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    *github.PagesData
+		config     *config.PagesConfig
+		expectKeys map[string]model.ChangeType
+	}{
+		{
+			name:       "add custom domain",
+			current:    &github.PagesData{BuildType: nullBuildType("workflow")},
+			config:     &config.PagesConfig{Cname: ptr("docs.example.com")},
+			expectKeys: map[string]model.ChangeType{"cname": model.ChangeAdd},
+		},
+		{
+			name: "remove custom domain",
+			current: &github.PagesData{
+				BuildType: nullBuildType("workflow"),
+				Cname:     nullable.NewNullableWithValue("docs.example.com"),
+			},
+			config:     &config.PagesConfig{Cname: ptr("")},
+			expectKeys: map[string]model.ChangeType{"cname": model.ChangeDelete},
+		},
+		{
+			name: "change custom domain",
+			current: &github.PagesData{
+				BuildType: nullBuildType("workflow"),
+				Cname:     nullable.NewNullableWithValue("old.example.com"),
+			},
+			config:     &config.PagesConfig{Cname: ptr("docs.example.com")},
+			expectKeys: map[string]model.ChangeType{"cname": model.ChangeUpdate},
+		},
+		{
+			name:       "no custom domain to remove",
+			current:    &github.PagesData{BuildType: nullBuildType("workflow"), Cname: nullable.NewNullNullable[string]()},
+			config:     &config.PagesConfig{Cname: ptr("")},
+			expectKeys: map[string]model.ChangeType{},
+		},
+		{
+			name:       "add custom domain and enforce https",
+			current:    &github.PagesData{BuildType: nullBuildType("workflow"), HttpsEnforced: ptr(false)},
+			config:     &config.PagesConfig{Cname: ptr("docs.example.com"), HttpsEnforced: ptr(true)},
+			expectKeys: map[string]model.ChangeType{"cname": model.ChangeAdd, "https_enforced": model.ChangeUpdate},
+		},
+		{
+			name:       "https already enforced",
+			current:    &github.PagesData{BuildType: nullBuildType("workflow"), HttpsEnforced: ptr(true)},
+			config:     &config.PagesConfig{HttpsEnforced: ptr(true)},
+			expectKeys: map[string]model.ChangeType{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			mock.PagesData = tt.current
+
+			plan, err := NewPagesComparator(mock, tt.config).Compare(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if plan.Size() != len(tt.expectKeys) {
+				t.Fatalf("expected %d changes, got %d: %+v", len(tt.expectKeys), plan.Size(), plan.Changes())
+			}
+			for _, c := range plan.Changes() {
+				want, ok := tt.expectKeys[c.Key]
+				if !ok {
+					t.Errorf("unexpected change for %q", c.Key)
+					continue
+				}
+				if c.Type != want {
+					t.Errorf("%s: expected %v, got %v", c.Key, want, c.Type)
+				}
+			}
+		})
+	}
+}
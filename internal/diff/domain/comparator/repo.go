@@ -2,23 +2,39 @@ package comparator
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/myzkey/gh-repo-settings/internal/config"
 	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/service"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 	"github.com/myzkey/gh-repo-settings/internal/infra/github"
 )
 
 // RepoComparator compares repository settings
 type RepoComparator struct {
-	client github.GitHubClient
-	config *config.RepoConfig
+	client  github.GitHubClient
+	config  *config.RepoConfig
+	options RepoComparatorOptions
+}
+
+// RepoComparatorOptions contains options for RepoComparator
+type RepoComparatorOptions struct {
+	ReportUndeclared bool // Report settings the config doesn't mention that differ from GitHub's defaults as info changes
 }
 
 // NewRepoComparator creates a new RepoComparator
 func NewRepoComparator(client github.GitHubClient, cfg *config.RepoConfig) *RepoComparator {
+	return NewRepoComparatorWithOptions(client, cfg, RepoComparatorOptions{})
+}
+
+// NewRepoComparatorWithOptions creates a new RepoComparator with options
+func NewRepoComparatorWithOptions(client github.GitHubClient, cfg *config.RepoConfig, opts RepoComparatorOptions) *RepoComparator {
 	return &RepoComparator{
-		client: client,
-		config: cfg,
+		client:  client,
+		config:  cfg,
+		options: opts,
 	}
 }
 
@@ -29,82 +45,79 @@ func (c *RepoComparator) Compare(ctx context.Context) (*model.Plan, error) {
 		return nil, err
 	}
 
-	plan := model.NewPlan()
-	cfg := c.config
-
-	if cfg.Description != nil && !model.NullableStringEqual(cfg.Description, current.Description) {
-		plan.Add(model.NewUpdateChange(
-			model.CategoryRepo,
-			"description",
-			model.NullableStringVal(current.Description),
-			model.PtrVal(cfg.Description),
-		))
-	}
-
-	if cfg.Homepage != nil && !model.NullableStringEqual(cfg.Homepage, current.Homepage) {
-		plan.Add(model.NewUpdateChange(
-			model.CategoryRepo,
-			"homepage",
-			model.NullableStringVal(current.Homepage),
-			model.PtrVal(cfg.Homepage),
-		))
+	// Map infrastructure and config types to domain models
+	currentState := mapRepoDataToDomain(current)
+	desired := mapRepoConfigToDomain(c.config)
+
+	// Use pure domain service for comparison
+	changes := service.CompareRepo(currentState, desired)
+	for _, change := range changes {
+		if change.Key != "visibility" {
+			continue
+		}
+		if err := checkVisibilityChange(current, *desired.Visibility); err != nil {
+			return nil, err
+		}
 	}
 
-	if cfg.Visibility != nil && !model.PtrStringEqual(cfg.Visibility, current.Visibility) {
-		plan.Add(model.NewUpdateChange(
-			model.CategoryRepo,
-			"visibility",
-			model.PtrVal(current.Visibility),
-			*cfg.Visibility,
-		))
-	}
-
-	if cfg.AllowMergeCommit != nil && !model.PtrBoolEqual(cfg.AllowMergeCommit, current.AllowMergeCommit) {
-		plan.Add(model.NewUpdateChange(
-			model.CategoryRepo,
-			"allow_merge_commit",
-			model.PtrBoolVal(current.AllowMergeCommit),
-			*cfg.AllowMergeCommit,
-		))
+	plan := model.NewPlan()
+	plan.AddAll(changes)
+	if c.options.ReportUndeclared {
+		plan.AddAll(service.UndeclaredRepoSettings(currentState, desired))
 	}
 
-	if cfg.AllowRebaseMerge != nil && !model.PtrBoolEqual(cfg.AllowRebaseMerge, current.AllowRebaseMerge) {
-		plan.Add(model.NewUpdateChange(
-			model.CategoryRepo,
-			"allow_rebase_merge",
-			model.PtrBoolVal(current.AllowRebaseMerge),
-			*cfg.AllowRebaseMerge,
-		))
-	}
+	return plan, nil
+}
 
-	if cfg.AllowSquashMerge != nil && !model.PtrBoolEqual(cfg.AllowSquashMerge, current.AllowSquashMerge) {
-		plan.Add(model.NewUpdateChange(
-			model.CategoryRepo,
-			"allow_squash_merge",
-			model.PtrBoolVal(current.AllowSquashMerge),
-			*cfg.AllowSquashMerge,
-		))
+// checkVisibilityChange rejects visibility changes GitHub refuses, so the plan fails
+// with the reason instead of apply failing with a bare 422
+func checkVisibilityChange(current *github.RepoData, visibility string) error {
+	switch {
+	case current.Fork:
+		return apperrors.NewValidationError("repo.visibility", "the visibility of a fork can't be changed")
+	case visibility == "internal" && current.Owner.Type == "User":
+		return apperrors.NewValidationError("repo.visibility",
+			fmt.Sprintf("only organization repositories can be internal, and %s is owned by a user", current.Owner.Login))
 	}
+	return nil
+}
 
-	if cfg.DeleteBranchOnMerge != nil && !model.PtrBoolEqual(cfg.DeleteBranchOnMerge, current.DeleteBranchOnMerge) {
-		plan.Add(model.NewUpdateChange(
-			model.CategoryRepo,
-			"delete_branch_on_merge",
-			model.PtrBoolVal(current.DeleteBranchOnMerge),
-			*cfg.DeleteBranchOnMerge,
-		))
+// mapRepoDataToDomain converts github.RepoData to domain model
+func mapRepoDataToDomain(data *github.RepoData) model.RepoCurrent {
+	return model.RepoCurrent{
+		Description:              model.NullableStringVal(data.Description),
+		Homepage:                 model.NullableStringVal(data.Homepage),
+		Visibility:               model.PtrVal(data.Visibility),
+		AllowMergeCommit:         model.PtrBoolVal(data.AllowMergeCommit),
+		AllowRebaseMerge:         model.PtrBoolVal(data.AllowRebaseMerge),
+		AllowSquashMerge:         model.PtrBoolVal(data.AllowSquashMerge),
+		DeleteBranchOnMerge:      model.PtrBoolVal(data.DeleteBranchOnMerge),
+		AllowUpdateBranch:        model.PtrBoolVal(data.AllowUpdateBranch),
+		AllowAutoMerge:           model.PtrBoolVal(data.AllowAutoMerge),
+		UseSquashPRTitle:         model.PtrBoolVal(data.UseSquashPrTitleAsDefault),
+		WebCommitSignoffRequired: model.PtrBoolVal(data.WebCommitSignoffRequired),
+		IsTemplate:               model.PtrBoolVal(data.IsTemplate),
+		Archived:                 data.Archived,
 	}
+}
 
-	if cfg.AllowUpdateBranch != nil && !model.PtrBoolEqual(cfg.AllowUpdateBranch, current.AllowUpdateBranch) {
-		plan.Add(model.NewUpdateChange(
-			model.CategoryRepo,
-			"allow_update_branch",
-			model.PtrBoolVal(current.AllowUpdateBranch),
-			*cfg.AllowUpdateBranch,
-		))
+// mapRepoConfigToDomain converts config.RepoConfig to domain model
+func mapRepoConfigToDomain(cfg *config.RepoConfig) model.RepoDesired {
+	return model.RepoDesired{
+		Description:              cfg.Description,
+		Homepage:                 cfg.Homepage,
+		Visibility:               cfg.Visibility,
+		AllowMergeCommit:         cfg.AllowMergeCommit,
+		AllowRebaseMerge:         cfg.AllowRebaseMerge,
+		AllowSquashMerge:         cfg.AllowSquashMerge,
+		DeleteBranchOnMerge:      cfg.DeleteBranchOnMerge,
+		AllowUpdateBranch:        cfg.AllowUpdateBranch,
+		AllowAutoMerge:           cfg.AllowAutoMerge,
+		UseSquashPRTitle:         cfg.UseSquashPRTitle,
+		WebCommitSignoffRequired: cfg.WebCommitSignoffRequired,
+		IsTemplate:               cfg.IsTemplate,
+		Archived:                 cfg.Archived,
 	}
-
-	return plan, nil
 }
 
 // TopicsComparator compares repository topics
@@ -135,7 +148,8 @@ func (c *TopicsComparator) Compare(ctx context.Context) (*model.Plan, error) {
 		currentTopics = *current.Topics
 	}
 
-	if !model.StringSliceEqualIgnoreOrder(c.topics, currentTopics) {
+	// GitHub stores topics in lowercase, so casing alone is not drift
+	if !model.StringSliceEqualIgnoreOrder(storedTopics(c.topics), lowerAll(currentTopics)) {
 		plan.Add(model.NewUpdateChange(
 			model.CategoryTopics,
 			"topics",
@@ -146,3 +160,30 @@ func (c *TopicsComparator) Compare(ctx context.Context) (*model.Plan, error) {
 
 	return plan, nil
 }
+
+// storedTopics returns topics the way GitHub stores them: lowercased, without duplicates
+// and cut to the first config.MaxTopics, so a config over the limit doesn't drift forever
+func storedTopics(topics []string) []string {
+	seen := make(map[string]bool, len(topics))
+	var stored []string
+	for _, topic := range lowerAll(topics) {
+		if seen[topic] {
+			continue
+		}
+		seen[topic] = true
+		stored = append(stored, topic)
+	}
+	if len(stored) > config.MaxTopics {
+		stored = stored[:config.MaxTopics]
+	}
+	return stored
+}
+
+// lowerAll returns a copy of values converted to lowercase
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(v)
+	}
+	return lowered
+}
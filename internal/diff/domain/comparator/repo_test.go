@@ -2,12 +2,15 @@ package comparator
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/myzkey/gh-repo-settings/internal/config"
 	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
 	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+	"github.com/myzkey/gh-repo-settings/internal/infra/githubopenapi"
 )
 
 func TestRepoComparator_Compare(t *testing.T) {
@@ -62,11 +65,13 @@ func TestRepoComparator_Compare(t *testing.T) {
 		{
 			name: "boolean fields change detected",
 			current: &github.RepoData{
-				AllowMergeCommit:    ptr(true),
-				AllowRebaseMerge:    ptr(true),
-				AllowSquashMerge:    ptr(false),
-				DeleteBranchOnMerge: ptr(false),
-				AllowUpdateBranch:   ptr(false),
+				AllowMergeCommit:          ptr(true),
+				AllowRebaseMerge:          ptr(true),
+				AllowSquashMerge:          ptr(false),
+				DeleteBranchOnMerge:       ptr(false),
+				AllowUpdateBranch:         ptr(false),
+				AllowAutoMerge:            ptr(false),
+				UseSquashPrTitleAsDefault: ptr(true),
 			},
 			config: &config.RepoConfig{
 				AllowMergeCommit:    ptr(false),
@@ -74,6 +79,8 @@ func TestRepoComparator_Compare(t *testing.T) {
 				AllowSquashMerge:    ptr(true),
 				DeleteBranchOnMerge: ptr(true),
 				AllowUpdateBranch:   ptr(true),
+				AllowAutoMerge:      ptr(true),
+				UseSquashPRTitle:    ptr(false),
 			},
 			expectedKeys: []string{
 				"allow_merge_commit",
@@ -81,6 +88,8 @@ func TestRepoComparator_Compare(t *testing.T) {
 				"allow_squash_merge",
 				"delete_branch_on_merge",
 				"allow_update_branch",
+				"allow_auto_merge",
+				"use_squash_pr_title_as_default",
 			},
 		},
 		{
@@ -151,6 +160,56 @@ func TestRepoComparator_GetRepoError(t *testing.T) {
 	}
 }
 
+func TestRepoComparator_VisibilityTransitions(t *testing.T) {
+	org := githubopenapi.SimpleUser{Login: "my-org", Type: "Organization"}
+	user := githubopenapi.SimpleUser{Login: "octocat", Type: "User"}
+
+	tests := []struct {
+		name      string
+		owner     githubopenapi.SimpleUser
+		fork      bool
+		current   string
+		desired   string
+		wantError string // Empty when the change is allowed
+	}{
+		{name: "org private to internal", owner: org, current: "private", desired: "internal"},
+		{name: "org internal to public", owner: org, current: "internal", desired: "public"},
+		{name: "org public to private", owner: org, current: "public", desired: "private"},
+		{name: "user private to public", owner: user, current: "private", desired: "public"},
+		{name: "user public to private", owner: user, current: "public", desired: "private"},
+		{name: "user private to internal", owner: user, current: "private", desired: "internal", wantError: "owned by a user"},
+		{name: "user public to internal", owner: user, current: "public", desired: "internal", wantError: "owned by a user"},
+		{name: "fork", owner: org, fork: true, current: "public", desired: "private", wantError: "fork"},
+		{name: "unchanged fork", owner: user, fork: true, current: "public", desired: "public"},
+		{name: "unchanged user repo", owner: user, current: "private", desired: "private"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			mock.RepoData = &github.RepoData{Owner: tt.owner, Fork: tt.fork, Visibility: ptr(tt.current)}
+
+			plan, err := NewRepoComparator(mock, &config.RepoConfig{Visibility: ptr(tt.desired)}).Compare(context.Background())
+			if tt.wantError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if want := tt.current != tt.desired; plan.HasChanges() != want {
+					t.Errorf("HasChanges() = %v, want %v", plan.HasChanges(), want)
+				}
+				return
+			}
+			var validationErr *apperrors.ValidationError
+			if !apperrors.As(err, &validationErr) || validationErr.Field != "repo.visibility" {
+				t.Fatalf("expected a repo.visibility validation error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
 func TestTopicsComparator_Compare(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -170,6 +229,12 @@ func TestTopicsComparator_Compare(t *testing.T) {
 			desired:      []string{"go", "cli"},
 			expectChange: false,
 		},
+		{
+			name:         "no changes when only casing differs",
+			current:      []string{"go", "cli"},
+			desired:      []string{"Go", "CLI"},
+			expectChange: false,
+		},
 		{
 			name:         "change when topics added",
 			current:      []string{"go"},
@@ -200,6 +265,24 @@ func TestTopicsComparator_Compare(t *testing.T) {
 			desired:      []string{"go"},
 			expectChange: true,
 		},
+		{
+			name:         "no changes when topics differ only by a duplicate in another case",
+			current:      []string{"go", "cli"},
+			desired:      []string{"go", "Go", "cli"},
+			expectChange: false,
+		},
+		{
+			name:         "no changes when GitHub kept the first 20 of 25 topics",
+			current:      numberedTopics(20),
+			desired:      numberedTopics(25),
+			expectChange: false,
+		},
+		{
+			name:         "change when the kept topics differ from the first 20",
+			current:      numberedTopics(25)[5:],
+			desired:      numberedTopics(25),
+			expectChange: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -235,3 +318,12 @@ func TestTopicsComparator_Compare(t *testing.T) {
 		})
 	}
 }
+
+// numberedTopics returns n topics named topic-0, topic-1, ...
+func numberedTopics(n int) []string {
+	topics := make([]string, n)
+	for i := range topics {
+		topics[i] = fmt.Sprintf("topic-%d", i)
+	}
+	return topics
+}
@@ -0,0 +1,34 @@
+package comparator
+
+import (
+	"context"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+// SocialPreviewComparator decides whether to upload the social preview image.
+// GitHub doesn't expose the current image in a comparable form, so a change is
+// emitted only when forced; otherwise every run would report a phantom diff.
+type SocialPreviewComparator struct {
+	imagePath *string
+	force     bool
+}
+
+// NewSocialPreviewComparator creates a new SocialPreviewComparator
+func NewSocialPreviewComparator(imagePath *string, force bool) *SocialPreviewComparator {
+	return &SocialPreviewComparator{
+		imagePath: imagePath,
+		force:     force,
+	}
+}
+
+// Compare returns an upload change when an image is configured and forced
+func (c *SocialPreviewComparator) Compare(ctx context.Context) (*model.Plan, error) {
+	plan := model.NewPlan()
+	if c.imagePath == nil || *c.imagePath == "" || !c.force {
+		return plan, nil
+	}
+
+	plan.Add(model.NewAddChange(model.CategorySocialPreview, "image", *c.imagePath))
+	return plan, nil
+}
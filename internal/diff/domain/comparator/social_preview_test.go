@@ -0,0 +1,45 @@
+package comparator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+func TestSocialPreviewComparator(t *testing.T) {
+	tests := []struct {
+		name      string
+		imagePath *string
+		force     bool
+		wantSize  int
+	}{
+		{name: "not configured", imagePath: nil, force: true, wantSize: 0},
+		{name: "empty path", imagePath: ptr(""), force: true, wantSize: 0},
+		{name: "configured without force", imagePath: ptr(".github/preview.png"), force: false, wantSize: 0},
+		{name: "configured and forced", imagePath: ptr(".github/preview.png"), force: true, wantSize: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := NewSocialPreviewComparator(tt.imagePath, tt.force).Compare(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if plan.Size() != tt.wantSize {
+				t.Fatalf("expected %d changes, got %d", tt.wantSize, plan.Size())
+			}
+			if tt.wantSize == 0 {
+				return
+			}
+
+			change := plan.Changes()[0]
+			if change.Category != model.CategorySocialPreview || change.Type != model.ChangeAdd {
+				t.Errorf("unexpected change: %+v", change)
+			}
+			if change.New != *tt.imagePath {
+				t.Errorf("New = %v, want %s", change.New, *tt.imagePath)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package comparator
+
+import (
+	"context"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/service"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+)
+
+// TemplatesComparator compares issue/PR template files
+type TemplatesComparator struct {
+	client github.GitHubClient
+	config *config.TemplatesConfig
+}
+
+// NewTemplatesComparator creates a new TemplatesComparator
+func NewTemplatesComparator(client github.GitHubClient, cfg *config.TemplatesConfig) *TemplatesComparator {
+	return &TemplatesComparator{
+		client: client,
+		config: cfg,
+	}
+}
+
+// Compare compares the files in the repository with the local template files
+func (c *TemplatesComparator) Compare(ctx context.Context) (*model.Plan, error) {
+	current := make(map[string]string, len(c.config.Files))
+	desired := make([]model.TemplateFile, 0, len(c.config.Files))
+
+	for _, f := range c.config.Files {
+		content, err := f.LoadContent()
+		if err != nil {
+			return nil, err
+		}
+		desired = append(desired, model.TemplateFile{Path: f.Path, SHA: model.BlobSHA(content)})
+
+		file, err := c.client.GetFile(ctx, f.Path)
+		if err != nil {
+			if apperrors.Is(err, apperrors.ErrFileNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		current[f.Path] = file.SHA
+	}
+
+	plan := model.NewPlan()
+	plan.AddAll(service.CompareTemplates(current, desired))
+	return plan, nil
+}
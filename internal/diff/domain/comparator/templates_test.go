@@ -0,0 +1,94 @@
+package comparator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+)
+
+func TestTemplatesComparator(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "pr.md")
+	if err := os.WriteFile(source, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	cfg := &config.TemplatesConfig{
+		Files: []config.TemplateFile{{Path: ".github/PULL_REQUEST_TEMPLATE.md", Source: source}},
+	}
+
+	tests := []struct {
+		name     string
+		files    map[string]*github.FileData
+		wantType model.ChangeType
+		wantSize int
+	}{
+		{
+			name:     "new file",
+			files:    map[string]*github.FileData{},
+			wantType: model.ChangeAdd,
+			wantSize: 1,
+		},
+		{
+			name: "changed file",
+			files: map[string]*github.FileData{
+				".github/PULL_REQUEST_TEMPLATE.md": {SHA: "3b18e512dba79e4c8300dd08aeb37f8e728b8dad"},
+			},
+			wantType: model.ChangeUpdate,
+			wantSize: 1,
+		},
+		{
+			name: "unchanged file",
+			files: map[string]*github.FileData{
+				".github/PULL_REQUEST_TEMPLATE.md": {SHA: "ce013625030ba8dba906f756967f9e9ca394464a"},
+			},
+			wantSize: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := github.NewMockClient()
+			mock.Files = tt.files
+
+			plan, err := NewTemplatesComparator(mock, cfg).Compare(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if plan.Size() != tt.wantSize {
+				t.Fatalf("expected %d changes, got %d", tt.wantSize, plan.Size())
+			}
+			if tt.wantSize > 0 {
+				change := plan.Changes()[0]
+				if change.Type != tt.wantType || change.Category != model.CategoryTemplates {
+					t.Errorf("unexpected change: %+v", change)
+				}
+				if change.Key != ".github/PULL_REQUEST_TEMPLATE.md" {
+					t.Errorf("key = %q, want target path", change.Key)
+				}
+			}
+		})
+	}
+
+	t.Run("missing source file", func(t *testing.T) {
+		missing := &config.TemplatesConfig{
+			Files: []config.TemplateFile{{Path: "a.md", Source: filepath.Join(dir, "missing.md")}},
+		}
+		if _, err := NewTemplatesComparator(github.NewMockClient(), missing).Compare(context.Background()); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("API error", func(t *testing.T) {
+		mock := github.NewMockClient()
+		mock.GetFileError = errors.New("boom")
+		if _, err := NewTemplatesComparator(mock, cfg).Compare(context.Background()); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -1,13 +1,17 @@
 package model
 
+import "fmt"
+
 // BranchProtectionCurrent represents the current state of branch protection
 // This is a domain model independent of infrastructure (GitHub API)
 type BranchProtectionCurrent struct {
+	ReviewsRequired      bool // Whether pull request reviews are required at all
 	RequiredReviews      int
 	DismissStaleReviews  bool
 	RequireCodeOwner     bool
 	StrictStatusChecks   bool
 	StatusChecks         []string
+	StatusCheckApps      map[string]int // App ID that must report each check, for checks tied to an app
 	EnforceAdmins        bool
 	RequireLinearHistory bool
 	AllowForcePushes     bool
@@ -18,14 +22,32 @@ type BranchProtectionCurrent struct {
 // BranchProtectionDesired represents the desired state of branch protection
 // This is a domain model independent of configuration format
 type BranchProtectionDesired struct {
+	RequireReviews       *bool // false removes the review requirement
 	RequiredReviews      *int
 	DismissStaleReviews  *bool
 	RequireCodeOwner     *bool
 	StrictStatusChecks   *bool
 	StatusChecks         []string
+	StatusCheckApps      map[string]int // App IDs pinned by the config; -1 requires that any app may report
+	StatusChecksOrdered  bool           // Compare StatusChecks in order instead of as a set
 	EnforceAdmins        *bool
 	RequireLinearHistory *bool
 	AllowForcePushes     *bool
 	AllowDeletions       *bool
 	RequireSignedCommits *bool
 }
+
+// AnyStatusCheckApp is the app ID that lets any app report a status check
+const AnyStatusCheckApp = -1
+
+// FormatStatusChecks lists status checks with the app each one is tied to, e.g. "test (app 15368)"
+func FormatStatusChecks(contexts []string, apps map[string]int) []string {
+	formatted := make([]string, len(contexts))
+	for i, c := range contexts {
+		formatted[i] = c
+		if id, ok := apps[c]; ok && id != AnyStatusCheckApp {
+			formatted[i] = fmt.Sprintf("%s (app %d)", c, id)
+		}
+	}
+	return formatted
+}
@@ -16,6 +16,9 @@ func TestChangeCategoryInvariants(t *testing.T) {
 			CategorySecrets,
 			CategoryActions,
 			CategoryPages,
+			CategorySocialPreview,
+			CategoryTemplates,
+			CategoryOrgActions,
 		}
 
 		for _, cat := range categories {
@@ -35,6 +38,9 @@ func TestChangeCategoryInvariants(t *testing.T) {
 			CategorySecrets,
 			CategoryActions,
 			CategoryPages,
+			CategorySocialPreview,
+			CategoryTemplates,
+			CategoryOrgActions,
 		}
 
 		seen := make(map[ChangeCategory]bool)
@@ -65,6 +71,7 @@ func TestChangeCategoryInvariants(t *testing.T) {
 			{CategorySecrets, "secrets"},
 			{CategoryActions, "actions"},
 			{CategoryPages, "pages"},
+			{CategoryOrgActions, "org_actions"},
 		}
 
 		for _, tt := range tests {
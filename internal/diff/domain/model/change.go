@@ -10,6 +10,7 @@ const (
 	ChangeUpdate
 	ChangeDelete
 	ChangeMissing // For secrets/env that are required but missing
+	ChangeInfo    // For findings that are reported but never applied, e.g. unmanaged secrets
 )
 
 func (c ChangeType) String() string {
@@ -22,11 +23,23 @@ func (c ChangeType) String() string {
 		return "delete"
 	case ChangeMissing:
 		return "missing"
+	case ChangeInfo:
+		return "info"
 	default:
 		return "unknown"
 	}
 }
 
+// ParseChangeType returns the ChangeType named s, the inverse of ChangeType.String
+func ParseChangeType(s string) (ChangeType, error) {
+	for _, t := range []ChangeType{ChangeAdd, ChangeUpdate, ChangeDelete, ChangeMissing, ChangeInfo} {
+		if t.String() == s {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown change type %q", s)
+}
+
 // ChangeCategory represents the category of a change
 type ChangeCategory string
 
@@ -39,6 +52,9 @@ const (
 	CategorySecrets          ChangeCategory = "secrets"
 	CategoryActions          ChangeCategory = "actions"
 	CategoryPages            ChangeCategory = "pages"
+	CategorySocialPreview    ChangeCategory = "social_preview"
+	CategoryTemplates        ChangeCategory = "templates"
+	CategoryOrgActions       ChangeCategory = "org_actions"
 )
 
 // CategoryEnv is an alias for CategoryVariables for backward compatibility
@@ -56,6 +72,18 @@ type Change struct {
 	Key      string
 	Old      interface{}
 	New      interface{}
+	// Branch and Field identify branch protection changes, whose Key joins them with a dot.
+	// Branch names may contain dots themselves, so the Key can't be split reliably.
+	// Field is empty when protection is added to the whole branch.
+	Branch string
+	Field  string
+	// CurrentName is the name of the existing label an update applies to, when it
+	// differs from Key, e.g. "bug " matched by "bug". It is empty otherwise.
+	CurrentName string
+	// Warning explains why applying the change may be disruptive; apply asks to confirm such changes
+	Warning string
+	// Reason is the config's _meta note on why the setting has its value
+	Reason string
 }
 
 // NewAddChange creates a new add change
@@ -89,6 +117,34 @@ func NewDeleteChange(category ChangeCategory, key string, oldValue interface{})
 	}
 }
 
+// NewBranchProtectionAddChange creates the change that protects an unprotected branch
+func NewBranchProtectionAddChange(branch string, description interface{}) Change {
+	change := NewAddChange(CategoryBranchProtection, branch, description)
+	change.Branch = branch
+	return change
+}
+
+// NewBranchProtectionUpdateChange creates a change to one protection setting of a branch
+func NewBranchProtectionUpdateChange(branch, field string, oldValue, newValue interface{}) Change {
+	change := NewUpdateChange(CategoryBranchProtection, branch+"."+field, oldValue, newValue)
+	change.Branch = branch
+	change.Field = field
+	return change
+}
+
+// NewBranchProtectionInfoChange creates an informational finding about one protection setting of a branch
+func NewBranchProtectionInfoChange(branch, field string, description interface{}) Change {
+	change := NewInfoChange(CategoryBranchProtection, branch+"."+field, description)
+	change.Branch = branch
+	change.Field = field
+	return change
+}
+
+// UndeclaredDescription describes a setting the config doesn't mention that GitHub has at value
+func UndeclaredDescription(value interface{}) string {
+	return fmt.Sprintf("%v on GitHub but not in the config", value)
+}
+
 // NewMissingChange creates a new missing change (for secrets/env)
 func NewMissingChange(category ChangeCategory, key string, description interface{}) Change {
 	return Change{
@@ -99,6 +155,36 @@ func NewMissingChange(category ChangeCategory, key string, description interface
 	}
 }
 
+// NewInfoChange creates an informational change, which plan reports but apply ignores
+func NewInfoChange(category ChangeCategory, key string, description interface{}) Change {
+	return Change{
+		Type:     ChangeInfo,
+		Category: category,
+		Key:      key,
+		New:      description,
+	}
+}
+
+// UnreadableSection is the value of a missing change recording that a section
+// of settings couldn't be read for lack of permissions
+type UnreadableSection string
+
+// String describes the unreadable section
+func (u UnreadableSection) String() string {
+	return "insufficient permissions to read " + string(u)
+}
+
+// MarshalText renders the description in JSON output
+func (u UnreadableSection) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// NewUnreadableChange creates a missing change for a section that couldn't be read,
+// e.g. NewUnreadableChange(CategoryActions, "actions permissions")
+func NewUnreadableChange(category ChangeCategory, section string) Change {
+	return NewMissingChange(category, section, UnreadableSection(section))
+}
+
 // IsAdd returns true if this is an add change
 func (c Change) IsAdd() bool {
 	return c.Type == ChangeAdd
@@ -119,6 +205,22 @@ func (c Change) IsMissing() bool {
 	return c.Type == ChangeMissing
 }
 
+// IsInfo returns true if this is an informational change
+func (c Change) IsInfo() bool {
+	return c.Type == ChangeInfo
+}
+
+// IsUnreadable returns true if this change records a section that couldn't be read
+func (c Change) IsUnreadable() bool {
+	_, ok := c.New.(UnreadableSection)
+	return c.Type == ChangeMissing && ok
+}
+
+// IsDisruptive returns true if applying this change may be disruptive
+func (c Change) IsDisruptive() bool {
+	return c.Warning != ""
+}
+
 // Invert returns the inverse of this change (add becomes delete, etc.)
 func (c Change) Invert() Change {
 	inverted := c
@@ -134,6 +236,10 @@ func (c Change) Invert() Change {
 	case ChangeUpdate:
 		inverted.Old = c.New
 		inverted.New = c.Old
+		// Undoing a rename finds the label by its new name and restores the old one
+		if c.CurrentName != "" {
+			inverted.Key, inverted.CurrentName = c.CurrentName, c.Key
+		}
 	}
 	return inverted
 }
@@ -145,6 +251,20 @@ func (c Change) WithCategory(category ChangeCategory) Change {
 	return result
 }
 
+// WithWarning returns a copy of the change marked as disruptive for the given reason
+func (c Change) WithWarning(warning string) Change {
+	result := c
+	result.Warning = warning
+	return result
+}
+
+// WithReason returns a copy of the change annotated with the config's reason for it
+func (c Change) WithReason(reason string) Change {
+	result := c
+	result.Reason = reason
+	return result
+}
+
 // WithKeyPrefix returns a copy of the change with a prefixed key
 func (c Change) WithKeyPrefix(prefix string) Change {
 	result := c
@@ -163,6 +283,8 @@ func (c Change) String() string {
 		return fmt.Sprintf("[DELETE] %s.%s (was %v)", c.Category, c.Key, c.Old)
 	case ChangeMissing:
 		return fmt.Sprintf("[MISSING] %s.%s: %v", c.Category, c.Key, c.New)
+	case ChangeInfo:
+		return fmt.Sprintf("[INFO] %s.%s: %v", c.Category, c.Key, c.New)
 	default:
 		return fmt.Sprintf("[UNKNOWN] %s.%s", c.Category, c.Key)
 	}
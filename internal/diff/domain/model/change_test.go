@@ -318,6 +318,21 @@ func TestChangeTypeString(t *testing.T) {
 	})
 }
 
+func TestParseChangeType(t *testing.T) {
+	for _, changeType := range []ChangeType{ChangeAdd, ChangeUpdate, ChangeDelete, ChangeMissing, ChangeInfo} {
+		got, err := ParseChangeType(changeType.String())
+		if err != nil || got != changeType {
+			t.Errorf("ParseChangeType(%q) = %v, %v; want %v", changeType.String(), got, err, changeType)
+		}
+	}
+
+	for _, name := range []string{"unknown", "", "ADD"} {
+		if _, err := ParseChangeType(name); err == nil {
+			t.Errorf("ParseChangeType(%q) expected error", name)
+		}
+	}
+}
+
 // TestChangeStringContainsEssentialInfo tests Change.String() contains essential information
 // These are loose assertions to avoid brittleness from formatting changes
 func TestChangeStringContainsEssentialInfo(t *testing.T) {
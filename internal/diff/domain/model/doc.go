@@ -8,6 +8,9 @@
 //   - ChangeCategory: Typed enumeration of resource categories (repo, labels, etc.)
 //   - Plan: A collection of changes with rich query and transformation methods
 //   - BranchProtectionCurrent/Desired: Domain models for branch protection state
+//   - RepoCurrent/Desired: Domain models for repository settings state
+//   - Label: Domain model for a repository label
+//   - TemplateFile: Domain model for a synced file, identified by its blob SHA
 //
 // # Design Principles
 //
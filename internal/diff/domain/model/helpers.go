@@ -78,6 +78,14 @@ func PtrBoolVal(b *bool) bool {
 	return *b
 }
 
+// PtrStringVal returns the string value from a *string, defaulting to "" if nil
+func PtrStringVal(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 // PtrStringEqual compares *string values, returning true if they're equal or if cfg is nil
 func PtrStringEqual(cfg, current *string) bool {
 	if cfg == nil {
@@ -0,0 +1,13 @@
+package model
+
+// Label represents a repository label
+// This is a domain model shared by the current (GitHub) and desired (config) states
+type Label struct {
+	Name        string
+	Color       string
+	Description string
+
+	// KeepDescription is set on desired labels whose description isn't managed,
+	// so the current description is left as it is
+	KeepDescription bool
+}
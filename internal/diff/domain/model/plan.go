@@ -1,8 +1,21 @@
 package model
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
 // Plan represents the execution plan containing all changes
 type Plan struct {
+	mu      sync.Mutex
 	changes []Change
+	// repository is the "owner/name" the plan was calculated for, when known
+	repository string
 }
 
 // NewPlan creates an empty plan
@@ -29,6 +42,24 @@ func (p *Plan) AddAll(changes []Change) {
 	p.changes = append(p.changes, changes...)
 }
 
+// Append adds changes to the plan while holding the plan's lock.
+// Unlike Add and AddAll, it is safe to call from multiple goroutines.
+func (p *Plan) Append(changes ...Change) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.changes = append(p.changes, changes...)
+}
+
+// Repository returns the "owner/name" the plan was calculated for, or "" if unknown
+func (p *Plan) Repository() string {
+	return p.repository
+}
+
+// SetRepository records the "owner/name" the plan was calculated for
+func (p *Plan) SetRepository(repository string) {
+	p.repository = repository
+}
+
 // Changes returns all changes in the plan
 func (p *Plan) Changes() []Change {
 	return p.changes
@@ -39,9 +70,15 @@ func (p *Plan) IsEmpty() bool {
 	return len(p.changes) == 0
 }
 
-// HasChanges returns true if there are any changes
+// HasChanges returns true if there are any changes to apply.
+// Informational changes don't count.
 func (p *Plan) HasChanges() bool {
-	return len(p.changes) > 0
+	for _, c := range p.changes {
+		if !c.IsInfo() {
+			return true
+		}
+	}
+	return false
 }
 
 // Size returns the number of changes
@@ -87,12 +124,37 @@ func (p *Plan) FilterByType(changeType ChangeType) *Plan {
 
 // HasMissingSecrets returns true if there are missing secrets
 func (p *Plan) HasMissingSecrets() bool {
-	return !p.FilterByCategory(CategorySecrets).FilterByType(ChangeMissing).IsEmpty()
+	return !p.FilterByCategory(CategorySecrets).Filter(isRequiredMissing).IsEmpty()
 }
 
 // HasMissingVariables returns true if there are missing variables
 func (p *Plan) HasMissingVariables() bool {
-	return !p.FilterByCategory(CategoryEnv).FilterByType(ChangeMissing).IsEmpty()
+	return !p.FilterByCategory(CategoryEnv).Filter(isRequiredMissing).IsEmpty()
+}
+
+// HasUnreadable returns true if a section of settings couldn't be read
+func (p *Plan) HasUnreadable() bool {
+	return !p.Filter(Change.IsUnreadable).IsEmpty()
+}
+
+// HasInfo returns true if the plan reports informational findings
+func (p *Plan) HasInfo() bool {
+	return !p.Filter(Change.IsInfo).IsEmpty()
+}
+
+// Disruptive returns the changes that may be disruptive to apply
+func (p *Plan) Disruptive() *Plan {
+	return p.Filter(Change.IsDisruptive)
+}
+
+// WithoutUnreadable returns a plan without the changes recording unreadable sections
+func (p *Plan) WithoutUnreadable() *Plan {
+	return p.Filter(func(c Change) bool { return !c.IsUnreadable() })
+}
+
+// isRequiredMissing reports whether c is a required secret or variable that is missing
+func isRequiredMissing(c Change) bool {
+	return c.IsMissing() && !c.IsUnreadable()
 }
 
 // HasDeletes returns true if there are any delete changes
@@ -105,6 +167,17 @@ func (p *Plan) HasDeletes() bool {
 	return false
 }
 
+// IsClean returns true if the repository already matches the configuration
+func (p *Plan) IsClean() bool {
+	return !p.HasChanges()
+}
+
+// NeedsAttention returns true if the plan can't be applied unattended:
+// required secrets/variables are missing or something would be deleted
+func (p *Plan) NeedsAttention() bool {
+	return p.HasMissingSecrets() || p.HasMissingVariables() || p.HasDeletes()
+}
+
 // CountByType returns the count of changes by type
 func (p *Plan) CountByType() map[ChangeType]int {
 	counts := make(map[ChangeType]int)
@@ -114,6 +187,53 @@ func (p *Plan) CountByType() map[ChangeType]int {
 	return counts
 }
 
+// PlanStats summarizes the number of changes in a plan by type
+type PlanStats struct {
+	Add     int
+	Update  int
+	Delete  int
+	Missing int
+	Info    int
+}
+
+// Count returns the number of changes of the given type
+func (s PlanStats) Count(changeType ChangeType) int {
+	switch changeType {
+	case ChangeAdd:
+		return s.Add
+	case ChangeUpdate:
+		return s.Update
+	case ChangeDelete:
+		return s.Delete
+	case ChangeMissing:
+		return s.Missing
+	case ChangeInfo:
+		return s.Info
+	default:
+		return 0
+	}
+}
+
+// Stats returns the number of changes by type
+func (p *Plan) Stats() PlanStats {
+	var stats PlanStats
+	for _, c := range p.changes {
+		switch c.Type {
+		case ChangeAdd:
+			stats.Add++
+		case ChangeUpdate:
+			stats.Update++
+		case ChangeDelete:
+			stats.Delete++
+		case ChangeMissing:
+			stats.Missing++
+		case ChangeInfo:
+			stats.Info++
+		}
+	}
+	return stats
+}
+
 // CountByCategory returns the count of changes by category
 func (p *Plan) CountByCategory() map[ChangeCategory]int {
 	counts := make(map[ChangeCategory]int)
@@ -144,3 +264,53 @@ func (p *Plan) Categories() []ChangeCategory {
 	}
 	return categories
 }
+
+// Hash returns a stable SHA-256 hex digest of the changes to apply, independent of
+// their order. Two plans with the same changes, values included, have the same hash.
+// Informational changes are left out since apply ignores them.
+func (p *Plan) Hash() string {
+	lines := make([]string, 0, len(p.changes))
+	for _, c := range p.changes {
+		if c.IsInfo() {
+			continue
+		}
+		lines = append(lines, changeIdentity(c))
+	}
+	sort.Strings(lines)
+
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Diff compares p with an earlier plan of the same repository. Changes only in p have
+// appeared since previous; changes only in previous have been resolved (or changed value).
+func (p *Plan) Diff(previous *Plan) (appeared, resolved *Plan) {
+	return p.without(previous), previous.without(p)
+}
+
+// without returns the changes of p that other doesn't have, values included
+func (p *Plan) without(other *Plan) *Plan {
+	seen := make(map[string]bool, len(other.changes))
+	for _, c := range other.changes {
+		seen[changeIdentity(c)] = true
+	}
+	return p.Filter(func(c Change) bool {
+		return !seen[changeIdentity(c)]
+	})
+}
+
+// changeIdentity identifies a change by its category, key, type and values
+func changeIdentity(c Change) string {
+	return strings.Join([]string{
+		string(c.Category), c.Key, c.Type.String(), hashValue(c.Old), hashValue(c.New),
+	}, "\x00")
+}
+
+// hashValue renders a change value for Hash; JSON sorts map keys, so it is stable
+func hashValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+	return string(data)
+}
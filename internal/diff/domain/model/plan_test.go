@@ -1,6 +1,8 @@
 package model
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 )
 
@@ -359,6 +361,27 @@ func TestPlanCountInvariants(t *testing.T) {
 		}
 	})
 
+	t.Run("Stats matches CountByType", func(t *testing.T) {
+		plan := NewPlanFromChanges([]Change{
+			NewAddChange(CategoryLabels, "a", "1"),
+			NewUpdateChange(CategoryRepo, "b", "old", "new"),
+			NewUpdateChange(CategoryRepo, "c", "old", "new"),
+			NewDeleteChange(CategoryLabels, "d", "val"),
+			NewMissingChange(CategorySecrets, "e", "missing"),
+		})
+
+		stats := plan.Stats()
+		counts := plan.CountByType()
+		for _, ct := range []ChangeType{ChangeAdd, ChangeUpdate, ChangeDelete, ChangeMissing} {
+			if stats.Count(ct) != counts[ct] {
+				t.Errorf("Stats().Count(%s) = %d, want %d", ct, stats.Count(ct), counts[ct])
+			}
+		}
+		if stats.Update != 2 {
+			t.Errorf("Stats().Update = %d, want 2", stats.Update)
+		}
+	})
+
 	t.Run("CountByCategory sums to Size", func(t *testing.T) {
 		plan := NewPlanFromChanges([]Change{
 			NewAddChange(CategoryLabels, "a", "1"),
@@ -466,6 +489,78 @@ func TestPlanHasMethodsInvariants(t *testing.T) {
 	})
 }
 
+func TestPlanIsCleanNeedsAttention(t *testing.T) {
+	tests := []struct {
+		name          string
+		changes       []Change
+		wantClean     bool
+		wantAttention bool
+	}{
+		{
+			name:      "empty plan",
+			changes:   nil,
+			wantClean: true,
+		},
+		{
+			name: "adds and updates only",
+			changes: []Change{
+				NewAddChange(CategoryLabels, "bug", "d73a4a"),
+				NewUpdateChange(CategoryRepo, "description", "old", "new"),
+			},
+		},
+		{
+			name: "delete",
+			changes: []Change{
+				NewUpdateChange(CategoryRepo, "description", "old", "new"),
+				NewDeleteChange(CategoryLabels, "stale", "ffffff"),
+			},
+			wantAttention: true,
+		},
+		{
+			name: "missing secret",
+			changes: []Change{
+				NewMissingChange(CategorySecrets, "TOKEN", "not set"),
+			},
+			wantAttention: true,
+		},
+		{
+			name: "missing variable",
+			changes: []Change{
+				NewMissingChange(CategoryVariables, "ENV", "not set"),
+			},
+			wantAttention: true,
+		},
+		{
+			name: "info only",
+			changes: []Change{
+				NewInfoChange(CategorySecrets, "LEGACY_TOKEN", "not in config"),
+			},
+			wantClean: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := NewPlanFromChanges(tt.changes)
+
+			if got := plan.IsClean(); got != tt.wantClean {
+				t.Errorf("IsClean() = %v, want %v", got, tt.wantClean)
+			}
+			if plan.IsClean() == plan.HasChanges() {
+				t.Error("IsClean() should be the inverse of HasChanges()")
+			}
+
+			if got := plan.NeedsAttention(); got != tt.wantAttention {
+				t.Errorf("NeedsAttention() = %v, want %v", got, tt.wantAttention)
+			}
+			want := plan.HasMissingSecrets() || plan.HasMissingVariables() || plan.HasDeletes()
+			if plan.NeedsAttention() != want {
+				t.Error("NeedsAttention() should match HasMissingSecrets || HasMissingVariables || HasDeletes")
+			}
+		})
+	}
+}
+
 // TestPlanCategoriesInvariants tests additional properties of Categories()
 func TestPlanCategoriesInvariants(t *testing.T) {
 	t.Run("Categories returns no duplicates", func(t *testing.T) {
@@ -576,3 +671,119 @@ func TestPlanCategoriesInvariants(t *testing.T) {
 		}
 	})
 }
+
+// TestPlanAppendConcurrent tests that Append is safe for concurrent use
+func TestPlanAppendConcurrent(t *testing.T) {
+	const goroutines = 50
+	const perGoroutine = 20
+
+	plan := NewPlan()
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				plan.Append(NewAddChange(CategoryLabels, fmt.Sprintf("label-%d-%d", i, j), "red"))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if plan.Size() != goroutines*perGoroutine {
+		t.Errorf("Size() = %d, want %d", plan.Size(), goroutines*perGoroutine)
+	}
+
+	t.Run("Append with multiple changes", func(t *testing.T) {
+		plan := NewPlan()
+		plan.Append(
+			NewAddChange(CategoryLabels, "bug", "red"),
+			NewAddChange(CategoryLabels, "feature", "blue"),
+		)
+		if plan.Size() != 2 {
+			t.Errorf("Size() = %d, want 2", plan.Size())
+		}
+	})
+}
+
+func TestPlanHash(t *testing.T) {
+	changes := func() []Change {
+		return []Change{
+			NewUpdateChange(CategoryRepo, "description", "old", "new"),
+			NewAddChange(CategoryLabels, "bug", map[string]string{"color": "d73a4a", "description": "Something isn't working"}),
+			NewDeleteChange(CategoryLabels, "stale", "ffffff"),
+			NewUpdateChange(CategoryBranchProtection, "main.status_checks", []string{"ci"}, []string{"ci", "lint"}),
+		}
+	}
+
+	hash := NewPlanFromChanges(changes()).Hash()
+	if len(hash) != 64 {
+		t.Fatalf("Hash() = %q, want a SHA-256 hex digest", hash)
+	}
+
+	t.Run("stable across runs and order", func(t *testing.T) {
+		reordered := changes()
+		reordered[0], reordered[3] = reordered[3], reordered[0]
+		for i := 0; i < 5; i++ {
+			if got := NewPlanFromChanges(changes()).Hash(); got != hash {
+				t.Fatalf("run %d: Hash() = %s, want %s", i, got, hash)
+			}
+		}
+		if got := NewPlanFromChanges(reordered).Hash(); got != hash {
+			t.Errorf("reordered Hash() = %s, want %s", got, hash)
+		}
+	})
+
+	t.Run("informational changes are ignored", func(t *testing.T) {
+		withInfo := append(changes(), NewInfoChange(CategorySecrets, "LEGACY_TOKEN", "not in config"))
+		if got := NewPlanFromChanges(withInfo).Hash(); got != hash {
+			t.Errorf("Hash() with info = %s, want %s", got, hash)
+		}
+	})
+
+	t.Run("values change the hash", func(t *testing.T) {
+		different := changes()
+		different[0] = NewUpdateChange(CategoryRepo, "description", "old", "newer")
+		if NewPlanFromChanges(different).Hash() == hash {
+			t.Error("expected a different hash for a different value")
+		}
+		if NewPlan().Hash() == hash {
+			t.Error("expected a different hash for an empty plan")
+		}
+	})
+}
+
+func TestPlanDiff(t *testing.T) {
+	previous := NewPlanFromChanges([]Change{
+		NewUpdateChange(CategoryRepo, "description", "old", "new"),
+		NewAddChange(CategoryLabels, "bug", "d73a4a"),
+		NewUpdateChange(CategoryRepo, "homepage", "a", "b"),
+	})
+	current := NewPlanFromChanges([]Change{
+		NewUpdateChange(CategoryRepo, "description", "old", "new"),
+		NewUpdateChange(CategoryRepo, "homepage", "a", "c"),
+		NewDeleteChange(CategoryTopics, "go", nil),
+	})
+
+	appeared, resolved := current.Diff(previous)
+
+	keys := func(p *Plan) []string {
+		var out []string
+		for _, c := range p.Changes() {
+			out = append(out, fmt.Sprintf("%s %s", c.Key, c.Type))
+		}
+		return out
+	}
+	if got := fmt.Sprint(keys(appeared)); got != "[homepage update go delete]" {
+		t.Errorf("appeared = %s", got)
+	}
+	if got := fmt.Sprint(keys(resolved)); got != "[bug add homepage update]" {
+		t.Errorf("resolved = %s", got)
+	}
+
+	appeared, resolved = current.Diff(current)
+	if !appeared.IsEmpty() || !resolved.IsEmpty() {
+		t.Errorf("Diff() with itself = %v, %v; want both empty", appeared.Changes(), resolved.Changes())
+	}
+}
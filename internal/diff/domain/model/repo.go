@@ -0,0 +1,37 @@
+package model
+
+// RepoCurrent represents the current state of repository settings
+// This is a domain model independent of infrastructure (GitHub API)
+type RepoCurrent struct {
+	Description              string
+	Homepage                 string
+	Visibility               string
+	AllowMergeCommit         bool
+	AllowRebaseMerge         bool
+	AllowSquashMerge         bool
+	DeleteBranchOnMerge      bool
+	AllowUpdateBranch        bool
+	AllowAutoMerge           bool
+	UseSquashPRTitle         bool
+	WebCommitSignoffRequired bool
+	IsTemplate               bool
+	Archived                 bool
+}
+
+// RepoDesired represents the desired state of repository settings
+// This is a domain model independent of configuration format
+type RepoDesired struct {
+	Description              *string
+	Homepage                 *string
+	Visibility               *string
+	AllowMergeCommit         *bool
+	AllowRebaseMerge         *bool
+	AllowSquashMerge         *bool
+	DeleteBranchOnMerge      *bool
+	AllowUpdateBranch        *bool
+	AllowAutoMerge           *bool
+	UseSquashPRTitle         *bool
+	WebCommitSignoffRequired *bool
+	IsTemplate               *bool
+	Archived                 *bool
+}
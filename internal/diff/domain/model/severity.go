@@ -0,0 +1,117 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity ranks the security impact of a change
+type Severity int
+
+const (
+	SeverityLow Severity = iota
+	SeverityMedium
+	SeverityHigh
+)
+
+// String returns the string representation of Severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// Severity classifies the security impact of the drift a change describes. Old is what the
+// repository has now and New what the config asks for, so a change is high when the
+// repository is currently less protected than configured.
+func (c Change) Severity() Severity {
+	switch {
+	case c.IsInfo():
+		return SeverityLow
+	case c.IsUnreadable():
+		// Settings that can't be read can't be verified either
+		return SeverityMedium
+	}
+
+	switch c.Category {
+	case CategoryRepo:
+		if c.Key == "visibility" {
+			if valueIs(c.Old, "public") {
+				return SeverityHigh
+			}
+			return SeverityMedium
+		}
+	case CategoryBranchProtection:
+		return branchProtectionSeverity(c)
+	case CategoryActions, CategoryOrgActions:
+		return actionsSeverity(c)
+	case CategorySecrets, CategoryVariables:
+		if c.Type == ChangeMissing {
+			return SeverityMedium
+		}
+	}
+	return SeverityLow
+}
+
+// branchProtectionSeverity rates branch protection drift. A branch without protection, or
+// a setting that is currently looser than configured, is high.
+func branchProtectionSeverity(c Change) Severity {
+	if c.Type == ChangeAdd {
+		return SeverityHigh
+	}
+	if c.Type != ChangeUpdate {
+		return SeverityMedium
+	}
+
+	setting := c.Key[strings.LastIndex(c.Key, ".")+1:]
+	switch setting {
+	case "required_reviews":
+		current, okCurrent := c.Old.(int)
+		desired, okDesired := c.New.(int)
+		if okCurrent && okDesired && current < desired {
+			return SeverityHigh
+		}
+	case "allow_force_pushes", "allow_deletions":
+		if valueIs(c.Old, "true") {
+			return SeverityHigh
+		}
+	case "enforce_admins", "dismiss_stale_reviews", "require_code_owner", "require_signed_commits":
+		if valueIs(c.Old, "false") {
+			return SeverityHigh
+		}
+	}
+	return SeverityMedium
+}
+
+// actionsSeverity rates Actions permission drift. Settings that currently grant workflows
+// more than configured are high.
+func actionsSeverity(c Change) Severity {
+	switch c.Key {
+	case "allowed_actions":
+		if valueIs(c.Old, "all") {
+			return SeverityHigh
+		}
+	case "default_workflow_permissions":
+		if valueIs(c.Old, "write") {
+			return SeverityHigh
+		}
+	case "can_approve_pull_request_reviews":
+		if valueIs(c.Old, "true") {
+			return SeverityHigh
+		}
+	}
+	return SeverityMedium
+}
+
+// valueIs compares a change value by its text, since comparators store some values as
+// named types of the API client (e.g. a bool-based enabled flag)
+func valueIs(v interface{}, want string) bool {
+	return fmt.Sprint(v) == want
+}
@@ -0,0 +1,40 @@
+package model
+
+import "testing"
+
+// namedBool stands in for the bool-based types of the API client
+type namedBool bool
+
+func TestChangeSeverity(t *testing.T) {
+	tests := []struct {
+		name   string
+		change Change
+		want   Severity
+	}{
+		{"public repository", NewUpdateChange(CategoryRepo, "visibility", "public", "private"), SeverityHigh},
+		{"private repository", NewUpdateChange(CategoryRepo, "visibility", "private", "internal"), SeverityMedium},
+		{"description", NewUpdateChange(CategoryRepo, "description", "a", "b"), SeverityLow},
+		{"unprotected branch", NewAddChange(CategoryBranchProtection, "main", "required_reviews=1"), SeverityHigh},
+		{"fewer reviews", NewUpdateChange(CategoryBranchProtection, "main.required_reviews", 1, 2), SeverityHigh},
+		{"more reviews", NewUpdateChange(CategoryBranchProtection, "main.required_reviews", 2, 1), SeverityMedium},
+		{"force pushes allowed", NewUpdateChange(CategoryBranchProtection, "release.v1.allow_force_pushes", true, false), SeverityHigh},
+		{"admins not enforced", NewUpdateChange(CategoryBranchProtection, "main.enforce_admins", false, true), SeverityHigh},
+		{"admins enforced", NewUpdateChange(CategoryBranchProtection, "main.enforce_admins", true, false), SeverityMedium},
+		{"status checks", NewUpdateChange(CategoryBranchProtection, "main.status_checks", []string{"ci"}, []string{"ci", "lint"}), SeverityMedium},
+		{"all actions allowed", NewUpdateChange(CategoryActions, "allowed_actions", "all", "selected"), SeverityHigh},
+		{"write token", NewUpdateChange(CategoryOrgActions, "default_workflow_permissions", "write", "read"), SeverityHigh},
+		{"actions approve reviews", NewUpdateChange(CategoryActions, "can_approve_pull_request_reviews", namedBool(true), false), SeverityHigh},
+		{"retention", NewUpdateChange(CategoryActions, "artifact_retention_days", 90, 30), SeverityMedium},
+		{"missing secret", NewMissingChange(CategorySecrets, "TOKEN", "not configured"), SeverityMedium},
+		{"label", NewAddChange(CategoryLabels, "bug", "d73a4a"), SeverityLow},
+		{"unreadable", NewUnreadableChange(CategoryBranchProtection, "branch_protection"), SeverityMedium},
+		{"info", NewInfoChange(CategoryActions, "allowed_actions", "note"), SeverityLow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.change.Severity(); got != tt.want {
+				t.Errorf("Severity() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+)
+
+// TemplateFile represents a repository file identified by the git blob SHA of its content
+// This is a domain model shared by the current (GitHub) and desired (config) states
+type TemplateFile struct {
+	Path string
+	SHA  string
+}
+
+// BlobSHA returns the git blob SHA-1 of content, as reported by the GitHub contents API.
+// Comparing blob SHAs avoids downloading the current file content.
+func BlobSHA(content []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
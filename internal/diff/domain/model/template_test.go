@@ -0,0 +1,20 @@
+package model
+
+import "testing"
+
+func TestBlobSHA(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		// Values match `git hash-object`
+		{content: "", want: "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"},
+		{content: "hello\n", want: "ce013625030ba8dba906f756967f9e9ca394464a"},
+	}
+
+	for _, tt := range tests {
+		if got := BlobSHA([]byte(tt.content)); got != tt.want {
+			t.Errorf("BlobSHA(%q) = %s, want %s", tt.content, got, tt.want)
+		}
+	}
+}
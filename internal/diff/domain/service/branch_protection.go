@@ -10,57 +10,129 @@ func CompareBranchRule(
 	desired model.BranchProtectionDesired,
 ) []model.Change {
 	var changes []model.Change
-	prefix := branch + "."
+
+	// Whether reviews are required at all; removing the requirement drops every review setting
+	addBoolChange(&changes, branch, "require_reviews", desired.RequireReviews, current.ReviewsRequired)
 
 	// Required reviews (int comparison)
 	if desired.RequiredReviews != nil && *desired.RequiredReviews != current.RequiredReviews {
-		changes = append(changes, model.NewUpdateChange(
-			model.CategoryBranchProtection,
-			prefix+"required_reviews",
+		changes = append(changes, model.NewBranchProtectionUpdateChange(
+			branch,
+			"required_reviews",
 			current.RequiredReviews,
 			*desired.RequiredReviews,
 		))
 	}
 
 	// Boolean fields
-	addBoolChange(&changes, prefix+"dismiss_stale_reviews", desired.DismissStaleReviews, current.DismissStaleReviews)
-	addBoolChange(&changes, prefix+"require_code_owner", desired.RequireCodeOwner, current.RequireCodeOwner)
-	addBoolChange(&changes, prefix+"strict_status_checks", desired.StrictStatusChecks, current.StrictStatusChecks)
-	addBoolChange(&changes, prefix+"enforce_admins", desired.EnforceAdmins, current.EnforceAdmins)
-	addBoolChange(&changes, prefix+"require_linear_history", desired.RequireLinearHistory, current.RequireLinearHistory)
-	addBoolChange(&changes, prefix+"allow_force_pushes", desired.AllowForcePushes, current.AllowForcePushes)
-	addBoolChange(&changes, prefix+"allow_deletions", desired.AllowDeletions, current.AllowDeletions)
-	addBoolChange(&changes, prefix+"require_signed_commits", desired.RequireSignedCommits, current.RequireSignedCommits)
-
-	// Status checks (slice comparison)
-	if desired.StatusChecks != nil && !stringSliceEqual(desired.StatusChecks, current.StatusChecks) {
-		changes = append(changes, model.NewUpdateChange(
-			model.CategoryBranchProtection,
-			prefix+"status_checks",
-			current.StatusChecks,
-			desired.StatusChecks,
+	addBoolChange(&changes, branch, "dismiss_stale_reviews", desired.DismissStaleReviews, current.DismissStaleReviews)
+	addBoolChange(&changes, branch, "require_code_owner", desired.RequireCodeOwner, current.RequireCodeOwner)
+	addBoolChange(&changes, branch, "strict_status_checks", desired.StrictStatusChecks, current.StrictStatusChecks)
+	addBoolChange(&changes, branch, "enforce_admins", desired.EnforceAdmins, current.EnforceAdmins)
+	addBoolChange(&changes, branch, "require_linear_history", desired.RequireLinearHistory, current.RequireLinearHistory)
+	addBoolChange(&changes, branch, "allow_force_pushes", desired.AllowForcePushes, current.AllowForcePushes)
+	addBoolChange(&changes, branch, "allow_deletions", desired.AllowDeletions, current.AllowDeletions)
+	addBoolChange(&changes, branch, "require_signed_commits", desired.RequireSignedCommits, current.RequireSignedCommits)
+
+	// Status checks: GitHub treats contexts as a set, so order is ignored unless requested.
+	// A nil desired list means "not managed"; an empty list equals no checks.
+	// Checks pinned to an app also change when another app reports them.
+	if desired.StatusChecks != nil && (!statusChecksEqual(desired.StatusChecks, current.StatusChecks, desired.StatusChecksOrdered) ||
+		!statusCheckAppsMatch(desired.StatusCheckApps, current.StatusCheckApps)) {
+		oldValue, newValue := current.StatusChecks, desired.StatusChecks
+		if len(desired.StatusCheckApps) > 0 {
+			oldValue = model.FormatStatusChecks(current.StatusChecks, current.StatusCheckApps)
+			newValue = model.FormatStatusChecks(desired.StatusChecks, desired.StatusCheckApps)
+		}
+		changes = append(changes, model.NewBranchProtectionUpdateChange(
+			branch,
+			"status_checks",
+			oldValue,
+			newValue,
 		))
 	}
 
 	return changes
 }
 
-// addBoolChange adds a change if the desired value differs from current
-func addBoolChange(changes *[]model.Change, key string, desired *bool, current bool) {
+// UndeclaredBranchRuleSettings reports the protection settings of branch that desired doesn't
+// mention and GitHub has enabled, as info changes. Unprotected is the default for each of them.
+func UndeclaredBranchRuleSettings(
+	branch string,
+	current model.BranchProtectionCurrent,
+	desired model.BranchProtectionDesired,
+) []model.Change {
+	var changes []model.Change
+
+	if desired.RequiredReviews == nil && current.RequiredReviews != 0 {
+		changes = append(changes, model.NewBranchProtectionInfoChange(branch, "required_reviews", model.UndeclaredDescription(current.RequiredReviews)))
+	}
+	if desired.StatusChecks == nil && len(current.StatusChecks) > 0 {
+		changes = append(changes, model.NewBranchProtectionInfoChange(branch, "status_checks", model.UndeclaredDescription(current.StatusChecks)))
+	}
+
+	addUndeclaredBranchBool(&changes, branch, "dismiss_stale_reviews", desired.DismissStaleReviews, current.DismissStaleReviews)
+	addUndeclaredBranchBool(&changes, branch, "require_code_owner", desired.RequireCodeOwner, current.RequireCodeOwner)
+	addUndeclaredBranchBool(&changes, branch, "strict_status_checks", desired.StrictStatusChecks, current.StrictStatusChecks)
+	addUndeclaredBranchBool(&changes, branch, "enforce_admins", desired.EnforceAdmins, current.EnforceAdmins)
+	addUndeclaredBranchBool(&changes, branch, "require_linear_history", desired.RequireLinearHistory, current.RequireLinearHistory)
+	addUndeclaredBranchBool(&changes, branch, "allow_force_pushes", desired.AllowForcePushes, current.AllowForcePushes)
+	addUndeclaredBranchBool(&changes, branch, "allow_deletions", desired.AllowDeletions, current.AllowDeletions)
+	addUndeclaredBranchBool(&changes, branch, "require_signed_commits", desired.RequireSignedCommits, current.RequireSignedCommits)
+
+	return changes
+}
+
+// addUndeclaredBranchBool adds an info change if desired is unset and current is enabled
+func addUndeclaredBranchBool(changes *[]model.Change, branch, field string, desired *bool, current bool) {
+	if desired != nil || !current {
+		return
+	}
+	*changes = append(*changes, model.NewBranchProtectionInfoChange(branch, field, model.UndeclaredDescription(current)))
+}
+
+// addBoolChange adds a change to a setting of branch if the desired value differs from current
+func addBoolChange(changes *[]model.Change, branch, field string, desired *bool, current bool) {
 	if desired == nil {
 		return
 	}
 	if *desired == current {
 		return
 	}
-	*changes = append(*changes, model.NewUpdateChange(
-		model.CategoryBranchProtection,
-		key,
+	*changes = append(*changes, model.NewBranchProtectionUpdateChange(
+		branch,
+		field,
 		current,
 		*desired,
 	))
 }
 
+// statusChecksEqual compares status check contexts, in order only when ordered is set
+func statusChecksEqual(desired, current []string, ordered bool) bool {
+	if ordered {
+		return stringSliceEqual(desired, current)
+	}
+	return model.StringSliceEqualIgnoreOrder(desired, current)
+}
+
+// statusCheckAppsMatch reports whether every pinned app is the one currently required.
+// AnyStatusCheckApp matches a check that isn't tied to an app.
+func statusCheckAppsMatch(desired, current map[string]int) bool {
+	for context, id := range desired {
+		currentID, ok := current[context]
+		if id == model.AnyStatusCheckApp {
+			if ok && currentID != model.AnyStatusCheckApp {
+				return false
+			}
+			continue
+		}
+		if !ok || currentID != id {
+			return false
+		}
+	}
+	return true
+}
+
 // stringSliceEqual compares two string slices for equality
 func stringSliceEqual(a, b []string) bool {
 	if len(a) != len(b) {
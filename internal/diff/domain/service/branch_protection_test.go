@@ -1,6 +1,8 @@
 package service
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
@@ -325,7 +327,7 @@ func TestCompareBranchRuleStatusChecks(t *testing.T) {
 		}
 	})
 
-	t.Run("status_checks order matters", func(t *testing.T) {
+	t.Run("status_checks order ignored by default", func(t *testing.T) {
 		current := model.BranchProtectionCurrent{
 			StatusChecks: []string{"a", "b"},
 		}
@@ -335,6 +337,39 @@ func TestCompareBranchRuleStatusChecks(t *testing.T) {
 
 		changes := CompareBranchRule("main", current, desired)
 
+		for _, c := range changes {
+			if c.Key == "main.status_checks" {
+				t.Error("reordered status_checks should produce no change")
+			}
+		}
+	})
+
+	t.Run("status_checks duplicates are not ignored", func(t *testing.T) {
+		current := model.BranchProtectionCurrent{
+			StatusChecks: []string{"a", "b"},
+		}
+		desired := model.BranchProtectionDesired{
+			StatusChecks: []string{"a", "a"},
+		}
+
+		changes := CompareBranchRule("main", current, desired)
+
+		if len(changes) != 1 || changes[0].Key != "main.status_checks" {
+			t.Errorf("expected status_checks change, got %+v", changes)
+		}
+	})
+
+	t.Run("status_checks order matters when ordered", func(t *testing.T) {
+		current := model.BranchProtectionCurrent{
+			StatusChecks: []string{"a", "b"},
+		}
+		desired := model.BranchProtectionDesired{
+			StatusChecks:        []string{"b", "a"},
+			StatusChecksOrdered: true,
+		}
+
+		changes := CompareBranchRule("main", current, desired)
+
 		// Order difference should be detected
 		found := false
 		for _, c := range changes {
@@ -387,6 +422,55 @@ func TestCompareBranchRuleStatusChecks(t *testing.T) {
 	})
 }
 
+func TestCompareBranchRuleStatusCheckApps(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    map[string]int
+		desired    map[string]int
+		wantChange bool
+	}{
+		{name: "no apps on either side", wantChange: false},
+		{name: "pinned app matches", current: map[string]int{"test": 15368}, desired: map[string]int{"test": 15368}, wantChange: false},
+		{name: "pinned app differs", current: map[string]int{"test": 1}, desired: map[string]int{"test": 15368}, wantChange: true},
+		{name: "check not yet pinned", desired: map[string]int{"test": 15368}, wantChange: true},
+		{name: "unpinned config ignores current app", current: map[string]int{"test": 15368}, wantChange: false},
+		{name: "any app matches unpinned check", desired: map[string]int{"test": model.AnyStatusCheckApp}, wantChange: false},
+		{name: "any app unpins a pinned check", current: map[string]int{"test": 15368}, desired: map[string]int{"test": model.AnyStatusCheckApp}, wantChange: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := model.BranchProtectionCurrent{StatusChecks: []string{"lint", "test"}, StatusCheckApps: tt.current}
+			desired := model.BranchProtectionDesired{StatusChecks: []string{"lint", "test"}, StatusCheckApps: tt.desired}
+
+			changes := CompareBranchRule("main", current, desired)
+
+			var change *model.Change
+			for i := range changes {
+				if changes[i].Field == "status_checks" {
+					change = &changes[i]
+				}
+			}
+			if (change != nil) != tt.wantChange {
+				t.Fatalf("status_checks change = %v, want change %v", change, tt.wantChange)
+			}
+		})
+	}
+
+	t.Run("change shows the apps", func(t *testing.T) {
+		current := model.BranchProtectionCurrent{StatusChecks: []string{"test"}, StatusCheckApps: map[string]int{"test": 1}}
+		desired := model.BranchProtectionDesired{StatusChecks: []string{"test"}, StatusCheckApps: map[string]int{"test": 15368}}
+
+		changes := CompareBranchRule("main", current, desired)
+		if len(changes) != 1 {
+			t.Fatalf("expected 1 change, got %d", len(changes))
+		}
+		if got := fmt.Sprint(changes[0].Old, changes[0].New); got != "[test (app 1)] [test (app 15368)]" {
+			t.Errorf("Old, New = %s", got)
+		}
+	})
+}
+
 // TestStatusChecksNilVsEmptySpec documents the intended behavior for nil vs empty slice
 // This is a specification test - changing this behavior is a breaking change
 func TestStatusChecksNilVsEmptySpec(t *testing.T) {
@@ -570,6 +654,115 @@ func TestCompareBranchRuleBranchNames(t *testing.T) {
 			if changes[0].Key != expectedKey {
 				t.Errorf("expected key '%s', got '%s'", expectedKey, changes[0].Key)
 			}
+			if changes[0].Branch != branchName || changes[0].Field != "required_reviews" {
+				t.Errorf("expected branch '%s' and field 'required_reviews', got '%s' and '%s'", branchName, changes[0].Branch, changes[0].Field)
+			}
+		})
+	}
+}
+
+func TestUndeclaredBranchRuleSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  model.BranchProtectionCurrent
+		desired  model.BranchProtectionDesired
+		wantKeys []string
+	}{
+		{
+			name:     "enabled setting not in config is reported",
+			current:  model.BranchProtectionCurrent{RequireSignedCommits: true},
+			desired:  model.BranchProtectionDesired{},
+			wantKeys: []string{"main.require_signed_commits"},
+		},
+		{
+			name:     "setting in config is compared instead",
+			current:  model.BranchProtectionCurrent{RequireSignedCommits: true},
+			desired:  model.BranchProtectionDesired{RequireSignedCommits: boolPtr(false)},
+			wantKeys: nil,
+		},
+		{
+			name:     "GitHub defaults are not reported",
+			current:  model.BranchProtectionCurrent{StatusChecks: []string{}},
+			desired:  model.BranchProtectionDesired{},
+			wantKeys: nil,
+		},
+		{
+			name:     "reviews and status checks not in config are reported",
+			current:  model.BranchProtectionCurrent{RequiredReviews: 2, StatusChecks: []string{"test"}, EnforceAdmins: true},
+			desired:  model.BranchProtectionDesired{EnforceAdmins: boolPtr(true)},
+			wantKeys: []string{"main.required_reviews", "main.status_checks"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := UndeclaredBranchRuleSettings("main", tt.current, tt.desired)
+
+			var keys []string
+			for _, change := range changes {
+				if !change.IsInfo() {
+					t.Errorf("change %s should be informational, got %v", change.Key, change.Type)
+				}
+				if change.Branch != "main" {
+					t.Errorf("change %s branch = %q, want main", change.Key, change.Branch)
+				}
+				keys = append(keys, change.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestCompareBranchRuleRequireReviews(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  model.BranchProtectionCurrent
+		desired  model.BranchProtectionDesired
+		wantKeys []string
+	}{
+		{
+			name:     "disable removes the review requirement",
+			current:  model.BranchProtectionCurrent{ReviewsRequired: true, RequiredReviews: 2},
+			desired:  model.BranchProtectionDesired{RequireReviews: boolPtr(false)},
+			wantKeys: []string{"main.require_reviews"},
+		},
+		{
+			name:     "already disabled",
+			current:  model.BranchProtectionCurrent{},
+			desired:  model.BranchProtectionDesired{RequireReviews: boolPtr(false)},
+			wantKeys: nil,
+		},
+		{
+			name:     "enable with count",
+			current:  model.BranchProtectionCurrent{},
+			desired:  model.BranchProtectionDesired{RequireReviews: boolPtr(true), RequiredReviews: intPtr(2)},
+			wantKeys: []string{"main.require_reviews", "main.required_reviews"},
+		},
+		{
+			name:     "unset leaves the requirement alone",
+			current:  model.BranchProtectionCurrent{ReviewsRequired: true, RequiredReviews: 1},
+			desired:  model.BranchProtectionDesired{},
+			wantKeys: nil,
+		},
+		{
+			name:     "count of 0 keeps reviews required",
+			current:  model.BranchProtectionCurrent{ReviewsRequired: true, RequiredReviews: 1},
+			desired:  model.BranchProtectionDesired{RequiredReviews: intPtr(0)},
+			wantKeys: []string{"main.required_reviews"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			for _, change := range CompareBranchRule("main", tt.current, tt.desired) {
+				keys = append(keys, change.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
 		})
 	}
 }
@@ -11,6 +11,9 @@
 // # Available Services
 //
 //   - CompareBranchRule: Compares current and desired branch protection states
+//   - CompareRepo: Compares current and desired repository settings
+//   - CompareLabels: Compares current and desired labels with normalization
+//   - CompareTemplates: Compares repository files with templates by content hash
 //
 // # Usage
 //
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+// CompareLabels compares current and desired repository labels
+// This is a pure domain service with no infrastructure dependencies
+//
+// Labels are matched by name case-insensitively and ignoring surrounding
+// whitespace. An update of a label whose name differs from the desired one
+// carries the current name in CurrentName, as GitHub addresses labels by it.
+// Colors are compared without a leading '#' and ignoring case, and descriptions ignore
+// surrounding whitespace, and are left alone when the desired label sets
+// KeepDescription. An empty desired description clears the current one.
+// Current labels missing from desired are deleted
+// only when replaceDefault is set.
+//
+// Current labels for which excluded returns true are never updated or deleted.
+// Desired labels that don't exist yet are still added, whatever their name.
+// A nil excluded excludes nothing.
+func CompareLabels(current, desired []model.Label, replaceDefault bool, excluded func(name string) bool) []model.Change {
+	var changes []model.Change
+
+	if excluded == nil {
+		excluded = func(string) bool { return false }
+	}
+
+	currentMap := make(map[string]model.Label, len(current))
+	for _, l := range current {
+		currentMap[normalizeLabelName(l.Name)] = l
+	}
+
+	desiredSet := make(map[string]bool, len(desired))
+	for _, l := range desired {
+		desiredSet[normalizeLabelName(l.Name)] = true
+	}
+
+	// Check for additions and updates
+	for _, want := range desired {
+		have, exists := currentMap[normalizeLabelName(want.Name)]
+		if !exists {
+			changes = append(changes, model.NewAddChange(
+				model.CategoryLabels,
+				want.Name,
+				formatLabel(want.Color, want.Description),
+			))
+			continue
+		}
+
+		if want.KeepDescription {
+			want.Description = have.Description
+		}
+		if !excluded(have.Name) && !labelsEqual(have, want) {
+			change := model.NewUpdateChange(
+				model.CategoryLabels,
+				want.Name,
+				formatLabel(have.Color, have.Description),
+				formatLabel(want.Color, want.Description),
+			)
+			if have.Name != want.Name {
+				change.CurrentName = have.Name
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	// Check for deletions (only if replace_default is true)
+	if replaceDefault {
+		for _, have := range current {
+			if !desiredSet[normalizeLabelName(have.Name)] && !excluded(have.Name) {
+				changes = append(changes, model.NewDeleteChange(
+					model.CategoryLabels,
+					have.Name,
+					formatLabel(have.Color, have.Description),
+				))
+			}
+		}
+	}
+
+	return changes
+}
+
+// labelsEqual reports whether two matched labels need no update.
+// A name differing only in case still counts as a change so the rename is applied.
+func labelsEqual(a, b model.Label) bool {
+	return a.Name == b.Name &&
+		normalizeLabelColor(a.Color) == normalizeLabelColor(b.Color) &&
+		strings.TrimSpace(a.Description) == strings.TrimSpace(b.Description)
+}
+
+// normalizeLabelName returns the key used to match labels by name
+func normalizeLabelName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeLabelColor strips a leading '#' and lowercases a hex color
+func normalizeLabelColor(color string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(color), "#"))
+}
+
+func formatLabel(color, description string) string {
+	return fmt.Sprintf("color=%s, description=%s", color, description)
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+// TestCompareLabelsInvariants tests the invariants of CompareLabels
+func TestCompareLabelsInvariants(t *testing.T) {
+	t.Run("identical labels produce no changes", func(t *testing.T) {
+		labels := []model.Label{
+			{Name: "bug", Color: "d73a4a", Description: "Something isn't working"},
+			{Name: "enhancement", Color: "a2eeef"},
+		}
+
+		changes := CompareLabels(labels, labels, true, nil)
+
+		if len(changes) != 0 {
+			t.Errorf("identical labels should produce no changes, got %d", len(changes))
+		}
+	})
+
+	t.Run("empty desired without replace_default produces no changes", func(t *testing.T) {
+		current := []model.Label{{Name: "bug", Color: "d73a4a"}}
+
+		changes := CompareLabels(current, nil, false, nil)
+
+		if len(changes) != 0 {
+			t.Errorf("expected no changes, got %d", len(changes))
+		}
+	})
+
+	t.Run("all changes have labels category", func(t *testing.T) {
+		current := []model.Label{
+			{Name: "bug", Color: "d73a4a"},
+			{Name: "wontfix", Color: "ffffff"},
+		}
+		desired := []model.Label{
+			{Name: "bug", Color: "ff0000"},
+			{Name: "feature", Color: "00ff00"},
+		}
+
+		changes := CompareLabels(current, desired, true, nil)
+
+		if len(changes) != 3 {
+			t.Fatalf("expected 3 changes, got %d", len(changes))
+		}
+		for _, c := range changes {
+			if c.Category != model.CategoryLabels {
+				t.Errorf("change category should be labels, got %s", c.Category)
+			}
+		}
+	})
+}
+
+// TestCompareLabelsChanges tests add/update/delete detection
+func TestCompareLabelsChanges(t *testing.T) {
+	tests := []struct {
+		name           string
+		current        []model.Label
+		desired        []model.Label
+		replaceDefault bool
+		want           []model.Change
+	}{
+		{
+			name:    "new label is added",
+			current: nil,
+			desired: []model.Label{{Name: "bug", Color: "d73a4a", Description: "Bug report"}},
+			want: []model.Change{
+				model.NewAddChange(model.CategoryLabels, "bug", "color=d73a4a, description=Bug report"),
+			},
+		},
+		{
+			name:    "color change is an update",
+			current: []model.Label{{Name: "bug", Color: "d73a4a"}},
+			desired: []model.Label{{Name: "bug", Color: "ff0000"}},
+			want: []model.Change{
+				model.NewUpdateChange(model.CategoryLabels, "bug", "color=d73a4a, description=", "color=ff0000, description="),
+			},
+		},
+		{
+			name:    "description change is an update",
+			current: []model.Label{{Name: "bug", Color: "d73a4a", Description: "old"}},
+			desired: []model.Label{{Name: "bug", Color: "d73a4a", Description: "new"}},
+			want: []model.Change{
+				model.NewUpdateChange(model.CategoryLabels, "bug", "color=d73a4a, description=old", "color=d73a4a, description=new"),
+			},
+		},
+		{
+			name:           "undeclared label is deleted with replace_default",
+			current:        []model.Label{{Name: "bug", Color: "d73a4a"}, {Name: "wontfix", Color: "ffffff"}},
+			desired:        []model.Label{{Name: "bug", Color: "d73a4a"}},
+			replaceDefault: true,
+			want: []model.Change{
+				model.NewDeleteChange(model.CategoryLabels, "wontfix", "color=ffffff, description="),
+			},
+		},
+		{
+			name:           "undeclared label is kept without replace_default",
+			current:        []model.Label{{Name: "bug", Color: "d73a4a"}, {Name: "wontfix", Color: "ffffff"}},
+			desired:        []model.Label{{Name: "bug", Color: "d73a4a"}},
+			replaceDefault: false,
+			want:           nil,
+		},
+		{
+			name:           "case-insensitive match is not deleted",
+			current:        []model.Label{{Name: "Bug", Color: "d73a4a"}},
+			desired:        []model.Label{{Name: "bug", Color: "d73a4a"}},
+			replaceDefault: true,
+			want: []model.Change{
+				model.NewUpdateChange(model.CategoryLabels, "bug", "color=d73a4a, description=", "color=d73a4a, description="),
+			},
+		},
+		{
+			name:    "case-insensitive match is not re-added",
+			current: []model.Label{{Name: "BUG", Color: "d73a4a"}},
+			desired: []model.Label{{Name: "bug", Color: "ff0000"}},
+			want: []model.Change{
+				model.NewUpdateChange(model.CategoryLabels, "bug", "color=d73a4a, description=", "color=ff0000, description="),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := CompareLabels(tt.current, tt.desired, tt.replaceDefault, nil)
+
+			if len(changes) != len(tt.want) {
+				t.Fatalf("expected %d changes, got %d: %+v", len(tt.want), len(changes), changes)
+			}
+			for i, want := range tt.want {
+				got := changes[i]
+				if got.Type != want.Type || got.Key != want.Key || got.Old != want.Old || got.New != want.New {
+					t.Errorf("change[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestCompareLabelsExcluded tests that excluded current labels are left alone
+func TestCompareLabelsExcluded(t *testing.T) {
+	excluded := func(name string) bool { return name == "dependencies" }
+
+	t.Run("excluded label survives replace_default", func(t *testing.T) {
+		current := []model.Label{
+			{Name: "bug", Color: "d73a4a"},
+			{Name: "dependencies", Color: "0366d6"},
+			{Name: "wontfix", Color: "ffffff"},
+		}
+		desired := []model.Label{{Name: "bug", Color: "d73a4a"}}
+
+		changes := CompareLabels(current, desired, true, excluded)
+		if len(changes) != 1 || changes[0].Type != model.ChangeDelete || changes[0].Key != "wontfix" {
+			t.Errorf("expected only wontfix to be deleted, got %+v", changes)
+		}
+	})
+
+	t.Run("excluded label is not updated", func(t *testing.T) {
+		current := []model.Label{{Name: "dependencies", Color: "0366d6"}}
+		desired := []model.Label{{Name: "dependencies", Color: "ff0000"}}
+
+		if changes := CompareLabels(current, desired, true, excluded); len(changes) != 0 {
+			t.Errorf("expected no changes, got %+v", changes)
+		}
+	})
+
+	t.Run("differently-named label is still added", func(t *testing.T) {
+		current := []model.Label{{Name: "dependencies", Color: "0366d6"}}
+		desired := []model.Label{{Name: "deps", Color: "ff0000"}}
+
+		changes := CompareLabels(current, desired, true, excluded)
+		if len(changes) != 1 || changes[0].Type != model.ChangeAdd || changes[0].Key != "deps" {
+			t.Errorf("expected deps to be added, got %+v", changes)
+		}
+	})
+}
+
+// TestCompareLabelsDescription tests clearing and leaving descriptions unmanaged
+func TestCompareLabelsDescription(t *testing.T) {
+	current := []model.Label{{Name: "bug", Color: "d73a4a", Description: "old"}}
+
+	t.Run("empty description clears it", func(t *testing.T) {
+		desired := []model.Label{{Name: "bug", Color: "d73a4a", Description: ""}}
+
+		changes := CompareLabels(current, desired, false, nil)
+		if len(changes) != 1 || changes[0].Old != "color=d73a4a, description=old" || changes[0].New != "color=d73a4a, description=" {
+			t.Errorf("expected description to be cleared, got %+v", changes)
+		}
+	})
+
+	t.Run("unmanaged description is kept", func(t *testing.T) {
+		desired := []model.Label{{Name: "bug", Color: "d73a4a", KeepDescription: true}}
+
+		if changes := CompareLabels(current, desired, false, nil); len(changes) != 0 {
+			t.Errorf("expected no changes, got %+v", changes)
+		}
+	})
+
+	t.Run("unmanaged description is kept on color change", func(t *testing.T) {
+		desired := []model.Label{{Name: "bug", Color: "ff0000", KeepDescription: true}}
+
+		changes := CompareLabels(current, desired, false, nil)
+		if len(changes) != 1 || changes[0].New != "color=ff0000, description=old" {
+			t.Errorf("expected only the color to change, got %+v", changes)
+		}
+	})
+}
+
+// TestCompareLabelsNormalization tests that formatting-only differences produce no changes
+func TestCompareLabelsNormalization(t *testing.T) {
+	tests := []struct {
+		name    string
+		current model.Label
+		desired model.Label
+	}{
+		{
+			name:    "uppercase color",
+			current: model.Label{Name: "bug", Color: "d73a4a"},
+			desired: model.Label{Name: "bug", Color: "D73A4A"},
+		},
+		{
+			name:    "color with leading hash",
+			current: model.Label{Name: "bug", Color: "d73a4a"},
+			desired: model.Label{Name: "bug", Color: "#d73a4a"},
+		},
+		{
+			name:    "description surrounding whitespace",
+			current: model.Label{Name: "bug", Color: "d73a4a", Description: "Bug report"},
+			desired: model.Label{Name: "bug", Color: "d73a4a", Description: "  Bug report\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := CompareLabels([]model.Label{tt.current}, []model.Label{tt.desired}, true, nil)
+
+			if len(changes) != 0 {
+				t.Errorf("expected no changes, got %+v", changes)
+			}
+		})
+	}
+}
+
+// TestCompareLabelsCurrentName tests that an update addresses the label by its current name
+func TestCompareLabelsCurrentName(t *testing.T) {
+	current := []model.Label{{Name: "bug ", Color: "d73a4a"}, {Name: "docs", Color: "0075ca"}}
+	desired := []model.Label{{Name: "bug", Color: "d73a4a"}, {Name: "docs", Color: "ffffff"}}
+
+	changes := CompareLabels(current, desired, false, nil)
+
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 updates, got %+v", changes)
+	}
+	if changes[0].Key != "bug" || changes[0].CurrentName != "bug " {
+		t.Errorf("rename: Key = %q, CurrentName = %q, want \"bug\" and \"bug \"", changes[0].Key, changes[0].CurrentName)
+	}
+	if changes[1].CurrentName != "" {
+		t.Errorf("same name: CurrentName = %q, want empty", changes[1].CurrentName)
+	}
+
+	inverted := changes[0].Invert()
+	if inverted.Key != "bug " || inverted.CurrentName != "bug" {
+		t.Errorf("inverted: Key = %q, CurrentName = %q, want \"bug \" and \"bug\"", inverted.Key, inverted.CurrentName)
+	}
+}
+
+func TestFormatLabel(t *testing.T) {
+	tests := []struct {
+		color       string
+		description string
+		expected    string
+	}{
+		{
+			color:       "d73a4a",
+			description: "Bug report",
+			expected:    "color=d73a4a, description=Bug report",
+		},
+		{
+			color:       "ffffff",
+			description: "",
+			expected:    "color=ffffff, description=",
+		},
+	}
+
+	for _, tt := range tests {
+		result := formatLabel(tt.color, tt.description)
+		if result != tt.expected {
+			t.Errorf("formatLabel(%q, %q) = %q, want %q", tt.color, tt.description, result, tt.expected)
+		}
+	}
+}
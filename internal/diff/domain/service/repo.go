@@ -0,0 +1,119 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+// CompareRepo compares current and desired repository settings
+// This is a pure domain service with no infrastructure dependencies
+func CompareRepo(current model.RepoCurrent, desired model.RepoDesired) []model.Change {
+	var changes []model.Change
+
+	// String fields
+	addRepoStringChange(&changes, "description", desired.Description, current.Description)
+	addRepoHomepageChange(&changes, desired.Homepage, current.Homepage)
+	addRepoStringChange(&changes, "visibility", desired.Visibility, current.Visibility)
+
+	// Boolean fields
+	addRepoBoolChange(&changes, "allow_merge_commit", desired.AllowMergeCommit, current.AllowMergeCommit)
+	addRepoBoolChange(&changes, "allow_rebase_merge", desired.AllowRebaseMerge, current.AllowRebaseMerge)
+	addRepoBoolChange(&changes, "allow_squash_merge", desired.AllowSquashMerge, current.AllowSquashMerge)
+	addRepoBoolChange(&changes, "delete_branch_on_merge", desired.DeleteBranchOnMerge, current.DeleteBranchOnMerge)
+	addRepoBoolChange(&changes, "allow_update_branch", desired.AllowUpdateBranch, current.AllowUpdateBranch)
+	addRepoBoolChange(&changes, "allow_auto_merge", desired.AllowAutoMerge, current.AllowAutoMerge)
+	addRepoBoolChange(&changes, "use_squash_pr_title_as_default", desired.UseSquashPRTitle, current.UseSquashPRTitle)
+	addRepoBoolChange(&changes, "web_commit_signoff_required", desired.WebCommitSignoffRequired, current.WebCommitSignoffRequired)
+	addRepoBoolChange(&changes, "is_template", desired.IsTemplate, current.IsTemplate)
+	addRepoBoolChange(&changes, "archived", desired.Archived, current.Archived)
+
+	return changes
+}
+
+// UndeclaredRepoSettings reports the settings desired doesn't mention that GitHub has at a
+// value other than its default for new repositories, as info changes.
+// Visibility has no default and is never reported.
+func UndeclaredRepoSettings(current model.RepoCurrent, desired model.RepoDesired) []model.Change {
+	var changes []model.Change
+
+	addUndeclaredString(&changes, model.CategoryRepo, "description", desired.Description, current.Description)
+	addUndeclaredString(&changes, model.CategoryRepo, "homepage", desired.Homepage, current.Homepage)
+
+	// GitHub allows every merge method on new repositories
+	addUndeclaredBool(&changes, "allow_merge_commit", desired.AllowMergeCommit, current.AllowMergeCommit, true)
+	addUndeclaredBool(&changes, "allow_rebase_merge", desired.AllowRebaseMerge, current.AllowRebaseMerge, true)
+	addUndeclaredBool(&changes, "allow_squash_merge", desired.AllowSquashMerge, current.AllowSquashMerge, true)
+	addUndeclaredBool(&changes, "delete_branch_on_merge", desired.DeleteBranchOnMerge, current.DeleteBranchOnMerge, false)
+	addUndeclaredBool(&changes, "allow_update_branch", desired.AllowUpdateBranch, current.AllowUpdateBranch, false)
+	addUndeclaredBool(&changes, "allow_auto_merge", desired.AllowAutoMerge, current.AllowAutoMerge, false)
+	addUndeclaredBool(&changes, "use_squash_pr_title_as_default", desired.UseSquashPRTitle, current.UseSquashPRTitle, false)
+	addUndeclaredBool(&changes, "web_commit_signoff_required", desired.WebCommitSignoffRequired, current.WebCommitSignoffRequired, false)
+	addUndeclaredBool(&changes, "is_template", desired.IsTemplate, current.IsTemplate, false)
+	addUndeclaredBool(&changes, "archived", desired.Archived, current.Archived, false)
+
+	return changes
+}
+
+// addUndeclaredString adds an info change if desired is unset and current isn't empty
+func addUndeclaredString(changes *[]model.Change, category model.ChangeCategory, key string, desired *string, current string) {
+	if desired != nil || current == "" {
+		return
+	}
+	*changes = append(*changes, model.NewInfoChange(category, key, model.UndeclaredDescription(current)))
+}
+
+// addUndeclaredBool adds an info change if desired is unset and current isn't GitHub's default
+func addUndeclaredBool(changes *[]model.Change, key string, desired *bool, current, githubDefault bool) {
+	if desired != nil || current == githubDefault {
+		return
+	}
+	*changes = append(*changes, model.NewInfoChange(model.CategoryRepo, key, model.UndeclaredDescription(current)))
+}
+
+// addRepoStringChange adds a change if the desired value differs from current
+func addRepoStringChange(changes *[]model.Change, key string, desired *string, current string) {
+	if desired == nil || *desired == current {
+		return
+	}
+	*changes = append(*changes, model.NewUpdateChange(model.CategoryRepo, key, current, *desired))
+}
+
+// addRepoHomepageChange adds a change if the desired homepage differs from current once
+// both are normalized. The change carries the normalized URL, which is what apply sends.
+func addRepoHomepageChange(changes *[]model.Change, desired *string, current string) {
+	if desired == nil {
+		return
+	}
+	homepage := normalizeHomepage(*desired)
+	if homepage == normalizeHomepage(current) {
+		return
+	}
+	*changes = append(*changes, model.NewUpdateChange(model.CategoryRepo, "homepage", current, homepage))
+}
+
+// normalizeHomepage lowercases the scheme and host of a homepage URL and drops the slash
+// of an empty path, so https://Example.com/ and https://example.com are the same homepage.
+// Other paths are left alone: /docs and /docs/ may be different pages. Values that
+// aren't absolute URLs are returned unchanged.
+func normalizeHomepage(homepage string) string {
+	u, err := url.Parse(homepage)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return homepage
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if u.Path == "/" {
+		u.Path = ""
+	}
+	return u.String()
+}
+
+// addRepoBoolChange adds a change if the desired value differs from current
+func addRepoBoolChange(changes *[]model.Change, key string, desired *bool, current bool) {
+	if desired == nil || *desired == current {
+		return
+	}
+	*changes = append(*changes, model.NewUpdateChange(model.CategoryRepo, key, current, *desired))
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+func strPtr(v string) *string { return &v }
+
+// TestCompareRepoInvariants tests the invariants of CompareRepo
+func TestCompareRepoInvariants(t *testing.T) {
+	t.Run("identical states produce no changes", func(t *testing.T) {
+		current := model.RepoCurrent{
+			Description:         "My repo",
+			Homepage:            "https://example.com",
+			Visibility:          "public",
+			AllowMergeCommit:    false,
+			AllowRebaseMerge:    true,
+			AllowSquashMerge:    true,
+			DeleteBranchOnMerge: true,
+			AllowUpdateBranch:   false,
+		}
+		desired := model.RepoDesired{
+			Description:         strPtr("My repo"),
+			Homepage:            strPtr("https://example.com"),
+			Visibility:          strPtr("public"),
+			AllowMergeCommit:    boolPtr(false),
+			AllowRebaseMerge:    boolPtr(true),
+			AllowSquashMerge:    boolPtr(true),
+			DeleteBranchOnMerge: boolPtr(true),
+			AllowUpdateBranch:   boolPtr(false),
+		}
+
+		changes := CompareRepo(current, desired)
+
+		if len(changes) != 0 {
+			t.Errorf("identical states should produce no changes, got %d", len(changes))
+		}
+	})
+
+	t.Run("nil desired fields produce no changes", func(t *testing.T) {
+		current := model.RepoCurrent{
+			Description:      "My repo",
+			Visibility:       "private",
+			AllowMergeCommit: true,
+		}
+		desired := model.RepoDesired{
+			// All nil - should not compare
+		}
+
+		changes := CompareRepo(current, desired)
+
+		if len(changes) != 0 {
+			t.Errorf("nil desired fields should produce no changes, got %d", len(changes))
+		}
+	})
+
+	t.Run("all changes are repo updates", func(t *testing.T) {
+		current := model.RepoCurrent{}
+		desired := model.RepoDesired{
+			Description:      strPtr("new"),
+			Visibility:       strPtr("private"),
+			AllowMergeCommit: boolPtr(true),
+		}
+
+		changes := CompareRepo(current, desired)
+
+		if len(changes) != 3 {
+			t.Fatalf("expected 3 changes, got %d", len(changes))
+		}
+		for _, c := range changes {
+			if c.Category != model.CategoryRepo {
+				t.Errorf("change category should be repo, got %s", c.Category)
+			}
+			if c.Type != model.ChangeUpdate {
+				t.Errorf("repo changes should be updates, got %v", c.Type)
+			}
+		}
+	})
+}
+
+// TestCompareRepoStringFields tests string field comparison
+func TestCompareRepoStringFields(t *testing.T) {
+	stringFields := []struct {
+		name       string
+		setCurrent func(*model.RepoCurrent, string)
+		setDesired func(*model.RepoDesired, *string)
+	}{
+		{
+			name:       "description",
+			setCurrent: func(c *model.RepoCurrent, v string) { c.Description = v },
+			setDesired: func(d *model.RepoDesired, v *string) { d.Description = v },
+		},
+		{
+			name:       "homepage",
+			setCurrent: func(c *model.RepoCurrent, v string) { c.Homepage = v },
+			setDesired: func(d *model.RepoDesired, v *string) { d.Homepage = v },
+		},
+		{
+			name:       "visibility",
+			setCurrent: func(c *model.RepoCurrent, v string) { c.Visibility = v },
+			setDesired: func(d *model.RepoDesired, v *string) { d.Visibility = v },
+		},
+	}
+
+	for _, field := range stringFields {
+		t.Run(field.name+" change detected", func(t *testing.T) {
+			current := model.RepoCurrent{}
+			desired := model.RepoDesired{}
+			field.setCurrent(&current, "old")
+			field.setDesired(&desired, strPtr("new"))
+
+			changes := CompareRepo(current, desired)
+
+			if len(changes) != 1 {
+				t.Fatalf("expected 1 change for %s, got %d", field.name, len(changes))
+			}
+			if changes[0].Key != field.name {
+				t.Errorf("expected key '%s', got %s", field.name, changes[0].Key)
+			}
+			if changes[0].Old != "old" {
+				t.Errorf("expected Old = 'old', got %v", changes[0].Old)
+			}
+			if changes[0].New != "new" {
+				t.Errorf("expected New = 'new', got %v", changes[0].New)
+			}
+		})
+
+		t.Run(field.name+" cleared to empty detected", func(t *testing.T) {
+			current := model.RepoCurrent{}
+			desired := model.RepoDesired{}
+			field.setCurrent(&current, "old")
+			field.setDesired(&desired, strPtr(""))
+
+			changes := CompareRepo(current, desired)
+
+			if len(changes) != 1 {
+				t.Fatalf("expected 1 change for %s, got %d", field.name, len(changes))
+			}
+		})
+
+		t.Run(field.name+" nil desired produces no change", func(t *testing.T) {
+			current := model.RepoCurrent{}
+			desired := model.RepoDesired{}
+			field.setCurrent(&current, "old")
+
+			changes := CompareRepo(current, desired)
+
+			if len(changes) != 0 {
+				t.Errorf("nil desired should produce no change for %s", field.name)
+			}
+		})
+
+		t.Run(field.name+" same value produces no change", func(t *testing.T) {
+			current := model.RepoCurrent{}
+			desired := model.RepoDesired{}
+			field.setCurrent(&current, "same")
+			field.setDesired(&desired, strPtr("same"))
+
+			changes := CompareRepo(current, desired)
+
+			if len(changes) != 0 {
+				t.Errorf("same value should produce no change for %s", field.name)
+			}
+		})
+	}
+}
+
+// TestCompareRepoHomepageNormalization tests that equivalent homepage URLs are not drift
+func TestCompareRepoHomepageNormalization(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		desired string
+		wantNew string // Empty when no change is expected
+	}{
+		{name: "trailing slash on current", current: "https://example.com/", desired: "https://example.com"},
+		{name: "trailing slash on desired", current: "https://example.com", desired: "https://example.com/"},
+		{name: "scheme and host case", current: "https://example.com", desired: "HTTPS://Example.COM/"},
+		{name: "root slash before a query", current: "https://example.com/?ref=gh", desired: "https://example.com?ref=gh"},
+		{name: "different path", current: "https://example.com/", desired: "https://example.com/docs", wantNew: "https://example.com/docs"},
+		{name: "path trailing slash is significant", current: "https://example.com/docs", desired: "https://example.com/docs/", wantNew: "https://example.com/docs/"},
+		{name: "path case is significant", current: "https://example.com/docs", desired: "https://example.com/Docs", wantNew: "https://example.com/Docs"},
+		{name: "different scheme", current: "http://example.com", desired: "https://example.com", wantNew: "https://example.com"},
+		{name: "normalized value is sent", current: "https://old.example.com", desired: "https://Example.com/", wantNew: "https://example.com"},
+		{name: "not a URL", current: "example.com", desired: "example.com/", wantNew: "example.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := CompareRepo(model.RepoCurrent{Homepage: tt.current}, model.RepoDesired{Homepage: strPtr(tt.desired)})
+
+			if tt.wantNew == "" {
+				if len(changes) != 0 {
+					t.Errorf("expected no change, got %+v", changes)
+				}
+				return
+			}
+			if len(changes) != 1 {
+				t.Fatalf("expected 1 change, got %d", len(changes))
+			}
+			if changes[0].Old != tt.current || changes[0].New != tt.wantNew {
+				t.Errorf("change = %v -> %v, want %v -> %v", changes[0].Old, changes[0].New, tt.current, tt.wantNew)
+			}
+		})
+	}
+}
+
+// TestCompareRepoBoolFields tests boolean field comparison
+func TestCompareRepoBoolFields(t *testing.T) {
+	boolFields := []struct {
+		name       string
+		setCurrent func(*model.RepoCurrent, bool)
+		setDesired func(*model.RepoDesired, *bool)
+	}{
+		{
+			name:       "allow_merge_commit",
+			setCurrent: func(c *model.RepoCurrent, v bool) { c.AllowMergeCommit = v },
+			setDesired: func(d *model.RepoDesired, v *bool) { d.AllowMergeCommit = v },
+		},
+		{
+			name:       "allow_rebase_merge",
+			setCurrent: func(c *model.RepoCurrent, v bool) { c.AllowRebaseMerge = v },
+			setDesired: func(d *model.RepoDesired, v *bool) { d.AllowRebaseMerge = v },
+		},
+		{
+			name:       "allow_squash_merge",
+			setCurrent: func(c *model.RepoCurrent, v bool) { c.AllowSquashMerge = v },
+			setDesired: func(d *model.RepoDesired, v *bool) { d.AllowSquashMerge = v },
+		},
+		{
+			name:       "delete_branch_on_merge",
+			setCurrent: func(c *model.RepoCurrent, v bool) { c.DeleteBranchOnMerge = v },
+			setDesired: func(d *model.RepoDesired, v *bool) { d.DeleteBranchOnMerge = v },
+		},
+		{
+			name:       "allow_update_branch",
+			setCurrent: func(c *model.RepoCurrent, v bool) { c.AllowUpdateBranch = v },
+			setDesired: func(d *model.RepoDesired, v *bool) { d.AllowUpdateBranch = v },
+		},
+		{
+			name:       "allow_auto_merge",
+			setCurrent: func(c *model.RepoCurrent, v bool) { c.AllowAutoMerge = v },
+			setDesired: func(d *model.RepoDesired, v *bool) { d.AllowAutoMerge = v },
+		},
+		{
+			name:       "use_squash_pr_title_as_default",
+			setCurrent: func(c *model.RepoCurrent, v bool) { c.UseSquashPRTitle = v },
+			setDesired: func(d *model.RepoDesired, v *bool) { d.UseSquashPRTitle = v },
+		},
+		{
+			name:       "web_commit_signoff_required",
+			setCurrent: func(c *model.RepoCurrent, v bool) { c.WebCommitSignoffRequired = v },
+			setDesired: func(d *model.RepoDesired, v *bool) { d.WebCommitSignoffRequired = v },
+		},
+		{
+			name:       "is_template",
+			setCurrent: func(c *model.RepoCurrent, v bool) { c.IsTemplate = v },
+			setDesired: func(d *model.RepoDesired, v *bool) { d.IsTemplate = v },
+		},
+		{
+			name:       "archived",
+			setCurrent: func(c *model.RepoCurrent, v bool) { c.Archived = v },
+			setDesired: func(d *model.RepoDesired, v *bool) { d.Archived = v },
+		},
+	}
+
+	for _, field := range boolFields {
+		t.Run(field.name+" false to true detected", func(t *testing.T) {
+			current := model.RepoCurrent{}
+			desired := model.RepoDesired{}
+			field.setCurrent(&current, false)
+			field.setDesired(&desired, boolPtr(true))
+
+			changes := CompareRepo(current, desired)
+
+			if len(changes) != 1 {
+				t.Fatalf("expected 1 change for %s, got %d", field.name, len(changes))
+			}
+			if changes[0].Key != field.name {
+				t.Errorf("expected key '%s', got %s", field.name, changes[0].Key)
+			}
+			if changes[0].Old != false {
+				t.Errorf("expected Old = false")
+			}
+			if changes[0].New != true {
+				t.Errorf("expected New = true")
+			}
+		})
+
+		t.Run(field.name+" true to false detected", func(t *testing.T) {
+			current := model.RepoCurrent{}
+			desired := model.RepoDesired{}
+			field.setCurrent(&current, true)
+			field.setDesired(&desired, boolPtr(false))
+
+			changes := CompareRepo(current, desired)
+
+			if len(changes) != 1 {
+				t.Fatalf("expected 1 change for %s, got %d", field.name, len(changes))
+			}
+		})
+
+		t.Run(field.name+" nil desired produces no change", func(t *testing.T) {
+			current := model.RepoCurrent{}
+			desired := model.RepoDesired{}
+			field.setCurrent(&current, true)
+
+			changes := CompareRepo(current, desired)
+
+			if len(changes) != 0 {
+				t.Errorf("nil desired should produce no change for %s", field.name)
+			}
+		})
+
+		t.Run(field.name+" same value produces no change", func(t *testing.T) {
+			current := model.RepoCurrent{}
+			desired := model.RepoDesired{}
+			field.setCurrent(&current, true)
+			field.setDesired(&desired, boolPtr(true))
+
+			changes := CompareRepo(current, desired)
+
+			if len(changes) != 0 {
+				t.Errorf("same value should produce no change for %s", field.name)
+			}
+		})
+	}
+}
+
+func TestUndeclaredRepoSettings(t *testing.T) {
+	// A repository as GitHub creates it
+	defaults := model.RepoCurrent{
+		Visibility:       "private",
+		AllowMergeCommit: true,
+		AllowRebaseMerge: true,
+		AllowSquashMerge: true,
+	}
+
+	t.Run("GitHub defaults are not reported", func(t *testing.T) {
+		if changes := UndeclaredRepoSettings(defaults, model.RepoDesired{}); len(changes) != 0 {
+			t.Errorf("expected no changes, got %v", changes)
+		}
+	})
+
+	t.Run("non-default settings not in config are reported", func(t *testing.T) {
+		current := defaults
+		current.Description = "My repo"
+		current.AllowMergeCommit = false
+		current.WebCommitSignoffRequired = true
+
+		changes := UndeclaredRepoSettings(current, model.RepoDesired{})
+
+		want := map[string]bool{"description": true, "allow_merge_commit": true, "web_commit_signoff_required": true}
+		if len(changes) != len(want) {
+			t.Fatalf("expected %d changes, got %v", len(want), changes)
+		}
+		for _, change := range changes {
+			if !want[change.Key] {
+				t.Errorf("unexpected change %s", change.Key)
+			}
+			if !change.IsInfo() {
+				t.Errorf("change %s should be informational", change.Key)
+			}
+		}
+	})
+
+	t.Run("settings in config are not reported", func(t *testing.T) {
+		current := defaults
+		current.Description = "My repo"
+		current.AllowMergeCommit = false
+
+		changes := UndeclaredRepoSettings(current, model.RepoDesired{
+			Description:      strPtr("Another description"),
+			AllowMergeCommit: boolPtr(true),
+		})
+
+		if len(changes) != 0 {
+			t.Errorf("expected no changes, got %v", changes)
+		}
+	})
+}
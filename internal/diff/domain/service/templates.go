@@ -0,0 +1,49 @@
+package service
+
+import (
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+// shortSHALength is the number of SHA characters shown in plan output
+const shortSHALength = 7
+
+// CompareTemplates compares current repository files with the desired templates
+// This is a pure domain service with no infrastructure dependencies
+//
+// current maps file paths to their blob SHA; a missing path means the file
+// doesn't exist. Files are compared by content hash and keyed by target path.
+// Files in the repository that aren't configured are left alone.
+func CompareTemplates(current map[string]string, desired []model.TemplateFile) []model.Change {
+	var changes []model.Change
+
+	for _, want := range desired {
+		have, exists := current[want.Path]
+		if !exists {
+			changes = append(changes, model.NewAddChange(
+				model.CategoryTemplates,
+				want.Path,
+				formatBlobSHA(want.SHA),
+			))
+			continue
+		}
+
+		if have != want.SHA {
+			changes = append(changes, model.NewUpdateChange(
+				model.CategoryTemplates,
+				want.Path,
+				formatBlobSHA(have),
+				formatBlobSHA(want.SHA),
+			))
+		}
+	}
+
+	return changes
+}
+
+// formatBlobSHA formats a blob SHA for display
+func formatBlobSHA(sha string) string {
+	if len(sha) > shortSHALength {
+		sha = sha[:shortSHALength]
+	}
+	return "blob " + sha
+}
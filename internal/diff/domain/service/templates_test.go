@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+func TestCompareTemplates(t *testing.T) {
+	const (
+		shaA = "ce013625030ba8dba906f756967f9e9ca394464a"
+		shaB = "3b18e512dba79e4c8300dd08aeb37f8e728b8dad"
+	)
+
+	tests := []struct {
+		name     string
+		current  map[string]string
+		desired  []model.TemplateFile
+		expected []model.Change
+	}{
+		{
+			name:    "new file",
+			current: map[string]string{},
+			desired: []model.TemplateFile{{Path: ".github/PULL_REQUEST_TEMPLATE.md", SHA: shaA}},
+			expected: []model.Change{
+				model.NewAddChange(model.CategoryTemplates, ".github/PULL_REQUEST_TEMPLATE.md", "blob ce01362"),
+			},
+		},
+		{
+			name:    "changed file",
+			current: map[string]string{".github/ISSUE_TEMPLATE/bug.md": shaA},
+			desired: []model.TemplateFile{{Path: ".github/ISSUE_TEMPLATE/bug.md", SHA: shaB}},
+			expected: []model.Change{
+				model.NewUpdateChange(model.CategoryTemplates, ".github/ISSUE_TEMPLATE/bug.md", "blob ce01362", "blob 3b18e51"),
+			},
+		},
+		{
+			name:     "unchanged file",
+			current:  map[string]string{".github/ISSUE_TEMPLATE/bug.md": shaA},
+			desired:  []model.TemplateFile{{Path: ".github/ISSUE_TEMPLATE/bug.md", SHA: shaA}},
+			expected: nil,
+		},
+		{
+			name:     "unconfigured repository files are ignored",
+			current:  map[string]string{".github/ISSUE_TEMPLATE/other.md": shaA},
+			desired:  nil,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := CompareTemplates(tt.current, tt.desired)
+			if len(changes) != len(tt.expected) {
+				t.Fatalf("expected %d changes, got %d: %+v", len(tt.expected), len(changes), changes)
+			}
+			for i, want := range tt.expected {
+				got := changes[i]
+				if got.Type != want.Type || got.Key != want.Key || got.Old != want.Old || got.New != want.New {
+					t.Errorf("change[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package diff
+
+import (
+	"fmt"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+// ComparatorError is returned when a comparator fails to read the current state.
+// Category identifies the config section that couldn't be compared; use errors.As
+// to recover it:
+//
+//	var cerr *diff.ComparatorError
+//	if errors.As(err, &cerr) {
+//	    fmt.Println("failed section:", cerr.Category)
+//	}
+type ComparatorError struct {
+	Category model.ChangeCategory // The first category of the step, e.g. variables for the env comparison
+	Step     string               // Human-readable name of the comparison, e.g. "repo settings"
+	Err      error
+}
+
+func (e *ComparatorError) Error() string {
+	return fmt.Sprintf("failed to compare %s: %v", e.Step, e.Err)
+}
+
+func (e *ComparatorError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,78 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+// Finding is a security-relevant drift for scanners and security dashboards,
+// modelled on SARIF results
+type Finding struct {
+	// RuleID is the category and key of the change, e.g. "branch_protection.main.enforce_admins"
+	RuleID string `json:"rule_id"`
+	// Level is the SARIF level: "error", "warning" or "note"
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// IsSecurityRelevant reports whether a change belongs in the findings output:
+// repository visibility, branch protection and Actions permissions
+func IsSecurityRelevant(c model.Change) bool {
+	switch c.Category {
+	case model.CategoryRepo:
+		return c.Key == "visibility"
+	case model.CategoryBranchProtection, model.CategoryActions, model.CategoryOrgActions:
+		return true
+	}
+	return false
+}
+
+// PlanToFindings converts the security-relevant changes of a plan into findings
+func PlanToFindings(p *model.Plan) []Finding {
+	findings := []Finding{}
+	for _, change := range p.Changes() {
+		if change.IsInfo() || !IsSecurityRelevant(change) {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:  fmt.Sprintf("%s.%s", change.Category, change.Key),
+			Level:   findingLevel(change.Severity()),
+			Message: findingMessage(change),
+		})
+	}
+	return findings
+}
+
+// PlanMarshalFindings returns the pretty-printed findings of a plan as a JSON array
+func PlanMarshalFindings(p *model.Plan) ([]byte, error) {
+	return json.MarshalIndent(PlanToFindings(p), "", "  ")
+}
+
+// findingLevel maps a severity to a SARIF level
+func findingLevel(s model.Severity) string {
+	switch s {
+	case model.SeverityHigh:
+		return "error"
+	case model.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// findingMessage describes the current and the configured value of a change
+func findingMessage(c model.Change) string {
+	name := fmt.Sprintf("%s.%s", c.Category, c.Key)
+	switch c.Type {
+	case model.ChangeAdd:
+		return fmt.Sprintf("%s is not set; the config requires %v", name, c.New)
+	case model.ChangeUpdate:
+		return fmt.Sprintf("%s is %v; the config requires %v", name, c.Old, c.New)
+	case model.ChangeDelete:
+		return fmt.Sprintf("%s is %v; the config requires it to be removed", name, c.Old)
+	default:
+		return fmt.Sprintf("%s: %v", name, c.New)
+	}
+}
@@ -0,0 +1,48 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+func TestPlanToFindings(t *testing.T) {
+	plan := model.NewPlanFromChanges([]model.Change{
+		model.NewUpdateChange(model.CategoryRepo, "description", "old", "new"),
+		model.NewUpdateChange(model.CategoryRepo, "visibility", "public", "private"),
+		model.NewAddChange(model.CategoryLabels, "bug", "d73a4a"),
+		model.NewUpdateChange(model.CategoryBranchProtection, "main.status_checks", []string{"ci"}, []string{"ci", "lint"}),
+		model.NewAddChange(model.CategoryBranchProtection, "release", "required_reviews=1"),
+		model.NewUpdateChange(model.CategoryActions, "artifact_retention_days", 90, 30),
+		model.NewInfoChange(model.CategoryActions, "allowed_actions", "note"),
+		model.NewMissingChange(model.CategorySecrets, "TOKEN", "not configured"),
+	})
+
+	want := []Finding{
+		{RuleID: "repo.visibility", Level: "error", Message: "repo.visibility is public; the config requires private"},
+		{RuleID: "branch_protection.main.status_checks", Level: "warning", Message: "branch_protection.main.status_checks is [ci]; the config requires [ci lint]"},
+		{RuleID: "branch_protection.release", Level: "error", Message: "branch_protection.release is not set; the config requires required_reviews=1"},
+		{RuleID: "actions.artifact_retention_days", Level: "warning", Message: "actions.artifact_retention_days is 90; the config requires 30"},
+	}
+	if got := PlanToFindings(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("PlanToFindings() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestPlanMarshalFindingsEmpty(t *testing.T) {
+	plan := model.NewPlanFromChanges([]model.Change{
+		model.NewAddChange(model.CategoryLabels, "bug", "d73a4a"),
+	})
+
+	data, err := PlanMarshalFindings(plan)
+	if err != nil {
+		t.Fatalf("PlanMarshalFindings() error = %v", err)
+	}
+	// Scanners expect an array even without findings
+	var findings []Finding
+	if err := json.Unmarshal(data, &findings); err != nil || findings == nil || len(findings) != 0 {
+		t.Errorf("PlanMarshalFindings() = %s, want []", data)
+	}
+}
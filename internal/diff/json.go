@@ -2,29 +2,53 @@ package diff
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
 
 	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
 )
 
 // JSONPlan represents the JSON output structure for plan
 type JSONPlan struct {
+	// Repository is the "owner/name" the plan was calculated for
+	Repository       string       `json:"repository,omitempty"`
 	Repo             []JSONChange `json:"repo,omitempty"`
 	Topics           []JSONChange `json:"topics,omitempty"`
 	Labels           []JSONChange `json:"labels,omitempty"`
 	BranchProtection []JSONChange `json:"branch_protection,omitempty"`
 	Actions          []JSONChange `json:"actions,omitempty"`
 	Pages            []JSONChange `json:"pages,omitempty"`
+	SocialPreview    []JSONChange `json:"social_preview,omitempty"`
+	Templates        []JSONChange `json:"templates,omitempty"`
 	Variables        []JSONChange `json:"variables,omitempty"`
 	Secrets          []JSONChange `json:"secrets,omitempty"`
+	OrgActions       []JSONChange `json:"org_actions,omitempty"`
 	Summary          JSONSummary  `json:"summary"`
 }
 
+// JSONGroupedPlan is the grouped JSON output for plan: changes keyed by category name.
+// Unlike JSONPlan it also includes categories of custom comparators.
+type JSONGroupedPlan struct {
+	Repository string                  `json:"repository,omitempty"`
+	Categories map[string][]JSONChange `json:"categories"`
+	Summary    JSONSummary             `json:"summary"`
+}
+
 // JSONChange represents a single change in JSON format
 type JSONChange struct {
 	Type string      `json:"type"`
 	Key  string      `json:"key"`
 	Old  interface{} `json:"old,omitempty"`
 	New  interface{} `json:"new,omitempty"`
+	// Branch and Field are set on branch protection changes; see model.Change
+	Branch string `json:"branch,omitempty"`
+	Field  string `json:"field,omitempty"`
+	// CurrentName is set on label updates that rename the label; see model.Change
+	CurrentName string `json:"current_name,omitempty"`
+	// Warning is set on changes that may be disruptive to apply
+	Warning string `json:"warning,omitempty"`
+	// Reason is the config's _meta note for the setting
+	Reason string `json:"reason,omitempty"`
 }
 
 // JSONSummary represents the summary counts
@@ -33,39 +57,41 @@ type JSONSummary struct {
 	Update  int `json:"update"`
 	Delete  int `json:"delete"`
 	Missing int `json:"missing"`
+	Info    int `json:"info"`
 }
 
 // PlanToJSON converts a Plan to JSON output structure
 func PlanToJSON(p *model.Plan) *JSONPlan {
-	jsonPlan := &JSONPlan{}
+	jsonPlan := &JSONPlan{Repository: p.Repository()}
 
-	var adds, updates, deletes, missing int
+	var adds, updates, deletes, missing, info int
 
 	for _, change := range p.Changes() {
-		jc := JSONChange{
-			Type: change.Type.String(),
-			Key:  change.Key,
-			Old:  change.Old,
-			New:  change.New,
-		}
+		jc := newJSONChange(change)
 
 		switch change.Category {
-		case "repo":
+		case model.CategoryRepo:
 			jsonPlan.Repo = append(jsonPlan.Repo, jc)
-		case "topics":
+		case model.CategoryTopics:
 			jsonPlan.Topics = append(jsonPlan.Topics, jc)
-		case "labels":
+		case model.CategoryLabels:
 			jsonPlan.Labels = append(jsonPlan.Labels, jc)
-		case "branch_protection":
+		case model.CategoryBranchProtection:
 			jsonPlan.BranchProtection = append(jsonPlan.BranchProtection, jc)
-		case "actions":
+		case model.CategoryActions:
 			jsonPlan.Actions = append(jsonPlan.Actions, jc)
-		case "pages":
+		case model.CategoryPages:
 			jsonPlan.Pages = append(jsonPlan.Pages, jc)
-		case "variables":
+		case model.CategorySocialPreview:
+			jsonPlan.SocialPreview = append(jsonPlan.SocialPreview, jc)
+		case model.CategoryTemplates:
+			jsonPlan.Templates = append(jsonPlan.Templates, jc)
+		case model.CategoryVariables:
 			jsonPlan.Variables = append(jsonPlan.Variables, jc)
-		case "secrets":
+		case model.CategorySecrets:
 			jsonPlan.Secrets = append(jsonPlan.Secrets, jc)
+		case model.CategoryOrgActions:
+			jsonPlan.OrgActions = append(jsonPlan.OrgActions, jc)
 		}
 
 		switch change.Type {
@@ -77,6 +103,8 @@ func PlanToJSON(p *model.Plan) *JSONPlan {
 			deletes++
 		case model.ChangeMissing:
 			missing++
+		case model.ChangeInfo:
+			info++
 		}
 	}
 
@@ -85,12 +113,152 @@ func PlanToJSON(p *model.Plan) *JSONPlan {
 		Update:  updates,
 		Delete:  deletes,
 		Missing: missing,
+		Info:    info,
 	}
 
 	return jsonPlan
 }
 
+// PlanToGroupedJSON converts a Plan to the grouped JSON output structure
+func PlanToGroupedJSON(p *model.Plan) *JSONGroupedPlan {
+	grouped := &JSONGroupedPlan{Repository: p.Repository(), Categories: make(map[string][]JSONChange)}
+	for _, category := range p.Categories() {
+		changes := p.FilterByCategory(category).Changes()
+		jsonChanges := make([]JSONChange, len(changes))
+		for i, change := range changes {
+			jsonChanges[i] = newJSONChange(change)
+		}
+		grouped.Categories[category.String()] = jsonChanges
+	}
+
+	stats := p.Stats()
+	grouped.Summary = JSONSummary{
+		Add:     stats.Add,
+		Update:  stats.Update,
+		Delete:  stats.Delete,
+		Missing: stats.Missing,
+		Info:    stats.Info,
+	}
+	return grouped
+}
+
+// newJSONChange converts a change to its JSON form
+func newJSONChange(change model.Change) JSONChange {
+	return JSONChange{
+		Type:        change.Type.String(),
+		Key:         change.Key,
+		Old:         change.Old,
+		New:         change.New,
+		Branch:      change.Branch,
+		Field:       change.Field,
+		CurrentName: change.CurrentName,
+		Warning:     change.Warning,
+		Reason:      change.Reason,
+	}
+}
+
 // PlanMarshalIndent returns pretty-printed JSON bytes for a plan
 func PlanMarshalIndent(p *model.Plan) ([]byte, error) {
 	return json.MarshalIndent(PlanToJSON(p), "", "  ")
 }
+
+// PlanMarshalIndentGrouped returns pretty-printed grouped JSON bytes for a plan
+func PlanMarshalIndentGrouped(p *model.Plan) ([]byte, error) {
+	return json.MarshalIndent(PlanToGroupedJSON(p), "", "  ")
+}
+
+// UnmarshalPlan parses a plan written by PlanMarshalIndent or PlanMarshalIndentGrouped.
+// Values come back as decoded JSON, so numbers are float64 and lists are []interface{}.
+func UnmarshalPlan(data []byte) (*model.Plan, error) {
+	var grouped JSONGroupedPlan
+	if err := json.Unmarshal(data, &grouped); err != nil {
+		return nil, fmt.Errorf("invalid plan JSON: %w", err)
+	}
+	if grouped.Categories != nil {
+		plan := model.NewPlan()
+		plan.SetRepository(grouped.Repository)
+		for _, category := range sortedCategoryNames(grouped.Categories) {
+			if err := addJSONChanges(plan, model.ChangeCategory(category), grouped.Categories[category]); err != nil {
+				return nil, err
+			}
+		}
+		return plan, nil
+	}
+
+	var jsonPlan JSONPlan
+	if err := json.Unmarshal(data, &jsonPlan); err != nil {
+		return nil, fmt.Errorf("invalid plan JSON: %w", err)
+	}
+	plan := model.NewPlan()
+	plan.SetRepository(jsonPlan.Repository)
+	for _, group := range []struct {
+		category model.ChangeCategory
+		changes  []JSONChange
+	}{
+		{model.CategoryRepo, jsonPlan.Repo},
+		{model.CategoryTopics, jsonPlan.Topics},
+		{model.CategoryLabels, jsonPlan.Labels},
+		{model.CategoryBranchProtection, jsonPlan.BranchProtection},
+		{model.CategoryActions, jsonPlan.Actions},
+		{model.CategoryPages, jsonPlan.Pages},
+		{model.CategorySocialPreview, jsonPlan.SocialPreview},
+		{model.CategoryTemplates, jsonPlan.Templates},
+		{model.CategoryVariables, jsonPlan.Variables},
+		{model.CategorySecrets, jsonPlan.Secrets},
+		{model.CategoryOrgActions, jsonPlan.OrgActions},
+	} {
+		if err := addJSONChanges(plan, group.category, group.changes); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// addJSONChanges adds changes decoded from JSON to plan under category
+func addJSONChanges(plan *model.Plan, category model.ChangeCategory, changes []JSONChange) error {
+	for _, jc := range changes {
+		changeType, err := model.ParseChangeType(jc.Type)
+		if err != nil {
+			return fmt.Errorf("invalid plan JSON: %s.%s: %w", category, jc.Key, err)
+		}
+		plan.Add(model.Change{
+			Type:        changeType,
+			Category:    category,
+			Key:         jc.Key,
+			Old:         jc.Old,
+			New:         jc.New,
+			Branch:      jc.Branch,
+			Field:       jc.Field,
+			CurrentName: jc.CurrentName,
+			Warning:     jc.Warning,
+			Reason:      jc.Reason,
+		})
+	}
+	return nil
+}
+
+// builtinCategoryOrder is the order of the built-in categories in JSONPlan
+var builtinCategoryOrder = []model.ChangeCategory{
+	model.CategoryRepo, model.CategoryTopics, model.CategoryLabels, model.CategoryBranchProtection,
+	model.CategoryActions, model.CategoryPages, model.CategorySocialPreview, model.CategoryTemplates,
+	model.CategoryVariables, model.CategorySecrets, model.CategoryOrgActions,
+}
+
+// sortedCategoryNames returns the category names of a grouped plan, built-in categories
+// first in the same order as JSONPlan, followed by custom categories sorted by name
+func sortedCategoryNames(categories map[string][]JSONChange) []string {
+	names := make([]string, 0, len(categories))
+	for _, category := range builtinCategoryOrder {
+		if _, ok := categories[category.String()]; ok {
+			names = append(names, category.String())
+		}
+	}
+	var custom []string
+	for name := range categories {
+		if !isBuiltinCategory(model.ChangeCategory(name)) {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	return append(names, custom...)
+}
@@ -2,6 +2,7 @@ package diff
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
@@ -98,6 +99,18 @@ func TestPlanToJSON(t *testing.T) {
 				Summary: JSONSummary{Add: 4, Update: 3, Delete: 0, Missing: 1},
 			},
 		},
+		{
+			name: "org actions change",
+			plan: model.NewPlanFromChanges([]model.Change{
+				{Type: model.ChangeUpdate, Category: "org_actions", Key: "allowed_actions", Old: "all", New: "selected"},
+			}),
+			expected: &JSONPlan{
+				OrgActions: []JSONChange{
+					{Type: "update", Key: "allowed_actions", Old: "all", New: "selected"},
+				},
+				Summary: JSONSummary{Add: 0, Update: 1, Delete: 0, Missing: 0},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -118,6 +131,7 @@ func TestPlanToJSON(t *testing.T) {
 			checkJSONChanges(t, "pages", result.Pages, tt.expected.Pages)
 			checkJSONChanges(t, "variables", result.Variables, tt.expected.Variables)
 			checkJSONChanges(t, "secrets", result.Secrets, tt.expected.Secrets)
+			checkJSONChanges(t, "org_actions", result.OrgActions, tt.expected.OrgActions)
 		})
 	}
 }
@@ -394,3 +408,145 @@ func TestJSONUnknownCategoryIgnored(t *testing.T) {
 		t.Errorf("expected 1 add (unknown category still counted), got %d", jsonPlan.Summary.Add)
 	}
 }
+
+func TestPlanToJSONRoutesEveryCategory(t *testing.T) {
+	categories := []model.ChangeCategory{
+		model.CategoryRepo, model.CategoryTopics, model.CategoryLabels, model.CategoryBranchProtection,
+		model.CategoryActions, model.CategoryPages, model.CategorySocialPreview, model.CategoryTemplates,
+		model.CategoryVariables, model.CategorySecrets, model.CategoryOrgActions,
+	}
+
+	for _, category := range categories {
+		t.Run(category.String(), func(t *testing.T) {
+			plan := model.NewPlanFromChanges([]model.Change{
+				model.NewAddChange(category, "key", "value"),
+			})
+			data, err := PlanMarshalIndent(plan)
+			if err != nil {
+				t.Fatalf("PlanMarshalIndent() error = %v", err)
+			}
+
+			// The JSON field is the category's string form
+			var output map[string]json.RawMessage
+			if err := json.Unmarshal(data, &output); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			var changes []JSONChange
+			if err := json.Unmarshal(output[category.String()], &changes); err != nil || len(changes) != 1 {
+				t.Errorf("expected 1 change under %q, got %s", category, data)
+			}
+		})
+	}
+}
+
+func TestPlanToGroupedJSON(t *testing.T) {
+	plan := model.NewPlanFromChanges([]model.Change{
+		model.NewUpdateChange(model.CategoryRepo, "description", "old", "new"),
+		model.NewAddChange(model.CategoryLabels, "bug", "d73a4a"),
+		model.NewDeleteChange(model.CategoryLabels, "stale", "ffffff"),
+		model.NewMissingChange(model.CategorySecrets, "TOKEN", "not set"),
+		model.NewMissingChange("policy", "description", "repository has no description"),
+	})
+
+	data, err := PlanMarshalIndentGrouped(plan)
+	if err != nil {
+		t.Fatalf("PlanMarshalIndentGrouped() error = %v", err)
+	}
+
+	var output struct {
+		Categories map[string][]JSONChange `json:"categories"`
+		Summary    JSONSummary             `json:"summary"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	wantCounts := map[string]int{"repo": 1, "labels": 2, "secrets": 1, "policy": 1}
+	if len(output.Categories) != len(wantCounts) {
+		t.Errorf("categories = %v, want %v", output.Categories, wantCounts)
+	}
+	for category, want := range wantCounts {
+		if got := len(output.Categories[category]); got != want {
+			t.Errorf("%s: %d changes, want %d", category, got, want)
+		}
+	}
+	if labels := output.Categories["labels"]; len(labels) == 2 && (labels[0].Key != "bug" || labels[1].Type != "delete") {
+		t.Errorf("labels changes out of order: %+v", labels)
+	}
+
+	want := JSONSummary{Add: 1, Update: 1, Delete: 1, Missing: 2}
+	if output.Summary != want {
+		t.Errorf("summary = %+v, want %+v", output.Summary, want)
+	}
+}
+
+func TestUnmarshalPlanRoundTrip(t *testing.T) {
+	// Changes in JSONPlan order, with values that survive JSON unchanged
+	original := model.NewPlanFromChanges([]model.Change{
+		{Type: model.ChangeUpdate, Category: model.CategoryRepo, Key: "description", Old: "old", New: "new"},
+		{Type: model.ChangeUpdate, Category: model.CategoryRepo, Key: "allow_merge_commit", Old: true, New: false},
+		{Type: model.ChangeAdd, Category: model.CategoryLabels, Key: "bug", New: "color=d73a4a"},
+		{Type: model.ChangeDelete, Category: model.CategoryLabels, Key: "wontfix", Old: "color=ffffff"},
+		{Type: model.ChangeUpdate, Category: model.CategoryPages, Key: "cname", Old: nil, New: "docs.example.com"},
+		{Type: model.ChangeUpdate, Category: model.CategoryPages, Key: "build_type", Old: "legacy", New: "workflow", Warning: "may take the site down"},
+		{Type: model.ChangeMissing, Category: model.CategorySecrets, Key: "API_TOKEN", New: "required"},
+		{Type: model.ChangeInfo, Category: model.CategorySecrets, Key: "LEGACY", Old: "exists on GitHub but is not in the config"},
+	})
+	original.SetRepository("acme/widgets")
+
+	marshalers := map[string]func(*model.Plan) ([]byte, error){
+		"flat":    PlanMarshalIndent,
+		"grouped": PlanMarshalIndentGrouped,
+	}
+	for name, marshal := range marshalers {
+		t.Run(name, func(t *testing.T) {
+			data, err := marshal(original)
+			if err != nil {
+				t.Fatalf("marshal error: %v", err)
+			}
+			got, err := UnmarshalPlan(data)
+			if err != nil {
+				t.Fatalf("UnmarshalPlan() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Changes(), original.Changes()) {
+				t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got.Changes(), original.Changes())
+			}
+			if got.Repository() != "acme/widgets" {
+				t.Errorf("Repository() = %q, want acme/widgets", got.Repository())
+			}
+		})
+	}
+}
+
+func TestUnmarshalPlanCustomCategory(t *testing.T) {
+	original := model.NewPlanFromChanges([]model.Change{
+		{Type: model.ChangeUpdate, Category: model.CategoryRepo, Key: "description", Old: "old", New: "new"},
+		{Type: model.ChangeMissing, Category: "policy", Key: "description", New: "repository has no description"},
+	})
+	data, err := PlanMarshalIndentGrouped(original)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	got, err := UnmarshalPlan(data)
+	if err != nil {
+		t.Fatalf("UnmarshalPlan() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Changes(), original.Changes()) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got.Changes(), original.Changes())
+	}
+}
+
+func TestUnmarshalPlanErrors(t *testing.T) {
+	tests := map[string]string{
+		"not JSON":     `plan`,
+		"unknown type": `{"repo": [{"type": "rename", "key": "description"}]}`,
+		"wrong shape":  `{"repo": {"type": "update"}}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := UnmarshalPlan([]byte(data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -11,6 +11,9 @@ import (
 // This is presentation logic for human-readable output
 func FormatBranchRule(rule *config.BranchRule) string {
 	var parts []string
+	if rule.RequireReviews != nil && !*rule.RequireReviews {
+		parts = append(parts, "require_reviews=false")
+	}
 	if rule.RequiredReviews != nil {
 		parts = append(parts, fmt.Sprintf("required_reviews=%d", *rule.RequiredReviews))
 	}
@@ -61,7 +61,7 @@ func TestFormatBranchRule(t *testing.T) {
 		{
 			name: "status_checks list",
 			rule: &config.BranchRule{
-				StatusChecks: []string{"ci", "lint"},
+				StatusChecks: []config.StatusCheck{{Context: "ci"}, {Context: "lint"}},
 			},
 			contains: []string{"status_checks=", "ci", "lint"},
 		},
@@ -203,7 +203,7 @@ func TestFormatBranchRule_NilValues(t *testing.T) {
 func TestFormatBranchRule_EmptyStatusChecks(t *testing.T) {
 	// Empty status checks slice should not be included
 	rule := &config.BranchRule{
-		StatusChecks: []string{},
+		StatusChecks: []config.StatusCheck{},
 	}
 
 	result := FormatBranchRule(rule)
@@ -0,0 +1,89 @@
+package diff
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/comparator"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+)
+
+// Comparator compares current GitHub state with the desired configuration and returns
+// the differences as a Plan. Compare must not modify the repository.
+type Comparator = comparator.Comparator
+
+// ComparatorFactory creates a custom comparator for a calculation.
+// It receives the same client and configuration as the built-in comparators
+// and may return nil to skip the check, e.g. when the config has nothing for it.
+type ComparatorFactory func(client github.GitHubClient, cfg *config.Config) Comparator
+
+// customComparator is a comparator registered with RegisterComparator
+type customComparator struct {
+	name     string
+	category ChangeCategory
+	factory  ComparatorFactory
+}
+
+var (
+	customComparatorsMu sync.RWMutex
+	customComparators   []customComparator
+)
+
+// RegisterComparator adds a comparator that runs after the built-in ones in every Calculator.
+// Its changes should use category, which must not be one of the built-in categories;
+// changes in other categories are dropped. name is used in progress and error messages.
+//
+// Custom changes are shown by plan and count towards its exit code, but apply has no
+// handler for them, so they describe checks rather than settings it can fix.
+// RegisterComparator is typically called from an init function and is safe for concurrent use.
+func RegisterComparator(name string, category ChangeCategory, factory ComparatorFactory) error {
+	if name == "" || category == "" || factory == nil {
+		return fmt.Errorf("custom comparator needs a name, a category and a factory")
+	}
+	if isBuiltinCategory(category) {
+		return fmt.Errorf("custom comparator %q can't use built-in category %q", name, category)
+	}
+
+	customComparatorsMu.Lock()
+	defer customComparatorsMu.Unlock()
+	for _, c := range customComparators {
+		if c.name == name {
+			return fmt.Errorf("custom comparator %q is already registered", name)
+		}
+	}
+	customComparators = append(customComparators, customComparator{name: name, category: category, factory: factory})
+	return nil
+}
+
+// customComparatorSteps returns steps for the registered custom comparators
+func (c *Calculator) customComparatorSteps() []comparatorStep {
+	customComparatorsMu.RLock()
+	defer customComparatorsMu.RUnlock()
+
+	var steps []comparatorStep
+	for _, custom := range customComparators {
+		cmp := custom.factory(c.client, c.config)
+		if cmp == nil {
+			continue
+		}
+		steps = append(steps, comparatorStep{
+			name:       custom.name,
+			categories: []ChangeCategory{custom.category},
+			comparator: cmp,
+			custom:     true,
+		})
+	}
+	return steps
+}
+
+// isBuiltinCategory reports whether category belongs to a built-in comparator
+func isBuiltinCategory(category ChangeCategory) bool {
+	switch category {
+	case CategoryRepo, CategoryTopics, CategoryLabels, CategoryBranchProtection,
+		CategoryVariables, CategorySecrets, CategoryActions, CategoryPages,
+		CategorySocialPreview, CategoryTemplates, CategoryOrgActions:
+		return true
+	}
+	return false
+}
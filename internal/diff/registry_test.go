@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+	"github.com/myzkey/gh-repo-settings/internal/infra/github"
+)
+
+// descriptionPolicy is a trivial organization-specific check: every repository needs a description
+type descriptionPolicy struct {
+	client github.GitHubClient
+}
+
+func (p descriptionPolicy) Compare(ctx context.Context) (*model.Plan, error) {
+	plan := model.NewPlan()
+	repo, err := p.client.GetRepo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if model.NullableStringVal(repo.Description) == "" {
+		plan.Add(model.NewMissingChange("policy", "description", "repository has no description"))
+	}
+	// Changes outside the registered category are dropped
+	plan.Add(model.NewUpdateChange(CategoryRepo, "visibility", "public", "private"))
+	return plan, nil
+}
+
+// withCustomComparators restores the registry after a test
+func withCustomComparators(t *testing.T) {
+	t.Helper()
+	customComparatorsMu.Lock()
+	saved := customComparators
+	customComparators = nil
+	customComparatorsMu.Unlock()
+	t.Cleanup(func() {
+		customComparatorsMu.Lock()
+		customComparators = saved
+		customComparatorsMu.Unlock()
+	})
+}
+
+func TestRegisterComparator(t *testing.T) {
+	withCustomComparators(t)
+
+	err := RegisterComparator("description policy", "policy", func(client github.GitHubClient, cfg *config.Config) Comparator {
+		return descriptionPolicy{client: client}
+	})
+	if err != nil {
+		t.Fatalf("RegisterComparator() error = %v", err)
+	}
+	// Returning nil skips the comparator
+	err = RegisterComparator("disabled policy", "policy", func(client github.GitHubClient, cfg *config.Config) Comparator {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterComparator() error = %v", err)
+	}
+
+	mock := github.NewMockClient()
+	mock.RepoData = &github.RepoData{}
+
+	var steps []string
+	plan, err := NewCalculator(mock, &config.Config{}).CalculateWithOptions(context.Background(), CalculateOptions{
+		Progress: func(step string) { steps = append(steps, step) },
+	})
+	if err != nil {
+		t.Fatalf("CalculateWithOptions() error = %v", err)
+	}
+
+	if plan.Size() != 1 {
+		t.Fatalf("expected 1 change, got %d: %+v", plan.Size(), plan.Changes())
+	}
+	change := plan.Changes()[0]
+	if change.Category != "policy" || change.Key != "description" || change.Type != ChangeMissing {
+		t.Errorf("unexpected change: %+v", change)
+	}
+	if strings.Join(steps, ",") != "description policy" {
+		t.Errorf("progress steps = %v", steps)
+	}
+}
+
+func TestRegisterComparatorErrors(t *testing.T) {
+	withCustomComparators(t)
+
+	factory := func(client github.GitHubClient, cfg *config.Config) Comparator { return nil }
+
+	if err := RegisterComparator("policy", CategoryLabels, factory); err == nil {
+		t.Error("expected error for a built-in category")
+	}
+	if err := RegisterComparator("", "policy", factory); err == nil {
+		t.Error("expected error for an empty name")
+	}
+	if err := RegisterComparator("policy", "policy", nil); err == nil {
+		t.Error("expected error for a nil factory")
+	}
+	if err := RegisterComparator("policy", "policy", factory); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := RegisterComparator("policy", "other", factory); err == nil {
+		t.Error("expected error for a duplicate name")
+	}
+}
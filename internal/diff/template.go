@@ -0,0 +1,108 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+// TemplateData is the view of a plan that plan --format template executes a template over.
+// Changes are in plan order; Categories groups them by category in the same order.
+type TemplateData struct {
+	Changes    []TemplateChange
+	Categories []TemplateCategory
+	Stats      model.PlanStats
+}
+
+// TemplateChange is a single change as seen by a template
+type TemplateChange struct {
+	Category string
+	Key      string
+	Type     string // add, update, delete, missing or info
+	Old      interface{}
+	New      interface{}
+	Warning  string
+	Reason   string // The config's _meta note for the setting
+	Severity string // low, medium or high
+}
+
+// TemplateCategory is the changes of one category
+type TemplateCategory struct {
+	Name    string
+	Changes []TemplateChange
+}
+
+// HasChanges reports whether the plan has anything to apply, as opposed to nothing or
+// only informational findings
+func (d TemplateData) HasChanges() bool {
+	return d.Stats.Add+d.Stats.Update+d.Stats.Delete+d.Stats.Missing > 0
+}
+
+// OfType returns the changes of a type, e.g. {{range .OfType "delete"}}
+func (d TemplateData) OfType(changeType string) []TemplateChange {
+	var changes []TemplateChange
+	for _, c := range d.Changes {
+		if c.Type == changeType {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// InCategory returns the changes of a category, e.g. {{range .InCategory "labels"}}
+func (d TemplateData) InCategory(category string) []TemplateChange {
+	for _, c := range d.Categories {
+		if c.Name == category {
+			return c.Changes
+		}
+	}
+	return nil
+}
+
+// PlanToTemplateData converts a plan to the data given to plan templates
+func PlanToTemplateData(p *model.Plan) TemplateData {
+	data := TemplateData{Stats: p.Stats()}
+	index := make(map[string]int)
+	for _, change := range p.Changes() {
+		tc := TemplateChange{
+			Category: string(change.Category),
+			Key:      change.Key,
+			Type:     change.Type.String(),
+			Old:      change.Old,
+			New:      change.New,
+			Warning:  change.Warning,
+			Reason:   change.Reason,
+			Severity: change.Severity().String(),
+		}
+		data.Changes = append(data.Changes, tc)
+
+		i, ok := index[tc.Category]
+		if !ok {
+			i = len(data.Categories)
+			index[tc.Category] = i
+			data.Categories = append(data.Categories, TemplateCategory{Name: tc.Category})
+		}
+		data.Categories[i].Changes = append(data.Categories[i].Changes, tc)
+	}
+	return data
+}
+
+// ParsePlanTemplate parses a plan template. Referring to a field that TemplateData
+// doesn't have is only detected when the template is executed.
+func ParsePlanTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// ExecutePlanTemplate renders a plan with a template parsed by ParsePlanTemplate
+func ExecutePlanTemplate(w io.Writer, tmpl *template.Template, p *model.Plan) error {
+	if err := tmpl.Execute(w, PlanToTemplateData(p)); err != nil {
+		return fmt.Errorf("failed to render plan template %s: %w", tmpl.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/myzkey/gh-repo-settings/internal/diff/domain/model"
+)
+
+func TestExecutePlanTemplate(t *testing.T) {
+	plan := model.NewPlanFromChanges([]model.Change{
+		model.NewUpdateChange(model.CategoryRepo, "description", "old", "new"),
+		model.NewAddChange(model.CategoryLabels, "bug", "d73a4a"),
+		model.NewDeleteChange(model.CategoryLabels, "wontfix", "ffffff"),
+		model.NewUpdateChange(model.CategoryRepo, "visibility", "public", "private"),
+	})
+
+	const text = `{{range .Categories}}## {{.Name}}
+{{range .Changes}}- {{.Type}} {{.Key}}{{if eq .Type "update"}}: {{.Old}} -> {{.New}}{{end}} ({{.Severity}})
+{{end}}{{end}}deletes:{{range .OfType "delete"}} {{.Key}}{{end}}
+labels: {{len (.InCategory "labels")}}
+total: {{.Stats.Add}}/{{.Stats.Update}}/{{.Stats.Delete}} changes={{.HasChanges}}
+`
+	tmpl, err := ParsePlanTemplate("plan.tmpl", text)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := ExecutePlanTemplate(&buf, tmpl, plan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `## repo
+- update description: old -> new (low)
+- update visibility: public -> private (high)
+## labels
+- add bug (low)
+- delete wontfix (low)
+deletes: wontfix
+labels: 2
+total: 1/2/1 changes=true
+`
+	if buf.String() != want {
+		t.Errorf("rendered:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestPlanTemplateErrors(t *testing.T) {
+	if _, err := ParsePlanTemplate("bad.tmpl", "{{range .Changes}"); err == nil || !strings.HasPrefix(err.Error(), "invalid plan template") {
+		t.Errorf("ParsePlanTemplate() error = %v, want an invalid plan template error", err)
+	}
+
+	tmpl, err := ParsePlanTemplate("plan.tmpl", "{{.Nope}}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = ExecutePlanTemplate(&bytes.Buffer{}, tmpl, model.NewPlan())
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to render plan template plan.tmpl") {
+		t.Errorf("ExecutePlanTemplate() error = %v, want a render error", err)
+	}
+}
@@ -9,6 +9,7 @@ type (
 	ChangeType     = model.ChangeType
 	ChangeCategory = model.ChangeCategory
 	Plan           = model.Plan
+	PlanStats      = model.PlanStats
 )
 
 // Re-export ChangeType constants for backward compatibility
@@ -17,6 +18,7 @@ const (
 	ChangeUpdate  = model.ChangeUpdate
 	ChangeDelete  = model.ChangeDelete
 	ChangeMissing = model.ChangeMissing
+	ChangeInfo    = model.ChangeInfo
 )
 
 // Re-export ChangeCategory constants for backward compatibility
@@ -29,5 +31,8 @@ const (
 	CategorySecrets          = model.CategorySecrets
 	CategoryActions          = model.CategoryActions
 	CategoryPages            = model.CategoryPages
+	CategorySocialPreview    = model.CategorySocialPreview
+	CategoryTemplates        = model.CategoryTemplates
+	CategoryOrgActions       = model.CategoryOrgActions
 	CategoryEnv              = model.CategoryEnv // Alias for CategoryVariables
 )
@@ -3,6 +3,8 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // Sentinel errors
@@ -18,6 +20,11 @@ var (
 	ErrVariableMissing    = errors.New("required variable is missing")
 	ErrBranchNotProtected = errors.New("branch protection not enabled")
 	ErrPagesNotEnabled    = errors.New("GitHub Pages not enabled")
+	ErrFileNotFound       = errors.New("file not found")
+	ErrPlanConflict       = errors.New("current value changed since plan")
+	ErrNotConverged       = errors.New("apply did not converge")
+	ErrTimeout            = errors.New("timed out")
+	ErrRepoMoved          = errors.New("repository was renamed or transferred")
 )
 
 // ConfigError represents a configuration error
@@ -53,10 +60,17 @@ type APIError struct {
 	Method     string
 	StatusCode int
 	Message    string
+	Resource   string // Human-readable resource being operated on (e.g. "label 'bug'")
 	Err        error
 }
 
 func (e *APIError) Error() string {
+	if e.Resource != "" {
+		if e.StatusCode > 0 {
+			return fmt.Sprintf("%s: %d %s", e.Resource, e.StatusCode, e.Message)
+		}
+		return fmt.Sprintf("%s: %s", e.Resource, e.Message)
+	}
 	if e.StatusCode > 0 {
 		return fmt.Sprintf("API error: %s %s returned %d: %s", e.Method, e.Endpoint, e.StatusCode, e.Message)
 	}
@@ -78,6 +92,51 @@ func NewAPIError(method, endpoint string, statusCode int, message string, err er
 	}
 }
 
+// TimeoutError represents a gh command that didn't finish within the per-call timeout
+type TimeoutError struct {
+	Endpoint string
+	Timeout  time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s", e.Endpoint, e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return ErrTimeout
+}
+
+// NewTimeoutError creates a new TimeoutError
+func NewTimeoutError(endpoint string, timeout time.Duration) *TimeoutError {
+	return &TimeoutError{
+		Endpoint: endpoint,
+		Timeout:  timeout,
+	}
+}
+
+// RepoMovedError represents a repository that GitHub resolves to a different owner or name,
+// because it was renamed or transferred
+type RepoMovedError struct {
+	Requested string // owner/name the command was pointed at
+	Actual    string // full name GitHub returned
+}
+
+func (e *RepoMovedError) Error() string {
+	return fmt.Sprintf("%s resolves to %s; it was renamed or transferred", e.Requested, e.Actual)
+}
+
+func (e *RepoMovedError) Unwrap() error {
+	return ErrRepoMoved
+}
+
+// NewRepoMovedError creates a new RepoMovedError
+func NewRepoMovedError(requested, actual string) *RepoMovedError {
+	return &RepoMovedError{
+		Requested: requested,
+		Actual:    actual,
+	}
+}
+
 // ValidationError represents a validation error
 type ValidationError struct {
 	Field   string
@@ -96,6 +155,19 @@ func NewValidationError(field, message string) *ValidationError {
 	}
 }
 
+// IsPermissionDenied reports whether err means the token may not read or write a resource:
+// ErrPermissionDenied, or an API error with status 403 that isn't a rate limit
+func IsPermissionDenied(err error) bool {
+	if errors.Is(err, ErrPermissionDenied) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == 403 {
+		return !strings.Contains(strings.ToLower(apiErr.Message), "rate limit")
+	}
+	return false
+}
+
 // Is checks if err matches target using errors.Is
 func Is(err, target error) bool {
 	return errors.Is(err, target)
@@ -2,7 +2,9 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 )
 
 func TestSentinelErrors(t *testing.T) {
@@ -109,6 +111,34 @@ func TestAPIError(t *testing.T) {
 			t.Errorf("got %q, want %q", err.Error(), want)
 		}
 	})
+
+	t.Run("with resource", func(t *testing.T) {
+		err := &APIError{
+			Endpoint:   "/repos/owner/repo/labels",
+			Method:     "POST",
+			StatusCode: 422,
+			Message:    "Validation Failed",
+			Resource:   "label 'bug'",
+		}
+
+		want := "label 'bug': 422 Validation Failed"
+		if err.Error() != want {
+			t.Errorf("got %q, want %q", err.Error(), want)
+		}
+	})
+
+	t.Run("with resource without status code", func(t *testing.T) {
+		err := &APIError{
+			Method:   "PUT",
+			Message:  "connection refused",
+			Resource: "topics",
+		}
+
+		want := "topics: connection refused"
+		if err.Error() != want {
+			t.Errorf("got %q, want %q", err.Error(), want)
+		}
+	})
 }
 
 func TestNewAPIError(t *testing.T) {
@@ -132,6 +162,30 @@ func TestNewAPIError(t *testing.T) {
 	}
 }
 
+func TestTimeoutError(t *testing.T) {
+	err := NewTimeoutError("repos/owner/repo", 30*time.Second)
+
+	want := "repos/owner/repo timed out after 30s"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+	if !errors.Is(fmt.Errorf("wrapped: %w", err), ErrTimeout) {
+		t.Error("expected TimeoutError to match ErrTimeout")
+	}
+}
+
+func TestRepoMovedError(t *testing.T) {
+	err := NewRepoMovedError("old-owner/repo", "new-owner/repo")
+
+	want := "old-owner/repo resolves to new-owner/repo; it was renamed or transferred"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+	if !errors.Is(fmt.Errorf("wrapped: %w", err), ErrRepoMoved) {
+		t.Error("expected RepoMovedError to match ErrRepoMoved")
+	}
+}
+
 func TestValidationError(t *testing.T) {
 	err := &ValidationError{
 		Field:   "repo.visibility",
@@ -155,6 +209,30 @@ func TestNewValidationError(t *testing.T) {
 	}
 }
 
+func TestIsPermissionDenied(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"sentinel", ErrPermissionDenied, true},
+		{"wrapped sentinel", fmt.Errorf("failed to compare env: %w", ErrPermissionDenied), true},
+		{"403", NewAPIError("GET", "repos/o/r/actions/permissions", 403, "Resource not accessible by integration", nil), true},
+		{"403 rate limit", NewAPIError("GET", "repos/o/r", 403, "API rate limit exceeded for user", nil), false},
+		{"404", NewAPIError("GET", "repos/o/r/pages", 404, "Not Found", nil), false},
+		{"other", ErrNetworkError, false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPermissionDenied(tt.err); got != tt.want {
+				t.Errorf("IsPermissionDenied() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestIs(t *testing.T) {
 	err := &ConfigError{
 		File:    "test.yaml",
@@ -16,15 +16,20 @@ func (c *Client) GetActionsPermissions(ctx context.Context) (*ActionsPermissions
 
 // UpdateActionsPermissions updates Actions permissions for the repository
 func (c *Client) UpdateActionsPermissions(ctx context.Context, enabled bool, allowedActions string) error {
+	_, err := c.callJSON(ctx, httpPut, c.repoPath("actions/permissions"), actionsPermissionsPayload(enabled, allowedActions))
+	return withResource(err, "actions permissions")
+}
+
+// actionsPermissionsPayload builds the request body for updating Actions permissions.
+// allowed_actions is only sent while Actions are enabled.
+func actionsPermissionsPayload(enabled bool, allowedActions string) map[string]interface{} {
 	payload := map[string]interface{}{
 		"enabled": enabled,
 	}
 	if enabled && allowedActions != "" {
 		payload["allowed_actions"] = allowedActions
 	}
-
-	_, err := c.callJSON(ctx, httpPut, c.repoPath("actions/permissions"), payload)
-	return err
+	return payload
 }
 
 // GetActionsSelectedActions fetches selected actions configuration
@@ -39,7 +44,7 @@ func (c *Client) GetActionsSelectedActions(ctx context.Context) (*ActionsSelecte
 // UpdateActionsSelectedActions updates selected actions configuration
 func (c *Client) UpdateActionsSelectedActions(ctx context.Context, settings *ActionsSelectedData) error {
 	_, err := c.callJSON(ctx, httpPut, c.repoPath("actions/permissions/selected-actions"), settings)
-	return err
+	return withResource(err, "selected actions")
 }
 
 // GetActionsWorkflowPermissions fetches workflow permissions
@@ -53,10 +58,52 @@ func (c *Client) GetActionsWorkflowPermissions(ctx context.Context) (*ActionsWor
 
 // UpdateActionsWorkflowPermissions updates workflow permissions
 func (c *Client) UpdateActionsWorkflowPermissions(ctx context.Context, permissions string, canApprove bool) error {
-	payload := map[string]interface{}{
+	_, err := c.callJSON(ctx, httpPut, c.repoPath("actions/permissions/workflow"), workflowPermissionsPayload(permissions, canApprove))
+	return withResource(err, "workflow permissions")
+}
+
+// workflowPermissionsPayload builds the request body for updating workflow permissions
+func workflowPermissionsPayload(permissions string, canApprove bool) map[string]interface{} {
+	return map[string]interface{}{
 		"default_workflow_permissions":     permissions,
 		"can_approve_pull_request_reviews": canApprove,
 	}
-	_, err := c.callJSON(ctx, httpPut, c.repoPath("actions/permissions/workflow"), payload)
-	return err
+}
+
+// GetActionsAccessLevel fetches which repositories outside this private repository may use its actions
+func (c *Client) GetActionsAccessLevel(ctx context.Context) (*ActionsAccessData, error) {
+	var data ActionsAccessData
+	if err := c.getJSON(ctx, c.repoPath("actions/permissions/access"), &data); err != nil {
+		return nil, fmt.Errorf("failed to get actions access level: %w", err)
+	}
+	return &data, nil
+}
+
+// UpdateActionsAccessLevel sets the actions access level (none, organization or enterprise)
+func (c *Client) UpdateActionsAccessLevel(ctx context.Context, level string) error {
+	payload := ActionsAccessData{AccessLevel: level}
+	_, err := c.callJSON(ctx, httpPut, c.repoPath("actions/permissions/access"), payload)
+	return withResource(err, "actions access level")
+}
+
+// GetActionsRetention fetches the artifact and log retention period
+func (c *Client) GetActionsRetention(ctx context.Context) (*ActionsRetentionData, error) {
+	var data ActionsRetentionData
+	if err := c.getJSON(ctx, c.repoPath("actions/permissions/artifact-and-log-retention"), &data); err != nil {
+		return nil, fmt.Errorf("failed to get artifact and log retention: %w", err)
+	}
+	return &data, nil
+}
+
+// UpdateActionsRetention sets the artifact and log retention period in days
+func (c *Client) UpdateActionsRetention(ctx context.Context, days int) error {
+	_, err := c.callJSON(ctx, httpPut, c.repoPath("actions/permissions/artifact-and-log-retention"), retentionPayload(days))
+	return withResource(err, "artifact and log retention")
+}
+
+// retentionPayload builds the request body for setting the retention period
+func retentionPayload(days int) map[string]interface{} {
+	return map[string]interface{}{
+		"days": days,
+	}
 }
@@ -2,7 +2,9 @@ package github
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/myzkey/gh-repo-settings/internal/config"
 	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 )
 
@@ -20,9 +22,36 @@ func (c *Client) GetBranchProtection(ctx context.Context, branch string) (*Branc
 	return &data, nil
 }
 
+// BranchExists reports whether a branch exists in the repository
+func (c *Client) BranchExists(ctx context.Context, branch string) (bool, error) {
+	if _, err := c.callAPI(ctx, httpGet, c.branchPath(branch, ""), nil); err != nil {
+		var apiErr *apperrors.APIError
+		if apperrors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check branch %s: %w", branch, err)
+	}
+	return true, nil
+}
+
+// ListBranches lists every branch with whether it is protected
+func (c *Client) ListBranches(ctx context.Context) ([]BranchData, error) {
+	var branches []BranchData
+	if err := c.getJSON(ctx, c.repoPath("branches?per_page=100"), &branches, "--paginate"); err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return branches, nil
+}
+
 // UpdateBranchProtection updates branch protection rules
 func (c *Client) UpdateBranchProtection(ctx context.Context, branch string, settings *BranchProtectionSettings) error {
-	// Build the protection payload
+	_, err := c.callJSON(ctx, httpPut, c.branchPath(branch, "protection"), branchProtectionPayload(settings))
+	return withResource(err, fmt.Sprintf("branch protection for '%s'", branch))
+}
+
+// branchProtectionPayload builds the request body for updating branch protection.
+// The whole rule is sent: unset switches are turned off and unset sections removed.
+func branchProtectionPayload(settings *BranchProtectionSettings) map[string]interface{} {
 	payload := map[string]interface{}{
 		"enforce_admins":          settings.EnforceAdmins != nil && *settings.EnforceAdmins,
 		"required_linear_history": settings.RequireLinearHistory != nil && *settings.RequireLinearHistory,
@@ -31,8 +60,12 @@ func (c *Client) UpdateBranchProtection(ctx context.Context, branch string, sett
 		"restrictions":            nil,
 	}
 
-	// Required pull request reviews
-	if settings.RequiredReviews != nil || settings.DismissStaleReviews != nil || settings.RequireCodeOwnerReviews != nil {
+	// Required pull request reviews, required when a review setting is set unless require_reviews says otherwise
+	requireReviews := settings.RequiredReviews != nil || settings.DismissStaleReviews != nil || settings.RequireCodeOwnerReviews != nil
+	if settings.RequireReviews != nil {
+		requireReviews = *settings.RequireReviews
+	}
+	if requireReviews {
 		reviews := map[string]interface{}{}
 		if settings.RequiredReviews != nil {
 			reviews["required_approving_review_count"] = *settings.RequiredReviews
@@ -53,8 +86,11 @@ func (c *Client) UpdateBranchProtection(ctx context.Context, branch string, sett
 		checks := map[string]interface{}{
 			"strict": settings.StrictStatusChecks != nil && *settings.StrictStatusChecks,
 		}
-		if len(settings.StatusChecks) > 0 {
-			checks["contexts"] = settings.StatusChecks
+		// Checks tied to an app need the checks form; GitHub rejects sending both
+		if hasStatusCheckApps(settings.StatusChecks) {
+			checks["checks"] = statusChecksPayload(settings.StatusChecks)
+		} else if len(settings.StatusChecks) > 0 {
+			checks["contexts"] = config.StatusCheckContexts(settings.StatusChecks)
 		} else {
 			checks["contexts"] = []string{}
 		}
@@ -63,6 +99,27 @@ func (c *Client) UpdateBranchProtection(ctx context.Context, branch string, sett
 		payload["required_status_checks"] = nil
 	}
 
-	_, err := c.callJSON(ctx, httpPut, c.branchPath(branch, "protection"), payload)
-	return err
+	return payload
+}
+
+// hasStatusCheckApps reports whether any check is tied to an app
+func hasStatusCheckApps(checks []config.StatusCheck) bool {
+	for _, c := range checks {
+		if c.AppID != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// statusChecksPayload builds the checks array; a check without an app is accepted from any app
+func statusChecksPayload(checks []config.StatusCheck) []map[string]interface{} {
+	payload := make([]map[string]interface{}, len(checks))
+	for i, c := range checks {
+		payload[i] = map[string]interface{}{"context": c.Context}
+		if c.AppID != nil {
+			payload[i]["app_id"] = *c.AppID
+		}
+	}
+	return payload
 }
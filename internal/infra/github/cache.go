@@ -0,0 +1,125 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResponseCache stores GET response bodies with their ETags on disk, keyed by endpoint
+// and by the host and account they were fetched as, so repeat requests can be sent with
+// If-None-Match and answered with 304 Not Modified.
+// Conditional requests that return 304 don't count against the API rate limit.
+type ResponseCache struct {
+	dir string
+}
+
+// NewResponseCache creates a response cache stored in dir.
+// The directory is created on the first write.
+func NewResponseCache(dir string) *ResponseCache {
+	return &ResponseCache{dir: dir}
+}
+
+// DefaultCacheDir returns the default response cache directory
+// under the user's cache directory (e.g. ~/.cache/gh-repo-settings)
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gh-repo-settings"), nil
+}
+
+// cachedResponse is a cache entry
+type cachedResponse struct {
+	ETag string `json:"etag"`
+	Body string `json:"body"`
+}
+
+// cacheKey identifies a request by the auth scope it is sent with (see Client.authScope),
+// its endpoint and the extra gh arguments (e.g. Accept headers)
+func cacheKey(scope, endpoint string, extraArgs []string) string {
+	sum := sha256.Sum256([]byte(scope + "\x00" + endpoint + "\x00" + strings.Join(extraArgs, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// authScope identifies the host and account gh sends requests as: GH_HOST and a hash
+// of the token gh uses. The cache is shared by every run, so an entry fetched as one
+// account or from one host (e.g. GitHub Enterprise Server) must not answer for another.
+// It reports false when gh has no token to tell, and the request then goes uncached.
+func (c *Client) authScope(ctx context.Context) (string, bool) {
+	c.authScopeOnce.Do(func() {
+		out, err := c.run(ctx, nil, "auth", "token")
+		token := bytes.TrimSpace(out)
+		if err != nil || len(token) == 0 {
+			return
+		}
+		sum := sha256.Sum256(token)
+		c.cacheScope = os.Getenv("GH_HOST") + "\x00" + hex.EncodeToString(sum[:])
+	})
+	return c.cacheScope, c.cacheScope != ""
+}
+
+func (r *ResponseCache) path(key string) string {
+	return filepath.Join(r.dir, key+".json")
+}
+
+// load returns the cached response for key, if any.
+// Unreadable or corrupt entries are treated as missing.
+func (r *ResponseCache) load(key string) (*cachedResponse, bool) {
+	data, err := os.ReadFile(r.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil || entry.ETag == "" {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// store writes a cache entry, readable only by the current user
+func (r *ResponseCache) store(key string, entry *cachedResponse) error {
+	if err := os.MkdirAll(r.dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path(key), data, 0o600)
+}
+
+// splitIncludedResponse splits `gh api --include` output into its headers and body.
+// Output without a leading status line is returned unchanged as the body.
+func splitIncludedResponse(out []byte) (http.Header, []byte) {
+	if !bytes.HasPrefix(out, []byte("HTTP/")) {
+		return http.Header{}, out
+	}
+
+	buffered := bufio.NewReader(bytes.NewReader(out))
+	reader := textproto.NewReader(buffered)
+	if _, err := reader.ReadLine(); err != nil { // status line
+		return http.Header{}, out
+	}
+	mimeHeader, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return http.Header{}, out
+	}
+
+	// The body is whatever follows the blank line ending the headers
+	body, err := io.ReadAll(buffered)
+	if err != nil {
+		return http.Header{}, out
+	}
+	return http.Header(mimeHeader), body
+}
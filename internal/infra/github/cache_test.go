@@ -0,0 +1,214 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// httpRunner stands in for `gh api`, sending GET requests to an httptest server.
+// It reproduces gh's --include output and its non-zero exit for statuses above 299.
+// `gh auth token` prints "test-token".
+func httpRunner(t *testing.T, baseURL string) commandRunner {
+	return httpRunnerWithToken(t, baseURL, "test-token")
+}
+
+// httpRunnerWithToken is httpRunner authenticated with token
+func httpRunnerWithToken(t *testing.T, baseURL, token string) commandRunner {
+	return func(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+		if len(args) == 2 && args[0] == "auth" && args[1] == "token" {
+			return []byte(token + "\n"), nil
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/"+args[1], nil)
+		if err != nil {
+			return nil, err
+		}
+		include := false
+		for i := 2; i < len(args); i++ {
+			switch args[i] {
+			case "--include":
+				include = true
+			case "-H":
+				name, value, _ := strings.Cut(args[i+1], ": ")
+				req.Header.Set(name, value)
+				i++
+			}
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+
+		var out strings.Builder
+		if include {
+			fmt.Fprintf(&out, "%s %s\r\n", resp.Proto, resp.Status)
+			for name, values := range resp.Header {
+				fmt.Fprintf(&out, "%s: %s\r\n", name, strings.Join(values, ", "))
+			}
+			out.WriteString("\r\n")
+		}
+		out.Write(body)
+
+		if resp.StatusCode > 299 {
+			return []byte(out.String()), &exec.ExitError{Stderr: []byte(fmt.Sprintf("gh: HTTP %d\n", resp.StatusCode))}
+		}
+		return []byte(out.String()), nil
+	}
+}
+
+// etagServer serves a fixed repository and answers 304 when If-None-Match matches its ETag
+type etagServer struct {
+	etag        string
+	body        string
+	requests    int
+	notModified int
+}
+
+func (s *etagServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests++
+	if r.Header.Get("If-None-Match") == s.etag {
+		s.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = io.WriteString(w, s.body)
+}
+
+func TestGetRepoConditionalFetch(t *testing.T) {
+	backend := &etagServer{
+		etag: `"v1"`,
+		body: `{"name":"repo","visibility":"public","allow_squash_merge":true,"topics":["go","cli"]}`,
+	}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	newClient := func() *Client {
+		client := &Client{Repo: RepoInfo{Owner: "owner", Name: "repo"}, runner: httpRunner(t, server.URL)}
+		client.SetResponseCache(NewResponseCache(cacheDir))
+		return client
+	}
+
+	first, err := newClient().GetRepo(context.Background())
+	if err != nil {
+		t.Fatalf("first GetRepo() error = %v", err)
+	}
+
+	// A fresh client sharing the cache directory, as in the next CI run
+	cached, err := newClient().GetRepo(context.Background())
+	if err != nil {
+		t.Fatalf("second GetRepo() error = %v", err)
+	}
+
+	if backend.notModified != 1 {
+		t.Fatalf("expected 1 Not Modified response, got %d of %d requests", backend.notModified, backend.requests)
+	}
+	if cached.Name != "repo" || cached.Visibility == nil || *cached.Visibility != "public" {
+		t.Errorf("cached repo not fully populated: %+v", cached)
+	}
+	if cached.AllowSquashMerge == nil || !*cached.AllowSquashMerge {
+		t.Error("expected allow_squash_merge from cached body")
+	}
+	if cached.Topics == nil || strings.Join(*cached.Topics, ",") != strings.Join(*first.Topics, ",") {
+		t.Errorf("cached topics = %v, want %v", cached.Topics, first.Topics)
+	}
+}
+
+func TestGetRefetchesWhenETagChanges(t *testing.T) {
+	backend := &etagServer{etag: `"v1"`, body: `{"name":"repo","visibility":"public"}`}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	client := &Client{Repo: RepoInfo{Owner: "owner", Name: "repo"}, runner: httpRunner(t, server.URL)}
+	client.SetResponseCache(NewResponseCache(t.TempDir()))
+
+	if _, err := client.GetRepo(context.Background()); err != nil {
+		t.Fatalf("GetRepo() error = %v", err)
+	}
+
+	backend.etag = `"v2"`
+	backend.body = `{"name":"repo","visibility":"private"}`
+
+	repo, err := client.GetRepo(context.Background())
+	if err != nil {
+		t.Fatalf("GetRepo() error = %v", err)
+	}
+	if backend.notModified != 0 {
+		t.Errorf("expected no Not Modified responses, got %d", backend.notModified)
+	}
+	if repo.Visibility == nil || *repo.Visibility != "private" {
+		t.Errorf("expected refreshed visibility 'private', got %v", repo.Visibility)
+	}
+}
+
+func TestCacheKeepsAccountsApart(t *testing.T) {
+	backend := &etagServer{etag: `"v1"`, body: `{"name":"repo","visibility":"private"}`}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	newClient := func(token string) *Client {
+		client := &Client{Repo: RepoInfo{Owner: "owner", Name: "repo"}, runner: httpRunnerWithToken(t, server.URL, token)}
+		client.SetResponseCache(NewResponseCache(cacheDir))
+		return client
+	}
+
+	if _, err := newClient("token-a").GetRepo(context.Background()); err != nil {
+		t.Fatalf("GetRepo() as a error = %v", err)
+	}
+	if _, err := newClient("token-b").GetRepo(context.Background()); err != nil {
+		t.Fatalf("GetRepo() as b error = %v", err)
+	}
+	if backend.notModified != 0 {
+		t.Errorf("another account must not reuse cached responses, got %d Not Modified", backend.notModified)
+	}
+
+	t.Setenv("GH_HOST", "ghe.example.com")
+	if _, err := newClient("token-a").GetRepo(context.Background()); err != nil {
+		t.Fatalf("GetRepo() on another host error = %v", err)
+	}
+	if backend.notModified != 0 {
+		t.Errorf("another host must not reuse cached responses, got %d Not Modified", backend.notModified)
+	}
+}
+
+func TestGetWithoutCache(t *testing.T) {
+	runner := newRecordingRunner()
+	runner.Responses["repos/owner/repo"] = `{"name":"repo"}`
+	client := &Client{Repo: RepoInfo{Owner: "owner", Name: "repo"}, runner: runner.run}
+
+	if _, err := client.GetRepo(context.Background()); err != nil {
+		t.Fatalf("GetRepo() error = %v", err)
+	}
+	for _, arg := range runner.Calls[0].Args {
+		if arg == "--include" || strings.HasPrefix(arg, "If-None-Match") {
+			t.Errorf("unexpected conditional request argument %q without a cache", arg)
+		}
+	}
+}
+
+func TestSplitIncludedResponse(t *testing.T) {
+	out := []byte("HTTP/2.0 200 OK\r\nEtag: \"abc\"\r\nContent-Type: application/json\r\n\r\n{\"a\":1}")
+	header, body := splitIncludedResponse(out)
+	if got := header.Get("ETag"); got != `"abc"` {
+		t.Errorf("ETag = %q, want %q", got, `"abc"`)
+	}
+	if string(body) != `{"a":1}` {
+		t.Errorf("body = %q", body)
+	}
+
+	plain := []byte(`{"a":1}`)
+	if _, body := splitIncludedResponse(plain); string(body) != string(plain) {
+		t.Errorf("plain body = %q", body)
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 )
@@ -17,6 +20,9 @@ import (
 // httpStatusRegex matches "HTTP XXX" in gh api stderr output
 var httpStatusRegex = regexp.MustCompile(`HTTP (\d{3})`)
 
+// httpStatusSuffixRegex matches the trailing "(HTTP XXX)" annotation in gh api stderr output
+var httpStatusSuffixRegex = regexp.MustCompile(`\s*\(HTTP \d{3}\)`)
+
 // httpMethod represents an HTTP method for API calls
 type httpMethod string
 
@@ -34,9 +40,40 @@ type RepoInfo struct {
 	Name  string
 }
 
+// commandRunner executes a gh CLI command with optional stdin and returns its stdout.
+// Failures should be returned as *exec.ExitError so stderr can be inspected.
+type commandRunner func(ctx context.Context, stdin []byte, args ...string) ([]byte, error)
+
+// execRunner runs gh as a subprocess
+func execRunner(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "gh", args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	return cmd.Output()
+}
+
 // Client wraps gh CLI commands
 type Client struct {
 	Repo RepoInfo
+
+	// runner executes gh commands; nil means execRunner.
+	// Tests replace it to record or stub API calls.
+	runner commandRunner
+
+	// cache makes GET requests conditional on a previously seen ETag; nil disables it
+	cache *ResponseCache
+
+	// cacheScope keeps cache entries of different hosts and accounts apart.
+	// It is looked up once, on the first cached request; see authScope.
+	authScopeOnce sync.Once
+	cacheScope    string
+
+	// timeout limits each gh command; zero means no limit
+	timeout time.Duration
+
+	// mutations keeps concurrent callers from changing the same resource at the same time
+	mutations keyedMutex
 }
 
 // NewClient creates a new GitHub client
@@ -70,10 +107,15 @@ func parseRepoArg(arg string) (RepoInfo, error) {
 	return RepoInfo{Owner: parts[0], Name: parts[1]}, nil
 }
 
+// getCurrentRepo determines the repository of the current directory with gh repo view,
+// falling back to the origin git remote when gh can't (e.g. outside an authenticated context)
 func getCurrentRepo(ctx context.Context) (RepoInfo, error) {
 	cmd := exec.CommandContext(ctx, "gh", "repo", "view", "--json", "owner,name")
 	out, err := cmd.Output()
 	if err != nil {
+		if repo, remoteErr := repoFromGitRemote(ctx); remoteErr == nil {
+			return repo, nil
+		}
 		return RepoInfo{}, apperrors.NewAPIError("GET", "repo/view", 0, "could not determine repository. Use --repo flag or run from a git repository", err)
 	}
 
@@ -91,6 +133,18 @@ func getCurrentRepo(ctx context.Context) (RepoInfo, error) {
 	return RepoInfo{Owner: result.Owner.Login, Name: result.Name}, nil
 }
 
+// SetResponseCache enables ETag-based conditional fetching through cache.
+// A nil cache disables it.
+func (c *Client) SetResponseCache(cache *ResponseCache) {
+	c.cache = cache
+}
+
+// SetTimeout limits how long each gh command may run.
+// Zero disables the limit; cancelling the caller's context still stops a command.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
 // RepoOwner returns the repository owner
 func (c *Client) RepoOwner() string {
 	return c.Repo.Owner
@@ -101,24 +155,71 @@ func (c *Client) RepoName() string {
 	return c.Repo.Name
 }
 
+// run executes a gh command through the configured runner.
+// A command that exceeds the client's timeout fails with a *apperrors.TimeoutError.
+func (c *Client) run(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	runner := c.runner
+	if runner == nil {
+		runner = execRunner
+	}
+	if c.timeout <= 0 {
+		return runner(ctx, stdin, args...)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	out, err := runner(callCtx, stdin, args...)
+	// Only our own deadline is a timeout; the caller's cancellation (e.g. Ctrl+C) is returned as is
+	if err != nil && ctx.Err() == nil && callCtx.Err() == context.DeadlineExceeded {
+		return nil, apperrors.NewTimeoutError(commandEndpoint(args), c.timeout)
+	}
+	return out, err
+}
+
+// commandEndpoint describes a gh command for error messages: the endpoint of
+// "gh api", or the leading words of other commands (never their flag values)
+func commandEndpoint(args []string) string {
+	if len(args) >= 2 && args[0] == "api" {
+		return args[1]
+	}
+	var words []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		words = append(words, arg)
+	}
+	return "gh " + strings.Join(words, " ")
+}
+
 // repoPath builds an API endpoint path for the current repository.
 // Example: repoPath("labels") returns "repos/{owner}/{name}/labels"
 func (c *Client) repoPath(path string) string {
+	return repoEndpoint(c.Repo.Owner, c.Repo.Name, path)
+}
+
+// repoEndpoint builds the API endpoint path of a repository, or of path within it
+func repoEndpoint(owner, name, path string) string {
 	if path == "" {
-		return fmt.Sprintf("repos/%s/%s", c.Repo.Owner, c.Repo.Name)
+		return fmt.Sprintf("repos/%s/%s", owner, name)
 	}
-	return fmt.Sprintf("repos/%s/%s/%s", c.Repo.Owner, c.Repo.Name, path)
+	return fmt.Sprintf("repos/%s/%s/%s", owner, name, path)
 }
 
 // branchPath builds an API endpoint path for branch-related operations.
 // It URL-encodes the branch name to handle branches with slashes (e.g., "feature/foo").
 // Example: branchPath("main", "protection") returns "repos/{owner}/{name}/branches/main/protection"
 func (c *Client) branchPath(branch, suffix string) string {
+	return c.repoPath(branchSubpath(branch, suffix))
+}
+
+// branchSubpath builds the path of a branch, or of suffix under it, within a repository
+func branchSubpath(branch, suffix string) string {
 	encodedBranch := url.PathEscape(branch)
 	if suffix == "" {
-		return c.repoPath(fmt.Sprintf("branches/%s", encodedBranch))
+		return fmt.Sprintf("branches/%s", encodedBranch)
 	}
-	return c.repoPath(fmt.Sprintf("branches/%s/%s", encodedBranch, suffix))
+	return fmt.Sprintf("branches/%s/%s", encodedBranch, suffix)
 }
 
 // labelPath builds an API endpoint path for label operations.
@@ -154,6 +255,34 @@ func parseHTTPStatus(stderr string) int {
 	return 0
 }
 
+// apiErrorMessage extracts a concise message from gh api stderr output.
+// Example: "gh: Validation Failed (HTTP 422)" returns "Validation Failed"
+func apiErrorMessage(stderr string) string {
+	var lines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "gh: ")
+		line = strings.TrimSpace(httpStatusSuffixRegex.ReplaceAllString(line, ""))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "; ")
+}
+
+// withResource annotates an API error with the resource being modified,
+// so callers can report e.g. "label 'bug': 422 Validation Failed".
+// Errors that are not API errors are returned unchanged.
+func withResource(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *apperrors.APIError
+	if apperrors.As(err, &apiErr) {
+		apiErr.Resource = resource
+	}
+	return err
+}
+
 // callAPI is the low-level function for executing gh api commands.
 // It handles GET requests (body must be nil) and other methods with optional body data.
 func (c *Client) callAPI(ctx context.Context, method httpMethod, endpoint string, body []byte, extraArgs ...string) ([]byte, error) {
@@ -167,21 +296,25 @@ func (c *Client) callAPI(ctx context.Context, method httpMethod, endpoint string
 	}
 	cmdArgs = append(cmdArgs, extraArgs...)
 
-	var cmd *exec.Cmd
 	if body != nil {
 		cmdArgs = append(cmdArgs, "--input", "-")
-		cmd = exec.CommandContext(ctx, "gh", cmdArgs...)
-		cmd.Stdin = bytes.NewReader(body)
-	} else {
-		cmd = exec.CommandContext(ctx, "gh", cmdArgs...)
 	}
 
-	out, err := cmd.Output()
+	if method != httpGet {
+		unlock := c.mutations.lock(mutationKey(endpoint))
+		defer unlock()
+	}
+
+	out, err := c.run(ctx, body, cmdArgs...)
 	if err != nil {
+		var timeoutErr *apperrors.TimeoutError
+		if apperrors.As(err, &timeoutErr) {
+			return nil, err
+		}
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			stderr := string(exitErr.Stderr)
 			statusCode := parseHTTPStatus(stderr)
-			return nil, apperrors.NewAPIError(string(method), endpoint, statusCode, stderr, err)
+			return nil, apperrors.NewAPIError(string(method), endpoint, statusCode, apiErrorMessage(stderr), err)
 		}
 		return nil, apperrors.NewAPIError(string(method), endpoint, 0, err.Error(), err)
 	}
@@ -196,13 +329,62 @@ func jsonHeaders() []string {
 // getJSON performs a GET request to the given endpoint and unmarshals the JSON response into result.
 // This function is GET-only; use callJSON for POST/PUT/PATCH/DELETE requests.
 func (c *Client) getJSON(ctx context.Context, endpoint string, result interface{}, extraArgs ...string) error {
-	out, err := c.callAPI(ctx, httpGet, endpoint, nil, extraArgs...)
+	out, err := c.get(ctx, endpoint, extraArgs...)
 	if err != nil {
 		return err
 	}
 	return json.Unmarshal(out, result)
 }
 
+// get performs a GET request and returns the response body.
+// With a response cache, the request carries If-None-Match for a cached ETag
+// and a 304 Not Modified response returns the cached body.
+// Paginated requests span several responses and are never cached.
+func (c *Client) get(ctx context.Context, endpoint string, extraArgs ...string) ([]byte, error) {
+	if c.cache == nil || containsArg(extraArgs, "--paginate") {
+		return c.callAPI(ctx, httpGet, endpoint, nil, extraArgs...)
+	}
+	scope, ok := c.authScope(ctx)
+	if !ok {
+		return c.callAPI(ctx, httpGet, endpoint, nil, extraArgs...)
+	}
+
+	key := cacheKey(scope, endpoint, extraArgs)
+	cached, hasCached := c.cache.load(key)
+
+	args := append(append([]string{}, extraArgs...), "--include")
+	if hasCached {
+		args = append(args, "-H", "If-None-Match: "+cached.ETag)
+	}
+
+	out, err := c.callAPI(ctx, httpGet, endpoint, nil, args...)
+	if err != nil {
+		// gh api exits non-zero for any status above 299, including 304
+		var apiErr *apperrors.APIError
+		if hasCached && apperrors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotModified {
+			return []byte(cached.Body), nil
+		}
+		return nil, err
+	}
+
+	header, body := splitIncludedResponse(out)
+	if etag := header.Get("ETag"); etag != "" {
+		// A cache write failure only costs the next request its 304
+		_ = c.cache.store(key, &cachedResponse{ETag: etag, Body: string(body)})
+	}
+	return body, nil
+}
+
+// containsArg reports whether args contains arg
+func containsArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
+
 // callJSON sends a JSON request body to an endpoint.
 // It marshals the body, adds JSON headers, and returns the response.
 func (c *Client) callJSON(ctx context.Context, method httpMethod, endpoint string, body interface{}) ([]byte, error) {
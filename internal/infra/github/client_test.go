@@ -1,8 +1,11 @@
 package github
 
 import (
+	"context"
 	"testing"
 
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 	"github.com/myzkey/gh-repo-settings/internal/infra/githubopenapi"
 	"github.com/oapi-codegen/nullable"
 )
@@ -80,6 +83,50 @@ func TestClientRepoOwnerAndName(t *testing.T) {
 	}
 }
 
+func TestCheckRepoLocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		fullName  string
+		wantMoved bool
+	}{
+		{name: "same repository", fullName: "test-owner/test-repo"},
+		{name: "different case", fullName: "Test-Owner/Test-Repo"},
+		{name: "full name not returned", fullName: ""},
+		{name: "renamed", fullName: "test-owner/new-name", wantMoved: true},
+		{name: "transferred", fullName: "new-owner/test-repo", wantMoved: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := NewMockClient()
+			mock.RepoData = &RepoData{FullName: tt.fullName}
+
+			err := CheckRepoLocation(context.Background(), mock)
+			if !tt.wantMoved {
+				if err != nil {
+					t.Errorf("CheckRepoLocation() unexpected error: %v", err)
+				}
+				return
+			}
+			var moved *apperrors.RepoMovedError
+			if !apperrors.As(err, &moved) {
+				t.Fatalf("CheckRepoLocation() = %v, want RepoMovedError", err)
+			}
+			if moved.Requested != "test-owner/test-repo" || moved.Actual != tt.fullName {
+				t.Errorf("RepoMovedError = %+v", moved)
+			}
+		})
+	}
+
+	t.Run("read error", func(t *testing.T) {
+		mock := NewMockClient()
+		mock.GetRepoError = apperrors.ErrRepoNotFound
+		if err := CheckRepoLocation(context.Background(), mock); !apperrors.Is(err, apperrors.ErrRepoNotFound) {
+			t.Errorf("CheckRepoLocation() = %v, want ErrRepoNotFound", err)
+		}
+	})
+}
+
 func TestMockClientImplementsInterface(t *testing.T) {
 	// This test verifies that MockClient implements GitHubClient
 	var _ GitHubClient = (*MockClient)(nil)
@@ -174,7 +221,7 @@ func TestBranchProtectionSettings(t *testing.T) {
 		RequiredReviews:    &reviews,
 		StrictStatusChecks: &strict,
 		EnforceAdmins:      &enforceAdmins,
-		StatusChecks:       []string{"ci/build", "ci/test"},
+		StatusChecks:       []config.StatusCheck{{Context: "ci/build"}, {Context: "ci/test"}},
 	}
 
 	if *settings.RequiredReviews != 2 {
@@ -203,7 +250,7 @@ func TestBranchProtectionSettingsAllFields(t *testing.T) {
 		DismissStaleReviews:     &dismiss,
 		RequireCodeOwnerReviews: &codeOwner,
 		RequireStatusChecks:     &statusChecks,
-		StatusChecks:            []string{"test", "lint"},
+		StatusChecks:            []config.StatusCheck{{Context: "test"}, {Context: "lint"}},
 		StrictStatusChecks:      &strict,
 		RequireLinearHistory:    &linear,
 		AllowForcePushes:        &forcePush,
@@ -0,0 +1,86 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+)
+
+// GetFile fetches file metadata from the default branch via the contents API
+func (c *Client) GetFile(ctx context.Context, path string) (*FileData, error) {
+	var data FileData
+	err := c.getJSON(ctx, c.repoPath(contentsPath(path)), &data)
+	if err != nil {
+		var apiErr *apperrors.APIError
+		if apperrors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+			return nil, apperrors.ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get file %s: %w", path, err)
+	}
+	return &data, nil
+}
+
+// ContentsFetcher returns a function that GETs an API endpoint with gh api, through the
+// same runner and per-command timeout as a Client (zero means no limit). config.Load reads
+// gh:// sources in other repositories with it. A 404 is reported as ErrFileNotFound.
+func ContentsFetcher(timeout time.Duration) func(ctx context.Context, endpoint string) ([]byte, error) {
+	return (&Client{timeout: timeout}).fetchContents
+}
+
+// fetchContents GETs endpoint; see ContentsFetcher
+func (c *Client) fetchContents(ctx context.Context, endpoint string) ([]byte, error) {
+	out, err := c.callAPI(ctx, httpGet, endpoint, nil)
+	if err != nil {
+		var apiErr *apperrors.APIError
+		if apperrors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+			return nil, apperrors.ErrFileNotFound
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// PutFile creates or updates a file on the default branch via the contents API.
+// Updating requires the current blob SHA, which is fetched first.
+func (c *Client) PutFile(ctx context.Context, path string, content []byte, message string) error {
+	var sha string
+	existing, err := c.GetFile(ctx, path)
+	switch {
+	case err == nil:
+		sha = existing.SHA
+	case !apperrors.Is(err, apperrors.ErrFileNotFound):
+		return err
+	}
+
+	_, err = c.callJSON(ctx, httpPut, c.repoPath(contentsPath(path)), putFilePayload(content, message, sha))
+	return withResource(err, fmt.Sprintf("file '%s'", path))
+}
+
+// putFilePayload builds the request body for writing a file.
+// sha is the blob being replaced, or empty when creating the file.
+func putFilePayload(content []byte, message, sha string) map[string]interface{} {
+	payload := map[string]interface{}{
+		"message": message,
+		"content": base64.StdEncoding.EncodeToString(content),
+	}
+	if sha != "" {
+		payload["sha"] = sha
+	}
+	return payload
+}
+
+// contentsPath builds an API endpoint path for the contents API.
+// Each path segment is URL-encoded while the separators are kept.
+// Example: contentsPath(".github/ISSUE_TEMPLATE/bug report.md") returns "contents/.github/ISSUE_TEMPLATE/bug%20report.md"
+func contentsPath(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return "contents/" + strings.Join(segments, "/")
+}
@@ -1,12 +1,18 @@
 package github
 
-import "context"
+import (
+	"context"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+)
 
 // Client interface defines all GitHub operations
 type GitHubClient interface {
 	// Repository operations
 	GetRepo(ctx context.Context) (*RepoData, error)
 	UpdateRepo(ctx context.Context, settings map[string]interface{}) error
+	CreateRepo(ctx context.Context, owner, name string, cfg *config.RepoConfig) error
+	SetSocialPreview(ctx context.Context, image []byte) error
 
 	// Topics operations
 	SetTopics(ctx context.Context, topics []string) error
@@ -14,12 +20,14 @@ type GitHubClient interface {
 	// Label operations
 	GetLabels(ctx context.Context) ([]LabelData, error)
 	CreateLabel(ctx context.Context, name, color, description string) error
-	UpdateLabel(ctx context.Context, oldName, newName, color, description string) error
+	UpdateLabel(ctx context.Context, oldName, newName, color string, description *string) error
 	DeleteLabel(ctx context.Context, name string) error
 
 	// Branch protection operations
 	GetBranchProtection(ctx context.Context, branch string) (*BranchProtectionData, error)
 	UpdateBranchProtection(ctx context.Context, branch string, settings *BranchProtectionSettings) error
+	BranchExists(ctx context.Context, branch string) (bool, error)
+	ListBranches(ctx context.Context) ([]BranchData, error)
 
 	// Secrets operations
 	GetSecrets(ctx context.Context) ([]string, error)
@@ -38,31 +46,57 @@ type GitHubClient interface {
 	UpdateActionsSelectedActions(ctx context.Context, settings *ActionsSelectedData) error
 	GetActionsWorkflowPermissions(ctx context.Context) (*ActionsWorkflowPermissionsData, error)
 	UpdateActionsWorkflowPermissions(ctx context.Context, permissions string, canApprove bool) error
+	GetActionsAccessLevel(ctx context.Context) (*ActionsAccessData, error)
+	UpdateActionsAccessLevel(ctx context.Context, level string) error
+	GetActionsRetention(ctx context.Context) (*ActionsRetentionData, error)
+	UpdateActionsRetention(ctx context.Context, days int) error
 
 	// Pages operations
 	GetPages(ctx context.Context) (*PagesData, error)
 	CreatePages(ctx context.Context, buildType string, source *PagesSourceData) error
-	UpdatePages(ctx context.Context, buildType string, source *PagesSourceData) error
+	UpdatePages(ctx context.Context, buildType string, source *PagesSourceData, cname *string, httpsEnforced *bool) error
+
+	// Contents operations
+	GetFile(ctx context.Context, path string) (*FileData, error)
+	PutFile(ctx context.Context, path string, content []byte, message string) error
 
 	// Repository info
 	RepoOwner() string
 	RepoName() string
 }
 
+// OrgGitHubClient defines GitHub operations on organization-level settings
+type OrgGitHubClient interface {
+	GetOrgActionsPermissions(ctx context.Context) (*OrgActionsPermissionsData, error)
+	UpdateOrgActionsPermissions(ctx context.Context, enabledRepositories, allowedActions string) error
+	GetOrgWorkflowPermissions(ctx context.Context) (*ActionsWorkflowPermissionsData, error)
+	UpdateOrgWorkflowPermissions(ctx context.Context, permissions string, canApprove bool) error
+
+	// Repositories
+	ListRepos(ctx context.Context) ([]OrgRepoData, error)
+
+	// Organization info
+	OrgName() string
+}
+
 // BranchProtectionSettings represents settings to update branch protection
 type BranchProtectionSettings struct {
-	RequiredReviews         *int     `json:"required_approving_review_count,omitempty"`
-	DismissStaleReviews     *bool    `json:"dismiss_stale_reviews,omitempty"`
-	RequireCodeOwnerReviews *bool    `json:"require_code_owner_reviews,omitempty"`
-	RequireStatusChecks     *bool    `json:"-"`
-	StatusChecks            []string `json:"contexts,omitempty"`
-	StrictStatusChecks      *bool    `json:"strict,omitempty"`
-	EnforceAdmins           *bool    `json:"enforce_admins,omitempty"`
-	RequireLinearHistory    *bool    `json:"required_linear_history,omitempty"`
-	AllowForcePushes        *bool    `json:"allow_force_pushes,omitempty"`
-	AllowDeletions          *bool    `json:"allow_deletions,omitempty"`
-	RequireSignedCommits    *bool    `json:"required_signatures,omitempty"`
+	RequireReviews          *bool                `json:"-"`
+	RequiredReviews         *int                 `json:"required_approving_review_count,omitempty"`
+	DismissStaleReviews     *bool                `json:"dismiss_stale_reviews,omitempty"`
+	RequireCodeOwnerReviews *bool                `json:"require_code_owner_reviews,omitempty"`
+	RequireStatusChecks     *bool                `json:"-"`
+	StatusChecks            []config.StatusCheck `json:"contexts,omitempty"`
+	StrictStatusChecks      *bool                `json:"strict,omitempty"`
+	EnforceAdmins           *bool                `json:"enforce_admins,omitempty"`
+	RequireLinearHistory    *bool                `json:"required_linear_history,omitempty"`
+	AllowForcePushes        *bool                `json:"allow_force_pushes,omitempty"`
+	AllowDeletions          *bool                `json:"allow_deletions,omitempty"`
+	RequireSignedCommits    *bool                `json:"required_signatures,omitempty"`
 }
 
 // Ensure Client implements GitHubClient
 var _ GitHubClient = (*Client)(nil)
+
+// Ensure OrgClient implements OrgGitHubClient
+var _ OrgGitHubClient = (*OrgClient)(nil)
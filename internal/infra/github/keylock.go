@@ -0,0 +1,58 @@
+package github
+
+import (
+	"strings"
+	"sync"
+)
+
+// keyedMutex serializes work per key while letting different keys proceed in parallel.
+// The zero value is ready to use.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock is the lock of one key; waiters counts the holder and everyone queued for it
+type keyLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// lock blocks until key is free, then holds it until the returned function is called
+func (k *keyedMutex) lock(key string) (unlock func()) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyLock)
+	}
+	l := k.locks[key]
+	if l == nil {
+		l = &keyLock{}
+		k.locks[key] = l
+	}
+	l.waiters++
+	k.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		k.mu.Lock()
+		// Forget the key once nobody holds or waits for it, so the map doesn't grow
+		if l.waiters--; l.waiters == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// mutationKey returns the resource a mutating request to endpoint changes.
+// Everything under a branch, e.g. its protection and required signatures, is one resource;
+// other endpoints are their own resource, ignoring the query string.
+func mutationKey(endpoint string) string {
+	endpoint, _, _ = strings.Cut(endpoint, "?")
+	parts := strings.Split(endpoint, "/")
+	// repos/{owner}/{name}/branches/{branch}/...
+	if len(parts) > 5 && parts[0] == "repos" && parts[3] == "branches" {
+		return strings.Join(parts[:5], "/")
+	}
+	return endpoint
+}
@@ -0,0 +1,126 @@
+package github
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// overlapRunner records, per resource, how many mutating calls were in flight at once.
+// Every call is slowed down so that unserialized calls would overlap.
+type overlapRunner struct {
+	delay time.Duration
+
+	mu          sync.Mutex
+	inFlight    map[string]int
+	maxInFlight map[string]int
+	total       int
+	maxTotal    int
+}
+
+func newOverlapRunner(delay time.Duration) *overlapRunner {
+	return &overlapRunner{
+		delay:       delay,
+		inFlight:    make(map[string]int),
+		maxInFlight: make(map[string]int),
+	}
+}
+
+func (r *overlapRunner) run(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	key := mutationKey(args[1])
+
+	r.mu.Lock()
+	r.inFlight[key]++
+	r.total++
+	if r.inFlight[key] > r.maxInFlight[key] {
+		r.maxInFlight[key] = r.inFlight[key]
+	}
+	if r.total > r.maxTotal {
+		r.maxTotal = r.total
+	}
+	r.mu.Unlock()
+
+	time.Sleep(r.delay)
+
+	r.mu.Lock()
+	r.inFlight[key]--
+	r.total--
+	r.mu.Unlock()
+	return []byte("{}"), nil
+}
+
+func TestConcurrentMutationsAreSerializedPerResource(t *testing.T) {
+	runner := newOverlapRunner(20 * time.Millisecond)
+	client := &Client{Repo: RepoInfo{Owner: "owner", Name: "repo"}, runner: runner.run}
+	ctx := context.Background()
+	reviews := 1
+
+	calls := []func() error{
+		func() error {
+			return client.UpdateBranchProtection(ctx, "main", &BranchProtectionSettings{RequiredReviews: &reviews})
+		},
+		func() error {
+			return client.UpdateBranchProtection(ctx, "main", &BranchProtectionSettings{RequiredReviews: &reviews})
+		},
+		func() error {
+			return client.UpdateBranchProtection(ctx, "develop", &BranchProtectionSettings{RequiredReviews: &reviews})
+		},
+		func() error { return client.UpdateRepo(ctx, map[string]interface{}{"description": "a"}) },
+		func() error { return client.UpdateRepo(ctx, map[string]interface{}{"archived": true}) },
+		func() error { return client.DeleteLabel(ctx, "bug") },
+		func() error { return client.DeleteLabel(ctx, "wontfix") },
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(calls))
+	for i, call := range calls {
+		wg.Add(1)
+		go func(i int, call func() error) {
+			defer wg.Done()
+			errs[i] = call()
+		}(i, call)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+	}
+	for key, max := range runner.maxInFlight {
+		if max > 1 {
+			t.Errorf("%d concurrent mutations of %s", max, key)
+		}
+	}
+	if runner.maxTotal < 2 {
+		t.Errorf("expected mutations of different resources to run in parallel, max in flight = %d", runner.maxTotal)
+	}
+}
+
+func TestMutationKey(t *testing.T) {
+	tests := map[string]string{
+		"repos/o/r":                          "repos/o/r",
+		"repos/o/r/topics":                   "repos/o/r/topics",
+		"repos/o/r/labels/bug":               "repos/o/r/labels/bug",
+		"repos/o/r/branches/main":            "repos/o/r/branches/main",
+		"repos/o/r/branches/main/protection": "repos/o/r/branches/main",
+		"repos/o/r/branches/main/protection/required_signatures": "repos/o/r/branches/main",
+		"repos/o/r/branches/feature%2Ffoo/protection":            "repos/o/r/branches/feature%2Ffoo",
+		"repos/o/r/labels?per_page=100":                          "repos/o/r/labels",
+	}
+	for endpoint, want := range tests {
+		if got := mutationKey(endpoint); got != want {
+			t.Errorf("mutationKey(%q) = %q, want %q", endpoint, got, want)
+		}
+	}
+}
+
+func TestKeyedMutexForgetsFreeKeys(t *testing.T) {
+	var k keyedMutex
+	unlock := k.lock("a")
+	unlock()
+	if len(k.locks) != 0 {
+		t.Errorf("expected no remembered keys, got %v", k.locks)
+	}
+}
@@ -3,6 +3,9 @@ package github
 import (
 	"context"
 	"fmt"
+	"strings"
+
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 )
 
 // GetLabels fetches repository labels
@@ -16,6 +19,12 @@ func (c *Client) GetLabels(ctx context.Context) ([]LabelData, error) {
 
 // CreateLabel creates a new label
 func (c *Client) CreateLabel(ctx context.Context, name, color, description string) error {
+	_, err := c.callJSON(ctx, httpPost, c.repoPath("labels"), createLabelPayload(name, color, description))
+	return withResource(err, labelResource(name))
+}
+
+// createLabelPayload builds the request body for creating a label
+func createLabelPayload(name, color, description string) map[string]string {
 	payload := map[string]string{
 		"name":  name,
 		"color": color,
@@ -23,25 +32,86 @@ func (c *Client) CreateLabel(ctx context.Context, name, color, description strin
 	if description != "" {
 		payload["description"] = description
 	}
-	_, err := c.callJSON(ctx, httpPost, c.repoPath("labels"), payload)
-	return err
+	return payload
 }
 
-// UpdateLabel updates an existing label
-func (c *Client) UpdateLabel(ctx context.Context, oldName, newName, color, description string) error {
+// UpdateLabel updates an existing label.
+// A nil description leaves the current one unchanged; an empty one clears it.
+func (c *Client) UpdateLabel(ctx context.Context, oldName, newName, color string, description *string) error {
+	_, err := c.callJSON(ctx, httpPatch, c.repoPath(labelPath(oldName)), updateLabelPayload(newName, color, description))
+	return withResource(err, labelResource(oldName))
+}
+
+// updateLabelPayload builds the request body for updating a label
+func updateLabelPayload(newName, color string, description *string) map[string]string {
 	payload := map[string]string{
 		"new_name": newName,
 		"color":    color,
 	}
-	if description != "" {
-		payload["description"] = description
+	if description != nil {
+		payload["description"] = *description
 	}
-	_, err := c.callJSON(ctx, httpPatch, c.repoPath(labelPath(oldName)), payload)
-	return err
+	return payload
 }
 
 // DeleteLabel deletes a label
 func (c *Client) DeleteLabel(ctx context.Context, name string) error {
 	_, err := c.callAPI(ctx, httpDelete, c.repoPath(labelPath(name)), nil)
-	return err
+	return withResource(err, labelResource(name))
+}
+
+// IsLabelRecreatable reports whether err is GitHub rejecting a label update as invalid (422),
+// e.g. a rename that only changes case and collides with the label itself. Deleting and
+// recreating the label can get past those rejections.
+func IsLabelRecreatable(err error) bool {
+	var apiErr *apperrors.APIError
+	if !apperrors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == 422 && apiErr.Method == string(httpPatch) && strings.Contains(apiErr.Endpoint, "/labels/")
+}
+
+// UpdateLabelOrRecreate updates a label like UpdateLabel. If GitHub rejects the update with
+// an error for which IsLabelRecreatable holds, the label is deleted and created again with
+// the new settings, and recreated is true. Recreating removes the label from every issue and
+// pull request. A nil description keeps the current one.
+func UpdateLabelOrRecreate(ctx context.Context, client GitHubClient, oldName, newName, color string, description *string) (recreated bool, err error) {
+	err = client.UpdateLabel(ctx, oldName, newName, color, description)
+	if err == nil || !IsLabelRecreatable(err) {
+		return false, err
+	}
+
+	desc := ""
+	if description != nil {
+		desc = *description
+	} else if desc, err = currentLabelDescription(ctx, client, oldName); err != nil {
+		return false, err
+	}
+
+	if err := client.DeleteLabel(ctx, oldName); err != nil {
+		return false, err
+	}
+	if err := client.CreateLabel(ctx, newName, color, desc); err != nil {
+		return true, fmt.Errorf("label '%s' was deleted but could not be recreated: %w", oldName, err)
+	}
+	return true, nil
+}
+
+// currentLabelDescription returns the description of the label named name, if it has one
+func currentLabelDescription(ctx context.Context, client GitHubClient, name string) (string, error) {
+	labels, err := client.GetLabels(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, l := range labels {
+		if l.Name == name && l.Description.IsSpecified() && !l.Description.IsNull() {
+			return l.Description.MustGet(), nil
+		}
+	}
+	return "", nil
+}
+
+// labelResource describes a label for error messages
+func labelResource(name string) string {
+	return fmt.Sprintf("label '%s'", name)
 }
@@ -3,6 +3,8 @@ package github
 import (
 	"context"
 
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 	"github.com/myzkey/gh-repo-settings/internal/infra/githubopenapi"
 )
 
@@ -16,20 +18,29 @@ type MockClient struct {
 	ActionsPermissions   *ActionsPermissionsData
 	ActionsSelected      *ActionsSelectedData
 	ActionsWorkflowPerms *ActionsWorkflowPermissionsData
+	ActionsAccess        *ActionsAccessData
+	ActionsRetention     *ActionsRetentionData
 	PagesData            *PagesData
+	Files                map[string]*FileData // Repository files keyed by path
+	MissingBranches      []string             // Branches reported as non-existent by BranchExists
+	Branches             []BranchData         // Returned by ListBranches; nil makes it fail, as if branches couldn't be listed
 	Owner                string
 	Name                 string
 
 	// Error fields for testing error scenarios
 	GetRepoError                       error
 	UpdateRepoError                    error
+	CreateRepoError                    error
 	GetLabelsError                     error
 	CreateLabelError                   error
 	UpdateLabelError                   error
 	DeleteLabelError                   error
 	SetTopicsError                     error
+	SetSocialPreviewError              error
 	GetBranchProtectionError           error
 	UpdateBranchProtectionError        error
+	BranchExistsError                  error
+	ListBranchesError                  error
 	GetSecretsError                    error
 	SetSecretError                     error
 	DeleteSecretError                  error
@@ -42,17 +53,27 @@ type MockClient struct {
 	UpdateActionsSelectedActionsError  error
 	GetActionsWorkflowPermissionsError error
 	UpdateActionsWorkflowPermsError    error
+	GetActionsAccessLevelError         error
+	UpdateActionsAccessLevelError      error
+	GetActionsRetentionError           error
+	UpdateActionsRetentionError        error
 	GetPagesError                      error
 	CreatePagesError                   error
 	UpdatePagesError                   error
+	GetFileError                       error
+	PutFileError                       error
 
 	// Call tracking
 	UpdateRepoCalls                 []map[string]interface{}
+	CreateRepoCalls                 []CreateRepoCall
 	SetTopicsCalls                  [][]string
+	SetSocialPreviewCalls           [][]byte
 	CreateLabelCalls                []LabelCall
 	UpdateLabelCalls                []UpdateLabelCall
 	DeleteLabelCalls                []string
 	UpdateBranchProtectionCalls     []BranchProtectionCall
+	GetBranchProtectionCalls        []string // Branches whose protection was read
+	ListBranchesCalls               int
 	SetSecretCalls                  []SecretCall
 	DeleteSecretCalls               []string
 	SetVariableCalls                []VariableCall
@@ -60,8 +81,18 @@ type MockClient struct {
 	UpdateActionsPermissionsCalls   []ActionsPermissionsCall
 	UpdateActionsSelectedCalls      []*ActionsSelectedData
 	UpdateActionsWorkflowPermsCalls []ActionsWorkflowPermsCall
+	UpdateActionsAccessLevelCalls   []string
+	UpdateActionsRetentionCalls     []int
 	CreatePagesCalls                []PagesCall
 	UpdatePagesCalls                []PagesCall
+	PutFileCalls                    []FileCall
+}
+
+// CreateRepoCall tracks CreateRepo calls
+type CreateRepoCall struct {
+	Owner  string
+	Name   string
+	Config *config.RepoConfig
 }
 
 // SecretCall tracks SetSecret calls
@@ -78,8 +109,17 @@ type VariableCall struct {
 
 // PagesCall tracks CreatePages and UpdatePages calls
 type PagesCall struct {
-	BuildType string
-	Source    *PagesSourceData
+	BuildType     string
+	Source        *PagesSourceData
+	Cname         *string
+	HttpsEnforced *bool
+}
+
+// FileCall tracks PutFile calls
+type FileCall struct {
+	Path    string
+	Content []byte
+	Message string
 }
 
 // ActionsPermissionsCall tracks UpdateActionsPermissions calls
@@ -106,7 +146,7 @@ type UpdateLabelCall struct {
 	OldName     string
 	NewName     string
 	Color       string
-	Description string
+	Description *string
 }
 
 // BranchProtectionCall tracks UpdateBranchProtection calls
@@ -123,6 +163,7 @@ func NewMockClient() *MockClient {
 		BranchProtections: make(map[string]*BranchProtectionData),
 		Secrets:           []string{},
 		Variables:         []VariableData{},
+		Files:             make(map[string]*FileData),
 		Owner:             "test-owner",
 		Name:              "test-repo",
 	}
@@ -155,6 +196,19 @@ func (m *MockClient) UpdateRepo(ctx context.Context, settings map[string]interfa
 	return nil
 }
 
+// CreateRepo records the create call
+func (m *MockClient) CreateRepo(ctx context.Context, owner, name string, cfg *config.RepoConfig) error {
+	if m.CreateRepoError != nil {
+		return m.CreateRepoError
+	}
+	m.CreateRepoCalls = append(m.CreateRepoCalls, CreateRepoCall{
+		Owner:  owner,
+		Name:   name,
+		Config: cfg,
+	})
+	return nil
+}
+
 // SetTopics records the topics call
 func (m *MockClient) SetTopics(ctx context.Context, topics []string) error {
 	if m.SetTopicsError != nil {
@@ -164,6 +218,15 @@ func (m *MockClient) SetTopics(ctx context.Context, topics []string) error {
 	return nil
 }
 
+// SetSocialPreview records the social preview upload
+func (m *MockClient) SetSocialPreview(ctx context.Context, image []byte) error {
+	if m.SetSocialPreviewError != nil {
+		return m.SetSocialPreviewError
+	}
+	m.SetSocialPreviewCalls = append(m.SetSocialPreviewCalls, image)
+	return nil
+}
+
 // GetLabels returns mock labels
 func (m *MockClient) GetLabels(ctx context.Context) ([]LabelData, error) {
 	if m.GetLabelsError != nil {
@@ -186,7 +249,7 @@ func (m *MockClient) CreateLabel(ctx context.Context, name, color, description s
 }
 
 // UpdateLabel records the update call
-func (m *MockClient) UpdateLabel(ctx context.Context, oldName, newName, color, description string) error {
+func (m *MockClient) UpdateLabel(ctx context.Context, oldName, newName, color string, description *string) error {
 	if m.UpdateLabelError != nil {
 		return m.UpdateLabelError
 	}
@@ -210,6 +273,7 @@ func (m *MockClient) DeleteLabel(ctx context.Context, name string) error {
 
 // GetBranchProtection returns mock branch protection
 func (m *MockClient) GetBranchProtection(ctx context.Context, branch string) (*BranchProtectionData, error) {
+	m.GetBranchProtectionCalls = append(m.GetBranchProtectionCalls, branch)
 	if m.GetBranchProtectionError != nil {
 		return nil, m.GetBranchProtectionError
 	}
@@ -231,6 +295,31 @@ func (m *MockClient) UpdateBranchProtection(ctx context.Context, branch string,
 	return nil
 }
 
+// ListBranches returns Branches, or an error if Branches is nil
+func (m *MockClient) ListBranches(ctx context.Context) ([]BranchData, error) {
+	m.ListBranchesCalls++
+	if m.ListBranchesError != nil {
+		return nil, m.ListBranchesError
+	}
+	if m.Branches == nil {
+		return nil, apperrors.NewAPIError("GET", "repos/"+m.Owner+"/"+m.Name+"/branches", 404, "Not Found", nil)
+	}
+	return m.Branches, nil
+}
+
+// BranchExists reports false for branches listed in MissingBranches
+func (m *MockClient) BranchExists(ctx context.Context, branch string) (bool, error) {
+	if m.BranchExistsError != nil {
+		return false, m.BranchExistsError
+	}
+	for _, b := range m.MissingBranches {
+		if b == branch {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // GetSecrets returns mock secrets
 func (m *MockClient) GetSecrets(ctx context.Context) ([]string, error) {
 	if m.GetSecretsError != nil {
@@ -350,6 +439,46 @@ func (m *MockClient) UpdateActionsWorkflowPermissions(ctx context.Context, permi
 	return nil
 }
 
+// GetActionsAccessLevel returns the mock actions access level, "none" by default
+func (m *MockClient) GetActionsAccessLevel(ctx context.Context) (*ActionsAccessData, error) {
+	if m.GetActionsAccessLevelError != nil {
+		return nil, m.GetActionsAccessLevelError
+	}
+	if m.ActionsAccess == nil {
+		return &ActionsAccessData{AccessLevel: "none"}, nil
+	}
+	return m.ActionsAccess, nil
+}
+
+// UpdateActionsAccessLevel records the update call
+func (m *MockClient) UpdateActionsAccessLevel(ctx context.Context, level string) error {
+	if m.UpdateActionsAccessLevelError != nil {
+		return m.UpdateActionsAccessLevelError
+	}
+	m.UpdateActionsAccessLevelCalls = append(m.UpdateActionsAccessLevelCalls, level)
+	return nil
+}
+
+// GetActionsRetention returns the mock retention period, 90 days by default
+func (m *MockClient) GetActionsRetention(ctx context.Context) (*ActionsRetentionData, error) {
+	if m.GetActionsRetentionError != nil {
+		return nil, m.GetActionsRetentionError
+	}
+	if m.ActionsRetention == nil {
+		return &ActionsRetentionData{Days: 90, MaximumAllowedDays: 90}, nil
+	}
+	return m.ActionsRetention, nil
+}
+
+// UpdateActionsRetention records the update call
+func (m *MockClient) UpdateActionsRetention(ctx context.Context, days int) error {
+	if m.UpdateActionsRetentionError != nil {
+		return m.UpdateActionsRetentionError
+	}
+	m.UpdateActionsRetentionCalls = append(m.UpdateActionsRetentionCalls, days)
+	return nil
+}
+
 // GetPages returns mock pages data
 func (m *MockClient) GetPages(ctx context.Context) (*PagesData, error) {
 	if m.GetPagesError != nil {
@@ -371,16 +500,133 @@ func (m *MockClient) CreatePages(ctx context.Context, buildType string, source *
 }
 
 // UpdatePages records the update call
-func (m *MockClient) UpdatePages(ctx context.Context, buildType string, source *PagesSourceData) error {
+func (m *MockClient) UpdatePages(ctx context.Context, buildType string, source *PagesSourceData, cname *string, httpsEnforced *bool) error {
 	if m.UpdatePagesError != nil {
 		return m.UpdatePagesError
 	}
 	m.UpdatePagesCalls = append(m.UpdatePagesCalls, PagesCall{
-		BuildType: buildType,
-		Source:    source,
+		BuildType:     buildType,
+		Source:        source,
+		Cname:         cname,
+		HttpsEnforced: httpsEnforced,
+	})
+	return nil
+}
+
+// GetFile returns mock file metadata, or ErrFileNotFound
+func (m *MockClient) GetFile(ctx context.Context, path string) (*FileData, error) {
+	if m.GetFileError != nil {
+		return nil, m.GetFileError
+	}
+	file, ok := m.Files[path]
+	if !ok {
+		return nil, apperrors.ErrFileNotFound
+	}
+	return file, nil
+}
+
+// PutFile records the put call
+func (m *MockClient) PutFile(ctx context.Context, path string, content []byte, message string) error {
+	if m.PutFileError != nil {
+		return m.PutFileError
+	}
+	m.PutFileCalls = append(m.PutFileCalls, FileCall{
+		Path:    path,
+		Content: content,
+		Message: message,
 	})
 	return nil
 }
 
 // Ensure MockClient implements GitHubClient
 var _ GitHubClient = (*MockClient)(nil)
+
+// MockOrgClient is a mock implementation of OrgGitHubClient for testing
+type MockOrgClient struct {
+	ActionsPermissions   *OrgActionsPermissionsData
+	ActionsWorkflowPerms *ActionsWorkflowPermissionsData
+	Repos                []OrgRepoData
+	Org                  string
+
+	// Error fields for testing error scenarios
+	GetOrgActionsPermissionsError    error
+	UpdateOrgActionsPermissionsError error
+	GetOrgWorkflowPermissionsError   error
+	UpdateOrgWorkflowPermsError      error
+	ListReposError                   error
+
+	// Call tracking
+	UpdateOrgActionsPermissionsCalls []OrgActionsPermissionsCall
+	UpdateOrgWorkflowPermsCalls      []ActionsWorkflowPermsCall
+}
+
+// OrgActionsPermissionsCall tracks UpdateOrgActionsPermissions calls
+type OrgActionsPermissionsCall struct {
+	EnabledRepositories string
+	AllowedActions      string
+}
+
+// NewMockOrgClient creates a new mock org client with default values
+func NewMockOrgClient() *MockOrgClient {
+	return &MockOrgClient{
+		ActionsPermissions:   &OrgActionsPermissionsData{EnabledRepositories: "all"},
+		ActionsWorkflowPerms: &ActionsWorkflowPermissionsData{},
+		Org:                  "test-org",
+	}
+}
+
+// OrgName returns the mock org name
+func (m *MockOrgClient) OrgName() string {
+	return m.Org
+}
+
+// GetOrgActionsPermissions returns mock org actions permissions
+func (m *MockOrgClient) GetOrgActionsPermissions(ctx context.Context) (*OrgActionsPermissionsData, error) {
+	if m.GetOrgActionsPermissionsError != nil {
+		return nil, m.GetOrgActionsPermissionsError
+	}
+	return m.ActionsPermissions, nil
+}
+
+// UpdateOrgActionsPermissions records the update call
+func (m *MockOrgClient) UpdateOrgActionsPermissions(ctx context.Context, enabledRepositories, allowedActions string) error {
+	if m.UpdateOrgActionsPermissionsError != nil {
+		return m.UpdateOrgActionsPermissionsError
+	}
+	m.UpdateOrgActionsPermissionsCalls = append(m.UpdateOrgActionsPermissionsCalls, OrgActionsPermissionsCall{
+		EnabledRepositories: enabledRepositories,
+		AllowedActions:      allowedActions,
+	})
+	return nil
+}
+
+// GetOrgWorkflowPermissions returns mock org workflow permissions
+func (m *MockOrgClient) GetOrgWorkflowPermissions(ctx context.Context) (*ActionsWorkflowPermissionsData, error) {
+	if m.GetOrgWorkflowPermissionsError != nil {
+		return nil, m.GetOrgWorkflowPermissionsError
+	}
+	return m.ActionsWorkflowPerms, nil
+}
+
+// UpdateOrgWorkflowPermissions records the update call
+func (m *MockOrgClient) UpdateOrgWorkflowPermissions(ctx context.Context, permissions string, canApprove bool) error {
+	if m.UpdateOrgWorkflowPermsError != nil {
+		return m.UpdateOrgWorkflowPermsError
+	}
+	m.UpdateOrgWorkflowPermsCalls = append(m.UpdateOrgWorkflowPermsCalls, ActionsWorkflowPermsCall{
+		Permissions: permissions,
+		CanApprove:  canApprove,
+	})
+	return nil
+}
+
+// ListRepos returns the mock repository list
+func (m *MockOrgClient) ListRepos(ctx context.Context) ([]OrgRepoData, error) {
+	if m.ListReposError != nil {
+		return nil, m.ListReposError
+	}
+	return m.Repos, nil
+}
+
+// Ensure MockOrgClient implements OrgGitHubClient
+var _ OrgGitHubClient = (*MockOrgClient)(nil)
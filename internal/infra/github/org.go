@@ -0,0 +1,88 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+)
+
+// OrgClient wraps gh CLI commands for organization-level settings
+type OrgClient struct {
+	Org string
+
+	// client provides the shared gh API transport; its Repo is unused
+	client *Client
+}
+
+// NewOrgClient creates a new GitHub client for an organization
+func NewOrgClient(org string) (*OrgClient, error) {
+	if org == "" || strings.Contains(org, "/") {
+		return nil, apperrors.NewValidationError("org", fmt.Sprintf("invalid organization name: %q", org))
+	}
+	return &OrgClient{Org: org, client: &Client{}}, nil
+}
+
+// SetTimeout limits how long each gh command may run; zero disables the limit
+func (c *OrgClient) SetTimeout(timeout time.Duration) {
+	c.client.SetTimeout(timeout)
+}
+
+// OrgName returns the organization name
+func (c *OrgClient) OrgName() string {
+	return c.Org
+}
+
+// orgPath builds an API endpoint path for the organization.
+// Example: orgPath("actions/permissions") returns "orgs/{org}/actions/permissions"
+func (c *OrgClient) orgPath(path string) string {
+	return fmt.Sprintf("orgs/%s/%s", c.Org, path)
+}
+
+// ListRepos lists every repository in the organization the token can see
+func (c *OrgClient) ListRepos(ctx context.Context) ([]OrgRepoData, error) {
+	var repos []OrgRepoData
+	if err := c.client.getJSON(ctx, c.orgPath("repos"), &repos, "--paginate"); err != nil {
+		return nil, fmt.Errorf("failed to list repositories of org %s: %w", c.Org, err)
+	}
+	return repos, nil
+}
+
+// GetOrgActionsPermissions fetches Actions permissions for the organization
+func (c *OrgClient) GetOrgActionsPermissions(ctx context.Context) (*OrgActionsPermissionsData, error) {
+	var data OrgActionsPermissionsData
+	if err := c.client.getJSON(ctx, c.orgPath("actions/permissions"), &data); err != nil {
+		return nil, fmt.Errorf("failed to get org actions permissions: %w", err)
+	}
+	return &data, nil
+}
+
+// UpdateOrgActionsPermissions updates Actions permissions for the organization
+func (c *OrgClient) UpdateOrgActionsPermissions(ctx context.Context, enabledRepositories, allowedActions string) error {
+	payload := map[string]interface{}{
+		"enabled_repositories": enabledRepositories,
+	}
+	if enabledRepositories != "none" && allowedActions != "" {
+		payload["allowed_actions"] = allowedActions
+	}
+
+	_, err := c.client.callJSON(ctx, httpPut, c.orgPath("actions/permissions"), payload)
+	return withResource(err, fmt.Sprintf("org '%s' actions permissions", c.Org))
+}
+
+// GetOrgWorkflowPermissions fetches default workflow permissions for the organization
+func (c *OrgClient) GetOrgWorkflowPermissions(ctx context.Context) (*ActionsWorkflowPermissionsData, error) {
+	var data ActionsWorkflowPermissionsData
+	if err := c.client.getJSON(ctx, c.orgPath("actions/permissions/workflow"), &data); err != nil {
+		return nil, fmt.Errorf("failed to get org workflow permissions: %w", err)
+	}
+	return &data, nil
+}
+
+// UpdateOrgWorkflowPermissions updates default workflow permissions for the organization
+func (c *OrgClient) UpdateOrgWorkflowPermissions(ctx context.Context, permissions string, canApprove bool) error {
+	_, err := c.client.callJSON(ctx, httpPut, c.orgPath("actions/permissions/workflow"), workflowPermissionsPayload(permissions, canApprove))
+	return withResource(err, fmt.Sprintf("org '%s' workflow permissions", c.Org))
+}
@@ -24,22 +24,24 @@ func (c *Client) GetPages(ctx context.Context) (*PagesData, error) {
 
 // CreatePages creates GitHub Pages for the repository
 func (c *Client) CreatePages(ctx context.Context, buildType string, source *PagesSourceData) error {
-	payload := map[string]interface{}{
-		"build_type": buildType,
-	}
-	if source != nil && buildType == "legacy" {
-		payload["source"] = map[string]string{
-			"branch": source.Branch,
-			"path":   source.Path,
-		}
-	}
+	_, err := c.callJSON(ctx, httpPost, c.repoPath("pages"), pagesPayload(buildType, source, nil, nil))
+	return withResource(err, "pages")
+}
 
-	_, err := c.callJSON(ctx, httpPost, c.repoPath("pages"), payload)
+// UpdatePages updates GitHub Pages configuration.
+// A nil cname or httpsEnforced leaves that setting unchanged; an empty cname removes the custom domain.
+func (c *Client) UpdatePages(ctx context.Context, buildType string, source *PagesSourceData, cname *string, httpsEnforced *bool) error {
+	_, err := c.callJSON(ctx, httpPut, c.repoPath("pages"), pagesPayload(buildType, source, cname, httpsEnforced))
+	err = withResource(err, "pages")
+	if err != nil && httpsEnforced != nil && *httpsEnforced && isCertificatePending(err) {
+		return fmt.Errorf("%w (HTTPS can only be enforced once GitHub has provisioned the certificate for the custom domain, which can take a while after the domain is set; re-run apply later)", err)
+	}
 	return err
 }
 
-// UpdatePages updates GitHub Pages configuration
-func (c *Client) UpdatePages(ctx context.Context, buildType string, source *PagesSourceData) error {
+// pagesPayload builds the request body for creating or updating Pages.
+// The source is only sent for legacy builds.
+func pagesPayload(buildType string, source *PagesSourceData, cname *string, httpsEnforced *bool) map[string]interface{} {
 	payload := map[string]interface{}{
 		"build_type": buildType,
 	}
@@ -49,7 +51,25 @@ func (c *Client) UpdatePages(ctx context.Context, buildType string, source *Page
 			"path":   source.Path,
 		}
 	}
+	if cname != nil {
+		if *cname == "" {
+			payload["cname"] = nil
+		} else {
+			payload["cname"] = *cname
+		}
+	}
+	if httpsEnforced != nil {
+		payload["https_enforced"] = *httpsEnforced
+	}
+	return payload
+}
 
-	_, err := c.callJSON(ctx, httpPut, c.repoPath("pages"), payload)
-	return err
+// isCertificatePending reports whether err is the rejection GitHub returns when HTTPS
+// is enforced before the custom domain's certificate exists (404 or 422)
+func isCertificatePending(err error) bool {
+	var apiErr *apperrors.APIError
+	if !apperrors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == 404 || apiErr.StatusCode == 422
 }
@@ -0,0 +1,178 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+)
+
+// PreviewClient reads through another client but only prints the request each write
+// would send, without sending it. Request bodies are built by the same functions the
+// Client uses, so the preview shows the exact payloads. Secret values are never printed.
+type PreviewClient struct {
+	GitHubClient
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewPreviewClient creates a PreviewClient that reads from client and prints requests to out
+func NewPreviewClient(client GitHubClient, out io.Writer) *PreviewClient {
+	return &PreviewClient{GitHubClient: client, out: out}
+}
+
+// show prints a request and its JSON body, if any
+func (p *PreviewClient) show(method httpMethod, path string, body interface{}) error {
+	endpoint := repoEndpoint(p.RepoOwner(), p.RepoName(), path)
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.MarshalIndent(body, "", "  "); err != nil {
+			return fmt.Errorf("failed to marshal request body for %s: %w", endpoint, err)
+		}
+	}
+	return p.print(fmt.Sprintf("%s %s", method, endpoint), data)
+}
+
+// print writes a request line and its body as one block, so concurrent steps don't interleave
+func (p *PreviewClient) print(request string, body []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if body == nil {
+		_, err := fmt.Fprintf(p.out, "%s\n\n", request)
+		return err
+	}
+	_, err := fmt.Fprintf(p.out, "%s\n%s\n\n", request, body)
+	return err
+}
+
+// UpdateRepo prints the repository settings PATCH
+func (p *PreviewClient) UpdateRepo(ctx context.Context, settings map[string]interface{}) error {
+	return p.show(httpPatch, "", settings)
+}
+
+// CreateRepo can't be previewed: nothing else can be read from a repository that doesn't exist
+func (p *PreviewClient) CreateRepo(ctx context.Context, owner, name string, cfg *config.RepoConfig) error {
+	return fmt.Errorf("creating repository '%s/%s' can't be previewed", owner, name)
+}
+
+// SetSocialPreview prints the image upload without the image data
+func (p *PreviewClient) SetSocialPreview(ctx context.Context, image []byte) error {
+	body, contentType, err := socialPreviewPayload(image)
+	if err != nil {
+		return err
+	}
+	endpoint := repoEndpoint(p.RepoOwner(), p.RepoName(), "")
+	return p.print(fmt.Sprintf("%s %s (%s, %d bytes)", httpPatch, endpoint, contentType, len(body)), nil)
+}
+
+// SetTopics prints the topics PUT
+func (p *PreviewClient) SetTopics(ctx context.Context, topics []string) error {
+	return p.show(httpPut, "topics", topicsPayload(topics))
+}
+
+// CreateLabel prints the label POST
+func (p *PreviewClient) CreateLabel(ctx context.Context, name, color, description string) error {
+	return p.show(httpPost, "labels", createLabelPayload(name, color, description))
+}
+
+// UpdateLabel prints the label PATCH
+func (p *PreviewClient) UpdateLabel(ctx context.Context, oldName, newName, color string, description *string) error {
+	return p.show(httpPatch, labelPath(oldName), updateLabelPayload(newName, color, description))
+}
+
+// DeleteLabel prints the label DELETE
+func (p *PreviewClient) DeleteLabel(ctx context.Context, name string) error {
+	return p.show(httpDelete, labelPath(name), nil)
+}
+
+// UpdateBranchProtection prints the branch protection PUT
+func (p *PreviewClient) UpdateBranchProtection(ctx context.Context, branch string, settings *BranchProtectionSettings) error {
+	return p.show(httpPut, branchSubpath(branch, "protection"), branchProtectionPayload(settings))
+}
+
+// SetSecret prints the secret PUT. gh encrypts the value, which is never shown.
+func (p *PreviewClient) SetSecret(ctx context.Context, name, value string) error {
+	endpoint := repoEndpoint(p.RepoOwner(), p.RepoName(), secretPath(name))
+	return p.print(fmt.Sprintf("%s %s (encrypted value, not shown)", httpPut, endpoint), nil)
+}
+
+// DeleteSecret prints the secret DELETE
+func (p *PreviewClient) DeleteSecret(ctx context.Context, name string) error {
+	return p.show(httpDelete, secretPath(name), nil)
+}
+
+// SetVariable prints the POST that creates the variable, or the PATCH that updates it
+func (p *PreviewClient) SetVariable(ctx context.Context, name, value string) error {
+	variables, err := p.GetVariables(ctx)
+	if err != nil {
+		return err
+	}
+	for _, v := range variables {
+		if v.Name == name {
+			return p.show(httpPatch, variablePath(name), variablePayload(name, value))
+		}
+	}
+	return p.show(httpPost, "actions/variables", variablePayload(name, value))
+}
+
+// DeleteVariable prints the variable DELETE
+func (p *PreviewClient) DeleteVariable(ctx context.Context, name string) error {
+	return p.show(httpDelete, variablePath(name), nil)
+}
+
+// UpdateActionsPermissions prints the Actions permissions PUT
+func (p *PreviewClient) UpdateActionsPermissions(ctx context.Context, enabled bool, allowedActions string) error {
+	return p.show(httpPut, "actions/permissions", actionsPermissionsPayload(enabled, allowedActions))
+}
+
+// UpdateActionsSelectedActions prints the selected actions PUT
+func (p *PreviewClient) UpdateActionsSelectedActions(ctx context.Context, settings *ActionsSelectedData) error {
+	return p.show(httpPut, "actions/permissions/selected-actions", settings)
+}
+
+// UpdateActionsWorkflowPermissions prints the workflow permissions PUT
+func (p *PreviewClient) UpdateActionsWorkflowPermissions(ctx context.Context, permissions string, canApprove bool) error {
+	return p.show(httpPut, "actions/permissions/workflow", workflowPermissionsPayload(permissions, canApprove))
+}
+
+// UpdateActionsAccessLevel prints the actions access level PUT
+func (p *PreviewClient) UpdateActionsAccessLevel(ctx context.Context, level string) error {
+	return p.show(httpPut, "actions/permissions/access", ActionsAccessData{AccessLevel: level})
+}
+
+// UpdateActionsRetention prints the artifact and log retention PUT
+func (p *PreviewClient) UpdateActionsRetention(ctx context.Context, days int) error {
+	return p.show(httpPut, "actions/permissions/artifact-and-log-retention", retentionPayload(days))
+}
+
+// CreatePages prints the Pages POST
+func (p *PreviewClient) CreatePages(ctx context.Context, buildType string, source *PagesSourceData) error {
+	return p.show(httpPost, "pages", pagesPayload(buildType, source, nil, nil))
+}
+
+// UpdatePages prints the Pages PUT
+func (p *PreviewClient) UpdatePages(ctx context.Context, buildType string, source *PagesSourceData, cname *string, httpsEnforced *bool) error {
+	return p.show(httpPut, "pages", pagesPayload(buildType, source, cname, httpsEnforced))
+}
+
+// PutFile prints the contents PUT, reading the current blob SHA like the Client does
+func (p *PreviewClient) PutFile(ctx context.Context, path string, content []byte, message string) error {
+	var sha string
+	existing, err := p.GetFile(ctx, path)
+	switch {
+	case err == nil:
+		sha = existing.SHA
+	case !apperrors.Is(err, apperrors.ErrFileNotFound):
+		return err
+	}
+	return p.show(httpPut, contentsPath(path), putFilePayload(content, message, sha))
+}
+
+// Ensure PreviewClient implements GitHubClient
+var _ GitHubClient = (*PreviewClient)(nil)
@@ -0,0 +1,210 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+)
+
+// jsonOf renders a payload the way callJSON sends it, for comparison
+func jsonOf(t *testing.T, payload interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return string(data)
+}
+
+func TestBranchProtectionPayload(t *testing.T) {
+	reviews := 2
+	yes, no := true, false
+
+	tests := []struct {
+		name     string
+		settings *BranchProtectionSettings
+		want     string
+	}{
+		{
+			name:     "empty rule turns everything off",
+			settings: &BranchProtectionSettings{},
+			want:     `{"allow_deletions":false,"allow_force_pushes":false,"enforce_admins":false,"required_linear_history":false,"required_pull_request_reviews":null,"required_status_checks":null,"restrictions":null}`,
+		},
+		{
+			name: "reviews and status checks",
+			settings: &BranchProtectionSettings{
+				RequiredReviews:     &reviews,
+				DismissStaleReviews: &yes,
+				RequireStatusChecks: &yes,
+				StatusChecks:        []config.StatusCheck{{Context: "build"}, {Context: "test"}},
+				StrictStatusChecks:  &yes,
+				EnforceAdmins:       &yes,
+				AllowForcePushes:    &no,
+			},
+			want: `{"allow_deletions":false,"allow_force_pushes":false,"enforce_admins":true,"required_linear_history":false,` +
+				`"required_pull_request_reviews":{"dismiss_stale_reviews":true,"required_approving_review_count":2},` +
+				`"required_status_checks":{"contexts":["build","test"],"strict":true},"restrictions":null}`,
+		},
+		{
+			name: "status checks tied to an app use the checks form",
+			settings: &BranchProtectionSettings{
+				RequireStatusChecks: &yes,
+				StatusChecks:        []config.StatusCheck{{Context: "lint"}, {Context: "test", AppID: &reviews}},
+			},
+			want: `{"allow_deletions":false,"allow_force_pushes":false,"enforce_admins":false,"required_linear_history":false,"required_pull_request_reviews":null,` +
+				`"required_status_checks":{"checks":[{"context":"lint"},{"app_id":2,"context":"test"}],"strict":false},"restrictions":null}`,
+		},
+		{
+			name:     "require_reviews false removes reviews",
+			settings: &BranchProtectionSettings{RequireReviews: &no},
+			want:     `{"allow_deletions":false,"allow_force_pushes":false,"enforce_admins":false,"required_linear_history":false,"required_pull_request_reviews":null,"required_status_checks":null,"restrictions":null}`,
+		},
+		{
+			name:     "require_reviews with a count",
+			settings: &BranchProtectionSettings{RequireReviews: &yes, RequiredReviews: &reviews},
+			want:     `{"allow_deletions":false,"allow_force_pushes":false,"enforce_admins":false,"required_linear_history":false,"required_pull_request_reviews":{"required_approving_review_count":2},"required_status_checks":null,"restrictions":null}`,
+		},
+		{
+			name:     "require_reviews without other review settings",
+			settings: &BranchProtectionSettings{RequireReviews: &yes},
+			want:     `{"allow_deletions":false,"allow_force_pushes":false,"enforce_admins":false,"required_linear_history":false,"required_pull_request_reviews":{},"required_status_checks":null,"restrictions":null}`,
+		},
+		{
+			name:     "status checks without contexts",
+			settings: &BranchProtectionSettings{RequireStatusChecks: &yes},
+			want:     `{"allow_deletions":false,"allow_force_pushes":false,"enforce_admins":false,"required_linear_history":false,"required_pull_request_reviews":null,"required_status_checks":{"contexts":[],"strict":false},"restrictions":null}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jsonOf(t, branchProtectionPayload(tt.settings)); got != tt.want {
+				t.Errorf("branchProtectionPayload() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPagesPayload(t *testing.T) {
+	source := &PagesSourceData{Branch: "gh-pages", Path: "/"}
+	empty, domain := "", "docs.example.com"
+	https := true
+
+	tests := []struct {
+		name          string
+		buildType     string
+		cname         *string
+		httpsEnforced *bool
+		want          string
+	}{
+		{"workflow drops the source", "workflow", nil, nil, `{"build_type":"workflow"}`},
+		{"legacy sends the source", "legacy", nil, nil, `{"build_type":"legacy","source":{"branch":"gh-pages","path":"/"}}`},
+		{"custom domain and https", "workflow", &domain, &https, `{"build_type":"workflow","cname":"docs.example.com","https_enforced":true}`},
+		{"empty domain removes it", "workflow", &empty, nil, `{"build_type":"workflow","cname":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jsonOf(t, pagesPayload(tt.buildType, source, tt.cname, tt.httpsEnforced)); got != tt.want {
+				t.Errorf("pagesPayload() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSmallPayloads(t *testing.T) {
+	description := ""
+	tests := []struct {
+		name    string
+		payload interface{}
+		want    string
+	}{
+		{"actions enabled", actionsPermissionsPayload(true, "selected"), `{"allowed_actions":"selected","enabled":true}`},
+		{"actions disabled", actionsPermissionsPayload(false, "selected"), `{"enabled":false}`},
+		{"workflow permissions", workflowPermissionsPayload("read", false), `{"can_approve_pull_request_reviews":false,"default_workflow_permissions":"read"}`},
+		{"retention", retentionPayload(30), `{"days":30}`},
+		{"topics", topicsPayload([]string{"go", "cli"}), `{"names":["go","cli"]}`},
+		{"create label", createLabelPayload("bug", "d73a4a", ""), `{"color":"d73a4a","name":"bug"}`},
+		{"update label clears description", updateLabelPayload("bug", "d73a4a", &description), `{"color":"d73a4a","description":"","new_name":"bug"}`},
+		{"new file", putFilePayload([]byte("hi"), "Add file", ""), `{"content":"aGk=","message":"Add file"}`},
+		{"existing file", putFilePayload([]byte("hi"), "Update file", "abc"), `{"content":"aGk=","message":"Update file","sha":"abc"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jsonOf(t, tt.payload); got != tt.want {
+				t.Errorf("payload = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewClientSendsNothing(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockClient()
+	mock.Variables = []VariableData{{Name: "EXISTING", Value: "old"}}
+	mock.Files[".github/CODEOWNERS"] = &FileData{SHA: "abc"}
+
+	var out bytes.Buffer
+	preview := NewPreviewClient(mock, &out)
+
+	reviews := 1
+	calls := []error{
+		preview.UpdateRepo(ctx, map[string]interface{}{"description": "new"}),
+		preview.SetTopics(ctx, []string{"go"}),
+		preview.CreateLabel(ctx, "bug", "d73a4a", ""),
+		preview.UpdateLabel(ctx, "help wanted", "help", "008672", nil),
+		preview.DeleteLabel(ctx, "stale"),
+		preview.UpdateBranchProtection(ctx, "release/v1", &BranchProtectionSettings{RequiredReviews: &reviews}),
+		preview.SetSecret(ctx, "TOKEN", "s3cr3t"),
+		preview.DeleteSecret(ctx, "OLD_TOKEN"),
+		preview.SetVariable(ctx, "EXISTING", "new"),
+		preview.SetVariable(ctx, "ADDED", "value"),
+		preview.DeleteVariable(ctx, "GONE"),
+		preview.UpdateActionsPermissions(ctx, true, "all"),
+		preview.UpdateActionsSelectedActions(ctx, &ActionsSelectedData{}),
+		preview.UpdateActionsWorkflowPermissions(ctx, "read", false),
+		preview.UpdateActionsAccessLevel(ctx, "organization"),
+		preview.UpdateActionsRetention(ctx, 30),
+		preview.CreatePages(ctx, "workflow", nil),
+		preview.UpdatePages(ctx, "workflow", nil, nil, nil),
+		preview.PutFile(ctx, ".github/CODEOWNERS", []byte("* @me"), "Update CODEOWNERS"),
+	}
+	for i, err := range calls {
+		if err != nil {
+			t.Errorf("call %d error = %v", i, err)
+		}
+	}
+
+	// Only reads may reach the underlying client
+	empty := NewMockClient()
+	empty.Variables, empty.Files = mock.Variables, mock.Files
+	if !reflect.DeepEqual(mock, empty) {
+		t.Errorf("preview reached the client: %+v", mock)
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"PATCH repos/test-owner/test-repo\n{\n  \"description\": \"new\"\n}\n",
+		"PATCH repos/test-owner/test-repo/labels/help%20wanted\n",
+		"DELETE repos/test-owner/test-repo/labels/stale\n\n",
+		"PUT repos/test-owner/test-repo/branches/release%2Fv1/protection\n",
+		"PUT repos/test-owner/test-repo/actions/secrets/TOKEN (encrypted value, not shown)\n",
+		"PATCH repos/test-owner/test-repo/actions/variables/EXISTING\n",
+		"POST repos/test-owner/test-repo/actions/variables\n",
+		"\"sha\": \"abc\"",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("preview output is missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "s3cr3t") {
+		t.Error("preview output contains a secret value")
+	}
+
+	if err := preview.CreateRepo(ctx, "o", "r", nil); err == nil {
+		t.Error("expected CreateRepo to refuse a preview")
+	}
+}
@@ -0,0 +1,46 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// repoFromGitRemote derives the repository from the origin remote of the git
+// repository in the current directory
+func repoFromGitRemote(ctx context.Context) (RepoInfo, error) {
+	out, err := exec.CommandContext(ctx, "git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		return RepoInfo{}, fmt.Errorf("failed to read origin remote: %w", err)
+	}
+	return parseGitRemoteURL(strings.TrimSpace(string(out)))
+}
+
+// parseGitRemoteURL extracts owner and name from a git remote URL.
+// Supported forms:
+//
+//	git@github.com:owner/repo.git
+//	ssh://git@github.com/owner/repo.git
+//	https://github.com/owner/repo.git
+//
+// The ".git" suffix and a trailing slash are optional.
+func parseGitRemoteURL(remote string) (RepoInfo, error) {
+	path := ""
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		path = u.Path
+	} else if at := strings.Index(remote, "@"); at >= 0 {
+		// scp-like syntax: [user@]host:owner/repo
+		if colon := strings.Index(remote[at:], ":"); colon >= 0 {
+			path = remote[at+colon+1:]
+		}
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return RepoInfo{}, fmt.Errorf("cannot determine owner/name from remote URL %q", remote)
+	}
+	return RepoInfo{Owner: parts[0], Name: parts[1]}, nil
+}
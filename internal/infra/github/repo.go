@@ -1,19 +1,122 @@
 package github
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
 )
 
+// FullName returns the "owner/name" of the client's repository
+func FullName(client GitHubClient) string {
+	return client.RepoOwner() + "/" + client.RepoName()
+}
+
+// CheckRepoLocation reports a RepoMovedError when GitHub resolves the client's repository
+// to a different owner or name: gh follows the redirect of a renamed or transferred
+// repository, so requests would silently reach its new location. Names are compared
+// without case, since GitHub ignores it.
+func CheckRepoLocation(ctx context.Context, client GitHubClient) error {
+	data, err := client.GetRepo(ctx)
+	if err != nil {
+		return err
+	}
+	requested := FullName(client)
+	if data.FullName == "" || strings.EqualFold(data.FullName, requested) {
+		return nil
+	}
+	return apperrors.NewRepoMovedError(requested, data.FullName)
+}
+
 // GetRepo fetches repository settings
 func (c *Client) GetRepo(ctx context.Context) (*RepoData, error) {
 	var data RepoData
 	if err := c.getJSON(ctx, c.repoPath(""), &data); err != nil {
+		// Repository doesn't exist (or isn't visible to the token)
+		var apiErr *apperrors.APIError
+		if apperrors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+			return nil, apperrors.ErrRepoNotFound
+		}
 		return nil, fmt.Errorf("failed to get repo: %w", err)
 	}
 	return &data, nil
 }
 
+// CreateRepo creates a new repository owned by the authenticated user or an organization.
+// Settings from cfg are applied at creation time when specified.
+func (c *Client) CreateRepo(ctx context.Context, owner, name string, cfg *config.RepoConfig) error {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := c.getJSON(ctx, "user", &user); err != nil {
+		return fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+
+	_, err := c.callJSON(ctx, httpPost, createRepoEndpoint(owner, user.Login), createRepoPayload(name, cfg))
+	return withResource(err, fmt.Sprintf("repository '%s/%s'", owner, name))
+}
+
+// createRepoEndpoint returns the endpoint for creating a repository.
+// Repositories owned by the authenticated user use "user/repos", others are treated as organizations.
+func createRepoEndpoint(owner, authenticatedUser string) string {
+	if strings.EqualFold(owner, authenticatedUser) {
+		return "user/repos"
+	}
+	return fmt.Sprintf("orgs/%s/repos", owner)
+}
+
+// createRepoPayload builds the request body for repository creation
+func createRepoPayload(name string, cfg *config.RepoConfig) map[string]interface{} {
+	payload := map[string]interface{}{
+		"name": name,
+	}
+	if cfg == nil {
+		return payload
+	}
+
+	if cfg.Description != nil {
+		payload["description"] = *cfg.Description
+	}
+	if cfg.Homepage != nil {
+		payload["homepage"] = *cfg.Homepage
+	}
+	if cfg.Visibility != nil {
+		payload["visibility"] = *cfg.Visibility
+		payload["private"] = *cfg.Visibility != "public"
+	}
+	if cfg.AllowMergeCommit != nil {
+		payload["allow_merge_commit"] = *cfg.AllowMergeCommit
+	}
+	if cfg.AllowRebaseMerge != nil {
+		payload["allow_rebase_merge"] = *cfg.AllowRebaseMerge
+	}
+	if cfg.AllowSquashMerge != nil {
+		payload["allow_squash_merge"] = *cfg.AllowSquashMerge
+	}
+	if cfg.DeleteBranchOnMerge != nil {
+		payload["delete_branch_on_merge"] = *cfg.DeleteBranchOnMerge
+	}
+	if cfg.AllowAutoMerge != nil {
+		payload["allow_auto_merge"] = *cfg.AllowAutoMerge
+	}
+	if cfg.UseSquashPRTitle != nil {
+		payload["use_squash_pr_title_as_default"] = *cfg.UseSquashPRTitle
+	}
+	if cfg.WebCommitSignoffRequired != nil {
+		payload["web_commit_signoff_required"] = *cfg.WebCommitSignoffRequired
+	}
+	if cfg.IsTemplate != nil {
+		payload["is_template"] = *cfg.IsTemplate
+	}
+	return payload
+}
+
 // UpdateRepo updates repository settings
 func (c *Client) UpdateRepo(ctx context.Context, settings map[string]interface{}) error {
 	endpoint := c.repoPath("")
@@ -35,14 +138,64 @@ func (c *Client) UpdateRepo(ctx context.Context, settings map[string]interface{}
 	}
 
 	_, err := c.callAPI(ctx, httpPatch, endpoint, nil, extraArgs...)
-	return err
+	return withResource(err, "repository settings")
 }
 
 // SetTopics sets repository topics
 func (c *Client) SetTopics(ctx context.Context, topics []string) error {
-	payload := struct {
+	_, err := c.callJSON(ctx, httpPut, c.repoPath("topics"), topicsPayload(topics))
+	return withResource(err, "topics")
+}
+
+// topicsPayload builds the request body for replacing the topics
+func topicsPayload(topics []string) interface{} {
+	return struct {
 		Names []string `json:"names"`
 	}{Names: topics}
-	_, err := c.callJSON(ctx, httpPut, c.repoPath("topics"), payload)
-	return err
+}
+
+// SetSocialPreview uploads the repository's social preview (open graph) image
+func (c *Client) SetSocialPreview(ctx context.Context, image []byte) error {
+	body, contentType, err := socialPreviewPayload(image)
+	if err != nil {
+		return err
+	}
+	_, err = c.callAPI(ctx, httpPatch, c.repoPath(""), body, "-H", "Content-Type: "+contentType)
+	return withResource(err, "social preview image")
+}
+
+// socialPreviewPayload builds the multipart form body for a social preview upload.
+// It returns the body and the Content-Type header value including the boundary.
+func socialPreviewPayload(image []byte) ([]byte, string, error) {
+	imageType := http.DetectContentType(image)
+	ext := ""
+	switch imageType {
+	case "image/png":
+		ext = ".png"
+	case "image/jpeg":
+		ext = ".jpg"
+	case "image/gif":
+		ext = ".gif"
+	default:
+		return nil, "", fmt.Errorf("unsupported social preview image type: %s", imageType)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="image"; filename="social-preview`+ext+`"`)
+	header.Set("Content-Type", imageType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build social preview payload: %w", err)
+	}
+	if _, err := part.Write(image); err != nil {
+		return nil, "", fmt.Errorf("failed to build social preview payload: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to build social preview payload: %w", err)
+	}
+
+	return buf.Bytes(), writer.FormDataContentType(), nil
 }
@@ -0,0 +1,681 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/myzkey/gh-repo-settings/internal/config"
+	apperrors "github.com/myzkey/gh-repo-settings/internal/errors"
+)
+
+// recordedCall is a single gh invocation captured by recordingRunner
+type recordedCall struct {
+	Args  []string
+	Stdin []byte
+}
+
+// recordingRunner records gh invocations and returns canned responses keyed by endpoint.
+// A "METHOD endpoint" key (e.g. "GET repos/owner/repo") takes precedence over the bare endpoint.
+type recordingRunner struct {
+	Calls     []recordedCall
+	Responses map[string]string
+	Stderr    map[string]string
+}
+
+func newRecordingRunner() *recordingRunner {
+	return &recordingRunner{
+		Responses: make(map[string]string),
+		Stderr:    make(map[string]string),
+	}
+}
+
+func (r *recordingRunner) run(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	r.Calls = append(r.Calls, recordedCall{Args: args, Stdin: stdin})
+	if len(args) < 2 {
+		return nil, nil
+	}
+	endpoint := args[1]
+	method := "GET"
+	for i := 2; i < len(args)-1; i++ {
+		if args[i] == "-X" {
+			method = args[i+1]
+		}
+	}
+	for _, key := range []string{method + " " + endpoint, endpoint} {
+		if stderr, ok := r.Stderr[key]; ok {
+			return nil, &exec.ExitError{Stderr: []byte(stderr)}
+		}
+		if response, ok := r.Responses[key]; ok {
+			return []byte(response), nil
+		}
+	}
+	return nil, nil
+}
+
+// client returns a Client wired to the recording runner
+func (r *recordingRunner) client() *Client {
+	return &Client{
+		Repo:   RepoInfo{Owner: "owner", Name: "repo"},
+		runner: r.run,
+	}
+}
+
+func TestGetRepo_NotFound(t *testing.T) {
+	runner := newRecordingRunner()
+	runner.Stderr["repos/owner/repo"] = "gh: Not Found (HTTP 404)"
+
+	_, err := runner.client().GetRepo(context.Background())
+	if !apperrors.Is(err, apperrors.ErrRepoNotFound) {
+		t.Errorf("expected ErrRepoNotFound, got %v", err)
+	}
+}
+
+func TestCreateRepo(t *testing.T) {
+	tests := []struct {
+		name         string
+		owner        string
+		login        string
+		wantEndpoint string
+	}{
+		{
+			name:         "owner is authenticated user",
+			owner:        "octocat",
+			login:        "octocat",
+			wantEndpoint: "user/repos",
+		},
+		{
+			name:         "owner matches case-insensitively",
+			owner:        "OctoCat",
+			login:        "octocat",
+			wantEndpoint: "user/repos",
+		},
+		{
+			name:         "owner is organization",
+			owner:        "my-org",
+			login:        "octocat",
+			wantEndpoint: "orgs/my-org/repos",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := newRecordingRunner()
+			runner.Responses["user"] = `{"login": "` + tt.login + `"}`
+
+			desc := "bootstrapped"
+			visibility := "private"
+			cfg := &config.RepoConfig{Description: &desc, Visibility: &visibility}
+
+			if err := runner.client().CreateRepo(context.Background(), tt.owner, "new-repo", cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(runner.Calls) != 2 {
+				t.Fatalf("expected 2 gh calls, got %d", len(runner.Calls))
+			}
+			create := runner.Calls[1]
+			if create.Args[1] != tt.wantEndpoint {
+				t.Errorf("endpoint = %q, want %q", create.Args[1], tt.wantEndpoint)
+			}
+
+			var payload map[string]interface{}
+			if err := json.Unmarshal(create.Stdin, &payload); err != nil {
+				t.Fatalf("failed to decode payload: %v", err)
+			}
+			if payload["name"] != "new-repo" {
+				t.Errorf("name = %v, want new-repo", payload["name"])
+			}
+			if payload["description"] != desc {
+				t.Errorf("description = %v, want %s", payload["description"], desc)
+			}
+			if payload["private"] != true {
+				t.Errorf("private = %v, want true", payload["private"])
+			}
+		})
+	}
+}
+
+func TestCreateRepoPayload_NilConfig(t *testing.T) {
+	payload := createRepoPayload("repo", nil)
+	if len(payload) != 1 || payload["name"] != "repo" {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+}
+
+func TestUpdateLabelDescription(t *testing.T) {
+	empty := ""
+	set := "Something isn't working"
+
+	tests := []struct {
+		name        string
+		description *string
+		want        interface{} // nil means the field is omitted
+	}{
+		{name: "unmanaged description is omitted", description: nil, want: nil},
+		{name: "empty description clears it", description: &empty, want: ""},
+		{name: "description is set", description: &set, want: set},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := newRecordingRunner()
+			if err := runner.client().UpdateLabel(context.Background(), "bug", "bug", "d73a4a", tt.description); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var payload map[string]interface{}
+			if err := json.Unmarshal(runner.Calls[0].Stdin, &payload); err != nil {
+				t.Fatalf("failed to decode payload: %v", err)
+			}
+			got, ok := payload["description"]
+			if tt.want == nil {
+				if ok {
+					t.Errorf("description = %q, want it omitted", got)
+				}
+				return
+			}
+			if !ok || got != tt.want {
+				t.Errorf("description = %v (present: %v), want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateLabelOrRecreate(t *testing.T) {
+	// requests lists the calls as "METHOD endpoint"
+	requests := func(runner *recordingRunner) []string {
+		var out []string
+		for _, call := range runner.Calls {
+			method := "GET"
+			for i, arg := range call.Args {
+				if arg == "-X" {
+					method = call.Args[i+1]
+				}
+			}
+			out = append(out, method+" "+call.Args[1])
+		}
+		return out
+	}
+
+	t.Run("422 recreates the label", func(t *testing.T) {
+		runner := newRecordingRunner()
+		runner.Stderr["PATCH repos/owner/repo/labels/bug"] = "gh: Validation Failed (HTTP 422)"
+		runner.Responses["GET repos/owner/repo/labels"] = `[{"name":"bug","color":"ffffff","description":"Broken"}]`
+
+		recreated, err := UpdateLabelOrRecreate(context.Background(), runner.client(), "bug", "Bug", "d73a4a", nil)
+		if err != nil || !recreated {
+			t.Fatalf("UpdateLabelOrRecreate() = %v, %v; want recreated", recreated, err)
+		}
+
+		want := []string{
+			"PATCH repos/owner/repo/labels/bug",
+			"GET repos/owner/repo/labels",
+			"DELETE repos/owner/repo/labels/bug",
+			"POST repos/owner/repo/labels",
+		}
+		if got := requests(runner); !reflect.DeepEqual(got, want) {
+			t.Fatalf("requests = %v, want %v", got, want)
+		}
+		var payload map[string]string
+		if err := json.Unmarshal(runner.Calls[3].Stdin, &payload); err != nil {
+			t.Fatalf("failed to decode payload: %v", err)
+		}
+		// The unmanaged description is carried over
+		if payload["name"] != "Bug" || payload["color"] != "d73a4a" || payload["description"] != "Broken" {
+			t.Errorf("create payload = %v", payload)
+		}
+	})
+
+	t.Run("other errors are returned", func(t *testing.T) {
+		runner := newRecordingRunner()
+		runner.Stderr["PATCH repos/owner/repo/labels/bug"] = "gh: Not Found (HTTP 404)"
+
+		recreated, err := UpdateLabelOrRecreate(context.Background(), runner.client(), "bug", "Bug", "d73a4a", nil)
+		if err == nil || recreated {
+			t.Fatalf("UpdateLabelOrRecreate() = %v, %v; want the 404", recreated, err)
+		}
+		if len(runner.Calls) != 1 {
+			t.Errorf("requests = %v, want only the update", requests(runner))
+		}
+	})
+
+	t.Run("failed create reports the deletion", func(t *testing.T) {
+		runner := newRecordingRunner()
+		runner.Stderr["PATCH repos/owner/repo/labels/bug"] = "gh: Validation Failed (HTTP 422)"
+		runner.Stderr["POST repos/owner/repo/labels"] = "gh: Validation Failed (HTTP 422)"
+		description := ""
+
+		recreated, err := UpdateLabelOrRecreate(context.Background(), runner.client(), "bug", "Bug", "d73a4a", &description)
+		if err == nil || !recreated || !strings.Contains(err.Error(), "was deleted but could not be recreated") {
+			t.Fatalf("UpdateLabelOrRecreate() = %v, %v", recreated, err)
+		}
+	})
+}
+
+func TestIsLabelRecreatable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"label update 422", apperrors.NewAPIError("PATCH", "repos/o/r/labels/bug", 422, "Validation Failed", nil), true},
+		{"label create 422", apperrors.NewAPIError("POST", "repos/o/r/labels", 422, "Validation Failed", nil), false},
+		{"label update 403", apperrors.NewAPIError("PATCH", "repos/o/r/labels/bug", 403, "Forbidden", nil), false},
+		{"repo update 422", apperrors.NewAPIError("PATCH", "repos/o/r", 422, "Validation Failed", nil), false},
+		{"not an API error", fmt.Errorf("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsLabelRecreatable(tt.err); got != tt.want {
+				t.Errorf("IsLabelRecreatable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdatePagesCustomDomain(t *testing.T) {
+	empty := ""
+	domain := "docs.example.com"
+	enforced := true
+
+	tests := []struct {
+		name          string
+		cname         *string
+		httpsEnforced *bool
+		want          map[string]interface{}
+		omitted       []string
+	}{
+		{name: "unmanaged settings are omitted", omitted: []string{"cname", "https_enforced"}},
+		{name: "custom domain is set", cname: &domain, want: map[string]interface{}{"cname": domain}, omitted: []string{"https_enforced"}},
+		{name: "empty custom domain removes it", cname: &empty, want: map[string]interface{}{"cname": nil}},
+		{name: "https is enforced", httpsEnforced: &enforced, want: map[string]interface{}{"https_enforced": true}, omitted: []string{"cname"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := newRecordingRunner()
+			if err := runner.client().UpdatePages(context.Background(), "workflow", nil, tt.cname, tt.httpsEnforced); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var payload map[string]interface{}
+			if err := json.Unmarshal(runner.Calls[0].Stdin, &payload); err != nil {
+				t.Fatalf("failed to decode payload: %v", err)
+			}
+			for key, want := range tt.want {
+				got, ok := payload[key]
+				if !ok || got != want {
+					t.Errorf("%s = %v (present: %v), want %v", key, got, ok, want)
+				}
+			}
+			for _, key := range tt.omitted {
+				if got, ok := payload[key]; ok {
+					t.Errorf("%s = %v, want it omitted", key, got)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdatePagesHTTPSBeforeCertificate(t *testing.T) {
+	runner := newRecordingRunner()
+	runner.Stderr["repos/owner/repo/pages"] = "gh: The certificate does not exist yet (HTTP 404)"
+
+	enforced := true
+	err := runner.client().UpdatePages(context.Background(), "workflow", nil, nil, &enforced)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	var apiErr *apperrors.APIError
+	if !apperrors.As(err, &apiErr) || apiErr.StatusCode != 404 {
+		t.Fatalf("expected wrapped 404 APIError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "certificate for the custom domain") {
+		t.Errorf("expected a hint about the certificate, got %q", err.Error())
+	}
+}
+
+func TestMutatingCalls_SurfaceStatusCode(t *testing.T) {
+	const validationFailed = "gh: Validation Failed (HTTP 422)"
+
+	tests := []struct {
+		name         string
+		endpoint     string
+		call         func(c *Client) error
+		wantResource string
+	}{
+		{
+			name:         "CreateLabel",
+			endpoint:     "repos/owner/repo/labels",
+			call:         func(c *Client) error { return c.CreateLabel(context.Background(), "bug", "d73a4a", "") },
+			wantResource: "label 'bug'",
+		},
+		{
+			name:     "UpdateLabel",
+			endpoint: "repos/owner/repo/labels/bug",
+			call: func(c *Client) error {
+				return c.UpdateLabel(context.Background(), "bug", "bug", "d73a4a", nil)
+			},
+			wantResource: "label 'bug'",
+		},
+		{
+			name:         "SetTopics",
+			endpoint:     "repos/owner/repo/topics",
+			call:         func(c *Client) error { return c.SetTopics(context.Background(), []string{"Go"}) },
+			wantResource: "topics",
+		},
+		{
+			name:     "UpdateBranchProtection",
+			endpoint: "repos/owner/repo/branches/main/protection",
+			call: func(c *Client) error {
+				return c.UpdateBranchProtection(context.Background(), "main", &BranchProtectionSettings{})
+			},
+			wantResource: "branch protection for 'main'",
+		},
+		{
+			name:         "UpdatePages",
+			endpoint:     "repos/owner/repo/pages",
+			call:         func(c *Client) error { return c.UpdatePages(context.Background(), "workflow", nil, nil, nil) },
+			wantResource: "pages",
+		},
+		{
+			name:         "SetSecret",
+			endpoint:     "set",
+			call:         func(c *Client) error { return c.SetSecret(context.Background(), "TOKEN", "value") },
+			wantResource: "secret 'TOKEN'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := newRecordingRunner()
+			runner.Stderr[tt.endpoint] = validationFailed
+
+			err := tt.call(runner.client())
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			var apiErr *apperrors.APIError
+			if !apperrors.As(err, &apiErr) {
+				t.Fatalf("expected APIError, got %T: %v", err, err)
+			}
+			if apiErr.StatusCode != 422 {
+				t.Errorf("StatusCode = %d, want 422", apiErr.StatusCode)
+			}
+			if apiErr.Resource != tt.wantResource {
+				t.Errorf("Resource = %q, want %q", apiErr.Resource, tt.wantResource)
+			}
+			want := tt.wantResource + ": 422 Validation Failed"
+			if err.Error() != want {
+				t.Errorf("Error() = %q, want %q", err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   string
+	}{
+		{"status suffix removed", "gh: Validation Failed (HTTP 422)", "Validation Failed"},
+		{"trailing newline", "gh: Not Found (HTTP 404)\n", "Not Found"},
+		{"multiline", "gh: Validation Failed (HTTP 422)\nName already exists", "Validation Failed; Name already exists"},
+		{"no prefix", "some other error", "some other error"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := apiErrorMessage(tt.stderr); got != tt.want {
+				t.Errorf("apiErrorMessage(%q) = %q, want %q", tt.stderr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListBranches(t *testing.T) {
+	runner := newRecordingRunner()
+	runner.Responses["repos/owner/repo/branches?per_page=100"] = `[{"name": "main", "protected": true}, {"name": "develop", "protected": false}]`
+
+	branches, err := runner.client().ListBranches(context.Background())
+	if err != nil {
+		t.Fatalf("ListBranches() error = %v", err)
+	}
+	want := []BranchData{{Name: "main", Protected: true}, {Name: "develop"}}
+	if !reflect.DeepEqual(branches, want) {
+		t.Errorf("ListBranches() = %+v, want %+v", branches, want)
+	}
+	if args := runner.Calls[0].Args; !containsArg(args, "--paginate") {
+		t.Errorf("args = %v, want --paginate", args)
+	}
+}
+
+func TestBranchExists(t *testing.T) {
+	runner := newRecordingRunner()
+	runner.Responses["repos/owner/repo/branches/main"] = `{"name": "main"}`
+	runner.Stderr["repos/owner/repo/branches/develop"] = "gh: Branch not found (HTTP 404)"
+	runner.Stderr["repos/owner/repo/branches/secret"] = "gh: Forbidden (HTTP 403)"
+	client := runner.client()
+
+	exists, err := client.BranchExists(context.Background(), "main")
+	if err != nil || !exists {
+		t.Errorf("BranchExists(main) = %v, %v; want true, nil", exists, err)
+	}
+
+	exists, err = client.BranchExists(context.Background(), "develop")
+	if err != nil || exists {
+		t.Errorf("BranchExists(develop) = %v, %v; want false, nil", exists, err)
+	}
+
+	if _, err := client.BranchExists(context.Background(), "secret"); err == nil {
+		t.Error("BranchExists(secret) should return error for 403")
+	}
+}
+
+func TestSetSocialPreview(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	runner := newRecordingRunner()
+	if err := runner.client().SetSocialPreview(context.Background(), png); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(runner.Calls) != 1 {
+		t.Fatalf("expected 1 gh call, got %d", len(runner.Calls))
+	}
+	call := runner.Calls[0]
+	if call.Args[1] != "repos/owner/repo" {
+		t.Errorf("endpoint = %q, want repos/owner/repo", call.Args[1])
+	}
+
+	var contentType string
+	for i, arg := range call.Args {
+		if arg == "-X" && call.Args[i+1] != "PATCH" {
+			t.Errorf("method = %q, want PATCH", call.Args[i+1])
+		}
+		if arg == "-H" && strings.HasPrefix(call.Args[i+1], "Content-Type: ") {
+			contentType = strings.TrimPrefix(call.Args[i+1], "Content-Type: ")
+		}
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("Content-Type = %q, want multipart/form-data", contentType)
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(call.Stdin), params["boundary"])
+	part, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("failed to read multipart body: %v", err)
+	}
+	if part.FormName() != "image" {
+		t.Errorf("form field = %q, want image", part.FormName())
+	}
+	if part.FileName() != "social-preview.png" {
+		t.Errorf("filename = %q, want social-preview.png", part.FileName())
+	}
+	if part.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("part Content-Type = %q, want image/png", part.Header.Get("Content-Type"))
+	}
+	data, err := io.ReadAll(part)
+	if err != nil {
+		t.Fatalf("failed to read image part: %v", err)
+	}
+	if !bytes.Equal(data, png) {
+		t.Error("uploaded image bytes mismatch")
+	}
+}
+
+func TestSocialPreviewPayload_UnsupportedType(t *testing.T) {
+	if _, _, err := socialPreviewPayload([]byte("<svg></svg>")); err == nil {
+		t.Error("expected error for non-image payload, got nil")
+	}
+}
+
+func TestPutFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		getFile string // Response for the GET; empty means 404
+		wantSHA interface{}
+	}{
+		{name: "create new file", wantSHA: nil},
+		{name: "update existing file", getFile: `{"path": ".github/ISSUE_TEMPLATE/bug report.md", "sha": "abc123"}`, wantSHA: "abc123"},
+	}
+
+	const endpoint = "repos/owner/repo/contents/.github/ISSUE_TEMPLATE/bug%20report.md"
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := newRecordingRunner()
+			if tt.getFile == "" {
+				runner.Stderr["GET "+endpoint] = "gh: Not Found (HTTP 404)"
+			} else {
+				runner.Responses["GET "+endpoint] = tt.getFile
+			}
+
+			err := runner.client().PutFile(context.Background(), ".github/ISSUE_TEMPLATE/bug report.md", []byte("hello\n"), "Sync templates")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(runner.Calls) != 2 {
+				t.Fatalf("expected 2 gh calls, got %d", len(runner.Calls))
+			}
+			put := runner.Calls[1]
+			if put.Args[1] != endpoint {
+				t.Errorf("endpoint = %q, want %q", put.Args[1], endpoint)
+			}
+
+			var payload map[string]interface{}
+			if err := json.Unmarshal(put.Stdin, &payload); err != nil {
+				t.Fatalf("failed to decode payload: %v", err)
+			}
+			if payload["message"] != "Sync templates" {
+				t.Errorf("message = %v", payload["message"])
+			}
+			if payload["content"] != "aGVsbG8K" {
+				t.Errorf("content = %v, want base64 of file", payload["content"])
+			}
+			if payload["sha"] != tt.wantSHA {
+				t.Errorf("sha = %v, want %v", payload["sha"], tt.wantSHA)
+			}
+		})
+	}
+}
+
+// sleepRunner runs a command that hangs like a stuck gh process
+func sleepRunner(t *testing.T) commandRunner {
+	t.Helper()
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep command not available")
+	}
+	return func(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+		return exec.CommandContext(ctx, "sleep", "10").Output()
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	client := &Client{Repo: RepoInfo{Owner: "owner", Name: "repo"}, runner: sleepRunner(t)}
+	client.SetTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	_, err := client.GetRepo(context.Background())
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("call wasn't stopped by the timeout, took %s", elapsed)
+	}
+
+	var timeoutErr *apperrors.TimeoutError
+	if !apperrors.As(err, &timeoutErr) {
+		t.Fatalf("expected TimeoutError, got %v", err)
+	}
+	if timeoutErr.Endpoint != "repos/owner/repo" {
+		t.Errorf("Endpoint = %q", timeoutErr.Endpoint)
+	}
+	if !apperrors.Is(err, apperrors.ErrTimeout) {
+		t.Error("expected error to match ErrTimeout")
+	}
+}
+
+func TestClientTimeoutCallerCancel(t *testing.T) {
+	client := &Client{Repo: RepoInfo{Owner: "owner", Name: "repo"}, runner: sleepRunner(t)}
+	client.SetTimeout(10 * time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := client.GetRepo(ctx)
+	if err == nil {
+		t.Fatal("expected error after cancellation")
+	}
+	if apperrors.Is(err, apperrors.ErrTimeout) {
+		t.Errorf("cancellation reported as timeout: %v", err)
+	}
+}
+
+func TestFetchContents(t *testing.T) {
+	runner := newRecordingRunner()
+	runner.Responses["repos/acme/settings/contents/base.yaml?ref=v1"] = `{"type":"file"}`
+	runner.Stderr["repos/acme/settings/contents/missing.yaml"] = "gh: Not Found (HTTP 404)"
+	client := runner.client()
+
+	body, err := client.fetchContents(context.Background(), "repos/acme/settings/contents/base.yaml?ref=v1")
+	if err != nil || string(body) != `{"type":"file"}` {
+		t.Errorf("fetchContents() = %q, %v", body, err)
+	}
+	if _, err := client.fetchContents(context.Background(), "repos/acme/settings/contents/missing.yaml"); !apperrors.Is(err, apperrors.ErrFileNotFound) {
+		t.Errorf("expected ErrFileNotFound, got %v", err)
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		client := &Client{runner: sleepRunner(t), timeout: 50 * time.Millisecond}
+		_, err := client.fetchContents(context.Background(), "repos/acme/settings/contents/base.yaml")
+		if !apperrors.Is(err, apperrors.ErrTimeout) {
+			t.Errorf("expected ErrTimeout, got %v", err)
+		}
+	})
+}
+
+func TestCommandEndpoint(t *testing.T) {
+	tests := map[string][]string{
+		"repos/owner/repo":      {"api", "repos/owner/repo", "-X", "PATCH"},
+		"gh secret set API_KEY": {"secret", "set", "API_KEY", "--repo", "owner/repo", "--body", "s3cret"},
+	}
+	for want, args := range tests {
+		if got := commandEndpoint(args); got != want {
+			t.Errorf("commandEndpoint(%v) = %q, want %q", args, got, want)
+		}
+	}
+}
@@ -29,13 +29,15 @@ func (c *Client) GetSecrets(ctx context.Context) ([]string, error) {
 // SetSecret creates or updates a repository secret using gh secret set
 func (c *Client) SetSecret(ctx context.Context, name, value string) error {
 	repo := fmt.Sprintf("%s/%s", c.Repo.Owner, c.Repo.Name)
-	cmd := exec.CommandContext(ctx, "gh", "secret", "set", name, "--repo", repo, "--body", value)
-	_, err := cmd.Output()
+	_, err := c.run(ctx, nil, "secret", "set", name, "--repo", repo, "--body", value)
 	if err != nil {
+		endpoint := c.repoPath(secretPath(name))
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			return apperrors.NewAPIError("SET", "secret/"+name, exitErr.ExitCode(), string(exitErr.Stderr), err)
+			stderr := string(exitErr.Stderr)
+			apiErr := apperrors.NewAPIError(string(httpPut), endpoint, parseHTTPStatus(stderr), apiErrorMessage(stderr), err)
+			return withResource(apiErr, secretResource(name))
 		}
-		return err
+		return withResource(apperrors.NewAPIError(string(httpPut), endpoint, 0, err.Error(), err), secretResource(name))
 	}
 	return nil
 }
@@ -43,7 +45,7 @@ func (c *Client) SetSecret(ctx context.Context, name, value string) error {
 // DeleteSecret deletes a repository secret
 func (c *Client) DeleteSecret(ctx context.Context, name string) error {
 	_, err := c.callAPI(ctx, httpDelete, c.repoPath(secretPath(name)), nil)
-	return err
+	return withResource(err, secretResource(name))
 }
 
 // GetVariables fetches repository variables with their values
@@ -63,10 +65,7 @@ func (c *Client) SetVariable(ctx context.Context, name, value string) error {
 	varEndpoint := c.repoPath(variablePath(name))
 	_, getErr := c.callAPI(ctx, httpGet, varEndpoint, nil)
 
-	payload := map[string]string{
-		"name":  name,
-		"value": value,
-	}
+	payload := variablePayload(name, value)
 
 	if getErr != nil {
 		// Check if it's a 404 (not found) error
@@ -74,19 +73,37 @@ func (c *Client) SetVariable(ctx context.Context, name, value string) error {
 		if apperrors.As(getErr, &apiErr) && apiErr.StatusCode == 404 {
 			// Variable doesn't exist, create it
 			_, err := c.callJSON(ctx, httpPost, c.repoPath("actions/variables"), payload)
-			return err
+			return withResource(err, variableResource(name))
 		}
 		// Other error (permission denied, rate limited, etc.)
-		return fmt.Errorf("failed to check variable existence: %w", getErr)
+		return fmt.Errorf("failed to check variable existence: %w", withResource(getErr, variableResource(name)))
 	}
 
 	// Variable exists, update it
 	_, err := c.callJSON(ctx, httpPatch, varEndpoint, payload)
-	return err
+	return withResource(err, variableResource(name))
 }
 
 // DeleteVariable deletes a repository variable
 func (c *Client) DeleteVariable(ctx context.Context, name string) error {
 	_, err := c.callAPI(ctx, httpDelete, c.repoPath(variablePath(name)), nil)
-	return err
+	return withResource(err, variableResource(name))
+}
+
+// variablePayload builds the request body for creating or updating a variable
+func variablePayload(name, value string) map[string]string {
+	return map[string]string{
+		"name":  name,
+		"value": value,
+	}
+}
+
+// secretResource describes a secret for error messages
+func secretResource(name string) string {
+	return fmt.Sprintf("secret '%s'", name)
+}
+
+// variableResource describes a variable for error messages
+func variableResource(name string) string {
+	return fmt.Sprintf("variable '%s'", name)
 }
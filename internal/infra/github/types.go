@@ -34,6 +34,38 @@ type PagesSourceData = githubopenapi.PagesSourceHash
 // VariableData is an alias for the generated ActionsVariable type.
 type VariableData = githubopenapi.ActionsVariable
 
+// OrgActionsPermissionsData represents organization Actions permissions.
+// The generated OpenAPI subset doesn't include org endpoints, so this is hand-written.
+type OrgActionsPermissionsData struct {
+	EnabledRepositories string  `json:"enabled_repositories"`
+	AllowedActions      *string `json:"allowed_actions,omitempty"`
+}
+
+// BranchData is a branch in a repository's branch list
+type BranchData struct {
+	Name      string `json:"name"`
+	Protected bool   `json:"protected"`
+}
+
+// OrgRepoData is a repository in an organization's repository list
+type OrgRepoData struct {
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
+}
+
+// ActionsAccessData represents who outside a private repository may use its actions and workflows.
+// The generated OpenAPI subset doesn't include this endpoint, so this is hand-written.
+type ActionsAccessData struct {
+	AccessLevel string `json:"access_level"`
+}
+
+// ActionsRetentionData represents how long workflow artifacts and logs are kept.
+// The generated OpenAPI subset doesn't include this endpoint, so this is hand-written.
+type ActionsRetentionData struct {
+	Days               int `json:"days"`
+	MaximumAllowedDays int `json:"maximum_allowed_days,omitempty"`
+}
+
 // CurrentSettings represents the current GitHub repository settings for JSON output.
 // This is a custom type for export functionality, not from OpenAPI.
 type CurrentSettings struct {
@@ -49,14 +81,18 @@ type CurrentSettings struct {
 
 // CurrentRepoSettings represents current repository settings for export.
 type CurrentRepoSettings struct {
-	Description         string `json:"description,omitempty"`
-	Homepage            string `json:"homepage,omitempty"`
-	Visibility          string `json:"visibility"`
-	AllowMergeCommit    bool   `json:"allow_merge_commit"`
-	AllowRebaseMerge    bool   `json:"allow_rebase_merge"`
-	AllowSquashMerge    bool   `json:"allow_squash_merge"`
-	DeleteBranchOnMerge bool   `json:"delete_branch_on_merge"`
-	AllowUpdateBranch   bool   `json:"allow_update_branch"`
+	Description              string `json:"description,omitempty"`
+	Homepage                 string `json:"homepage,omitempty"`
+	Visibility               string `json:"visibility"`
+	AllowMergeCommit         bool   `json:"allow_merge_commit"`
+	AllowRebaseMerge         bool   `json:"allow_rebase_merge"`
+	AllowSquashMerge         bool   `json:"allow_squash_merge"`
+	DeleteBranchOnMerge      bool   `json:"delete_branch_on_merge"`
+	AllowUpdateBranch        bool   `json:"allow_update_branch"`
+	AllowAutoMerge           bool   `json:"allow_auto_merge"`
+	UseSquashPRTitle         bool   `json:"use_squash_pr_title_as_default"`
+	WebCommitSignoffRequired bool   `json:"web_commit_signoff_required"`
+	IsTemplate               bool   `json:"is_template"`
 }
 
 // CurrentBranchRule represents current branch protection rule for export.
@@ -80,3 +116,10 @@ type CurrentActionsSettings struct {
 	DefaultWorkflowPermissions   string `json:"default_workflow_permissions,omitempty"`
 	CanApprovePullRequestReviews *bool  `json:"can_approve_pull_request_reviews,omitempty"`
 }
+
+// FileData represents file metadata from the repository contents API.
+// The generated OpenAPI subset doesn't include contents endpoints, so this is hand-written.
+type FileData struct {
+	Path string `json:"path"`
+	SHA  string `json:"sha"` // Git blob SHA of the file content
+}
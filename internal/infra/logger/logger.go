@@ -1,9 +1,13 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -17,11 +21,43 @@ const (
 	LevelVerbose
 )
 
+// Format selects how log entries are written
+type Format int
+
+const (
+	// FormatText writes human-readable lines
+	FormatText Format = iota
+	// FormatJSON writes one JSON object per entry (JSON lines)
+	FormatJSON
+)
+
+// ParseFormat parses a --log-format value
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	}
+	return FormatText, fmt.Errorf("invalid log format %q (must be text or json)", s)
+}
+
 // Logger provides structured logging with levels
 type Logger struct {
 	level  Level
+	format Format
+	fields map[string]interface{}
 	out    io.Writer
 	errOut io.Writer
+	now    func() time.Time
+}
+
+// jsonEntry is a log entry in FormatJSON
+type jsonEntry struct {
+	Timestamp string                 `json:"timestamp"`
+	Level     string                 `json:"level"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
 }
 
 // global logger instance
@@ -33,6 +69,7 @@ func New(level Level) *Logger {
 		level:  level,
 		out:    os.Stdout,
 		errOut: os.Stderr,
+		now:    time.Now,
 	}
 }
 
@@ -41,6 +78,57 @@ func (l *Logger) SetLevel(level Level) {
 	l.level = level
 }
 
+// SetFormat sets the output format
+func (l *Logger) SetFormat(format Format) {
+	l.format = format
+}
+
+// WithFields returns a copy of the logger that adds fields to every entry.
+// JSON entries carry them in "fields"; text lines end with sorted key=value pairs.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	child := *l
+	child.fields = make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		child.fields[k] = v
+	}
+	for k, v := range fields {
+		child.fields[k] = v
+	}
+	return &child
+}
+
+// writeJSON writes a single JSON log entry; surrounding whitespace is dropped from the message
+func (l *Logger) writeJSON(w io.Writer, level, msg string) {
+	data, err := json.Marshal(jsonEntry{
+		Timestamp: l.now().UTC().Format(time.RFC3339Nano),
+		Level:     level,
+		Message:   strings.TrimSpace(msg),
+		Fields:    l.fields,
+	})
+	if err != nil {
+		// Fields that can't be encoded shouldn't lose the message
+		data, _ = json.Marshal(jsonEntry{Timestamp: l.now().UTC().Format(time.RFC3339Nano), Level: level, Message: strings.TrimSpace(msg)})
+	}
+	_, _ = fmt.Fprintln(w, string(data))
+}
+
+// textFields renders fields for text output
+func (l *Logger) textFields() string {
+	if len(l.fields) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(l.fields))
+	for k := range l.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, l.fields[k])
+	}
+	return b.String()
+}
+
 // SetOutput sets the output writer
 func (l *Logger) SetOutput(w io.Writer) {
 	l.out = w
@@ -54,43 +142,73 @@ func (l *Logger) SetErrorOutput(w io.Writer) {
 // Debug prints debug messages (only in verbose mode)
 func (l *Logger) Debug(format string, args ...interface{}) {
 	if l.level >= LevelVerbose {
+		msg := fmt.Sprintf(format, args...)
+		if l.format == FormatJSON {
+			l.writeJSON(l.out, "debug", msg)
+			return
+		}
 		gray := color.New(color.FgHiBlack).SprintFunc()
-		_, _ = fmt.Fprintf(l.out, gray("[DEBUG] "+format)+"\n", args...)
+		_, _ = fmt.Fprintln(l.out, gray("[DEBUG] "+msg)+l.textFields())
 	}
 }
 
 // Info prints info messages (normal and verbose mode)
 func (l *Logger) Info(format string, args ...interface{}) {
 	if l.level >= LevelNormal {
-		_, _ = fmt.Fprintf(l.out, format+"\n", args...)
+		msg := fmt.Sprintf(format, args...)
+		if l.format == FormatJSON {
+			l.writeJSON(l.out, "info", msg)
+			return
+		}
+		_, _ = fmt.Fprintln(l.out, msg+l.textFields())
 	}
 }
 
 // Success prints success messages with green checkmark
 func (l *Logger) Success(format string, args ...interface{}) {
 	if l.level >= LevelNormal {
+		msg := fmt.Sprintf(format, args...)
+		if l.format == FormatJSON {
+			l.writeJSON(l.out, "info", msg)
+			return
+		}
 		green := color.New(color.FgGreen).SprintFunc()
-		_, _ = fmt.Fprintf(l.out, green("✓")+" "+format+"\n", args...)
+		_, _ = fmt.Fprintln(l.out, green("✓")+" "+msg+l.textFields())
 	}
 }
 
 // Warn prints warning messages (always shown except in quiet mode)
 func (l *Logger) Warn(format string, args ...interface{}) {
 	if l.level >= LevelNormal {
+		msg := fmt.Sprintf(format, args...)
+		if l.format == FormatJSON {
+			l.writeJSON(l.errOut, "warn", msg)
+			return
+		}
 		yellow := color.New(color.FgYellow).SprintFunc()
-		_, _ = fmt.Fprintf(l.errOut, yellow("⚠")+" "+format+"\n", args...)
+		_, _ = fmt.Fprintln(l.errOut, yellow("⚠")+" "+msg+l.textFields())
 	}
 }
 
 // Error prints error messages (always shown)
 func (l *Logger) Error(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if l.format == FormatJSON {
+		l.writeJSON(l.errOut, "error", msg)
+		return
+	}
 	red := color.New(color.FgRed).SprintFunc()
-	_, _ = fmt.Fprintf(l.errOut, red("✗")+" "+format+"\n", args...)
+	_, _ = fmt.Fprintln(l.errOut, red("✗")+" "+msg+l.textFields())
 }
 
-// Print prints messages without any formatting
+// Print prints messages without any formatting.
+// In JSON format every call is an entry of its own, and blank ones are dropped.
 func (l *Logger) Print(format string, args ...interface{}) {
 	if l.level >= LevelNormal {
+		if l.format == FormatJSON {
+			l.printJSON(fmt.Sprintf(format, args...))
+			return
+		}
 		_, _ = fmt.Fprintf(l.out, format, args...)
 	}
 }
@@ -98,6 +216,10 @@ func (l *Logger) Print(format string, args ...interface{}) {
 // Println prints messages with newline
 func (l *Logger) Println(args ...interface{}) {
 	if l.level >= LevelNormal {
+		if l.format == FormatJSON {
+			l.printJSON(fmt.Sprint(args...))
+			return
+		}
 		_, _ = fmt.Fprintln(l.out, args...)
 	}
 }
@@ -105,6 +227,10 @@ func (l *Logger) Println(args ...interface{}) {
 // Progress prints inline progress (no newline)
 func (l *Logger) Progress(format string, args ...interface{}) {
 	if l.level >= LevelNormal {
+		if l.format == FormatJSON {
+			l.printJSON(fmt.Sprintf(format, args...))
+			return
+		}
 		_, _ = fmt.Fprintf(l.out, format, args...)
 	}
 }
@@ -112,6 +238,10 @@ func (l *Logger) Progress(format string, args ...interface{}) {
 // ProgressDone completes a progress line with success
 func (l *Logger) ProgressDone() {
 	if l.level >= LevelNormal {
+		if l.format == FormatJSON {
+			l.writeJSON(l.out, "info", "done")
+			return
+		}
 		green := color.New(color.FgGreen).SprintFunc()
 		_, _ = fmt.Fprintln(l.out, green("✓"))
 	}
@@ -120,11 +250,22 @@ func (l *Logger) ProgressDone() {
 // ProgressFail completes a progress line with failure
 func (l *Logger) ProgressFail() {
 	if l.level >= LevelNormal {
+		if l.format == FormatJSON {
+			l.writeJSON(l.out, "error", "failed")
+			return
+		}
 		red := color.New(color.FgRed).SprintFunc()
 		_, _ = fmt.Fprintln(l.out, red("✗"))
 	}
 }
 
+// printJSON writes unformatted output as an info entry unless it is blank
+func (l *Logger) printJSON(msg string) {
+	if strings.TrimSpace(msg) != "" {
+		l.writeJSON(l.out, "info", msg)
+	}
+}
+
 // Global functions that use defaultLogger
 
 // SetDefaultLevel sets the default logger level
@@ -132,6 +273,16 @@ func SetDefaultLevel(level Level) {
 	defaultLogger.SetLevel(level)
 }
 
+// SetDefaultFormat sets the default logger format
+func SetDefaultFormat(format Format) {
+	defaultLogger.SetFormat(format)
+}
+
+// DefaultLevel returns the default logger level
+func DefaultLevel() Level {
+	return defaultLogger.level
+}
+
 // Default returns the default logger
 func Default() *Logger {
 	return defaultLogger
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/fatih/color"
 )
 
 func TestNew(t *testing.T) {
@@ -446,3 +449,82 @@ func TestLevelConstants(t *testing.T) {
 		t.Errorf("LevelVerbose = %d, want 2", LevelVerbose)
 	}
 }
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]Format{"": FormatText, "text": FormatText, "json": FormatJSON} {
+		got, err := ParseFormat(input)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestFormats(t *testing.T) {
+	// logCalls makes the same log calls in every format
+	logCalls := func(l *Logger) {
+		l.Debug("fetching %s", "labels")
+		l.Info("Applying changes to %s...\n", "owner/repo")
+		l.WithFields(map[string]interface{}{"repo": "owner/repo", "changes": 2}).Success("applied")
+		l.Warn("skipping %s", "pages")
+		l.Error("failed")
+	}
+
+	t.Run("text", func(t *testing.T) {
+		color.NoColor = true
+		defer func() { color.NoColor = false }()
+
+		out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+		l := New(LevelVerbose)
+		l.SetOutput(out)
+		l.SetErrorOutput(errOut)
+		logCalls(l)
+
+		wantOut := "[DEBUG] fetching labels\nApplying changes to owner/repo...\n\n✓ applied changes=2 repo=owner/repo\n"
+		if out.String() != wantOut {
+			t.Errorf("stdout = %q, want %q", out.String(), wantOut)
+		}
+		wantErr := "⚠ skipping pages\n✗ failed\n"
+		if errOut.String() != wantErr {
+			t.Errorf("stderr = %q, want %q", errOut.String(), wantErr)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+		l := New(LevelVerbose)
+		l.SetFormat(FormatJSON)
+		l.SetOutput(out)
+		l.SetErrorOutput(errOut)
+		l.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+		logCalls(l)
+
+		wantOut := `{"timestamp":"2024-01-02T03:04:05Z","level":"debug","message":"fetching labels"}
+{"timestamp":"2024-01-02T03:04:05Z","level":"info","message":"Applying changes to owner/repo..."}
+{"timestamp":"2024-01-02T03:04:05Z","level":"info","message":"applied","fields":{"changes":2,"repo":"owner/repo"}}
+`
+		if out.String() != wantOut {
+			t.Errorf("stdout = %q, want %q", out.String(), wantOut)
+		}
+		wantErr := `{"timestamp":"2024-01-02T03:04:05Z","level":"warn","message":"skipping pages"}
+{"timestamp":"2024-01-02T03:04:05Z","level":"error","message":"failed"}
+`
+		if errOut.String() != wantErr {
+			t.Errorf("stderr = %q, want %q", errOut.String(), wantErr)
+		}
+	})
+
+	t.Run("json skips blank prints", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		l := New(LevelNormal)
+		l.SetFormat(FormatJSON)
+		l.SetOutput(out)
+		l.Println()
+		l.Print("\n")
+		if out.Len() != 0 {
+			t.Errorf("expected no output, got %q", out.String())
+		}
+	})
+}